@@ -2,14 +2,20 @@
 package main
 
 import (
+	"context"
 	"flag"
 	"fmt"
 	"log"
+	"log/slog"
+	"net"
 	"os"
 	"os/signal"
+	"strconv"
 	"syscall"
+	"time"
 
 	"github.com/MiFaceDEV/miface/internal/config"
+	"github.com/MiFaceDEV/miface/pkg/mediapipe"
 	"github.com/MiFaceDEV/miface/pkg/miface"
 )
 
@@ -28,6 +34,10 @@ func main() {
 	noMirror := flag.Bool("no-mirror", false, "Disable horizontal flip (mirror mode)")
 	preview := flag.Bool("preview", false, "Show camera preview window (debug mode)")
 	verbose := flag.Bool("verbose", false, "Enable verbose output")
+	watchConfig := flag.Bool("watch-config", false, "Reload configuration when the config file changes")
+	jsonOut := flag.Bool("json", false, "Write tracking data to stdout as JSON lines")
+	statusAddr := flag.String("status-addr", "", "Serve /healthz, /readyz and /stats on this address (e.g. 127.0.0.1:8080)")
+	simulate := flag.Bool("simulate", false, "Send synthetic tracking data without a camera (for CI and demos)")
 
 	flag.Usage = func() {
 		fmt.Fprintf(os.Stderr, "MiFace - Real-time facial and upper body tracking for VTubers\n\n")
@@ -40,6 +50,8 @@ func main() {
 		fmt.Fprintf(os.Stderr, "  %s -preview                 # Show camera preview window\n", os.Args[0])
 		fmt.Fprintf(os.Stderr, "  %s -vmc-port 39540          # Override VMC port\n", os.Args[0])
 		fmt.Fprintf(os.Stderr, "  %s -vrm model.vrm           # Calibrate with VRM model\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "  %s -config c.toml -watch-config  # Reload config on change\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "  %s -simulate -verbose       # Send synthetic data, no camera\n", os.Args[0])
 	}
 
 	flag.Parse()
@@ -55,36 +67,59 @@ func main() {
 		log.Fatalf("Failed to load config: %v", err)
 	}
 
-	// Apply command line overrides
-	if *vmcAddr != "" {
-		cfg.VMC.Address = *vmcAddr
+	if *watchConfig && *configPath == "" {
+		log.Fatalf("-watch-config requires -config")
 	}
-	if *vmcPort > 0 {
-		cfg.VMC.Port = *vmcPort
+
+	// The simulation replaces the camera, so camera options make no sense
+	if *simulate {
+		if *cameraID >= 0 {
+			log.Fatalf("-simulate cannot be used with -camera: the simulation replaces the camera")
+		}
+		if *preview {
+			log.Fatalf("-simulate cannot be used with -preview: there are no camera frames to show")
+		}
 	}
-	if *cameraID >= 0 {
-		cfg.Camera.DeviceID = *cameraID
+
+	// Apply command line overrides
+	applyOverrides := func(cfg *config.Config) {
+		if *vmcAddr != "" {
+			cfg.VMC.Address = *vmcAddr
+		}
+		if *vmcPort > 0 {
+			cfg.VMC.Port = *vmcPort
+		}
+		if *cameraID >= 0 {
+			cfg.Camera.DeviceID = *cameraID
+		}
 	}
+	applyOverrides(cfg)
 
 	if *verbose {
 		log.Printf("Configuration:")
 		log.Printf("  Camera: device=%d, %dx%d@%dfps",
 			cfg.Camera.DeviceID, cfg.Camera.Width, cfg.Camera.Height, cfg.Camera.FPS)
-		log.Printf("  Tracking: face=%v, hands=%v, pose=%v, smoothing=%.2f",
+		log.Printf("  Tracking: face=%v, hands=%v, pose=%v, smoothing=%s/%.2f",
 			cfg.Tracking.EnableFace, cfg.Tracking.EnableHands,
-			cfg.Tracking.EnablePose, cfg.Tracking.SmoothingFactor)
-		log.Printf("  VMC: enabled=%v, %s:%d",
-			cfg.VMC.Enabled, cfg.VMC.Address, cfg.VMC.Port)
+			cfg.Tracking.EnablePose, cfg.Tracking.SmoothingAlgorithm, cfg.Tracking.SmoothingFactor)
+		log.Printf("  VMC: enabled=%v, %s",
+			cfg.VMC.Enabled, net.JoinHostPort(cfg.VMC.Address, strconv.Itoa(cfg.VMC.Port)))
 	}
 
 	// Load VRM for calibration if provided
+	var retargeter *miface.Retargeter
 	if *vrmPath != "" {
 		skeleton, err := miface.LoadVRMSkeleton(*vrmPath)
 		if err != nil {
 			log.Fatalf("Failed to load VRM file: %v", err)
 		}
 
+		if missing := skeleton.ValidateHumanoid(); len(missing) > 0 {
+			log.Printf("Warning: VRM is missing required humanoid bones %v; proportions may be wrong", missing)
+		}
+
 		props := skeleton.GetProportions()
+		retargeter = miface.NewRetargeter(props)
 		if *verbose {
 			log.Printf("VRM skeleton loaded:")
 			log.Printf("  Bones: %d", len(skeleton.Bones))
@@ -110,45 +145,116 @@ func main() {
 	}
 	defer tracker.Close()
 
-	// Set up OpenCV camera
-	mirror := !*noMirror // Mirror enabled by default for VTubing
-	camera := miface.NewOpenCVCamera(mirror)
-	if err := camera.Open(cfg.Camera.DeviceID, cfg.Camera.Width, cfg.Camera.Height, cfg.Camera.FPS); err != nil {
-		log.Fatalf("Failed to open camera: %v", err)
-	}
-	if err := tracker.SetCameraSource(camera); err != nil {
-		log.Fatalf("Failed to set camera source: %v", err)
+	// Log tracker warnings, and lifecycle events in verbose mode
+	logLevel := slog.LevelWarn
+	if *verbose {
+		logLevel = slog.LevelInfo
 	}
+	tracker.SetLogger(slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: logLevel})))
 
-	// Log actual camera settings
-	actualWidth, actualHeight := camera.GetActualResolution()
-	actualFPS := camera.GetActualFPS()
-	if *verbose {
-		log.Printf("Camera opened: device=%d, resolution=%dx%d, fps=%d, mirror=%v",
-			cfg.Camera.DeviceID, actualWidth, actualHeight, actualFPS, mirror)
+	// Set up the data source: synthetic data or the OpenCV camera
+	var camera *miface.OpenCVCamera
+	if *simulate {
+		stubCfg := miface.DefaultStubConfig()
+		stubCfg.EnableFace = cfg.Tracking.EnableFace
+		stubCfg.EnableHands = cfg.Tracking.EnableHands
+		stubCfg.EnablePose = cfg.Tracking.EnablePose
+		if err := tracker.SetProcessor(miface.NewStubProcessor(stubCfg)); err != nil {
+			log.Fatalf("Failed to set stub processor: %v", err)
+		}
+		log.Printf("Simulating tracking data at %dfps (no camera)", cfg.Camera.FPS)
 	} else {
-		log.Printf("Camera opened: %dx%d@%dfps", actualWidth, actualHeight, actualFPS)
+		mirror := !*noMirror // Mirror enabled by default for VTubing
+		camera = miface.NewOpenCVCamera(mirror)
+		if err := camera.SetBufferSize(cfg.Camera.BufferSize); err != nil {
+			log.Fatalf("Failed to set camera buffer size: %v", err)
+		}
+		if err := camera.Open(cfg.Camera.DeviceID, cfg.Camera.Width, cfg.Camera.Height, cfg.Camera.FPS); err != nil {
+			log.Fatalf("Failed to open camera: %v", err)
+		}
+		if err := tracker.SetCameraSource(camera); err != nil {
+			log.Fatalf("Failed to set camera source: %v", err)
+		}
+
+		processor, err := mediapipe.NewProcessor(cfg)
+		if err != nil {
+			log.Fatalf("Failed to create processor: %v", err)
+		}
+		if _, ok := processor.(*miface.FallbackProcessor); ok {
+			log.Printf("MediaPipe bridge not built in, using the fallback processor (head position only)")
+		}
+		if err := tracker.SetProcessor(processor); err != nil {
+			log.Fatalf("Failed to set processor: %v", err)
+		}
+
+		// Log actual camera settings
+		actualWidth, actualHeight := camera.GetActualResolution()
+		actualFPS := camera.GetActualFPS()
+		if *verbose {
+			log.Printf("Camera opened: device=%d, resolution=%dx%d, fps=%d, mirror=%v",
+				cfg.Camera.DeviceID, actualWidth, actualHeight, actualFPS, mirror)
+		} else {
+			log.Printf("Camera opened: %dx%d@%dfps", actualWidth, actualHeight, actualFPS)
+		}
 	}
 
 	// Set up preview window if enabled
+	var previewDone <-chan struct{}
 	if *preview {
 		previewWindow := miface.NewPreviewWindow("MiFace Preview")
+		previewWindow.SetHUD(true)
+		previewWindow.RegisterKey('m', func() { camera.SetMirror(!camera.IsMirror()) })
+		previewWindow.RegisterKey('h', func() { previewWindow.SetHUD(!previewWindow.HUDEnabled()) })
+		previewWindow.RegisterKey('c', func() {
+			go func() {
+				log.Println("Calibrating neutral face, hold a relaxed expression...")
+				if err := tracker.CalibrateNeutral(3 * time.Second); err != nil {
+					log.Printf("Neutral calibration failed: %v", err)
+					return
+				}
+				log.Println("Neutral calibration done")
+			}()
+		})
 		if err := tracker.SetPreviewWindow(previewWindow); err != nil {
 			log.Fatalf("Failed to set preview window: %v", err)
 		}
-		log.Println("Preview window enabled")
+		previewDone = previewWindow.Done()
+		log.Println("Preview window enabled (m: mirror, h: HUD, c: calibrate neutral face, q: quit)")
+	}
+
+	// Return the avatar to a rest pose when tracking is lost
+	restPose := miface.DefaultRestPose()
+	if cfg.Tracking.RestPoseFile != "" {
+		restPose, err = miface.LoadRestPose(cfg.Tracking.RestPoseFile)
+		if err != nil {
+			log.Fatalf("Failed to load rest pose: %v", err)
+		}
+	}
+	tracker.SetRestPose(restPose, miface.DefaultRestPoseTimeout)
+
+	// Solve the arms for the avatar's proportions, including on VMC
+	// senders a config reload creates
+	if retargeter != nil {
+		tracker.SetRetargeter(retargeter)
 	}
 
 	// Set up VMC sender if enabled
 	if cfg.VMC.Enabled {
-		vmcSender, err := miface.NewVMCSender(cfg.VMC.Address, cfg.VMC.Port)
+		vmcSender, err := miface.NewVMCSenderFromConfig(cfg.VMC)
 		if err != nil {
-			log.Fatalf("Failed to create VMC sender: %v", err)
+			log.Fatalf("Failed to set up VMC sender: %v", err)
 		}
 		if err := tracker.SetVMCSender(vmcSender); err != nil {
 			log.Fatalf("Failed to set VMC sender: %v", err)
 		}
-		log.Printf("VMC sender configured: %s:%d", cfg.VMC.Address, cfg.VMC.Port)
+		log.Printf("VMC sender configured: %s", net.JoinHostPort(cfg.VMC.Address, strconv.Itoa(cfg.VMC.Port)))
+	}
+
+	// Write frames to stdout for piping into other tools; logs go to stderr
+	if *jsonOut {
+		if err := tracker.AddSender(miface.NewJSONSender(os.Stdout)); err != nil {
+			log.Fatalf("Failed to add JSON sender: %v", err)
+		}
 	}
 
 	// Subscribe to tracking data for verbose output
@@ -163,10 +269,46 @@ func main() {
 	}
 	log.Println("Tracking started. Press Ctrl+C to stop.")
 
+	// Serve health and stats for service supervisors
+	if *statusAddr != "" {
+		statusServer := miface.NewStatusServer(tracker, *statusAddr)
+		if err := statusServer.Start(); err != nil {
+			log.Fatalf("Failed to start status server: %v", err)
+		}
+		defer statusServer.Close()
+		log.Printf("Status server listening on %s", statusServer.Addr())
+	}
+
+	// Watch config file for changes
+	if *watchConfig {
+		watchCtx, stopWatch := context.WithCancel(context.Background())
+		defer stopWatch()
+		go config.Watch(watchCtx, *configPath, time.Second, func(newCfg *config.Config, err error) {
+			if err != nil {
+				log.Printf("Config reload failed: %v", err)
+				return
+			}
+			applyOverrides(newCfg)
+			if err := tracker.ApplyConfig(newCfg); err != nil {
+				log.Printf("Config reload rejected: %v", err)
+				return
+			}
+			log.Printf("Configuration reloaded from %s", *configPath)
+		})
+		log.Printf("Watching %s for changes", *configPath)
+	}
+
 	// Handle shutdown signals
 	sigCh := make(chan os.Signal, 1)
 	signal.Notify(sigCh, syscall.SIGINT, syscall.SIGTERM)
 
+	// Flush the frame in flight and leave the avatar in its rest pose
+	drain := func() {
+		if err := tracker.Drain(time.Second); err != nil {
+			log.Printf("Shutdown: %v", err)
+		}
+	}
+
 	// Main loop
 	if *verbose && dataCh != nil {
 		// Verbose mode: log tracking data
@@ -175,6 +317,12 @@ func main() {
 			select {
 			case sig := <-sigCh:
 				log.Printf("Received signal %v, shutting down...", sig)
+				drain()
+				return
+
+			case <-previewDone:
+				log.Println("Preview window closed, shutting down...")
+				drain()
 				return
 
 			case data, ok := <-dataCh:
@@ -193,7 +341,12 @@ func main() {
 		}
 	} else {
 		// Non-verbose mode: just wait for shutdown signal
-		sig := <-sigCh
-		log.Printf("Received signal %v, shutting down...", sig)
+		select {
+		case sig := <-sigCh:
+			log.Printf("Received signal %v, shutting down...", sig)
+		case <-previewDone:
+			log.Println("Preview window closed, shutting down...")
+		}
+		drain()
 	}
 }
@@ -23,6 +23,9 @@ int main() {
     config.smooth_landmarks = true;
     config.refine_face_landmarks = true;
     config.enable_segmentation = false;
+    config.enable_face = true;
+    config.enable_hands = true;
+    config.enable_pose = true;
 
     // Initialize processor
     std::cout << "Initializing processor...\n";
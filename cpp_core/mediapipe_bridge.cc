@@ -83,6 +83,63 @@ MPLandmark* ConvertWorldLandmarks(
     return result;
 }
 
+// Remove a node from the graph by calculator name
+void RemoveNode(mediapipe::CalculatorGraphConfig* graph_config, const std::string& calculator) {
+    auto* nodes = graph_config->mutable_node();
+    for (int i = nodes->size() - 1; i >= 0; --i) {
+        if (nodes->Get(i).calculator() == calculator) {
+            nodes->DeleteSubrange(i, 1);
+        }
+    }
+}
+
+// Remove a graph output stream by name
+void RemoveOutputStream(mediapipe::CalculatorGraphConfig* graph_config, const std::string& stream) {
+    auto* streams = graph_config->mutable_output_stream();
+    for (int i = streams->size() - 1; i >= 0; --i) {
+        if (streams->Get(i) == stream) {
+            streams->DeleteSubrange(i, 1);
+        }
+    }
+}
+
+// Strip the nodes and outputs of disabled modalities, so they are never
+// computed. Hands are located from the pose, so the pose model stays in
+// the graph while hands are enabled.
+void ConfigureModalities(mediapipe::CalculatorGraphConfig* graph_config, const MPConfig& config) {
+    if (!config.enable_face) {
+        RemoveNode(graph_config, "FaceLandmarkCpu");
+        RemoveOutputStream(graph_config, "face_landmarks");
+    }
+    if (!config.enable_hands) {
+        RemoveNode(graph_config, "HandLandmarkTrackingCpu");
+        RemoveOutputStream(graph_config, "left_hand_landmarks");
+        RemoveOutputStream(graph_config, "right_hand_landmarks");
+    }
+    if (!config.enable_pose && !config.enable_hands) {
+        RemoveNode(graph_config, "PoseLandmarkCpu");
+    }
+    if (!config.enable_pose) {
+        RemoveOutputStream(graph_config, "pose_landmarks");
+        RemoveOutputStream(graph_config, "pose_world_landmarks");
+    }
+
+    // The flow limiter waits for a frame to finish before admitting the
+    // next one; point it at a stream that is still produced
+    std::string finished = config.enable_face ? "face_landmarks" : "pose_landmarks";
+    for (auto& node : *graph_config->mutable_node()) {
+        if (node.calculator() != "FlowLimiterCalculator") {
+            continue;
+        }
+        auto* inputs = node.mutable_input_stream();
+        for (int i = 0; i < inputs->size(); ++i) {
+            if (inputs->Get(i).rfind("FINISHED:", 0) == 0) {
+                *inputs->Mutable(i) = "FINISHED:" + finished;
+            }
+        }
+    }
+}
+
 } // anonymous namespace
 
 // ============================================================================
@@ -100,6 +157,10 @@ public:
                 kHolisticGraphConfig, &graph_config)) {
             throw std::runtime_error("Failed to parse graph config");
         }
+        if (!config_.enable_face && !config_.enable_hands && !config_.enable_pose) {
+            throw std::runtime_error("No modality enabled");
+        }
+        ConfigureModalities(&graph_config, config_);
 
         // Initialize calculator graph
         graph_ = std::make_unique<mediapipe::CalculatorGraph>();
@@ -182,7 +243,8 @@ private:
     void FetchResults(MPResults* results) {
         // Try to get face landmarks
         mediapipe::Packet face_packet;
-        if (graph_->GetOutputStream("face_landmarks")->GetPacket(&face_packet)) {
+        if (config_.enable_face &&
+            graph_->GetOutputStream("face_landmarks")->GetPacket(&face_packet)) {
             const auto& face_landmarks = 
                 face_packet.Get<mediapipe::NormalizedLandmarkList>();
             results->face_landmarks = ConvertLandmarks(
@@ -192,7 +254,8 @@ private:
 
         // Try to get left hand landmarks
         mediapipe::Packet left_hand_packet;
-        if (graph_->GetOutputStream("left_hand_landmarks")->GetPacket(&left_hand_packet)) {
+        if (config_.enable_hands &&
+            graph_->GetOutputStream("left_hand_landmarks")->GetPacket(&left_hand_packet)) {
             const auto& left_hand_landmarks = 
                 left_hand_packet.Get<mediapipe::NormalizedLandmarkList>();
             results->left_hand_landmarks = ConvertLandmarks(
@@ -202,7 +265,8 @@ private:
 
         // Try to get right hand landmarks
         mediapipe::Packet right_hand_packet;
-        if (graph_->GetOutputStream("right_hand_landmarks")->GetPacket(&right_hand_packet)) {
+        if (config_.enable_hands &&
+            graph_->GetOutputStream("right_hand_landmarks")->GetPacket(&right_hand_packet)) {
             const auto& right_hand_landmarks = 
                 right_hand_packet.Get<mediapipe::NormalizedLandmarkList>();
             results->right_hand_landmarks = ConvertLandmarks(
@@ -212,7 +276,8 @@ private:
 
         // Try to get pose landmarks
         mediapipe::Packet pose_packet;
-        if (graph_->GetOutputStream("pose_landmarks")->GetPacket(&pose_packet)) {
+        if (config_.enable_pose &&
+            graph_->GetOutputStream("pose_landmarks")->GetPacket(&pose_packet)) {
             const auto& pose_landmarks = 
                 pose_packet.Get<mediapipe::NormalizedLandmarkList>();
             results->pose_landmarks = ConvertLandmarks(
@@ -222,7 +287,8 @@ private:
 
         // Try to get pose world landmarks (3D in meters)
         mediapipe::Packet pose_world_packet;
-        if (graph_->GetOutputStream("pose_world_landmarks")->GetPacket(&pose_world_packet)) {
+        if (config_.enable_pose &&
+            graph_->GetOutputStream("pose_world_landmarks")->GetPacket(&pose_world_packet)) {
             const auto& pose_world_landmarks = 
                 pose_world_packet.Get<mediapipe::LandmarkList>();
             results->pose_world_landmarks = ConvertWorldLandmarks(
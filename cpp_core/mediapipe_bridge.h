@@ -24,6 +24,9 @@ typedef struct {
     bool smooth_landmarks;          // temporal smoothing
     bool refine_face_landmarks;     // enable face mesh refinement
     bool enable_segmentation;       // enable person segmentation
+    bool enable_face;               // run face mesh
+    bool enable_hands;              // run hand tracking (needs the pose model)
+    bool enable_pose;               // report pose landmarks
 } MPConfig;
 
 // Single 3D landmark point
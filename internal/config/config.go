@@ -7,17 +7,47 @@
 //	width = 1280
 //	height = 720
 //	fps = 30
+//	buffer_size = 0
 //
 //	[tracking]
 //	enable_face = true
 //	enable_hands = true
 //	enable_pose = true
+//	smoothing_algorithm = "kalman"
 //	smoothing_factor = 0.5
+//	min_hand_confidence = 0.0
+//	mirror_landmarks = false
+//	lock_lower_body = true
+//	max_output_fps = 0
+//	calibration_file = "calibration.toml"
+//	downscale = false
+//	downscale_max_dimension = 640
+//
+//	[tracking.one_euro]
+//	min_cutoff = 1.0
+//	beta = 10.0
+//	d_cutoff = 1.0
+//
+//	[tracking.double_exp]
+//	alpha = 0.5
+//	beta = 0.3
+//	lookahead = 0.0
 //
 //	[vmc]
 //	enabled = true
 //	address = "127.0.0.1"
 //	port = 39539
+//	axes = "mediapipe-vrm"
+//	local_address = ""
+//	network = ""
+//	delta_epsilon = 0.0
+//	keyframe_interval = 30
+//
+//	[blend_shape_curves.jawOpen]
+//	gain = 1.5
+//	bias = 0.0
+//	gamma = 1.0
+//	ease = "out"
 //
 // Example usage:
 //
@@ -29,8 +59,12 @@
 package config
 
 import (
+	"errors"
 	"fmt"
+	"net"
 	"os"
+	"strconv"
+	"strings"
 
 	"github.com/BurntSushi/toml"
 )
@@ -40,6 +74,9 @@ type Config struct {
 	Camera   CameraConfig   `toml:"camera"`
 	Tracking TrackingConfig `toml:"tracking"`
 	VMC      VMCConfig      `toml:"vmc"`
+	// BlendShapeCurves shapes blend shape weights by name before they are
+	// sent (default: none).
+	BlendShapeCurves map[string]BlendShapeCurve `toml:"blend_shape_curves"`
 }
 
 // CameraConfig holds webcam capture settings.
@@ -52,6 +89,10 @@ type CameraConfig struct {
 	Height int `toml:"height"`
 	// FPS is the target frame rate (default: 30).
 	FPS int `toml:"fps"`
+	// BufferSize is how many frames the capture driver queues; 1 keeps
+	// only the newest so a slow frame doesn't leave the next one stale.
+	// Not every backend supports it (0 = driver default, default: 0).
+	BufferSize int `toml:"buffer_size"`
 }
 
 // TrackingConfig holds face/body tracking settings.
@@ -62,8 +103,161 @@ type TrackingConfig struct {
 	EnableHands bool `toml:"enable_hands"`
 	// EnablePose enables pose/body tracking (default: true).
 	EnablePose bool `toml:"enable_pose"`
+	// EstimateGaze refines the face mesh with the iris landmarks and fills
+	// in the eye gaze blend shapes from them (default: false).
+	EstimateGaze bool `toml:"estimate_gaze"`
+	// SmoothingAlgorithm selects the landmark and blend shape filter:
+	// "kalman" (tuned by SmoothingFactor), "oneeuro" (tuned by OneEuro) or
+	// "double_exp" (tuned by DoubleExp). "" is treated as "kalman"
+	// (default: "kalman").
+	SmoothingAlgorithm string `toml:"smoothing_algorithm"`
 	// SmoothingFactor controls Kalman filter smoothing (0.0-1.0, default: 0.5).
 	SmoothingFactor float64 `toml:"smoothing_factor"`
+	// OneEuro holds the One Euro filter parameters.
+	OneEuro OneEuroConfig `toml:"one_euro"`
+	// DoubleExp holds the double-exponential filter parameters.
+	DoubleExp DoubleExpConfig `toml:"double_exp"`
+	// VisibilityFloor holds the per-modality visibility below which
+	// landmarks are treated as not present.
+	VisibilityFloor VisibilityFloorConfig `toml:"visibility_floor"`
+	// MinHandConfidence is the hand detection confidence below which hand
+	// bones are not sent (0.0-1.0, default: 0, which sends every hand).
+	// The MediaPipe processor derives the confidence from the presence and
+	// visibility of the hand landmarks, so raising it drops hands that
+	// linger with low scores.
+	MinHandConfidence float64 `toml:"min_hand_confidence"`
+	// MirrorLandmarks flips tracking output horizontally, for camera sources
+	// that don't mirror the image themselves (default: false).
+	MirrorLandmarks bool `toml:"mirror_landmarks"`
+	// CorrectHandedness reassigns the left and right hands by which pose
+	// wrist each is nearest, for when MediaPipe mislabels them. It needs
+	// pose tracking enabled (default: false).
+	CorrectHandedness bool `toml:"correct_handedness"`
+	// LockLowerBody keeps the avatar's hips and legs in their rest pose: the
+	// VMC sender pins the Hips bone and skips the leg bones. The pose
+	// landmarks themselves are left intact (default: true).
+	LockLowerBody bool `toml:"lock_lower_body"`
+	// MaxOutputFPS caps how often tracking data is sent and broadcast,
+	// independent of the camera frame rate. Frames above the cap are still
+	// processed but not output (0 = unlimited, default: 0).
+	MaxOutputFPS int `toml:"max_output_fps"`
+	// CalibrationFile is where the neutral face calibration is loaded from
+	// and saved to ("" = not persisted, default: "").
+	CalibrationFile string `toml:"calibration_file"`
+	// RestPoseFile is a JSON file with the pose sent once tracking has been
+	// lost for a while and when the tracker stops, in the form written by
+	// miface -json ("" = built-in neutral pose, default: "").
+	RestPoseFile string `toml:"rest_pose_file"`
+	// Downscale shrinks each frame before landmark detection so its long
+	// edge is at most DownscaleMaxDimension pixels. Landmarks are
+	// normalized, so they are unaffected apart from precision
+	// (default: false).
+	Downscale bool `toml:"downscale"`
+	// DownscaleMaxDimension is the long edge in pixels frames are shrunk
+	// to when Downscale is set (default: 640).
+	DownscaleMaxDimension int `toml:"downscale_max_dimension"`
+	// ProcessTimeoutMS bounds how long landmark detection may take per
+	// frame, in milliseconds. A frame that takes longer is dropped; the
+	// processor may keep working on it in the background
+	// (0 = no limit, default: 0).
+	ProcessTimeoutMS int `toml:"process_timeout_ms"`
+	// AdaptiveComplexity lowers the MediaPipe model complexity while frames
+	// take longer to process than the camera frame interval, and raises it
+	// again once there is headroom (default: false).
+	AdaptiveComplexity bool `toml:"adaptive_complexity"`
+	// MaxFrameAgeMS is the oldest a camera frame may be when it is read, in
+	// milliseconds. Older frames are discarded to drain the camera's queue
+	// so the newest one is processed. Only cameras that timestamp their
+	// frames are checked, which includes the OpenCV camera (0 = no limit,
+	// default: 0).
+	MaxFrameAgeMS int `toml:"max_frame_age_ms"`
+}
+
+// Smoothing algorithms for TrackingConfig.SmoothingAlgorithm.
+const (
+	SmoothingKalman    = "kalman"
+	SmoothingOneEuro   = "oneeuro"
+	SmoothingDoubleExp = "double_exp"
+)
+
+// OneEuroConfig holds One Euro filter parameters. The filter's cutoff
+// frequency rises with speed: it smooths heavily at rest and follows
+// quickly in motion.
+type OneEuroConfig struct {
+	// MinCutoff is the cutoff frequency at rest, in Hz; lower smooths more
+	// (default: 1.0).
+	MinCutoff float64 `toml:"min_cutoff"`
+	// Beta is how much the cutoff rises with speed; higher lags less in
+	// fast motion (default: 10.0).
+	Beta float64 `toml:"beta"`
+	// DCutoff is the cutoff frequency for the speed estimate, in Hz
+	// (default: 1.0).
+	DCutoff float64 `toml:"d_cutoff"`
+}
+
+// Validate checks the One Euro parameters for invalid values.
+func (c OneEuroConfig) Validate() error {
+	if c.MinCutoff <= 0 {
+		return fmt.Errorf("min cutoff must be positive, got %f", c.MinCutoff)
+	}
+	if c.Beta < 0 {
+		return fmt.Errorf("beta must not be negative, got %f", c.Beta)
+	}
+	if c.DCutoff <= 0 {
+		return fmt.Errorf("d cutoff must be positive, got %f", c.DCutoff)
+	}
+	return nil
+}
+
+// DoubleExpConfig holds double-exponential filter parameters.
+type DoubleExpConfig struct {
+	// Alpha is the level smoothing factor (0.0-1.0, higher = more responsive,
+	// default: 0.5).
+	Alpha float64 `toml:"alpha"`
+	// Beta is the trend smoothing factor (0.0-1.0, higher = more responsive,
+	// default: 0.3).
+	Beta float64 `toml:"beta"`
+	// Lookahead is how many frames the output is extrapolated along the
+	// trend, to offset latency (0 = no prediction, default: 0).
+	Lookahead float64 `toml:"lookahead"`
+}
+
+// Validate checks the double-exponential parameters for invalid values.
+func (c DoubleExpConfig) Validate() error {
+	if c.Alpha <= 0 || c.Alpha > 1 {
+		return fmt.Errorf("alpha must be greater than 0 and at most 1, got %f", c.Alpha)
+	}
+	if c.Beta < 0 || c.Beta > 1 {
+		return fmt.Errorf("beta must be between 0 and 1, got %f", c.Beta)
+	}
+	if c.Lookahead < 0 {
+		return fmt.Errorf("lookahead must not be negative, got %f", c.Lookahead)
+	}
+	return nil
+}
+
+// VisibilityFloorConfig holds the landmark Visibility, per modality, below
+// which a landmark is treated as not present: the smoother holds its last
+// good position, it is left out of centroids and bounds and its bone is not
+// sent. It cuts phantom motion from occluded and off-frame landmarks
+// (0.0-1.0, 0 = disabled, default: 0).
+type VisibilityFloorConfig struct {
+	Face  float64 `toml:"face"`
+	Hands float64 `toml:"hands"`
+	Pose  float64 `toml:"pose"`
+}
+
+// Validate checks the visibility floors for invalid values.
+func (c VisibilityFloorConfig) Validate() error {
+	for _, floor := range []struct {
+		name  string
+		value float64
+	}{{"face", c.Face}, {"hands", c.Hands}, {"pose", c.Pose}} {
+		if floor.value < 0 || floor.value > 1 {
+			return fmt.Errorf("%s visibility floor must be between 0 and 1, got %f", floor.name, floor.value)
+		}
+	}
+	return nil
 }
 
 // VMCConfig holds VMC (Virtual Motion Capture) protocol sender settings.
@@ -71,10 +265,70 @@ type TrackingConfig struct {
 type VMCConfig struct {
 	// Enabled enables VMC protocol output (default: true).
 	Enabled bool `toml:"enabled"`
-	// Address is the destination IP address (default: "127.0.0.1").
+	// Address is the destination IP address or hostname (default: "127.0.0.1").
 	Address string `toml:"address"`
 	// Port is the destination UDP port (default: 39539).
 	Port int `toml:"port"`
+	// Axes is the axis convention bone positions and rotations are sent
+	// in: "mediapipe-vrm", "mediapipe-unity" or "none" to keep MediaPipe's
+	// image axes. "" is treated as "mediapipe-vrm"
+	// (default: "mediapipe-vrm").
+	Axes string `toml:"axes"`
+	// LocalAddress is the local "host:port" datagrams are sent from, for
+	// receivers or firewalls that expect a fixed source port or interface.
+	// An empty host binds all interfaces and port 0 picks an ephemeral
+	// port; "" leaves both to the OS (default: "").
+	LocalAddress string `toml:"local_address"`
+	// Network restricts addresses to one family: "udp4" for IPv4 or
+	// "udp6" for IPv6. "" uses whichever Address resolves to first
+	// (default: "").
+	Network string `toml:"network"`
+	// DeltaEpsilon, if positive, only sends bones and blend shapes whose
+	// values moved by more than it since they were last sent, to save
+	// bandwidth while the user holds still (default: 0, send everything).
+	DeltaEpsilon float64 `toml:"delta_epsilon"`
+	// KeyframeInterval is how many frames apart everything is sent
+	// regardless of DeltaEpsilon, so late-joining receivers sync
+	// (default: 30).
+	KeyframeInterval int `toml:"keyframe_interval"`
+}
+
+// Axis conventions for VMCConfig.Axes.
+const (
+	AxesMediaPipeVRM   = "mediapipe-vrm"
+	AxesMediaPipeUnity = "mediapipe-unity"
+	AxesNone           = "none"
+)
+
+// BlendShapeCurve shapes the weight of one blend shape. The weight is eased,
+// raised to Gamma, then scaled by Gain and offset by Bias, and clamped to [0, 1].
+type BlendShapeCurve struct {
+	// Gain multiplies the weight (0 is treated as 1, default: 1).
+	Gain float64 `toml:"gain"`
+	// Bias is added to the weight after the gain (default: 0).
+	Bias float64 `toml:"bias"`
+	// Gamma is the exponent applied to the weight; below 1 boosts small
+	// weights, above 1 damps them (0 is treated as 1, default: 1).
+	Gamma float64 `toml:"gamma"`
+	// Ease is an easing curve applied first: "in", "out", "in-out", or
+	// "" for linear (default: "").
+	Ease string `toml:"ease"`
+}
+
+// Validate checks the curve for invalid values.
+func (c BlendShapeCurve) Validate() error {
+	if c.Gain < 0 {
+		return fmt.Errorf("gain must not be negative, got %f", c.Gain)
+	}
+	if c.Gamma < 0 {
+		return fmt.Errorf("gamma must not be negative, got %f", c.Gamma)
+	}
+	switch c.Ease {
+	case "", "in", "out", "in-out":
+	default:
+		return fmt.Errorf("ease must be \"in\", \"out\", \"in-out\" or empty, got %q", c.Ease)
+	}
+	return nil
 }
 
 // Default returns the default configuration.
@@ -87,15 +341,29 @@ func Default() *Config {
 			FPS:      30,
 		},
 		Tracking: TrackingConfig{
-			EnableFace:      true,
-			EnableHands:     true,
-			EnablePose:      true,
-			SmoothingFactor: 0.5,
+			EnableFace:         true,
+			EnableHands:        true,
+			EnablePose:         true,
+			SmoothingAlgorithm: SmoothingKalman,
+			SmoothingFactor:    0.5,
+			OneEuro: OneEuroConfig{
+				MinCutoff: 1.0,
+				Beta:      10.0,
+				DCutoff:   1.0,
+			},
+			DoubleExp: DoubleExpConfig{
+				Alpha: 0.5,
+				Beta:  0.3,
+			},
+			LockLowerBody:         true,
+			DownscaleMaxDimension: 640,
 		},
 		VMC: VMCConfig{
-			Enabled: true,
-			Address: "127.0.0.1",
-			Port:    39539,
+			Enabled:          true,
+			Address:          "127.0.0.1",
+			Port:             39539,
+			Axes:             AxesMediaPipeVRM,
+			KeyframeInterval: 30,
 		},
 	}
 }
@@ -139,11 +407,149 @@ func (c *Config) Validate() error {
 	if c.Camera.FPS <= 0 {
 		return fmt.Errorf("camera FPS must be positive, got %d", c.Camera.FPS)
 	}
-	if c.Tracking.SmoothingFactor < 0 || c.Tracking.SmoothingFactor > 1 {
-		return fmt.Errorf("smoothing factor must be between 0 and 1, got %f", c.Tracking.SmoothingFactor)
+	if c.Camera.BufferSize < 0 {
+		return fmt.Errorf("camera buffer size must not be negative, got %d", c.Camera.BufferSize)
+	}
+	if err := c.Tracking.Validate(); err != nil {
+		return err
 	}
 	if c.VMC.Port <= 0 || c.VMC.Port > 65535 {
 		return fmt.Errorf("VMC port must be between 1 and 65535, got %d", c.VMC.Port)
 	}
+	if c.VMC.Enabled || c.VMC.Address != "" {
+		if err := validateHost(c.VMC.Address); err != nil {
+			return fmt.Errorf("invalid VMC address %q: %w", c.VMC.Address, err)
+		}
+	}
+	if c.VMC.LocalAddress != "" {
+		if err := validateLocalAddress(c.VMC.LocalAddress); err != nil {
+			return fmt.Errorf("invalid VMC local address %q: %w", c.VMC.LocalAddress, err)
+		}
+	}
+	if c.VMC.DeltaEpsilon < 0 {
+		return fmt.Errorf("VMC delta epsilon must not be negative, got %f", c.VMC.DeltaEpsilon)
+	}
+	if c.VMC.DeltaEpsilon > 0 && c.VMC.KeyframeInterval < 1 {
+		return fmt.Errorf("VMC keyframe interval must be positive, got %d", c.VMC.KeyframeInterval)
+	}
+	switch c.VMC.Network {
+	case "", "udp4", "udp6":
+	default:
+		return fmt.Errorf("VMC network must be \"udp4\" or \"udp6\", got %q", c.VMC.Network)
+	}
+	if ip := net.ParseIP(c.VMC.Address); ip != nil && c.VMC.Network != "" &&
+		(ip.To4() != nil) != (c.VMC.Network == "udp4") {
+		return fmt.Errorf("VMC address %q is not a %s address", c.VMC.Address, c.VMC.Network)
+	}
+	switch c.VMC.Axes {
+	case "", AxesMediaPipeVRM, AxesMediaPipeUnity, AxesNone:
+	default:
+		return fmt.Errorf("VMC axes must be %q, %q or %q, got %q",
+			AxesMediaPipeVRM, AxesMediaPipeUnity, AxesNone, c.VMC.Axes)
+	}
+	for name, curve := range c.BlendShapeCurves {
+		if err := curve.Validate(); err != nil {
+			return fmt.Errorf("invalid curve for blend shape %q: %w", name, err)
+		}
+	}
+	return nil
+}
+
+// Validate checks the tracking settings for invalid values.
+func (t TrackingConfig) Validate() error {
+	if t.SmoothingFactor < 0 || t.SmoothingFactor > 1 {
+		return fmt.Errorf("smoothing factor must be between 0 and 1, got %f", t.SmoothingFactor)
+	}
+	if t.MinHandConfidence < 0 || t.MinHandConfidence > 1 {
+		return fmt.Errorf("min hand confidence must be between 0 and 1, got %f", t.MinHandConfidence)
+	}
+	if t.MaxOutputFPS < 0 {
+		return fmt.Errorf("max output FPS must not be negative, got %d", t.MaxOutputFPS)
+	}
+	if t.Downscale && t.DownscaleMaxDimension <= 0 {
+		return fmt.Errorf("downscale max dimension must be positive, got %d", t.DownscaleMaxDimension)
+	}
+	if t.ProcessTimeoutMS < 0 {
+		return fmt.Errorf("process timeout must not be negative, got %d", t.ProcessTimeoutMS)
+	}
+	if t.MaxFrameAgeMS < 0 {
+		return fmt.Errorf("max frame age must not be negative, got %d", t.MaxFrameAgeMS)
+	}
+	if err := t.VisibilityFloor.Validate(); err != nil {
+		return fmt.Errorf("invalid visibility_floor settings: %w", err)
+	}
+	switch t.SmoothingAlgorithm {
+	case "", SmoothingKalman:
+	case SmoothingOneEuro:
+		if err := t.OneEuro.Validate(); err != nil {
+			return fmt.Errorf("invalid one_euro settings: %w", err)
+		}
+	case SmoothingDoubleExp:
+		if err := t.DoubleExp.Validate(); err != nil {
+			return fmt.Errorf("invalid double_exp settings: %w", err)
+		}
+	default:
+		return fmt.Errorf("smoothing algorithm must be %q, %q or %q, got %q",
+			SmoothingKalman, SmoothingOneEuro, SmoothingDoubleExp, t.SmoothingAlgorithm)
+	}
 	return nil
 }
+
+// validateLocalAddress checks that address is a "host:port" a socket can be
+// bound to: the host, if any, must be an IP address and the port must be
+// between 0 and 65535.
+func validateLocalAddress(address string) error {
+	host, port, err := net.SplitHostPort(address)
+	if err != nil {
+		return err
+	}
+	if host != "" && net.ParseIP(host) == nil {
+		return errors.New("host must be an IP address")
+	}
+	n, err := strconv.Atoi(port)
+	if err != nil || n < 0 || n > 65535 {
+		return fmt.Errorf("port must be between 0 and 65535, got %q", port)
+	}
+	return nil
+}
+
+// validateHost checks that host is an IP address or a well-formed hostname
+// that resolves. Catching this here gives a clear error at load time instead
+// of a resolver failure when the sender is created.
+func validateHost(host string) error {
+	if host == "" {
+		return errors.New("address is empty")
+	}
+	if net.ParseIP(host) != nil {
+		return nil
+	}
+	if !isValidHostname(host) {
+		return errors.New("not an IP address or hostname")
+	}
+	if _, err := net.LookupHost(host); err != nil {
+		return fmt.Errorf("cannot resolve host: %w", err)
+	}
+	return nil
+}
+
+// isValidHostname reports whether s is a syntactically valid RFC 1123 hostname.
+func isValidHostname(s string) bool {
+	s = strings.TrimSuffix(s, ".")
+	if len(s) == 0 || len(s) > 253 {
+		return false
+	}
+	for _, label := range strings.Split(s, ".") {
+		if len(label) == 0 || len(label) > 63 {
+			return false
+		}
+		if label[0] == '-' || label[len(label)-1] == '-' {
+			return false
+		}
+		for _, r := range label {
+			if !(r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || r == '-') {
+				return false
+			}
+		}
+	}
+	return true
+}
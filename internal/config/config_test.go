@@ -3,6 +3,7 @@ package config
 import (
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 )
 
@@ -30,9 +31,27 @@ func TestDefault(t *testing.T) {
 	if !cfg.Tracking.EnablePose {
 		t.Error("expected EnablePose to be true")
 	}
+	if cfg.Tracking.SmoothingAlgorithm != SmoothingKalman {
+		t.Errorf("expected SmoothingAlgorithm %q, got %q", SmoothingKalman, cfg.Tracking.SmoothingAlgorithm)
+	}
 	if cfg.Tracking.SmoothingFactor != 0.5 {
 		t.Errorf("expected SmoothingFactor 0.5, got %f", cfg.Tracking.SmoothingFactor)
 	}
+	if cfg.Tracking.MinHandConfidence != 0 {
+		t.Errorf("expected MinHandConfidence 0, got %f", cfg.Tracking.MinHandConfidence)
+	}
+	if cfg.Tracking.MirrorLandmarks {
+		t.Error("expected MirrorLandmarks to be disabled by default")
+	}
+	if !cfg.Tracking.LockLowerBody {
+		t.Error("expected LockLowerBody to be enabled by default")
+	}
+	if cfg.Tracking.MaxOutputFPS != 0 {
+		t.Errorf("expected MaxOutputFPS 0, got %d", cfg.Tracking.MaxOutputFPS)
+	}
+	if cfg.Tracking.CalibrationFile != "" {
+		t.Errorf("expected empty CalibrationFile, got %q", cfg.Tracking.CalibrationFile)
+	}
 	if !cfg.VMC.Enabled {
 		t.Error("expected VMC.Enabled to be true")
 	}
@@ -120,6 +139,41 @@ port = 39540
 	}
 }
 
+func TestLoad_BlendShapeCurves(t *testing.T) {
+	content := `
+[blend_shape_curves.jawOpen]
+gain = 2.0
+ease = "out"
+
+[blend_shape_curves.mouthSmileLeft]
+gamma = 1.5
+bias = -0.1
+`
+	dir := t.TempDir()
+	path := filepath.Join(dir, "config.toml")
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatalf("failed to write test file: %v", err)
+	}
+
+	cfg, err := Load(path)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	want := map[string]BlendShapeCurve{
+		"jawOpen":        {Gain: 2, Ease: "out"},
+		"mouthSmileLeft": {Gamma: 1.5, Bias: -0.1},
+	}
+	if len(cfg.BlendShapeCurves) != len(want) {
+		t.Fatalf("expected %d curves, got %d", len(want), len(cfg.BlendShapeCurves))
+	}
+	for name, curve := range want {
+		if got := cfg.BlendShapeCurves[name]; got != curve {
+			t.Errorf("%s: expected %+v, got %+v", name, curve, got)
+		}
+	}
+}
+
 func TestLoad_InvalidTOML(t *testing.T) {
 	dir := t.TempDir()
 	path := filepath.Join(dir, "invalid.toml")
@@ -170,6 +224,112 @@ func TestValidate_InvalidSmoothingFactor(t *testing.T) {
 	}
 }
 
+func TestValidate_InvalidMinHandConfidence(t *testing.T) {
+	cfg := Default()
+	cfg.Tracking.MinHandConfidence = 1.5
+	if err := cfg.Validate(); err == nil {
+		t.Error("expected error for min hand confidence > 1")
+	}
+
+	cfg.Tracking.MinHandConfidence = -0.1
+	if err := cfg.Validate(); err == nil {
+		t.Error("expected error for min hand confidence < 0")
+	}
+}
+
+func TestValidate_InvalidMaxOutputFPS(t *testing.T) {
+	cfg := Default()
+	cfg.Tracking.MaxOutputFPS = -1
+	if err := cfg.Validate(); err == nil {
+		t.Error("expected error for negative max output FPS")
+	}
+}
+
+func TestValidate_DownscaleMaxDimension(t *testing.T) {
+	cfg := Default()
+	cfg.Tracking.DownscaleMaxDimension = 0
+	if err := cfg.Validate(); err != nil {
+		t.Errorf("unexpected error with downscaling off: %v", err)
+	}
+
+	cfg.Tracking.Downscale = true
+	if err := cfg.Validate(); err == nil {
+		t.Error("expected error for zero downscale max dimension")
+	}
+}
+
+func TestValidate_InvalidProcessTimeout(t *testing.T) {
+	cfg := Default()
+	cfg.Tracking.ProcessTimeoutMS = -1
+	if err := cfg.Validate(); err == nil {
+		t.Error("expected error for negative process timeout")
+	}
+}
+
+func TestValidate_InvalidVMCDeltaMode(t *testing.T) {
+	cfg := Default()
+	cfg.VMC.DeltaEpsilon = -0.1
+	if err := cfg.Validate(); err == nil {
+		t.Error("expected error for negative delta epsilon")
+	}
+
+	cfg = Default()
+	cfg.VMC.DeltaEpsilon = 0.001
+	cfg.VMC.KeyframeInterval = 0
+	if err := cfg.Validate(); err == nil {
+		t.Error("expected error for zero keyframe interval in delta mode")
+	}
+}
+
+func TestValidate_InvalidCameraBufferSize(t *testing.T) {
+	cfg := Default()
+	cfg.Camera.BufferSize = -1
+	if err := cfg.Validate(); err == nil {
+		t.Error("expected error for negative camera buffer size")
+	}
+}
+
+func TestValidate_InvalidMaxFrameAge(t *testing.T) {
+	cfg := Default()
+	cfg.Tracking.MaxFrameAgeMS = -1
+	if err := cfg.Validate(); err == nil {
+		t.Error("expected error for negative max frame age")
+	}
+}
+
+func TestValidate_InvalidVisibilityFloor(t *testing.T) {
+	cfg := Default()
+	cfg.Tracking.VisibilityFloor.Hands = 1.5
+	if err := cfg.Validate(); err == nil {
+		t.Error("expected error for hands visibility floor > 1")
+	}
+
+	cfg = Default()
+	cfg.Tracking.VisibilityFloor.Pose = -0.1
+	if err := cfg.Validate(); err == nil {
+		t.Error("expected error for negative pose visibility floor")
+	}
+}
+
+func TestValidate_InvalidBlendShapeCurve(t *testing.T) {
+	tests := []struct {
+		name  string
+		curve BlendShapeCurve
+	}{
+		{"negative gain", BlendShapeCurve{Gain: -1}},
+		{"negative gamma", BlendShapeCurve{Gamma: -1}},
+		{"unknown ease", BlendShapeCurve{Ease: "bounce"}},
+	}
+
+	for _, tt := range tests {
+		cfg := Default()
+		cfg.BlendShapeCurves = map[string]BlendShapeCurve{"jawOpen": tt.curve}
+		if err := cfg.Validate(); err == nil {
+			t.Errorf("%s: expected validation error", tt.name)
+		}
+	}
+}
+
 func TestValidate_InvalidVMCPort(t *testing.T) {
 	cfg := Default()
 	cfg.VMC.Port = 0
@@ -182,3 +342,223 @@ func TestValidate_InvalidVMCPort(t *testing.T) {
 		t.Error("expected error for VMC port > 65535")
 	}
 }
+
+func TestValidate_VMCAddress(t *testing.T) {
+	tests := []struct {
+		name    string
+		address string
+		enabled bool
+		wantErr bool
+	}{
+		{"valid IPv4", "192.168.1.100", true, false},
+		{"valid IPv6", "::1", true, false},
+		{"valid hostname", "localhost", true, false},
+		{"empty when enabled", "", true, true},
+		{"empty when disabled", "", false, false},
+		{"garbage", "not a host!", true, true},
+		{"trailing dash label", "bad-.example", true, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := Default()
+			cfg.VMC.Enabled = tt.enabled
+			cfg.VMC.Address = tt.address
+			err := cfg.Validate()
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil && !strings.Contains(err.Error(), "invalid VMC address") {
+				t.Errorf("expected \"invalid VMC address\" in error, got %q", err)
+			}
+		})
+	}
+}
+
+func TestValidate_VMCLocalAddress(t *testing.T) {
+	tests := []struct {
+		name    string
+		address string
+		wantErr bool
+	}{
+		{"unset", "", false},
+		{"IPv4 and port", "192.168.1.10:39540", false},
+		{"IPv6 and port", "[::1]:39540", false},
+		{"any interface", ":39540", false},
+		{"ephemeral port", "127.0.0.1:0", false},
+		{"missing port", "127.0.0.1", true},
+		{"hostname", "localhost:39540", true},
+		{"port out of range", ":70000", true},
+		{"named port", ":vmc", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := Default()
+			cfg.VMC.LocalAddress = tt.address
+			err := cfg.Validate()
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil && !strings.Contains(err.Error(), "invalid VMC local address") {
+				t.Errorf("expected \"invalid VMC local address\" in error, got %q", err)
+			}
+		})
+	}
+}
+
+func TestValidate_VMCNetwork(t *testing.T) {
+	tests := []struct {
+		name    string
+		network string
+		address string
+		wantErr bool
+	}{
+		{"any IPv4", "", "127.0.0.1", false},
+		{"any IPv6", "", "::1", false},
+		{"udp4 IPv4", "udp4", "127.0.0.1", false},
+		{"udp6 IPv6", "udp6", "::1", false},
+		{"udp6 hostname", "udp6", "localhost", false},
+		{"udp4 IPv6", "udp4", "::1", true},
+		{"udp6 IPv4", "udp6", "127.0.0.1", true},
+		{"unknown", "tcp", "127.0.0.1", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := Default()
+			cfg.VMC.Network = tt.network
+			cfg.VMC.Address = tt.address
+			if err := cfg.Validate(); (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestValidate_VMCAxes(t *testing.T) {
+	for _, axes := range []string{"", AxesMediaPipeVRM, AxesMediaPipeUnity, AxesNone} {
+		cfg := Default()
+		cfg.VMC.Axes = axes
+		if err := cfg.Validate(); err != nil {
+			t.Errorf("axes %q: unexpected error: %v", axes, err)
+		}
+	}
+
+	cfg := Default()
+	cfg.VMC.Axes = "z-up"
+	if err := cfg.Validate(); err == nil {
+		t.Error("expected error for unknown axes")
+	}
+}
+
+func TestLoad_SmoothingAlgorithm(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		check   func(t *testing.T, cfg *Config)
+	}{
+		{
+			name:    "kalman",
+			content: "[tracking]\nsmoothing_algorithm = \"kalman\"\nsmoothing_factor = 0.7\n",
+			check: func(t *testing.T, cfg *Config) {
+				if cfg.Tracking.SmoothingFactor != 0.7 {
+					t.Errorf("expected SmoothingFactor 0.7, got %f", cfg.Tracking.SmoothingFactor)
+				}
+			},
+		},
+		{
+			name:    "oneeuro",
+			content: "[tracking]\nsmoothing_algorithm = \"oneeuro\"\n\n[tracking.one_euro]\nmin_cutoff = 0.5\nbeta = 20.0\n",
+			check: func(t *testing.T, cfg *Config) {
+				expected := OneEuroConfig{MinCutoff: 0.5, Beta: 20, DCutoff: 1}
+				if cfg.Tracking.OneEuro != expected {
+					t.Errorf("expected %+v, got %+v", expected, cfg.Tracking.OneEuro)
+				}
+			},
+		},
+		{
+			name:    "double_exp",
+			content: "[tracking]\nsmoothing_algorithm = \"double_exp\"\n\n[tracking.double_exp]\nalpha = 0.6\nbeta = 0.2\nlookahead = 1.5\n",
+			check: func(t *testing.T, cfg *Config) {
+				expected := DoubleExpConfig{Alpha: 0.6, Beta: 0.2, Lookahead: 1.5}
+				if cfg.Tracking.DoubleExp != expected {
+					t.Errorf("expected %+v, got %+v", expected, cfg.Tracking.DoubleExp)
+				}
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "config.toml")
+			if err := os.WriteFile(path, []byte(tt.content), 0644); err != nil {
+				t.Fatalf("failed to write test file: %v", err)
+			}
+
+			cfg, err := Load(path)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if cfg.Tracking.SmoothingAlgorithm != tt.name {
+				t.Errorf("expected SmoothingAlgorithm %q, got %q", tt.name, cfg.Tracking.SmoothingAlgorithm)
+			}
+			tt.check(t, cfg)
+		})
+	}
+}
+
+func TestLoad_InvalidSmoothingAlgorithm(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.toml")
+	content := "[tracking]\nsmoothing_algorithm = \"median\"\n"
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatalf("failed to write test file: %v", err)
+	}
+
+	_, err := Load(path)
+	if err == nil {
+		t.Fatal("expected error for unknown smoothing algorithm")
+	}
+	if !strings.Contains(err.Error(), "median") {
+		t.Errorf("expected error to name the algorithm, got %v", err)
+	}
+}
+
+func TestValidate_InvalidSmoothingParameters(t *testing.T) {
+	tests := []struct {
+		name   string
+		modify func(*TrackingConfig)
+	}{
+		{"oneeuro min cutoff", func(c *TrackingConfig) {
+			c.SmoothingAlgorithm = SmoothingOneEuro
+			c.OneEuro.MinCutoff = 0
+		}},
+		{"oneeuro beta", func(c *TrackingConfig) {
+			c.SmoothingAlgorithm = SmoothingOneEuro
+			c.OneEuro.Beta = -1
+		}},
+		{"double_exp alpha", func(c *TrackingConfig) {
+			c.SmoothingAlgorithm = SmoothingDoubleExp
+			c.DoubleExp.Alpha = 1.5
+		}},
+		{"double_exp lookahead", func(c *TrackingConfig) {
+			c.SmoothingAlgorithm = SmoothingDoubleExp
+			c.DoubleExp.Lookahead = -1
+		}},
+	}
+
+	for _, tt := range tests {
+		cfg := Default()
+		tt.modify(&cfg.Tracking)
+		if err := cfg.Validate(); err == nil {
+			t.Errorf("%s: expected validation error", tt.name)
+		}
+	}
+
+	// Parameters of an algorithm that isn't selected are not checked
+	cfg := Default()
+	cfg.Tracking.DoubleExp.Alpha = 0
+	if err := cfg.Validate(); err != nil {
+		t.Errorf("expected unselected parameters to be ignored, got %v", err)
+	}
+}
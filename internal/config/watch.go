@@ -0,0 +1,62 @@
+package config
+
+import (
+	"context"
+	"os"
+	"time"
+)
+
+// Watch polls the configuration file at path and calls onChange whenever its
+// modification time or size changes. The reloaded configuration is passed to
+// onChange, or a non-nil error if the file could not be read or is invalid.
+//
+// Polling is used instead of filesystem notifications so that editors which
+// replace the file on save are handled the same way on every platform. A
+// change is only reported once the file has been stable for one interval,
+// so a partially written file is never loaded.
+// Watch blocks until ctx is cancelled.
+func Watch(ctx context.Context, path string, interval time.Duration, onChange func(*Config, error)) {
+	if interval <= 0 {
+		interval = time.Second
+	}
+
+	last := statFile(path)
+	pending := last
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			current := statFile(path)
+			if current != pending {
+				// Still changing; wait for it to settle
+				pending = current
+				continue
+			}
+			if current == last {
+				continue
+			}
+			last = current
+			onChange(Load(path))
+		}
+	}
+}
+
+// fileState identifies a version of a file by modification time and size.
+type fileState struct {
+	modTime time.Time
+	size    int64
+}
+
+// statFile returns the state of path, or the zero state if it cannot be stat'ed.
+func statFile(path string) fileState {
+	info, err := os.Stat(path)
+	if err != nil {
+		return fileState{}
+	}
+	return fileState{modTime: info.ModTime(), size: info.Size()}
+}
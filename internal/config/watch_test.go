@@ -0,0 +1,87 @@
+package config
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestWatch_ReloadsOnChange(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "config.toml")
+	if err := os.WriteFile(path, []byte("[tracking]\nsmoothing_factor = 0.5\n"), 0644); err != nil {
+		t.Fatalf("failed to write test file: %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	reloaded := make(chan *Config, 1)
+	go Watch(ctx, path, 10*time.Millisecond, func(cfg *Config, err error) {
+		if err != nil {
+			t.Errorf("unexpected reload error: %v", err)
+			return
+		}
+		select {
+		case reloaded <- cfg:
+		default:
+		}
+	})
+
+	// Give the watcher time to record the initial file state
+	time.Sleep(30 * time.Millisecond)
+
+	if err := os.WriteFile(path, []byte("[tracking]\nsmoothing_factor = 0.25\n"), 0644); err != nil {
+		t.Fatalf("failed to rewrite test file: %v", err)
+	}
+	// Bump mtime explicitly in case the filesystem has coarse timestamps
+	future := time.Now().Add(time.Second)
+	if err := os.Chtimes(path, future, future); err != nil {
+		t.Fatalf("failed to update mtime: %v", err)
+	}
+
+	select {
+	case cfg := <-reloaded:
+		if cfg.Tracking.SmoothingFactor != 0.25 {
+			t.Errorf("expected SmoothingFactor 0.25, got %f", cfg.Tracking.SmoothingFactor)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("timeout waiting for config reload")
+	}
+}
+
+func TestWatch_ReportsInvalidFile(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "config.toml")
+	if err := os.WriteFile(path, []byte("[camera]\nfps = 30\n"), 0644); err != nil {
+		t.Fatalf("failed to write test file: %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	errCh := make(chan error, 1)
+	go Watch(ctx, path, 10*time.Millisecond, func(cfg *Config, err error) {
+		select {
+		case errCh <- err:
+		default:
+		}
+	})
+
+	time.Sleep(30 * time.Millisecond)
+
+	if err := os.WriteFile(path, []byte("invalid [ toml"), 0644); err != nil {
+		t.Fatalf("failed to rewrite test file: %v", err)
+	}
+
+	select {
+	case err := <-errCh:
+		if err == nil {
+			t.Error("expected error for invalid TOML")
+		}
+	case <-time.After(time.Second):
+		t.Fatal("timeout waiting for config reload")
+	}
+}
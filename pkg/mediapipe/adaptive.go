@@ -0,0 +1,192 @@
+package mediapipe
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/MiFaceDEV/miface/pkg/miface"
+)
+
+// AdaptiveConfig controls how ComplexityController trades accuracy for speed.
+type AdaptiveConfig struct {
+	// Budget is the processing time available per frame, usually 1/fps.
+	Budget time.Duration
+	// Headroom is the fraction of Budget latency must stay under before
+	// the complexity steps back up (0.0-1.0).
+	Headroom float64
+	// DownAfter is how many frames in a row must exceed Budget before the
+	// complexity steps down.
+	DownAfter int
+	// UpAfter is how many frames in a row must stay under Headroom*Budget
+	// before the complexity steps up. Keep it well above DownAfter so a
+	// model that only just fits doesn't flap between levels.
+	UpAfter int
+	// Max is the highest complexity used, and the one started with.
+	Max ModelComplexity
+}
+
+// DefaultAdaptiveConfig returns an adaptive configuration for a camera at
+// fps frames per second, starting at max: it steps down after 10 frames
+// over budget and back up after 90 frames under half the budget.
+func DefaultAdaptiveConfig(fps int, max ModelComplexity) AdaptiveConfig {
+	if fps <= 0 {
+		fps = 30
+	}
+	return AdaptiveConfig{
+		Budget:    time.Second / time.Duration(fps),
+		Headroom:  0.5,
+		DownAfter: 10,
+		UpAfter:   90,
+		Max:       max,
+	}
+}
+
+// ComplexityController picks a model complexity from observed processing
+// latencies, with hysteresis so a borderline machine doesn't flap.
+// It is not safe for concurrent use.
+type ComplexityController struct {
+	cfg     AdaptiveConfig
+	current ModelComplexity
+	over    int // Frames over budget in a row
+	under   int // Frames under the headroom in a row
+}
+
+// NewComplexityController creates a controller starting at cfg.Max.
+func NewComplexityController(cfg AdaptiveConfig) *ComplexityController {
+	return &ComplexityController{cfg: cfg, current: cfg.Max}
+}
+
+// Complexity returns the current complexity.
+func (c *ComplexityController) Complexity() ModelComplexity {
+	return c.current
+}
+
+// Observe records the processing latency of a frame and returns the
+// complexity to use from now on, and whether it changed.
+func (c *ComplexityController) Observe(latency time.Duration) (ModelComplexity, bool) {
+	switch {
+	case latency > c.cfg.Budget:
+		c.over++
+		c.under = 0
+	case float64(latency) < c.cfg.Headroom*float64(c.cfg.Budget):
+		c.under++
+		c.over = 0
+	default:
+		c.over, c.under = 0, 0
+	}
+
+	switch {
+	case c.over >= c.cfg.DownAfter && c.current > ComplexityLite:
+		c.current--
+	case c.under >= c.cfg.UpAfter && c.current < c.cfg.Max:
+		c.current++
+	default:
+		return c.current, false
+	}
+	c.over, c.under = 0, 0
+	return c.current, true
+}
+
+// AdaptiveProcessor is a miface.Processor that runs MediaPipe at the
+// complexity a ComplexityController picks from how long each frame takes,
+// re-creating the processor whenever the complexity changes.
+type AdaptiveProcessor struct {
+	mu         sync.Mutex
+	config     Config
+	controller *ComplexityController
+	processor  *TrackerProcessor
+	closed     bool
+
+	// open creates a processor for a config; replaced in tests
+	open func(Config) (*MediaPipeProcessor, error)
+	now  func() time.Time
+}
+
+// NewAdaptiveProcessor creates an adaptive processor. config.ModelComplexity
+// is ignored: the processor starts at adaptive.Max.
+func NewAdaptiveProcessor(config Config, adaptive AdaptiveConfig) (*AdaptiveProcessor, error) {
+	return newAdaptiveProcessor(config, adaptive, NewMediaPipeProcessor)
+}
+
+// newAdaptiveProcessor creates an adaptive processor whose processors are
+// created by open.
+func newAdaptiveProcessor(config Config, adaptive AdaptiveConfig, open func(Config) (*MediaPipeProcessor, error)) (*AdaptiveProcessor, error) {
+	a := &AdaptiveProcessor{
+		config:     config,
+		controller: NewComplexityController(adaptive),
+		open:       open,
+		now:        time.Now,
+	}
+	a.config.ModelComplexity = adaptive.Max
+
+	p, err := open(a.config)
+	if err != nil {
+		return nil, err
+	}
+	a.processor = NewTrackerProcessor(p)
+	return a, nil
+}
+
+// Complexity returns the model complexity in use.
+func (a *AdaptiveProcessor) Complexity() ModelComplexity {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return a.config.ModelComplexity
+}
+
+// Process runs MediaPipe on an RGB24 frame, then adjusts the complexity
+// for the following frames from how long it took.
+func (a *AdaptiveProcessor) Process(ctx context.Context, frame []byte, width, height int) (*miface.TrackingData, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if a.closed {
+		return nil, fmt.Errorf("processor is closed")
+	}
+
+	start := a.now()
+	data, err := a.processor.Process(ctx, frame, width, height)
+	if err != nil && ctx.Err() == nil {
+		return nil, err
+	}
+
+	// Timed-out frames count too: they are the slowest of all
+	if complexity, changed := a.controller.Observe(a.now().Sub(start)); changed {
+		if err := a.reopen(complexity); err != nil {
+			return nil, err
+		}
+	}
+	return data, err
+}
+
+// reopen replaces the processor with one at complexity. If that fails,
+// the current processor and complexity are kept. Must be called with a.mu
+// held.
+func (a *AdaptiveProcessor) reopen(complexity ModelComplexity) error {
+	config := a.config
+	config.ModelComplexity = complexity
+	p, err := a.open(config)
+	if err != nil {
+		a.controller.current = a.config.ModelComplexity
+		return fmt.Errorf("switching to model complexity %d: %w", complexity, err)
+	}
+
+	a.processor.Close()
+	a.processor = NewTrackerProcessor(p)
+	a.config = config
+	return nil
+}
+
+// Close releases the current MediaPipe processor.
+func (a *AdaptiveProcessor) Close() error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if a.closed {
+		return nil
+	}
+	a.closed = true
+	return a.processor.Close()
+}
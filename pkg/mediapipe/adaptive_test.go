@@ -0,0 +1,179 @@
+package mediapipe
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+// testAdaptiveConfig has a 30ms budget, steps down after 3 slow frames and
+// up after 5 frames under 15ms.
+func testAdaptiveConfig() AdaptiveConfig {
+	return AdaptiveConfig{
+		Budget:    30 * time.Millisecond,
+		Headroom:  0.5,
+		DownAfter: 3,
+		UpAfter:   5,
+		Max:       ComplexityHeavy,
+	}
+}
+
+// observe feeds latency to c n times and returns the complexities at which
+// it changed.
+func observe(c *ComplexityController, latency time.Duration, n int) []ModelComplexity {
+	var changes []ModelComplexity
+	for i := 0; i < n; i++ {
+		if complexity, changed := c.Observe(latency); changed {
+			changes = append(changes, complexity)
+		}
+	}
+	return changes
+}
+
+func equalComplexities(a, b []ModelComplexity) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func TestComplexityControllerStepsDownAndRecovers(t *testing.T) {
+	c := NewComplexityController(testAdaptiveConfig())
+	if c.Complexity() != ComplexityHeavy {
+		t.Fatalf("expected to start at heavy, got %d", c.Complexity())
+	}
+
+	// Over budget: one step per 3 frames, stopping at lite
+	got := observe(c, 50*time.Millisecond, 10)
+	if want := []ModelComplexity{ComplexityFull, ComplexityLite}; !equalComplexities(got, want) {
+		t.Errorf("expected steps down %v, got %v", want, got)
+	}
+
+	// Plenty of headroom: one step per 5 frames, stopping at the maximum
+	got = observe(c, 10*time.Millisecond, 15)
+	if want := []ModelComplexity{ComplexityFull, ComplexityHeavy}; !equalComplexities(got, want) {
+		t.Errorf("expected steps up %v, got %v", want, got)
+	}
+}
+
+func TestComplexityControllerHysteresis(t *testing.T) {
+	c := NewComplexityController(testAdaptiveConfig())
+
+	// Occasional slow frames don't add up
+	for i := 0; i < 20; i++ {
+		if _, changed := c.Observe(50 * time.Millisecond); changed {
+			t.Fatal("expected isolated slow frames not to step down")
+		}
+		c.Observe(10 * time.Millisecond)
+	}
+
+	// Within budget but without headroom, the complexity holds
+	observe(c, 50*time.Millisecond, 3)
+	if got := observe(c, 20*time.Millisecond, 50); len(got) != 0 {
+		t.Errorf("expected no change between headroom and budget, got %v", got)
+	}
+	if c.Complexity() != ComplexityFull {
+		t.Errorf("expected to stay at full, got %d", c.Complexity())
+	}
+}
+
+// newTestAdaptiveProcessor returns an adaptive processor over mock bridges
+// whose frames take *latency on a fake clock, and the bridges it opened.
+func newTestAdaptiveProcessor(t *testing.T, latency *time.Duration) (*AdaptiveProcessor, *[]*mockBridge) {
+	t.Helper()
+	bridges := new([]*mockBridge)
+	open := func(config Config) (*MediaPipeProcessor, error) {
+		mock := &mockBridge{}
+		*bridges = append(*bridges, mock)
+		return newMediaPipeProcessor(config, mock.open)
+	}
+
+	a, err := newAdaptiveProcessor(DefaultConfig(), testAdaptiveConfig(), open)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	t.Cleanup(func() { a.Close() })
+
+	now := time.Unix(0, 0)
+	var calls int
+	a.now = func() time.Time {
+		// Every other call ends a frame
+		calls++
+		if calls%2 == 0 {
+			now = now.Add(*latency)
+		}
+		return now
+	}
+	return a, bridges
+}
+
+func TestAdaptiveProcessorReopens(t *testing.T) {
+	latency := 50 * time.Millisecond
+	a, bridges := newTestAdaptiveProcessor(t, &latency)
+	frame := make([]byte, 4*4*3)
+
+	process := func(n int) {
+		t.Helper()
+		for i := 0; i < n; i++ {
+			if _, err := a.Process(context.Background(), frame, 4, 4); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+		}
+	}
+
+	process(3)
+	if a.Complexity() != ComplexityFull {
+		t.Fatalf("expected full after 3 slow frames, got %d", a.Complexity())
+	}
+	if len(*bridges) != 2 {
+		t.Fatalf("expected the processor to be re-created, got %d bridges", len(*bridges))
+	}
+	if !(*bridges)[0].closed {
+		t.Error("expected the heavy bridge to be closed")
+	}
+	if got := (*bridges)[1].config.modelComplexity; got != int(ComplexityFull) {
+		t.Errorf("expected the new bridge at full, got %d", got)
+	}
+
+	latency = 5 * time.Millisecond
+	process(5)
+	if a.Complexity() != ComplexityHeavy {
+		t.Errorf("expected heavy again after 5 fast frames, got %d", a.Complexity())
+	}
+	if got := (*bridges)[len(*bridges)-1].config.modelComplexity; got != int(ComplexityHeavy) {
+		t.Errorf("expected the newest bridge at heavy, got %d", got)
+	}
+}
+
+func TestAdaptiveProcessorReopenFailure(t *testing.T) {
+	latency := 50 * time.Millisecond
+	a, bridges := newTestAdaptiveProcessor(t, &latency)
+	failure := errors.New("out of memory")
+	a.open = func(Config) (*MediaPipeProcessor, error) { return nil, failure }
+
+	frame := make([]byte, 4*4*3)
+	var err error
+	for i := 0; i < 3 && err == nil; i++ {
+		_, err = a.Process(context.Background(), frame, 4, 4)
+	}
+	if !errors.Is(err, failure) {
+		t.Errorf("expected the reopen error, got %v", err)
+	}
+
+	// The heavy processor keeps running
+	if a.Complexity() != ComplexityHeavy || a.controller.Complexity() != ComplexityHeavy {
+		t.Errorf("expected to stay at heavy, got %d (controller %d)", a.Complexity(), a.controller.Complexity())
+	}
+	if (*bridges)[0].closed {
+		t.Error("expected the heavy bridge to stay open")
+	}
+	if _, err := a.Process(context.Background(), frame, 4, 4); err != nil {
+		t.Errorf("unexpected error after a failed switch: %v", err)
+	}
+}
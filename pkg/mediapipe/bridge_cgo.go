@@ -0,0 +1,176 @@
+//go:build mediapipe
+
+package mediapipe
+
+/*
+#cgo CXXFLAGS: -std=c++17
+#cgo LDFLAGS: -L${SRCDIR}/../../cpp_core/bazel-bin -lmediapipe_bridge
+#cgo LDFLAGS: -Wl,-rpath,${SRCDIR}/../../cpp_core/bazel-bin
+#include "../../cpp_core/mediapipe_bridge.h"
+#include <stdlib.h>
+*/
+import "C"
+import "unsafe"
+
+// cgoBridge runs the graph through the C++ bridge library.
+type cgoBridge struct {
+	handle C.MPHandle // Opaque C++ object handle
+}
+
+// openBridge creates the C++ MediaPipe graph.
+func openBridge(config bridgeConfig) (bridge, error) {
+	cConfig := C.MPConfig{
+		model_complexity:         C.int(config.modelComplexity),
+		min_detection_confidence: C.float(config.minDetectionConfidence),
+		min_tracking_confidence:  C.float(config.minTrackingConfidence),
+		static_image_mode:        C.bool(config.staticImageMode),
+		smooth_landmarks:         C.bool(config.smoothLandmarks),
+		refine_face_landmarks:    C.bool(config.refineFaceLandmarks),
+		enable_segmentation:      C.bool(config.enableSegmentation),
+		enable_face:              C.bool(config.enableFace),
+		enable_hands:             C.bool(config.enableHands),
+		enable_pose:              C.bool(config.enablePose),
+	}
+
+	handle := C.MP_Create(&cConfig)
+	if handle == nil {
+		return nil, lastError(OpInit, handle)
+	}
+	return &cgoBridge{handle: handle}, nil
+}
+
+// process runs the C++ graph on an RGB24 frame.
+func (b *cgoBridge) process(pixels []uint8, width, height int) (*TrackingData, error) {
+	// Call C++ bridge to process frame
+	var result C.MPResults
+	success := C.MP_Process(
+		b.handle,
+		(*C.uint8_t)(unsafe.Pointer(&pixels[0])),
+		C.int(width),
+		C.int(height),
+		&result,
+	)
+
+	if !success {
+		return nil, lastError(OpProcess, b.handle)
+	}
+
+	// Convert C result to Go TrackingData
+	data := convertResult(&result)
+
+	// Free C++ allocated memory
+	C.MP_ReleaseResults(&result)
+
+	return data, nil
+}
+
+// close destroys the C++ graph.
+func (b *cgoBridge) close() {
+	if b.handle != nil {
+		C.MP_Destroy(b.handle)
+		b.handle = nil
+	}
+}
+
+// lastError fetches the bridge's last error as a *MediaPipeError.
+func lastError(op string, handle C.MPHandle) *MediaPipeError {
+	err := C.MP_GetLastError(handle)
+	return &MediaPipeError{
+		Op:      op,
+		Code:    int(err.code),
+		Message: C.GoString(&err.message[0]),
+	}
+}
+
+// convertResult converts MediaPipe C++ results to Go TrackingData structure.
+func convertResult(result *C.MPResults) *TrackingData {
+	data := &TrackingData{
+		Timestamp: 0, // TODO: Get actual timestamp from MediaPipe
+	}
+
+	// Convert face landmarks (468 or 478 points with refinement)
+	if result.face_count > 0 {
+		data.Face = &FaceData{
+			Landmarks:    make([]Landmark, result.face_count),
+			BlendShapes:  make(map[string]float32),
+			HeadRotation: Quaternion{X: 0, Y: 0, Z: 0, W: 1}, // Identity, will be computed later
+			HeadPosition: Point3D{X: 0, Y: 0, Z: 0},          // Will be computed later
+		}
+
+		// Copy landmarks from C array
+		landmarks := (*[1 << 16]C.MPLandmark)(unsafe.Pointer(result.face_landmarks))[:result.face_count:result.face_count]
+		for i, lm := range landmarks {
+			data.Face.Landmarks[i] = Landmark{
+				Point: Point3D{
+					X: float64(lm.x),
+					Y: float64(lm.y),
+					Z: float64(lm.z),
+				},
+				Visibility: float32(lm.visibility),
+				Presence:   float32(lm.presence),
+			}
+		}
+	}
+
+	// Convert left hand landmarks (21 points)
+	if result.left_hand_count > 0 {
+		data.LeftHand = &HandData{
+			Landmarks: make([]Landmark, result.left_hand_count),
+		}
+
+		landmarks := (*[21]C.MPLandmark)(unsafe.Pointer(result.left_hand_landmarks))[:result.left_hand_count:result.left_hand_count]
+		for i, lm := range landmarks {
+			data.LeftHand.Landmarks[i] = Landmark{
+				Point: Point3D{
+					X: float64(lm.x),
+					Y: float64(lm.y),
+					Z: float64(lm.z),
+				},
+				Visibility: float32(lm.visibility),
+				Presence:   float32(lm.presence),
+			}
+		}
+	}
+
+	// Convert right hand landmarks (21 points)
+	if result.right_hand_count > 0 {
+		data.RightHand = &HandData{
+			Landmarks: make([]Landmark, result.right_hand_count),
+		}
+
+		landmarks := (*[21]C.MPLandmark)(unsafe.Pointer(result.right_hand_landmarks))[:result.right_hand_count:result.right_hand_count]
+		for i, lm := range landmarks {
+			data.RightHand.Landmarks[i] = Landmark{
+				Point: Point3D{
+					X: float64(lm.x),
+					Y: float64(lm.y),
+					Z: float64(lm.z),
+				},
+				Visibility: float32(lm.visibility),
+				Presence:   float32(lm.presence),
+			}
+		}
+	}
+
+	// Convert pose landmarks (33 points, but we focus on upper body 0-16)
+	if result.pose_count > 0 {
+		data.Pose = &PoseData{
+			Landmarks: make([]Landmark, result.pose_count),
+		}
+
+		landmarks := (*[33]C.MPLandmark)(unsafe.Pointer(result.pose_landmarks))[:result.pose_count:result.pose_count]
+		for i, lm := range landmarks {
+			data.Pose.Landmarks[i] = Landmark{
+				Point: Point3D{
+					X: float64(lm.x),
+					Y: float64(lm.y),
+					Z: float64(lm.z),
+				},
+				Visibility: float32(lm.visibility),
+				Presence:   float32(lm.presence),
+			}
+		}
+	}
+
+	return data
+}
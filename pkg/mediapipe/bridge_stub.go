@@ -0,0 +1,9 @@
+//go:build !mediapipe
+
+package mediapipe
+
+// openBridge always fails with ErrBridgeUnavailable in this build.
+// Build with -tags mediapipe to enable real processing.
+func openBridge(config bridgeConfig) (bridge, error) {
+	return nil, unavailableError(OpInit)
+}
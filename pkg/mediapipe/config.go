@@ -0,0 +1,84 @@
+// Package mediapipe provides MediaPipe Holistic integration for facial landmark detection.
+//
+// The CGO bridge to the C++ library in cpp_core is only compiled with the
+// "mediapipe" build tag. Without it, NewMediaPipeProcessor returns
+// ErrBridgeUnavailable, so the rest of the package can be built and tested
+// on machines without the MediaPipe library.
+package mediapipe
+
+import (
+	"errors"
+
+	"github.com/MiFaceDEV/miface/internal/config"
+)
+
+// ErrBridgeUnavailable is returned when the package is built without the
+// "mediapipe" build tag and therefore without the C++ bridge.
+var ErrBridgeUnavailable = errors.New("mediapipe: built without the C++ bridge (use -tags mediapipe)")
+
+// ErrFrameSize is returned when an RGB24 frame's length does not match its
+// width and height.
+var ErrFrameSize = errors.New("mediapipe: frame size does not match its dimensions")
+
+// ModelComplexity defines the MediaPipe model complexity level.
+type ModelComplexity int
+
+const (
+	// ComplexityLite is the fastest, least accurate model (0).
+	ComplexityLite ModelComplexity = 0
+	// ComplexityFull is balanced performance and accuracy (1).
+	ComplexityFull ModelComplexity = 1
+	// ComplexityHeavy is the most accurate, slowest model (2).
+	ComplexityHeavy ModelComplexity = 2
+)
+
+// Config holds MediaPipe Holistic configuration.
+type Config struct {
+	// ModelComplexity controls the trade-off between speed and accuracy.
+	ModelComplexity ModelComplexity
+	// MinDetectionConfidence is the minimum confidence [0.0, 1.0] for person detection.
+	MinDetectionConfidence float32
+	// MinTrackingConfidence is the minimum confidence [0.0, 1.0] for landmark tracking.
+	MinTrackingConfidence float32
+	// StaticImageMode disables tracking between frames (slower but more accurate).
+	StaticImageMode bool
+	// SmoothLandmarks applies temporal smoothing (only when StaticImageMode=false).
+	SmoothLandmarks bool
+	// RefineFaceLandmarks adds the 10 iris landmarks (468-477) to the face mesh,
+	// which miface.EstimateGaze needs.
+	RefineFaceLandmarks bool
+
+	// EnableFace, EnableHands and EnablePose select the modalities the graph
+	// runs. Disabled modalities are skipped in the bridge, not just dropped
+	// from the results. Hands are located from the pose, so the pose model
+	// still runs when hands are enabled; only its output is skipped.
+	EnableFace  bool
+	EnableHands bool
+	EnablePose  bool
+}
+
+// DefaultConfig returns a recommended configuration for real-time VTubing.
+func DefaultConfig() Config {
+	return Config{
+		ModelComplexity:        ComplexityFull,
+		MinDetectionConfidence: 0.5,
+		MinTrackingConfidence:  0.5,
+		StaticImageMode:        false,
+		SmoothLandmarks:        true,
+		EnableFace:             true,
+		EnableHands:            true,
+		EnablePose:             true,
+	}
+}
+
+// ConfigForTracking returns DefaultConfig with the modalities enabled in
+// tracking, so disabled modalities are never computed. The face mesh is
+// refined with the iris landmarks when tracking.EstimateGaze is set.
+func ConfigForTracking(tracking config.TrackingConfig) Config {
+	c := DefaultConfig()
+	c.EnableFace = tracking.EnableFace
+	c.EnableHands = tracking.EnableHands
+	c.EnablePose = tracking.EnablePose
+	c.RefineFaceLandmarks = tracking.EstimateGaze
+	return c
+}
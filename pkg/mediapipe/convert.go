@@ -0,0 +1,159 @@
+package mediapipe
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/MiFaceDEV/miface/internal/config"
+	"github.com/MiFaceDEV/miface/pkg/miface"
+	"gocv.io/x/gocv"
+)
+
+// ConvertTrackingData converts MediaPipe results to the miface.TrackingData
+// form used by the tracker and senders. Scores are widened to float64.
+// Hand Confidence is derived from the hand landmarks' scores with
+// miface.HandConfidence, so a hand MediaPipe keeps reporting as it fades
+// out can be dropped with the min_hand_confidence tracking setting.
+func ConvertTrackingData(data *TrackingData) *miface.TrackingData {
+	if data == nil {
+		return nil
+	}
+
+	out := &miface.TrackingData{}
+	if data.Timestamp != 0 {
+		out.Timestamp = time.UnixMilli(data.Timestamp)
+	}
+
+	if data.Face != nil {
+		face := &miface.FaceData{
+			Landmarks:    convertLandmarks(data.Face.Landmarks),
+			HeadRotation: miface.Quaternion(data.Face.HeadRotation),
+			HeadPosition: miface.Point3D(data.Face.HeadPosition),
+		}
+		if data.Face.BlendShapes != nil {
+			face.BlendShapes = make(map[string]float64, len(data.Face.BlendShapes))
+			for name, value := range data.Face.BlendShapes {
+				face.BlendShapes[name] = float64(value)
+			}
+		}
+		out.Face = face
+	}
+
+	if data.LeftHand != nil {
+		out.LeftHand = convertHand(data.LeftHand, true)
+	}
+	if data.RightHand != nil {
+		out.RightHand = convertHand(data.RightHand, false)
+	}
+
+	if data.Pose != nil {
+		out.Pose = &miface.PoseData{
+			Landmarks: convertLandmarks(data.Pose.Landmarks),
+		}
+	}
+
+	return out
+}
+
+// convertHand converts a MediaPipe hand, estimating its confidence from
+// its landmarks.
+func convertHand(hand *HandData, isLeft bool) *miface.HandData {
+	landmarks := convertLandmarks(hand.Landmarks)
+	return &miface.HandData{
+		IsLeft:     isLeft,
+		Landmarks:  landmarks,
+		Confidence: miface.HandConfidence(landmarks),
+	}
+}
+
+// convertLandmarks converts MediaPipe landmarks to miface landmarks.
+func convertLandmarks(landmarks []Landmark) []miface.Landmark {
+	if landmarks == nil {
+		return nil
+	}
+	out := make([]miface.Landmark, len(landmarks))
+	for i, lm := range landmarks {
+		out[i] = miface.Landmark{
+			Point:      miface.Point3D(lm.Point),
+			Visibility: float64(lm.Visibility),
+			Presence:   float64(lm.Presence),
+		}
+	}
+	return out
+}
+
+// TrackerProcessor adapts a MediaPipeProcessor to the miface.Processor
+// interface so it can be passed to Tracker.SetProcessor.
+type TrackerProcessor struct {
+	processor *MediaPipeProcessor
+}
+
+// NewTrackerProcessor wraps p for use with miface.Tracker.
+func NewTrackerProcessor(p *MediaPipeProcessor) *TrackerProcessor {
+	return &TrackerProcessor{processor: p}
+}
+
+// Process runs MediaPipe on an RGB24 frame and returns miface tracking data.
+// The frame must be exactly width*height*3 bytes, or ErrFrameSize is returned.
+// It returns early with the context error if ctx ends first; see
+// MediaPipeProcessor.ProcessContext.
+func (t *TrackerProcessor) Process(ctx context.Context, frame []byte, width, height int) (*miface.TrackingData, error) {
+	if width <= 0 || height <= 0 || len(frame) != width*height*3 {
+		return nil, fmt.Errorf("%w: %d bytes for %dx%d RGB24, want %d",
+			ErrFrameSize, len(frame), width, height, max(width*height*3, 0))
+	}
+
+	mat, err := gocv.NewMatFromBytes(height, width, gocv.MatTypeCV8UC3, frame)
+	if err != nil {
+		return nil, fmt.Errorf("wrapping frame: %w", err)
+	}
+	defer mat.Close()
+
+	data, err := t.processor.ProcessContext(ctx, mat)
+	if err != nil {
+		return nil, err
+	}
+	return ConvertTrackingData(data), nil
+}
+
+// Close releases the underlying MediaPipe processor.
+func (t *TrackerProcessor) Close() error {
+	return t.processor.Close()
+}
+
+// NewProcessor returns a miface.Processor for the tracking settings in cfg:
+// MediaPipe wrapped with NewTrackerProcessor when the bridge is compiled in,
+// or an AdaptiveProcessor budgeted for the camera frame rate with
+// Tracking.AdaptiveComplexity, and otherwise a miface.FallbackProcessor so
+// the pipeline still runs. With Tracking.EstimateGaze the MediaPipe
+// processor is chained with miface.NewGazeStage. Other MediaPipe failures
+// are returned.
+func NewProcessor(cfg *config.Config) (miface.Processor, error) {
+	mpConfig := ConfigForTracking(cfg.Tracking)
+	var p miface.Processor
+	var err error
+	if cfg.Tracking.AdaptiveComplexity {
+		p, err = NewAdaptiveProcessor(mpConfig, DefaultAdaptiveConfig(cfg.Camera.FPS, mpConfig.ModelComplexity))
+	} else {
+		var mp *MediaPipeProcessor
+		if mp, err = NewMediaPipeProcessor(mpConfig); err == nil {
+			p = NewTrackerProcessor(mp)
+		}
+	}
+
+	if errors.Is(err, ErrBridgeUnavailable) {
+		fallback := miface.DefaultFallbackConfig()
+		fallback.EnableFace = cfg.Tracking.EnableFace
+		fallback.EnablePose = cfg.Tracking.EnablePose
+		return miface.NewFallbackProcessor(fallback), nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if cfg.Tracking.EstimateGaze {
+		p = miface.NewChainProcessor(p, miface.NewGazeStage())
+	}
+	return p, nil
+}
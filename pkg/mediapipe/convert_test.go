@@ -0,0 +1,147 @@
+package mediapipe
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/MiFaceDEV/miface/pkg/miface"
+)
+
+func TestConvertTrackingData(t *testing.T) {
+	lm := func(x, y, z float64, vis, pres float32) Landmark {
+		return Landmark{Point: Point3D{X: x, Y: y, Z: z}, Visibility: vis, Presence: pres}
+	}
+
+	src := &TrackingData{
+		Timestamp: 1700000000123,
+		Face: &FaceData{
+			Landmarks:    []Landmark{lm(0.1, 0.2, 0.3, 0.9, 0.8)},
+			BlendShapes:  map[string]float32{"jawOpen": 0.25},
+			HeadRotation: Quaternion{X: 0.1, Y: 0.2, Z: 0.3, W: 0.9},
+			HeadPosition: Point3D{X: 1, Y: 2, Z: 3},
+		},
+		LeftHand:  &HandData{Landmarks: []Landmark{lm(0.4, 0.5, 0.6, 0.5, 0.25)}},
+		RightHand: &HandData{Landmarks: []Landmark{lm(0.7, 0.8, 0.9, 0.75, 0.5)}},
+		Pose:      &PoseData{Landmarks: []Landmark{lm(0.5, 0.5, 0, 1, 0.125)}},
+	}
+
+	got := ConvertTrackingData(src)
+
+	if got.Timestamp.UnixMilli() != src.Timestamp {
+		t.Errorf("timestamp = %d, want %d", got.Timestamp.UnixMilli(), src.Timestamp)
+	}
+
+	if got.Face == nil {
+		t.Fatal("expected face data")
+	}
+	wantFace := miface.Landmark{Point: miface.Point3D{X: 0.1, Y: 0.2, Z: 0.3}, Visibility: float64(float32(0.9)), Presence: float64(float32(0.8))}
+	if got.Face.Landmarks[0] != wantFace {
+		t.Errorf("face landmark = %+v, want %+v", got.Face.Landmarks[0], wantFace)
+	}
+	if got.Face.BlendShapes["jawOpen"] != 0.25 {
+		t.Errorf("jawOpen = %f, want 0.25", got.Face.BlendShapes["jawOpen"])
+	}
+	if got.Face.HeadRotation != (miface.Quaternion{X: 0.1, Y: 0.2, Z: 0.3, W: 0.9}) {
+		t.Errorf("head rotation = %+v", got.Face.HeadRotation)
+	}
+	if got.Face.HeadPosition != (miface.Point3D{X: 1, Y: 2, Z: 3}) {
+		t.Errorf("head position = %+v", got.Face.HeadPosition)
+	}
+
+	if got.LeftHand == nil || !got.LeftHand.IsLeft {
+		t.Fatalf("expected left hand with IsLeft=true, got %+v", got.LeftHand)
+	}
+	if got.LeftHand.Landmarks[0].Presence != 0.25 || got.LeftHand.Landmarks[0].Visibility != 0.5 {
+		t.Errorf("left hand scores = %+v", got.LeftHand.Landmarks[0])
+	}
+	if got.RightHand == nil || got.RightHand.IsLeft {
+		t.Fatalf("expected right hand with IsLeft=false, got %+v", got.RightHand)
+	}
+	if got.RightHand.Landmarks[0].Presence != 0.5 || got.RightHand.Landmarks[0].Visibility != 0.75 {
+		t.Errorf("right hand scores = %+v", got.RightHand.Landmarks[0])
+	}
+	if got.LeftHand.Confidence != 0.5 || got.RightHand.Confidence != 0.75 {
+		t.Errorf("expected hand confidence from the landmark scores, got %f and %f",
+			got.LeftHand.Confidence, got.RightHand.Confidence)
+	}
+
+	if got.Pose == nil || got.Pose.Landmarks[0].Presence != 0.125 {
+		t.Errorf("pose landmark = %+v", got.Pose)
+	}
+}
+
+func TestConvertTrackingDataNil(t *testing.T) {
+	if got := ConvertTrackingData(nil); got != nil {
+		t.Errorf("expected nil, got %+v", got)
+	}
+
+	got := ConvertTrackingData(&TrackingData{})
+	if got.Face != nil || got.LeftHand != nil || got.RightHand != nil || got.Pose != nil {
+		t.Errorf("expected empty tracking data, got %+v", got)
+	}
+	if !got.Timestamp.IsZero() {
+		t.Errorf("expected zero timestamp, got %v", got.Timestamp)
+	}
+}
+
+func TestTrackerProcessorImplementsProcessor(t *testing.T) {
+	var _ miface.Processor = (*TrackerProcessor)(nil)
+}
+
+func TestTrackerProcessorProcess(t *testing.T) {
+	mock := &mockBridge{}
+	p, err := newMediaPipeProcessor(DefaultConfig(), mock.open)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	tp := NewTrackerProcessor(p)
+	defer tp.Close()
+
+	data, err := tp.Process(context.Background(), make([]byte, 4*3*3), 4, 3)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if mock.frames != 1 {
+		t.Errorf("expected 1 frame to reach the bridge, got %d", mock.frames)
+	}
+	if data.Face == nil || len(data.Face.Landmarks) != 1 {
+		t.Fatalf("expected the converted face, got %+v", data.Face)
+	}
+	if got := data.Face.Landmarks[0].Point; got != (miface.Point3D{X: 0.5, Y: 0.5}) {
+		t.Errorf("expected landmark at (0.5, 0.5), got %+v", got)
+	}
+	if data.Pose == nil || data.LeftHand == nil || data.RightHand == nil {
+		t.Error("expected pose and hands to be converted")
+	}
+}
+
+func TestTrackerProcessorFrameSize(t *testing.T) {
+	mock := &mockBridge{}
+	p, err := newMediaPipeProcessor(DefaultConfig(), mock.open)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	tp := NewTrackerProcessor(p)
+	defer tp.Close()
+
+	tests := []struct {
+		name          string
+		size          int
+		width, height int
+	}{
+		{"short", 4*3*3 - 1, 4, 3},
+		{"long", 4*3*3 + 3, 4, 3},
+		{"no pixels", 0, 0, 0},
+		{"negative", 12, -2, -2},
+	}
+	for _, tt := range tests {
+		_, err := tp.Process(context.Background(), make([]byte, tt.size), tt.width, tt.height)
+		if !errors.Is(err, ErrFrameSize) {
+			t.Errorf("%s: expected ErrFrameSize, got %v", tt.name, err)
+		}
+	}
+	if mock.frames != 0 {
+		t.Errorf("expected no frame to reach the bridge, got %d", mock.frames)
+	}
+}
@@ -0,0 +1,59 @@
+package mediapipe
+
+import (
+	"fmt"
+
+	"github.com/MiFaceDEV/miface/pkg/miface"
+)
+
+// Operations reported in MediaPipeError.Op.
+const (
+	// OpInit is creating the MediaPipe graph.
+	OpInit = "init"
+	// OpProcess is running the graph on a frame.
+	OpProcess = "process"
+)
+
+// codeBridgeUnavailable is the error code used when the C++ bridge is not compiled in.
+const codeBridgeUnavailable = -1
+
+// MediaPipeError is a failure reported by the C++ bridge, carrying the
+// code and message from MP_GetLastError.
+//
+// Initialization failures match miface.ErrMediaPipeInit with errors.Is.
+type MediaPipeError struct {
+	// Op is the bridge operation that failed (OpInit or OpProcess).
+	Op string
+	// Code is the non-zero bridge error code.
+	Code int
+	// Message is the bridge error message.
+	Message string
+	// Err is an optional underlying cause.
+	Err error
+}
+
+// Error implements the error interface.
+func (e *MediaPipeError) Error() string {
+	return fmt.Sprintf("mediapipe %s failed (code %d): %s", e.Op, e.Code, e.Message)
+}
+
+// Unwrap returns the underlying cause, if any.
+func (e *MediaPipeError) Unwrap() error {
+	return e.Err
+}
+
+// Is reports whether an initialization failure is being matched against
+// miface.ErrMediaPipeInit.
+func (e *MediaPipeError) Is(target error) bool {
+	return e.Op == OpInit && target == miface.ErrMediaPipeInit
+}
+
+// unavailableError reports the missing bridge as a *MediaPipeError.
+func unavailableError(op string) *MediaPipeError {
+	return &MediaPipeError{
+		Op:      op,
+		Code:    codeBridgeUnavailable,
+		Message: ErrBridgeUnavailable.Error(),
+		Err:     ErrBridgeUnavailable,
+	}
+}
@@ -1,233 +1,219 @@
-// Package mediapipe provides MediaPipe Holistic integration for facial landmark detection.
 package mediapipe
 
-/*
-#cgo CXXFLAGS: -std=c++17
-#cgo LDFLAGS: -L${SRCDIR}/../../cpp_core/bazel-bin -lmediapipe_bridge
-#cgo LDFLAGS: -Wl,-rpath,${SRCDIR}/../../cpp_core/bazel-bin
-#include "../../cpp_core/mediapipe_bridge.h"
-#include <stdlib.h>
-*/
-import "C"
 import (
+	"context"
+	"errors"
 	"fmt"
 	"sync"
-	"unsafe"
 
+	"github.com/MiFaceDEV/miface/pkg/miface"
 	"gocv.io/x/gocv"
 )
 
-// ModelComplexity defines the MediaPipe model complexity level.
-type ModelComplexity int
-
-const (
-	// ComplexityLite is the fastest, least accurate model (0).
-	ComplexityLite ModelComplexity = 0
-	// ComplexityFull is balanced performance and accuracy (1).
-	ComplexityFull ModelComplexity = 1
-	// ComplexityHeavy is the most accurate, slowest model (2).
-	ComplexityHeavy ModelComplexity = 2
-)
+// bridge runs the MediaPipe graph. The cgo implementation is only compiled
+// with the "mediapipe" build tag; tests substitute a mock.
+type bridge interface {
+	// process runs the graph on an RGB24 frame.
+	process(pixels []uint8, width, height int) (*TrackingData, error)
+	// close releases the graph.
+	close()
+}
 
-// Config holds MediaPipe Holistic configuration.
-type Config struct {
-	// ModelComplexity controls the trade-off between speed and accuracy.
-	ModelComplexity ModelComplexity
-	// MinDetectionConfidence is the minimum confidence [0.0, 1.0] for person detection.
-	MinDetectionConfidence float32
-	// MinTrackingConfidence is the minimum confidence [0.0, 1.0] for landmark tracking.
-	MinTrackingConfidence float32
-	// StaticImageMode disables tracking between frames (slower but more accurate).
-	StaticImageMode bool
-	// SmoothLandmarks applies temporal smoothing (only when StaticImageMode=false).
-	SmoothLandmarks bool
+// bridgeConfig mirrors the C MPConfig passed to MP_Create.
+type bridgeConfig struct {
+	modelComplexity        int
+	minDetectionConfidence float32
+	minTrackingConfidence  float32
+	staticImageMode        bool
+	smoothLandmarks        bool
+	refineFaceLandmarks    bool
+	enableSegmentation     bool
+	enableFace             bool
+	enableHands            bool
+	enablePose             bool
 }
 
-// DefaultConfig returns a recommended configuration for real-time VTubing.
-func DefaultConfig() Config {
-	return Config{
-		ModelComplexity:        ComplexityFull,
-		MinDetectionConfidence: 0.5,
-		MinTrackingConfidence:  0.5,
-		StaticImageMode:        false,
-		SmoothLandmarks:        true,
+// newBridgeConfig converts config to the bridge form.
+func newBridgeConfig(config Config) bridgeConfig {
+	return bridgeConfig{
+		modelComplexity:        int(config.ModelComplexity),
+		minDetectionConfidence: config.MinDetectionConfidence,
+		minTrackingConfidence:  config.MinTrackingConfidence,
+		staticImageMode:        config.StaticImageMode,
+		smoothLandmarks:        config.SmoothLandmarks,
+		refineFaceLandmarks:    config.RefineFaceLandmarks,
+		enableSegmentation:     false, // Not exposed in Go config yet
+		enableFace:             config.EnableFace,
+		enableHands:            config.EnableHands,
+		enablePose:             config.EnablePose,
 	}
 }
 
-// MediaPipeProcessor implements the Processor interface using MediaPipe Holistic.
+// MediaPipeProcessor runs MediaPipe Holistic on frames.
 type MediaPipeProcessor struct {
 	config Config
-	handle C.MPHandle // Opaque C++ object handle
+	bridge bridge
 	mu     sync.Mutex
 	closed bool
+
+	// busy holds a token while the bridge processes a frame, which may
+	// outlive a ProcessContext call whose context ended
+	busy chan struct{}
 }
 
 // NewMediaPipeProcessor creates a new MediaPipe processor instance.
+// At least one of EnableFace, EnableHands and EnablePose must be set.
 func NewMediaPipeProcessor(config Config) (*MediaPipeProcessor, error) {
-	p := &MediaPipeProcessor{
-		config: config,
-	}
+	return newMediaPipeProcessor(config, openBridge)
+}
 
-	// Initialize the C++ bridge
-	cConfig := C.MPConfig{
-		model_complexity:         C.int(config.ModelComplexity),
-		min_detection_confidence: C.float(config.MinDetectionConfidence),
-		min_tracking_confidence:  C.float(config.MinTrackingConfidence),
-		static_image_mode:        C.bool(config.StaticImageMode),
-		smooth_landmarks:         C.bool(config.SmoothLandmarks),
-		refine_face_landmarks:    C.bool(false), // Not exposed in Go config yet
-		enable_segmentation:      C.bool(false), // Not exposed in Go config yet
+// newMediaPipeProcessor creates a processor whose graph is created by open.
+func newMediaPipeProcessor(config Config, open func(bridgeConfig) (bridge, error)) (*MediaPipeProcessor, error) {
+	if !config.EnableFace && !config.EnableHands && !config.EnablePose {
+		return nil, fmt.Errorf("creating MediaPipe processor: %w: no modality enabled", miface.ErrMediaPipeInit)
 	}
 
-	p.handle = C.MP_Create(&cConfig)
-	if p.handle == nil {
-		err := C.MP_GetLastError(p.handle)
-		return nil, fmt.Errorf("mediapipe init failed: %s", C.GoString(&err.message[0]))
+	b, err := open(newBridgeConfig(config))
+	if err != nil {
+		// Bridge errors are usually *MediaPipeError, which already match
+		if !errors.Is(err, miface.ErrMediaPipeInit) {
+			err = fmt.Errorf("%w: %w", miface.ErrMediaPipeInit, err)
+		}
+		return nil, fmt.Errorf("creating MediaPipe processor: %w", err)
 	}
 
-	return p, nil
+	return &MediaPipeProcessor{
+		config: config,
+		bridge: b,
+		busy:   make(chan struct{}, 1),
+	}, nil
 }
 
 // Process processes a single frame and returns tracking data.
 // The input frame must be in RGB format (gocv.MatTypeCV8UC3).
+// Modalities disabled in the config are never reported.
 func (p *MediaPipeProcessor) Process(frame gocv.Mat) (*TrackingData, error) {
-	p.mu.Lock()
-	defer p.mu.Unlock()
+	return p.ProcessContext(context.Background(), frame)
+}
 
-	if p.closed {
-		return nil, fmt.Errorf("processor is closed")
+// ProcessContext is Process with a context. If ctx has a deadline and it
+// passes first, it returns the context error without waiting for MediaPipe:
+// the bridge call cannot be interrupted, so it finishes in the background
+// on a copy of the frame, and later frames wait for it. Without a deadline
+// the frame is processed in place, with no copy, and cancelling ctx only
+// stops it from starting.
+func (p *MediaPipeProcessor) ProcessContext(ctx context.Context, frame gocv.Mat) (*TrackingData, error) {
+	if err := p.checkFrame(frame); err != nil {
+		return nil, err
 	}
-
-	if frame.Empty() {
-		return nil, fmt.Errorf("empty frame")
+	pixels, err := frame.DataPtrUint8()
+	if err != nil {
+		return nil, fmt.Errorf("reading frame pixels: %w", err)
 	}
+	width, height := frame.Cols(), frame.Rows()
 
-	// Ensure RGB format
-	if frame.Type() != gocv.MatTypeCV8UC3 {
-		return nil, fmt.Errorf("frame must be RGB (CV_8UC3), got type %d", frame.Type())
+	if err := ctx.Err(); err != nil {
+		return nil, fmt.Errorf("processing frame: %w", err)
 	}
 
-	width := frame.Cols()
-	height := frame.Rows()
-
-	// Get raw pixel data pointer
-	pixels, _ := frame.DataPtrUint8()
-
-	// Call C++ bridge to process frame
-	var result C.MPResults
-	success := C.MP_Process(
-		p.handle,
-		(*C.uint8_t)(unsafe.Pointer(&pixels[0])),
-		C.int(width),
-		C.int(height),
-		&result,
-	)
-
-	if !success {
-		err := C.MP_GetLastError(p.handle)
-		return nil, fmt.Errorf("mediapipe processing failed: %s", C.GoString(&err.message[0]))
+	// Only one frame is processed at a time
+	select {
+	case p.busy <- struct{}{}:
+	case <-ctx.Done():
+		return nil, fmt.Errorf("processing frame: %w", ctx.Err())
+	}
+	p.mu.Lock()
+	b, closed := p.bridge, p.closed
+	p.mu.Unlock()
+	if closed {
+		p.release()
+		return nil, fmt.Errorf("processor is closed")
 	}
 
-	// Convert C result to Go TrackingData
-	data := p.convertResult(&result)
-
-	// Free C++ allocated memory
-	C.MP_ReleaseResults(&result)
-
-	return data, nil
-}
+	// Copying the frame to return early only pays off with a deadline; a
+	// context that is only cancelled, like the tracker's, would copy every
+	// frame for nothing
+	if _, ok := ctx.Deadline(); !ok {
+		data, err := b.process(pixels, width, height)
+		p.release()
+		return p.result(data, err)
+	}
 
-// convertResult converts MediaPipe C++ results to Go TrackingData structure.
-func (p *MediaPipeProcessor) convertResult(result *C.MPResults) *TrackingData {
-	data := &TrackingData{
-		Timestamp: 0, // TODO: Get actual timestamp from MediaPipe
+	type result struct {
+		data *TrackingData
+		err  error
 	}
+	done := make(chan result, 1)
+	pixels = append([]uint8(nil), pixels...) // frame may be closed before the bridge is done
+	go func() {
+		data, err := b.process(pixels, width, height)
+		p.release()
+		done <- result{data, err}
+	}()
+
+	select {
+	case r := <-done:
+		return p.result(r.data, r.err)
+	case <-ctx.Done():
+		return nil, fmt.Errorf("processing frame: %w", ctx.Err())
+	}
+}
 
-	// Convert face landmarks (468 or 478 points with refinement)
-	if result.face_count > 0 {
-		data.Face = &FaceData{
-			Landmarks:    make([]Landmark, result.face_count),
-			BlendShapes:  make(map[string]float32),
-			HeadRotation: Quaternion{X: 0, Y: 0, Z: 0, W: 1}, // Identity, will be computed later
-			HeadPosition: Point3D{X: 0, Y: 0, Z: 0},          // Will be computed later
-		}
+// checkFrame returns an error if the processor or frame cannot be used.
+func (p *MediaPipeProcessor) checkFrame(frame gocv.Mat) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
 
-		// Copy landmarks from C array
-		landmarks := (*[1 << 16]C.MPLandmark)(unsafe.Pointer(result.face_landmarks))[:result.face_count:result.face_count]
-		for i, lm := range landmarks {
-			data.Face.Landmarks[i] = Landmark{
-				Point: Point3D{
-					X: float64(lm.x),
-					Y: float64(lm.y),
-					Z: float64(lm.z),
-				},
-				Visibility: float32(lm.visibility),
-				Presence:   float32(lm.presence),
-			}
-		}
+	if p.closed {
+		return fmt.Errorf("processor is closed")
+	}
+	if p.bridge == nil {
+		return fmt.Errorf("processing frame: %w", unavailableError(OpProcess))
 	}
 
-	// Convert left hand landmarks (21 points)
-	if result.left_hand_count > 0 {
-		data.LeftHand = &HandData{
-			Landmarks: make([]Landmark, result.left_hand_count),
-		}
+	if frame.Empty() {
+		return fmt.Errorf("empty frame")
+	}
 
-		landmarks := (*[21]C.MPLandmark)(unsafe.Pointer(result.left_hand_landmarks))[:result.left_hand_count:result.left_hand_count]
-		for i, lm := range landmarks {
-			data.LeftHand.Landmarks[i] = Landmark{
-				Point: Point3D{
-					X: float64(lm.x),
-					Y: float64(lm.y),
-					Z: float64(lm.z),
-				},
-				Visibility: float32(lm.visibility),
-				Presence:   float32(lm.presence),
-			}
-		}
+	// Ensure RGB format
+	if frame.Type() != gocv.MatTypeCV8UC3 {
+		return fmt.Errorf("frame must be RGB (CV_8UC3), got type %d", frame.Type())
 	}
+	return nil
+}
 
-	// Convert right hand landmarks (21 points)
-	if result.right_hand_count > 0 {
-		data.RightHand = &HandData{
-			Landmarks: make([]Landmark, result.right_hand_count),
-		}
+// release ends a bridge call, closing the bridge if Close was called
+// while it ran.
+func (p *MediaPipeProcessor) release() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
 
-		landmarks := (*[21]C.MPLandmark)(unsafe.Pointer(result.right_hand_landmarks))[:result.right_hand_count:result.right_hand_count]
-		for i, lm := range landmarks {
-			data.RightHand.Landmarks[i] = Landmark{
-				Point: Point3D{
-					X: float64(lm.x),
-					Y: float64(lm.y),
-					Z: float64(lm.z),
-				},
-				Visibility: float32(lm.visibility),
-				Presence:   float32(lm.presence),
-			}
-		}
+	if p.closed && p.bridge != nil {
+		p.bridge.close()
+		p.bridge = nil
 	}
+	<-p.busy
+}
 
-	// Convert pose landmarks (33 points, but we focus on upper body 0-16)
-	if result.pose_count > 0 {
-		data.Pose = &PoseData{
-			Landmarks: make([]Landmark, result.pose_count),
-		}
-
-		landmarks := (*[33]C.MPLandmark)(unsafe.Pointer(result.pose_landmarks))[:result.pose_count:result.pose_count]
-		for i, lm := range landmarks {
-			data.Pose.Landmarks[i] = Landmark{
-				Point: Point3D{
-					X: float64(lm.x),
-					Y: float64(lm.y),
-					Z: float64(lm.z),
-				},
-				Visibility: float32(lm.visibility),
-				Presence:   float32(lm.presence),
-			}
-		}
+// result wraps a bridge error, or drops the modalities disabled in the
+// config from data.
+func (p *MediaPipeProcessor) result(data *TrackingData, err error) (*TrackingData, error) {
+	if err != nil {
+		return nil, fmt.Errorf("processing frame: %w", err)
 	}
 
-	return data
+	// The bridge skips disabled modalities; drop any it reported anyway
+	if !p.config.EnableFace {
+		data.Face = nil
+	}
+	if !p.config.EnableHands {
+		data.LeftHand = nil
+		data.RightHand = nil
+	}
+	if !p.config.EnablePose {
+		data.Pose = nil
+	}
+	return data, nil
 }
 
 // Close releases MediaPipe resources.
@@ -238,57 +224,17 @@ func (p *MediaPipeProcessor) Close() error {
 	if p.closed {
 		return nil
 	}
+	p.closed = true
 
-	if p.handle != nil {
-		C.MP_Destroy(p.handle)
-		p.handle = nil
+	// A frame still being processed closes the bridge when it is done
+	select {
+	case p.busy <- struct{}{}:
+		if p.bridge != nil {
+			p.bridge.close()
+			p.bridge = nil
+		}
+		<-p.busy
+	default:
 	}
-
-	p.closed = true
 	return nil
 }
-
-// TrackingData represents the complete tracking output from MediaPipe.
-// This is defined here to avoid circular imports with the main miface package.
-type TrackingData struct {
-	Timestamp int64     // Frame timestamp in milliseconds
-	Face      *FaceData // Facial landmarks and expressions
-	LeftHand  *HandData // Left hand landmarks
-	RightHand *HandData // Right hand landmarks
-	Pose      *PoseData // Body pose landmarks
-}
-
-// FaceData contains facial tracking information.
-type FaceData struct {
-	Landmarks    []Landmark         // 468 face mesh landmarks
-	BlendShapes  map[string]float32 // ARKit-style blend shapes (to be computed)
-	HeadRotation Quaternion         // Head orientation
-	HeadPosition Point3D            // Head position in world space
-}
-
-// HandData contains hand tracking information.
-type HandData struct {
-	Landmarks []Landmark // 21 hand landmarks
-}
-
-// PoseData contains body pose tracking information.
-type PoseData struct {
-	Landmarks []Landmark // 33 pose landmarks (focus on upper body)
-}
-
-// Landmark represents a single 3D point with confidence scores.
-type Landmark struct {
-	Point      Point3D // 3D coordinates
-	Visibility float32 // Visibility score [0.0, 1.0]
-	Presence   float32 // Presence score [0.0, 1.0]
-}
-
-// Point3D represents a 3D point in space.
-type Point3D struct {
-	X, Y, Z float64
-}
-
-// Quaternion represents a rotation in 3D space.
-type Quaternion struct {
-	X, Y, Z, W float64
-}
@@ -0,0 +1,64 @@
+//go:build !mediapipe
+
+package mediapipe
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/MiFaceDEV/miface/internal/config"
+	"github.com/MiFaceDEV/miface/pkg/miface"
+	"gocv.io/x/gocv"
+)
+
+func TestNewMediaPipeProcessorWithoutBridge(t *testing.T) {
+	_, err := NewMediaPipeProcessor(DefaultConfig())
+	if !errors.Is(err, ErrBridgeUnavailable) {
+		t.Errorf("expected ErrBridgeUnavailable, got %v", err)
+	}
+}
+
+func TestMediaPipeErrorExtraction(t *testing.T) {
+	_, err := NewMediaPipeProcessor(DefaultConfig())
+
+	var mpErr *MediaPipeError
+	if !errors.As(err, &mpErr) {
+		t.Fatalf("expected *MediaPipeError, got %T: %v", err, err)
+	}
+	if mpErr.Op != OpInit {
+		t.Errorf("expected op %q, got %q", OpInit, mpErr.Op)
+	}
+	if mpErr.Code == 0 {
+		t.Error("expected non-zero error code")
+	}
+	if !errors.Is(err, miface.ErrMediaPipeInit) {
+		t.Error("expected init failure to match miface.ErrMediaPipeInit")
+	}
+
+	var p MediaPipeProcessor
+	_, err = p.Process(gocv.NewMat())
+	if !errors.As(err, &mpErr) {
+		t.Fatalf("expected *MediaPipeError, got %T: %v", err, err)
+	}
+	if mpErr.Op != OpProcess {
+		t.Errorf("expected op %q, got %q", OpProcess, mpErr.Op)
+	}
+	if errors.Is(err, miface.ErrMediaPipeInit) {
+		t.Error("expected processing failure not to match miface.ErrMediaPipeInit")
+	}
+}
+
+func TestNewProcessorFallsBackWithoutBridge(t *testing.T) {
+	cfg := config.Default()
+	for _, adaptive := range []bool{false, true} {
+		cfg.Tracking.AdaptiveComplexity = adaptive
+		p, err := NewProcessor(cfg)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if _, ok := p.(*miface.FallbackProcessor); !ok {
+			t.Errorf("adaptive=%v: expected a *miface.FallbackProcessor, got %T", adaptive, p)
+		}
+		p.Close()
+	}
+}
@@ -0,0 +1,287 @@
+package mediapipe
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/MiFaceDEV/miface/internal/config"
+	"github.com/MiFaceDEV/miface/pkg/miface"
+	"gocv.io/x/gocv"
+)
+
+// mockBridge records the config it was opened with and reports every
+// modality, like a bridge that ignores the enable flags.
+type mockBridge struct {
+	config bridgeConfig
+	frames int
+	closed bool
+}
+
+func (b *mockBridge) open(config bridgeConfig) (bridge, error) {
+	b.config = config
+	return b, nil
+}
+
+func (b *mockBridge) process(pixels []uint8, width, height int) (*TrackingData, error) {
+	b.frames++
+	lm := []Landmark{{Point: Point3D{X: 0.5, Y: 0.5}}}
+	return &TrackingData{
+		Face:      &FaceData{Landmarks: lm},
+		LeftHand:  &HandData{Landmarks: lm},
+		RightHand: &HandData{Landmarks: lm},
+		Pose:      &PoseData{Landmarks: lm},
+	}, nil
+}
+
+func (b *mockBridge) close() { b.closed = true }
+
+func TestMediaPipeProcessorHandsOnly(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.EnableFace = false
+	cfg.EnablePose = false
+
+	mock := &mockBridge{}
+	p, err := newMediaPipeProcessor(cfg, mock.open)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if mock.config.enableFace || mock.config.enablePose {
+		t.Errorf("expected face and pose not to be requested, got %+v", mock.config)
+	}
+	if !mock.config.enableHands {
+		t.Error("expected hands to be requested")
+	}
+
+	frame := gocv.NewMatWithSize(4, 4, gocv.MatTypeCV8UC3)
+	defer frame.Close()
+	data, err := p.Process(frame)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if data.Face != nil || data.Pose != nil {
+		t.Error("expected disabled modalities to be dropped")
+	}
+	if data.LeftHand == nil || data.RightHand == nil {
+		t.Error("expected hands to be reported")
+	}
+
+	if err := p.Close(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !mock.closed {
+		t.Error("expected bridge to be closed")
+	}
+}
+
+// slowBridge is a mockBridge whose process blocks until release is closed.
+type slowBridge struct {
+	mockBridge
+	release chan struct{}
+}
+
+func (b *slowBridge) open(config bridgeConfig) (bridge, error) {
+	b.config = config
+	return b, nil
+}
+
+func (b *slowBridge) process(pixels []uint8, width, height int) (*TrackingData, error) {
+	<-b.release
+	return b.mockBridge.process(pixels, width, height)
+}
+
+func TestMediaPipeProcessorDeadline(t *testing.T) {
+	slow := &slowBridge{release: make(chan struct{})}
+	p, err := newMediaPipeProcessor(DefaultConfig(), slow.open)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer p.Close()
+
+	frame := gocv.NewMatWithSize(4, 4, gocv.MatTypeCV8UC3)
+	defer frame.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	_, err = p.ProcessContext(ctx, frame)
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("expected a deadline error, got %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("expected ProcessContext to return at the deadline, took %v", elapsed)
+	}
+
+	// The next frame waits for the abandoned one to finish
+	done := make(chan error, 1)
+	go func() {
+		_, err := p.Process(frame)
+		done <- err
+	}()
+	select {
+	case err := <-done:
+		t.Fatalf("expected the next frame to wait, got %v", err)
+	case <-time.After(20 * time.Millisecond):
+	}
+
+	close(slow.release)
+	if err := <-done; err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+	if slow.frames != 2 {
+		t.Errorf("expected both frames to reach the bridge, got %d", slow.frames)
+	}
+}
+
+// pixelBridge records the pixel buffer it was given.
+type pixelBridge struct {
+	mockBridge
+	pixels []uint8
+}
+
+func (b *pixelBridge) open(config bridgeConfig) (bridge, error) {
+	b.config = config
+	return b, nil
+}
+
+func (b *pixelBridge) process(pixels []uint8, width, height int) (*TrackingData, error) {
+	b.pixels = pixels
+	return b.mockBridge.process(pixels, width, height)
+}
+
+func TestMediaPipeProcessorCopiesOnlyWithDeadline(t *testing.T) {
+	b := &pixelBridge{}
+	p, err := newMediaPipeProcessor(DefaultConfig(), b.open)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer p.Close()
+
+	frame := gocv.NewMatWithSize(4, 4, gocv.MatTypeCV8UC3)
+	defer frame.Close()
+	pixels, err := frame.DataPtrUint8()
+	if err != nil {
+		t.Fatalf("reading pixels: %v", err)
+	}
+
+	// A cancel-only context, like the tracker's, processes the frame in place
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	if _, err := p.ProcessContext(ctx, frame); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if &b.pixels[0] != &pixels[0] {
+		t.Error("expected the frame to be processed without a copy")
+	}
+
+	ctx, cancel = context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	if _, err := p.ProcessContext(ctx, frame); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if &b.pixels[0] == &pixels[0] {
+		t.Error("expected a copy of the frame with a deadline")
+	}
+}
+
+func TestMediaPipeProcessorCloseWhileProcessing(t *testing.T) {
+	slow := &slowBridge{release: make(chan struct{})}
+	p, err := newMediaPipeProcessor(DefaultConfig(), slow.open)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	frame := gocv.NewMatWithSize(4, 4, gocv.MatTypeCV8UC3)
+	defer frame.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := p.ProcessContext(ctx, frame); !errors.Is(err, context.Canceled) {
+		t.Errorf("expected a cancellation error, got %v", err)
+	}
+
+	ctx, cancel = context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if _, err := p.ProcessContext(ctx, frame); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected a deadline error, got %v", err)
+	}
+
+	// Close must not wait for the bridge, nor close it under the running frame
+	if err := p.Close(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if slow.closed {
+		t.Fatal("expected the bridge to stay open while processing")
+	}
+
+	close(slow.release)
+	deadline := time.Now().Add(time.Second)
+	for {
+		p.mu.Lock()
+		closed := p.bridge == nil
+		p.mu.Unlock()
+		if closed {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("expected the bridge to be closed after the frame finished")
+		}
+		time.Sleep(time.Millisecond)
+	}
+	if !slow.closed {
+		t.Error("expected the bridge to be closed")
+	}
+}
+
+func TestMediaPipeProcessorNoModality(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.EnableFace = false
+	cfg.EnableHands = false
+	cfg.EnablePose = false
+
+	mock := &mockBridge{}
+	_, err := newMediaPipeProcessor(cfg, mock.open)
+	if err == nil {
+		t.Fatal("expected error with every modality disabled")
+	}
+	if !errors.Is(err, miface.ErrMediaPipeInit) {
+		t.Errorf("expected ErrMediaPipeInit, got %v", err)
+	}
+}
+
+func TestMediaPipeProcessorInitError(t *testing.T) {
+	cause := errors.New("graph failed")
+	failing := func(bridgeConfig) (bridge, error) { return nil, cause }
+
+	_, err := newMediaPipeProcessor(DefaultConfig(), failing)
+	if !errors.Is(err, miface.ErrMediaPipeInit) {
+		t.Errorf("expected ErrMediaPipeInit, got %v", err)
+	}
+	if !errors.Is(err, cause) {
+		t.Errorf("expected the bridge error to be kept, got %v", err)
+	}
+}
+
+func TestConfigForTracking(t *testing.T) {
+	tracking := config.Default().Tracking
+	tracking.EnableFace = false
+	tracking.EnablePose = false
+
+	cfg := ConfigForTracking(tracking)
+	if cfg.EnableFace || cfg.EnablePose || !cfg.EnableHands {
+		t.Errorf("expected hands only, got face=%v hands=%v pose=%v", cfg.EnableFace, cfg.EnableHands, cfg.EnablePose)
+	}
+	if cfg.ModelComplexity != DefaultConfig().ModelComplexity {
+		t.Errorf("expected default model complexity, got %d", cfg.ModelComplexity)
+	}
+	if cfg.RefineFaceLandmarks {
+		t.Error("expected an unrefined face mesh without gaze estimation")
+	}
+
+	tracking.EstimateGaze = true
+	if cfg := ConfigForTracking(tracking); !cfg.RefineFaceLandmarks {
+		t.Error("expected gaze estimation to refine the face mesh")
+	}
+}
@@ -0,0 +1,47 @@
+package mediapipe
+
+// TrackingData represents the complete tracking output from MediaPipe.
+// It mirrors the C bridge results; use ConvertTrackingData to obtain the
+// miface.TrackingData form consumed by the tracker and senders.
+type TrackingData struct {
+	Timestamp int64     // Frame timestamp in milliseconds
+	Face      *FaceData // Facial landmarks and expressions
+	LeftHand  *HandData // Left hand landmarks
+	RightHand *HandData // Right hand landmarks
+	Pose      *PoseData // Body pose landmarks
+}
+
+// FaceData contains facial tracking information.
+type FaceData struct {
+	Landmarks    []Landmark         // 468 face mesh landmarks
+	BlendShapes  map[string]float32 // ARKit-style blend shapes (to be computed)
+	HeadRotation Quaternion         // Head orientation
+	HeadPosition Point3D            // Head position in world space
+}
+
+// HandData contains hand tracking information.
+type HandData struct {
+	Landmarks []Landmark // 21 hand landmarks
+}
+
+// PoseData contains body pose tracking information.
+type PoseData struct {
+	Landmarks []Landmark // 33 pose landmarks (focus on upper body)
+}
+
+// Landmark represents a single 3D point with confidence scores.
+type Landmark struct {
+	Point      Point3D // 3D coordinates
+	Visibility float32 // Visibility score [0.0, 1.0]
+	Presence   float32 // Presence score [0.0, 1.0]
+}
+
+// Point3D represents a 3D point in space.
+type Point3D struct {
+	X, Y, Z float64
+}
+
+// Quaternion represents a rotation in 3D space.
+type Quaternion struct {
+	X, Y, Z, W float64
+}
@@ -0,0 +1,174 @@
+package miface
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// asyncFrame is a frame waiting for the AsyncProcessor worker. ctx carries
+// the caller's values but not its cancellation, which ends when Process
+// returns; the caller's remaining time is kept in timeout instead.
+type asyncFrame struct {
+	ctx     context.Context
+	timeout time.Duration // 0 = no deadline
+	data    []byte
+	width   int
+	height  int
+}
+
+// AsyncProcessor runs a Processor on a background worker so that slow
+// inference doesn't stall capture. Process hands the frame to the worker
+// and returns immediately with the newest result that hasn't been returned
+// yet (or nil if there is none).
+//
+// At most one frame waits for the worker. When a new frame arrives while
+// another is still waiting, the older one is dropped, so latency stays
+// bounded under load instead of growing a backlog.
+type AsyncProcessor struct {
+	inner Processor
+
+	mu      sync.Mutex
+	pending *asyncFrame
+	spare   []byte // Frame buffer recycled from the worker
+	result  *TrackingData
+	err     error
+	dropped uint64
+	closed  bool
+
+	wake chan struct{}
+	done chan struct{}
+	wg   sync.WaitGroup
+}
+
+// NewAsyncProcessor wraps inner and starts its worker goroutine.
+func NewAsyncProcessor(inner Processor) *AsyncProcessor {
+	a := &AsyncProcessor{
+		inner: inner,
+		wake:  make(chan struct{}, 1),
+		done:  make(chan struct{}),
+	}
+
+	a.wg.Add(1)
+	go a.worker()
+
+	return a
+}
+
+// Process queues the frame for the worker and returns the latest unreturned
+// result. The frame is copied, so the caller may reuse its buffer. The
+// worker doesn't use ctx after Process returns: if ctx has a deadline, the
+// time left until it is applied again from when the worker takes the frame.
+func (a *AsyncProcessor) Process(ctx context.Context, frame []byte, width, height int) (*TrackingData, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if a.closed {
+		return nil, fmt.Errorf("processor is closed")
+	}
+
+	buf := a.spare
+	a.spare = nil
+	if a.pending != nil {
+		// Drop the oldest frame and reuse its buffer
+		buf = a.pending.data
+		a.dropped++
+	}
+	if cap(buf) < len(frame) {
+		buf = make([]byte, len(frame))
+	}
+	buf = buf[:len(frame)]
+	copy(buf, frame)
+
+	pending := &asyncFrame{ctx: context.WithoutCancel(ctx), data: buf, width: width, height: height}
+	if deadline, ok := ctx.Deadline(); ok {
+		// A deadline already passed still bounds the frame, if barely
+		pending.timeout = max(time.Until(deadline), time.Nanosecond)
+	}
+	a.pending = pending
+
+	select {
+	case a.wake <- struct{}{}:
+	default:
+	}
+
+	result, err := a.result, a.err
+	a.result, a.err = nil, nil
+	return result, err
+}
+
+// worker processes pending frames until Close is called.
+func (a *AsyncProcessor) worker() {
+	defer a.wg.Done()
+
+	for {
+		select {
+		case <-a.done:
+			return
+		case <-a.wake:
+		}
+
+		a.mu.Lock()
+		frame := a.pending
+		a.pending = nil
+		a.mu.Unlock()
+
+		if frame == nil {
+			continue
+		}
+
+		data, err := a.process(frame)
+
+		a.mu.Lock()
+		if err != nil {
+			a.err = err
+		} else if data != nil {
+			a.result, a.err = data, nil
+		}
+		a.spare = frame.data
+		a.mu.Unlock()
+	}
+}
+
+// process runs the wrapped processor on frame, within its timeout if it
+// has one.
+func (a *AsyncProcessor) process(frame *asyncFrame) (*TrackingData, error) {
+	ctx := frame.ctx
+	if frame.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, frame.timeout)
+		defer cancel()
+	}
+	return a.inner.Process(ctx, frame.data, frame.width, frame.height)
+}
+
+// Dropped returns how many frames were dropped because the worker was busy.
+func (a *AsyncProcessor) Dropped() uint64 {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return a.dropped
+}
+
+// Close stops the worker, waiting for any in-flight frame, and closes the
+// wrapped processor.
+func (a *AsyncProcessor) Close() error {
+	a.mu.Lock()
+	if a.closed {
+		a.mu.Unlock()
+		return nil
+	}
+	a.closed = true
+	a.pending = nil
+	a.mu.Unlock()
+
+	close(a.done)
+	a.wg.Wait()
+
+	if a.inner != nil {
+		if err := a.inner.Close(); err != nil {
+			return fmt.Errorf("closing wrapped processor: %w", err)
+		}
+	}
+	return nil
+}
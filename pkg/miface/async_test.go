@@ -0,0 +1,112 @@
+package miface
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// slowProcessor takes delay to process each frame and records the first byte
+// of every frame it sees.
+type slowProcessor struct {
+	delay  time.Duration
+	calls  atomic.Int32
+	mu     sync.Mutex
+	seen   []byte
+	closed bool
+}
+
+func (p *slowProcessor) Process(ctx context.Context, frame []byte, width, height int) (*TrackingData, error) {
+	p.calls.Add(1)
+	time.Sleep(p.delay)
+
+	p.mu.Lock()
+	p.seen = append(p.seen, frame[0])
+	p.mu.Unlock()
+
+	return &TrackingData{Face: &FaceData{HeadRotation: Quaternion{W: float64(frame[0])}}}, nil
+}
+
+func (p *slowProcessor) Close() error {
+	p.closed = true
+	return nil
+}
+
+func TestAsyncProcessorDropsStaleFrames(t *testing.T) {
+	inner := &slowProcessor{delay: 50 * time.Millisecond}
+	async := NewAsyncProcessor(inner)
+
+	const frames = 30
+	frame := make([]byte, 16)
+	var results int
+	start := time.Now()
+
+	for i := 1; i <= frames; i++ {
+		frame[0] = byte(i)
+
+		callStart := time.Now()
+		data, err := async.Process(context.Background(), frame, 4, 4)
+		if err != nil {
+			t.Fatalf("frame %d: unexpected error: %v", i, err)
+		}
+		if elapsed := time.Since(callStart); elapsed > 20*time.Millisecond {
+			t.Errorf("frame %d: Process blocked for %v", i, elapsed)
+		}
+		if data != nil {
+			results++
+		}
+
+		time.Sleep(5 * time.Millisecond)
+	}
+
+	// The loop ran at its own pace rather than the processor's
+	if elapsed := time.Since(start); elapsed >= frames*inner.delay {
+		t.Errorf("expected loop to outpace the processor, took %v", elapsed)
+	}
+
+	if err := async.Close(); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+	if !inner.closed {
+		t.Error("expected wrapped processor to be closed")
+	}
+
+	calls := int(inner.calls.Load())
+	if calls >= frames {
+		t.Errorf("expected fewer than %d inner calls, got %d", frames, calls)
+	}
+	if async.Dropped() == 0 {
+		t.Error("expected stale frames to be dropped")
+	}
+	if int(async.Dropped())+calls > frames {
+		t.Errorf("dropped (%d) + processed (%d) exceeds frames submitted (%d)", async.Dropped(), calls, frames)
+	}
+	if results == 0 {
+		t.Error("expected at least one result to be published")
+	}
+
+	// Frames are processed in order, never replayed
+	inner.mu.Lock()
+	defer inner.mu.Unlock()
+	for i := 1; i < len(inner.seen); i++ {
+		if inner.seen[i] <= inner.seen[i-1] {
+			t.Errorf("expected increasing frames, got %v", inner.seen)
+			break
+		}
+	}
+}
+
+func TestAsyncProcessorClosed(t *testing.T) {
+	async := NewAsyncProcessor(&slowProcessor{})
+	if err := async.Close(); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+	if _, err := async.Process(context.Background(), []byte{1}, 1, 1); err == nil {
+		t.Error("expected error processing after close")
+	}
+	if err := async.Close(); err != nil {
+		t.Errorf("expected second close to succeed, got %v", err)
+	}
+}
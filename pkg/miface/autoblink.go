@@ -0,0 +1,184 @@
+package miface
+
+import (
+	"math"
+	"math/rand"
+	"sync"
+	"time"
+)
+
+// Eye landmark indices in the MediaPipe face mesh (corners and lids).
+var eyeLandmarkIndices = []int{
+	FaceRightEyeOuter, FaceRightEyeInner, FaceRightEyeUpper, FaceRightEyeLower,
+	FaceLeftEyeInner, FaceLeftEyeOuter, FaceLeftEyeUpper, FaceLeftEyeLower,
+}
+
+// AutoBlinkConfig controls the synthetic blinks generated by AutoBlink.
+type AutoBlinkConfig struct {
+	// LostAfter is how long face tracking must be lost before auto-blink starts.
+	LostAfter time.Duration
+	// Interval is the average time between synthetic blinks.
+	Interval time.Duration
+	// Jitter randomizes each interval by up to ±Jitter (fraction of Interval).
+	Jitter float64
+	// BlinkDuration is how long one synthetic blink takes to close and reopen.
+	BlinkDuration time.Duration
+	// MinEyeVisibility treats the face as lost when the average visibility
+	// of the eye landmarks falls below it (0 = only a missing face counts).
+	MinEyeVisibility float64
+}
+
+// DefaultAutoBlinkConfig returns an auto-blink configuration with a natural
+// blink roughly every four seconds.
+func DefaultAutoBlinkConfig() AutoBlinkConfig {
+	return AutoBlinkConfig{
+		LostAfter:     500 * time.Millisecond,
+		Interval:      4 * time.Second,
+		Jitter:        0.3,
+		BlinkDuration: 150 * time.Millisecond,
+	}
+}
+
+// AutoBlink injects periodic synthetic eyeBlinkLeft/eyeBlinkRight blend
+// shapes while face tracking is lost, so the avatar doesn't stare with
+// frozen eyes. The last tracked head pose is held meanwhile. As soon as
+// real tracking resumes the data passes through untouched.
+//
+// AutoBlink implements TransformStage for use in a ChainProcessor.
+type AutoBlink struct {
+	mu  sync.Mutex
+	cfg AutoBlinkConfig
+
+	lostSince  time.Time // Zero while tracking
+	active     bool
+	blinkStart time.Time
+	nextBlink  time.Time
+
+	headRotation Quaternion
+	headPosition Point3D
+
+	rand  *rand.Rand
+	clock Clock
+}
+
+// NewAutoBlink creates an auto-blink generator.
+func NewAutoBlink(cfg AutoBlinkConfig) *AutoBlink {
+	return &AutoBlink{
+		cfg:          cfg,
+		headRotation: Quaternion{W: 1},
+		rand:         rand.New(rand.NewSource(time.Now().UnixNano())),
+		clock:        realClock{},
+	}
+}
+
+// SetClock sets the clock the lost timeout and blinks are timed with. It
+// defaults to the system clock; pass the tracker's clock so blinks follow
+// its time, e.g. a FakeClock in tests.
+func (a *AutoBlink) SetClock(clock Clock) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.clock = clock
+}
+
+// SetSeed seeds the jitter of the blink intervals, so the blink schedule
+// is reproducible. By default it is seeded from the current time.
+func (a *AutoBlink) SetSeed(seed int64) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.rand = rand.New(rand.NewSource(seed))
+}
+
+// Transform passes tracked faces through and fills in blinks when the face is lost.
+func (a *AutoBlink) Transform(data *TrackingData) (*TrackingData, error) {
+	if data == nil {
+		return data, nil
+	}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	now := a.clock.Now()
+
+	if a.tracked(data.Face) {
+		a.lostSince = time.Time{}
+		a.active = false
+		a.headRotation = data.Face.HeadRotation
+		a.headPosition = data.Face.HeadPosition
+		return data, nil
+	}
+
+	if a.lostSince.IsZero() {
+		a.lostSince = now
+	}
+	if now.Sub(a.lostSince) < a.cfg.LostAfter {
+		return data, nil
+	}
+
+	if !a.active {
+		a.active = true
+		a.blinkStart = time.Time{}
+		a.nextBlink = now.Add(a.interval())
+	}
+	if !now.Before(a.nextBlink) {
+		a.blinkStart = a.nextBlink
+		a.nextBlink = a.blinkStart.Add(a.cfg.BlinkDuration + a.interval())
+	}
+
+	value := a.blinkValue(now)
+	if data.Face == nil {
+		data.Face = &FaceData{
+			HeadRotation: a.headRotation,
+			HeadPosition: a.headPosition,
+		}
+	}
+	if data.Face.BlendShapes == nil {
+		data.Face.BlendShapes = make(map[string]float64)
+	}
+	data.Face.BlendShapes["eyeBlinkLeft"] = value
+	data.Face.BlendShapes["eyeBlinkRight"] = value
+
+	return data, nil
+}
+
+// tracked reports whether the face is present with confident eye landmarks.
+func (a *AutoBlink) tracked(face *FaceData) bool {
+	if face == nil {
+		return false
+	}
+	if a.cfg.MinEyeVisibility <= 0 {
+		return true
+	}
+
+	var sum float64
+	var count int
+	for _, idx := range eyeLandmarkIndices {
+		if idx < len(face.Landmarks) {
+			sum += face.Landmarks[idx].Visibility
+			count++
+		}
+	}
+	return count > 0 && sum/float64(count) >= a.cfg.MinEyeVisibility
+}
+
+// interval returns the next randomized time between blinks.
+func (a *AutoBlink) interval() time.Duration {
+	if a.cfg.Jitter <= 0 {
+		return a.cfg.Interval
+	}
+	scale := 1 + a.cfg.Jitter*(2*a.rand.Float64()-1)
+	return time.Duration(float64(a.cfg.Interval) * scale)
+}
+
+// blinkValue returns the eye closure for the current blink: a triangle
+// ramping from open to fully closed and back over BlinkDuration.
+func (a *AutoBlink) blinkValue(now time.Time) float64 {
+	if a.blinkStart.IsZero() || a.cfg.BlinkDuration <= 0 {
+		return 0
+	}
+	elapsed := now.Sub(a.blinkStart)
+	if elapsed < 0 || elapsed >= a.cfg.BlinkDuration {
+		return 0
+	}
+	t := float64(elapsed) / float64(a.cfg.BlinkDuration)
+	return 1 - math.Abs(2*t-1)
+}
@@ -0,0 +1,134 @@
+package miface
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestAutoBlinkSchedule(t *testing.T) {
+	cfg := AutoBlinkConfig{
+		LostAfter:     500 * time.Millisecond,
+		Interval:      time.Second,
+		BlinkDuration: 200 * time.Millisecond,
+	}
+	a := NewAutoBlink(cfg)
+
+	const step = 100 * time.Millisecond
+	clock := NewFakeClock(time.Unix(0, 0))
+	a.SetClock(clock)
+
+	head := Quaternion{Y: 0.1, W: 0.99}
+	frame := func(withFace bool) *TrackingData {
+		clock.Advance(step)
+		data := &TrackingData{}
+		if withFace {
+			data.Face = &FaceData{HeadRotation: head, BlendShapes: map[string]float64{}}
+		}
+		result, err := a.Transform(data)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		return result
+	}
+
+	for i := 0; i < 3; i++ {
+		data := frame(true)
+		if _, ok := data.Face.BlendShapes["eyeBlinkLeft"]; ok {
+			t.Fatal("expected no blink injected while tracking")
+		}
+	}
+
+	// Drop the face: lost at frame 0, active at frame 5, blinks start at
+	// frame 15 and 27 (interval + duration), peaking one frame later.
+	var peaks []int
+	for i := 0; i < 30; i++ {
+		data := frame(false)
+		if i < 5 {
+			if data.Face != nil {
+				t.Fatalf("frame %d: expected no face before LostAfter", i)
+			}
+			continue
+		}
+		if data.Face == nil {
+			t.Fatalf("frame %d: expected synthetic face", i)
+		}
+		if data.Face.HeadRotation != head {
+			t.Errorf("frame %d: expected held head rotation %+v, got %+v", i, head, data.Face.HeadRotation)
+		}
+		left := data.Face.BlendShapes["eyeBlinkLeft"]
+		if right := data.Face.BlendShapes["eyeBlinkRight"]; right != left {
+			t.Errorf("frame %d: expected equal blinks, got %f and %f", i, left, right)
+		}
+		if left == 1 {
+			peaks = append(peaks, i)
+		}
+	}
+
+	want := []int{16, 28}
+	if len(peaks) != len(want) || peaks[0] != want[0] || peaks[1] != want[1] {
+		t.Errorf("expected blink peaks at frames %v, got %v", want, peaks)
+	}
+
+	// Tracking resumes: data passes through untouched
+	data := frame(true)
+	if _, ok := data.Face.BlendShapes["eyeBlinkLeft"]; ok {
+		t.Error("expected no blink injected after tracking resumed")
+	}
+}
+
+// autoBlinkValues returns the eyeBlinkLeft value of a seeded AutoBlink with
+// jitter for 100 frames, 100ms apart, while the face is lost.
+func autoBlinkValues(t *testing.T, seed int64) []float64 {
+	cfg := AutoBlinkConfig{
+		Interval:      time.Second,
+		Jitter:        0.5,
+		BlinkDuration: 200 * time.Millisecond,
+	}
+	a := NewAutoBlink(cfg)
+	clock := NewFakeClock(time.Unix(0, 0))
+	a.SetClock(clock)
+	a.SetSeed(seed)
+
+	values := make([]float64, 100)
+	for i := range values {
+		clock.Advance(100 * time.Millisecond)
+		data, err := a.Transform(&TrackingData{})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		values[i] = data.Face.BlendShapes["eyeBlinkLeft"]
+	}
+	return values
+}
+
+func TestAutoBlinkSeed(t *testing.T) {
+	first := autoBlinkValues(t, 42)
+	var blinked bool
+	for _, v := range first {
+		blinked = blinked || v > 0
+	}
+	if !blinked {
+		t.Fatal("expected blinks while the face is lost")
+	}
+	if second := autoBlinkValues(t, 42); !reflect.DeepEqual(first, second) {
+		t.Errorf("expected the same seed to blink the same, got %v and %v", first, second)
+	}
+}
+
+func TestAutoBlinkLowEyeVisibility(t *testing.T) {
+	cfg := DefaultAutoBlinkConfig()
+	cfg.LostAfter = 0
+	cfg.MinEyeVisibility = 0.5
+	a := NewAutoBlink(cfg)
+
+	data, err := a.Transform(&TrackingData{
+		Face: &FaceData{Landmarks: make([]Landmark, numFaceLandmarks)},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, ok := data.Face.BlendShapes["eyeBlinkLeft"]; !ok {
+		t.Error("expected auto-blink to take over for invisible eyes")
+	}
+}
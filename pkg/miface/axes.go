@@ -0,0 +1,124 @@
+package miface
+
+import "github.com/MiFaceDEV/miface/internal/config"
+
+// AxisConvention converts positions and rotations from MediaPipe's image
+// axes (X right, Y down, Z away from the camera) to the axes a receiver
+// expects.
+type AxisConvention struct {
+	// Basis maps a vector in MediaPipe axes to the target axes:
+	// target = Basis × source. It must be orthonormal, in practice a
+	// permutation of the axes with some of them flipped.
+	Basis [3][3]float64
+}
+
+// Axis convention presets.
+var (
+	// AxesMediaPipe keeps MediaPipe's image axes:
+	//
+	//	| 1  0  0 |
+	//	| 0  1  0 |
+	//	| 0  0  1 |
+	AxesMediaPipe = AxisConvention{Basis: [3][3]float64{
+		{1, 0, 0},
+		{0, 1, 0},
+		{0, 0, 1},
+	}}
+
+	// AxesMediaPipeToVRM converts to VRM (glTF) axes: right-handed, Y up
+	// and Z toward the camera, the way an avatar facing the viewer looks.
+	// Y and Z are flipped, a half turn about X, so handedness is kept:
+	//
+	//	| 1  0  0 |
+	//	| 0 -1  0 |
+	//	| 0  0 -1 |
+	//
+	// Positions come out as ImageToNormalized gives them. This is the
+	// default.
+	AxesMediaPipeToVRM = AxisConvention{Basis: [3][3]float64{
+		{1, 0, 0},
+		{0, -1, 0},
+		{0, 0, -1},
+	}}
+
+	// AxesMediaPipeToUnity converts to Unity axes: left-handed, Y up and
+	// Z away from the camera. Only Y is flipped, which mirrors handedness,
+	// so rotations also change direction:
+	//
+	//	| 1  0  0 |
+	//	| 0 -1  0 |
+	//	| 0  0  1 |
+	AxesMediaPipeToUnity = AxisConvention{Basis: [3][3]float64{
+		{1, 0, 0},
+		{0, -1, 0},
+		{0, 0, 1},
+	}}
+)
+
+// axisConventions maps the config.VMCConfig.Axes presets to conventions.
+var axisConventions = map[string]AxisConvention{
+	"":                        AxesMediaPipeToVRM,
+	config.AxesNone:           AxesMediaPipe,
+	config.AxesMediaPipeVRM:   AxesMediaPipeToVRM,
+	config.AxesMediaPipeUnity: AxesMediaPipeToUnity,
+}
+
+// axisConventionFor returns the convention for a config preset name,
+// falling back to AxesMediaPipeToVRM for unknown names.
+func axisConventionFor(name string) AxisConvention {
+	if a, ok := axisConventions[name]; ok {
+		return a
+	}
+	return AxesMediaPipeToVRM
+}
+
+// Point converts a vector from MediaPipe axes to the target axes.
+func (a AxisConvention) Point(p Point3D) Point3D {
+	b := a.Basis
+	return Point3D{
+		X: b[0][0]*p.X + b[0][1]*p.Y + b[0][2]*p.Z,
+		Y: b[1][0]*p.X + b[1][1]*p.Y + b[1][2]*p.Z,
+		Z: b[2][0]*p.X + b[2][1]*p.Y + b[2][2]*p.Z,
+	}
+}
+
+// Position converts a normalized image position to the target axes,
+// centered on the image like ImageToNormalized.
+func (a AxisConvention) Position(p Point3D) Point3D {
+	return a.Point(Point3D{X: p.X - 0.5, Y: p.Y - 0.5, Z: p.Z})
+}
+
+// Rotation converts a rotation expressed in MediaPipe axes to the target
+// axes. The rotation axis is mapped like a vector, and flipped again if the
+// basis changes handedness, since a mirrored rotation turns the other way.
+func (a AxisConvention) Rotation(q Quaternion) Quaternion {
+	axis := a.Point(Point3D{X: q.X, Y: q.Y, Z: q.Z})
+	if a.det() < 0 {
+		axis = axis.Scale(-1)
+	}
+	return Quaternion{X: axis.X, Y: axis.Y, Z: axis.Z, W: q.W}
+}
+
+// fromVRM returns the convention that takes VRM axes, which the tracking
+// pipeline computes rotations in, to a's target axes.
+func (a AxisConvention) fromVRM() AxisConvention {
+	// AxesMediaPipeToVRM is its own inverse
+	var r AxisConvention
+	for i := 0; i < 3; i++ {
+		for j := 0; j < 3; j++ {
+			for k := 0; k < 3; k++ {
+				r.Basis[i][j] += a.Basis[i][k] * AxesMediaPipeToVRM.Basis[k][j]
+			}
+		}
+	}
+	return r
+}
+
+// det returns the determinant of the basis: 1 if it keeps handedness and
+// -1 if it mirrors it.
+func (a AxisConvention) det() float64 {
+	b := a.Basis
+	return b[0][0]*(b[1][1]*b[2][2]-b[1][2]*b[2][1]) -
+		b[0][1]*(b[1][0]*b[2][2]-b[1][2]*b[2][0]) +
+		b[0][2]*(b[1][0]*b[2][1]-b[1][1]*b[2][0])
+}
@@ -0,0 +1,73 @@
+package miface
+
+import (
+	"math"
+	"testing"
+)
+
+func TestAxesMediaPipeToVRM(t *testing.T) {
+	// Right of, below and behind the image center in MediaPipe axes
+	p := Point3D{X: 0.7, Y: 0.8, Z: 0.1}
+
+	got := AxesMediaPipeToVRM.Point(p)
+	if want := (Point3D{X: 0.7, Y: -0.8, Z: -0.1}); got != want {
+		t.Errorf("Point: expected %+v, got %+v", want, got)
+	}
+	got = AxesMediaPipeToVRM.Position(p)
+	if want := ImageToNormalized(p); !pointsClose(got, want) {
+		t.Errorf("Position: expected %+v to match ImageToNormalized %+v", got, want)
+	}
+
+	// A quarter turn about MediaPipe's Y axis, which points down, turns
+	// about -Y in VRM axes
+	s := math.Sqrt(0.5)
+	q := Quaternion{Y: s, W: s}
+	if got, want := AxesMediaPipeToVRM.Rotation(q), (Quaternion{Y: -s, W: s}); got != want {
+		t.Errorf("Rotation: expected %+v, got %+v", want, got)
+	}
+}
+
+func TestAxisConventionRotationMatchesPoints(t *testing.T) {
+	// Rotating then converting must equal converting then rotating, also
+	// for a basis that mirrors handedness
+	q := QuaternionFromEuler(0.3, -0.5, 0.8)
+	p := Point3D{X: 0.2, Y: -0.4, Z: 0.6}
+
+	for name, axes := range map[string]AxisConvention{
+		"mediapipe": AxesMediaPipe,
+		"vrm":       AxesMediaPipeToVRM,
+		"unity":     AxesMediaPipeToUnity,
+	} {
+		want := axes.Point(rotatePoint(q, p))
+		got := rotatePoint(axes.Rotation(q), axes.Point(p))
+		if !pointsClose(got, want) {
+			t.Errorf("%s: expected %+v, got %+v", name, want, got)
+		}
+	}
+}
+
+func TestAxisConventionFromVRM(t *testing.T) {
+	if got := AxesMediaPipeToVRM.fromVRM(); got != AxesMediaPipe {
+		t.Errorf("expected identity from VRM to VRM, got %+v", got.Basis)
+	}
+
+	// VRM and Unity differ only in the Z direction
+	want := [3][3]float64{{1, 0, 0}, {0, 1, 0}, {0, 0, -1}}
+	if got := AxesMediaPipeToUnity.fromVRM(); got.Basis != want {
+		t.Errorf("expected %v from VRM to Unity, got %v", want, got.Basis)
+	}
+}
+
+func TestAxisConventionFor(t *testing.T) {
+	tests := map[string]AxisConvention{
+		"":                AxesMediaPipeToVRM,
+		"mediapipe-vrm":   AxesMediaPipeToVRM,
+		"mediapipe-unity": AxesMediaPipeToUnity,
+		"none":            AxesMediaPipe,
+	}
+	for name, want := range tests {
+		if got := axisConventionFor(name); got != want {
+			t.Errorf("%q: expected %v, got %v", name, want.Basis, got.Basis)
+		}
+	}
+}
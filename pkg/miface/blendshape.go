@@ -0,0 +1,197 @@
+package miface
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/BurntSushi/toml"
+	"github.com/MiFaceDEV/miface/internal/config"
+)
+
+// arkitBlendShapes lists the 52 ARKit face blend shape names.
+var arkitBlendShapes = []string{
+	"eyeBlinkLeft", "eyeLookDownLeft", "eyeLookInLeft", "eyeLookOutLeft",
+	"eyeLookUpLeft", "eyeSquintLeft", "eyeWideLeft",
+	"eyeBlinkRight", "eyeLookDownRight", "eyeLookInRight", "eyeLookOutRight",
+	"eyeLookUpRight", "eyeSquintRight", "eyeWideRight",
+	"jawForward", "jawLeft", "jawRight", "jawOpen",
+	"mouthClose", "mouthFunnel", "mouthPucker", "mouthLeft", "mouthRight",
+	"mouthSmileLeft", "mouthSmileRight", "mouthFrownLeft", "mouthFrownRight",
+	"mouthDimpleLeft", "mouthDimpleRight", "mouthStretchLeft", "mouthStretchRight",
+	"mouthRollLower", "mouthRollUpper", "mouthShrugLower", "mouthShrugUpper",
+	"mouthPressLeft", "mouthPressRight", "mouthLowerDownLeft", "mouthLowerDownRight",
+	"mouthUpperUpLeft", "mouthUpperUpRight",
+	"browDownLeft", "browDownRight", "browInnerUp", "browOuterUpLeft", "browOuterUpRight",
+	"cheekPuff", "cheekSquintLeft", "cheekSquintRight",
+	"noseSneerLeft", "noseSneerRight",
+	"tongueOut",
+}
+
+// BlendShapeMapper renames blend shapes, for avatars whose blend shape names
+// differ from the ones the estimator produces. Names without an entry in
+// the table pass through unchanged, or are dropped if SetDropUnmapped is
+// enabled.
+type BlendShapeMapper struct {
+	mu           sync.RWMutex
+	table        map[string]string
+	dropUnmapped bool
+}
+
+// NewBlendShapeMapper creates a mapper that renames each key of table to
+// its value. The table is copied.
+func NewBlendShapeMapper(table map[string]string) *BlendShapeMapper {
+	m := &BlendShapeMapper{table: make(map[string]string, len(table))}
+	for from, to := range table {
+		m.table[from] = to
+	}
+	return m
+}
+
+// NewPerfectSyncMapper creates a mapper from ARKit names to VRM "Perfect
+// Sync" names, which are the ARKit names in UpperCamelCase
+// (e.g. jawOpen → JawOpen).
+func NewPerfectSyncMapper() *BlendShapeMapper {
+	table := make(map[string]string, len(arkitBlendShapes))
+	for _, name := range arkitBlendShapes {
+		r, size := utf8.DecodeRuneInString(name)
+		table[name] = string(unicode.ToUpper(r)) + name[size:]
+	}
+	return &BlendShapeMapper{table: table}
+}
+
+// LoadBlendShapeMapper reads a mapping table from a TOML or JSON file,
+// chosen by the .toml or .json extension. The file is a flat table of
+// source name to target name, e.g. jawOpen = "A" in TOML or
+// {"jawOpen": "A"} in JSON.
+func LoadBlendShapeMapper(path string) (*BlendShapeMapper, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("reading blend shape mapping: %w", err)
+	}
+
+	table := make(map[string]string)
+	switch ext := strings.ToLower(filepath.Ext(path)); ext {
+	case ".toml":
+		if _, err := toml.Decode(string(data), &table); err != nil {
+			return nil, fmt.Errorf("parsing blend shape mapping: %w", err)
+		}
+	case ".json":
+		if err := json.Unmarshal(data, &table); err != nil {
+			return nil, fmt.Errorf("parsing blend shape mapping: %w", err)
+		}
+	default:
+		return nil, fmt.Errorf("unsupported blend shape mapping format %q", ext)
+	}
+
+	return &BlendShapeMapper{table: table}, nil
+}
+
+// SetDropUnmapped sets whether names without a table entry are dropped
+// instead of passed through unchanged.
+func (m *BlendShapeMapper) SetDropUnmapped(drop bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.dropUnmapped = drop
+}
+
+// Map returns the target name for name, and false if the name is dropped.
+func (m *BlendShapeMapper) Map(name string) (string, bool) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	if to, ok := m.table[name]; ok {
+		return to, true
+	}
+	return name, !m.dropUnmapped
+}
+
+// Apply returns a copy of shapes with every name mapped. If several names
+// map to the same target, the largest weight is kept.
+func (m *BlendShapeMapper) Apply(shapes map[string]float64) map[string]float64 {
+	if shapes == nil {
+		return nil
+	}
+
+	result := make(map[string]float64, len(shapes))
+	for name, value := range shapes {
+		to, ok := m.Map(name)
+		if !ok {
+			continue
+		}
+		if prev, exists := result[to]; !exists || value > prev {
+			result[to] = value
+		}
+	}
+	return result
+}
+
+// BlendShapeShaper adjusts blend shape weights by name, for estimators
+// whose weights feel too weak or too strong on a given avatar. It changes
+// magnitudes only; use BlendShapeMapper to rename blend shapes. Names
+// without a curve pass through unchanged.
+//
+// BlendShapeShaper implements TransformStage, so it can also be used in a
+// ChainProcessor.
+type BlendShapeShaper struct {
+	curves map[string]config.BlendShapeCurve
+}
+
+// NewBlendShapeShaper creates a shaper with a curve per blend shape name.
+// The map is copied.
+func NewBlendShapeShaper(curves map[string]config.BlendShapeCurve) *BlendShapeShaper {
+	s := &BlendShapeShaper{curves: make(map[string]config.BlendShapeCurve, len(curves))}
+	for name, curve := range curves {
+		s.curves[name] = curve
+	}
+	return s
+}
+
+// Apply shapes the weights in shapes in place.
+func (s *BlendShapeShaper) Apply(shapes map[string]float64) {
+	for name, value := range shapes {
+		if curve, ok := s.curves[name]; ok {
+			shapes[name] = ShapeBlendShape(value, curve)
+		}
+	}
+}
+
+// Transform shapes the face blend shapes of data in place.
+func (s *BlendShapeShaper) Transform(data *TrackingData) (*TrackingData, error) {
+	if data != nil && data.Face != nil {
+		s.Apply(data.Face.BlendShapes)
+	}
+	return data, nil
+}
+
+// ShapeBlendShape applies curve to a blend shape weight. The input is
+// clamped to [0, 1], eased, raised to the gamma, scaled by the gain, offset
+// by the bias and clamped to [0, 1] again.
+func ShapeBlendShape(value float64, curve config.BlendShapeCurve) float64 {
+	v := clamp01(value)
+
+	switch curve.Ease {
+	case "in":
+		v = v * v
+	case "out":
+		v = 1 - (1-v)*(1-v)
+	case "in-out":
+		v = v * v * (3 - 2*v)
+	}
+
+	if curve.Gamma > 0 && curve.Gamma != 1 {
+		v = math.Pow(v, curve.Gamma)
+	}
+
+	gain := curve.Gain
+	if gain == 0 {
+		gain = 1
+	}
+	return clamp01(v*gain + curve.Bias)
+}
@@ -0,0 +1,160 @@
+package miface
+
+import (
+	"math"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/MiFaceDEV/miface/internal/config"
+)
+
+func TestPerfectSyncMapper(t *testing.T) {
+	m := NewPerfectSyncMapper()
+
+	tests := []struct {
+		in, want string
+	}{
+		{"jawOpen", "JawOpen"},
+		{"eyeBlinkLeft", "EyeBlinkLeft"},
+		{"tongueOut", "TongueOut"},
+		{"custom", "custom"},
+	}
+	for _, tt := range tests {
+		got, ok := m.Map(tt.in)
+		if !ok || got != tt.want {
+			t.Errorf("Map(%q): expected %q, got %q (ok=%v)", tt.in, tt.want, got, ok)
+		}
+	}
+
+	if len(arkitBlendShapes) != 52 {
+		t.Errorf("expected 52 ARKit blend shapes, got %d", len(arkitBlendShapes))
+	}
+}
+
+func TestBlendShapeMapperDropUnmapped(t *testing.T) {
+	m := NewBlendShapeMapper(map[string]string{"jawOpen": "A", "mouthFunnel": "A"})
+	m.SetDropUnmapped(true)
+
+	got := m.Apply(map[string]float64{"jawOpen": 0.3, "mouthFunnel": 0.6, "unknown": 1})
+	if len(got) != 1 {
+		t.Fatalf("expected 1 blend shape, got %v", got)
+	}
+	if got["A"] != 0.6 {
+		t.Errorf("expected the larger weight 0.6 for A, got %f", got["A"])
+	}
+}
+
+func TestLoadBlendShapeMapper(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"map.toml": "jawOpen = \"A\"\n",
+		"map.json": `{"jawOpen": "A"}`,
+	}
+
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatalf("writing %s: %v", name, err)
+		}
+
+		m, err := LoadBlendShapeMapper(path)
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %v", name, err)
+		}
+		if got, _ := m.Map("jawOpen"); got != "A" {
+			t.Errorf("%s: expected jawOpen to map to A, got %q", name, got)
+		}
+	}
+
+	bad := filepath.Join(dir, "map.yaml")
+	if err := os.WriteFile(bad, []byte("jawOpen: A\n"), 0o644); err != nil {
+		t.Fatalf("writing map.yaml: %v", err)
+	}
+	if _, err := LoadBlendShapeMapper(bad); err == nil {
+		t.Error("expected error for unsupported format")
+	}
+	if _, err := LoadBlendShapeMapper(filepath.Join(dir, "missing.toml")); err == nil {
+		t.Error("expected error for missing file")
+	}
+}
+
+func TestVMCSenderBlendShapeMapper(t *testing.T) {
+	sender, listener := newTestVMCSender(t)
+	sender.SetBlendShapeMapper(NewPerfectSyncMapper())
+
+	data := &TrackingData{Face: &FaceData{
+		BlendShapes:  map[string]float64{"jawOpen": 0.5},
+		HeadRotation: Quaternion{W: 1},
+	}}
+	if err := sender.Send(data); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var names []string
+	for _, m := range readOSCMessages(t, listener) {
+		if m.address == "/VMC/Ext/Blend/Val" {
+			names = append(names, m.args[0].(string))
+		}
+	}
+	if len(names) != 1 || names[0] != "JawOpen" {
+		t.Errorf("expected a single JawOpen blend shape, got %v", names)
+	}
+	if _, ok := data.Face.BlendShapes["jawOpen"]; !ok {
+		t.Error("expected the tracking data to keep its original names")
+	}
+}
+
+func TestShapeBlendShape(t *testing.T) {
+	tests := []struct {
+		name  string
+		value float64
+		curve config.BlendShapeCurve
+		want  float64
+	}{
+		{"identity", 0.3, config.BlendShapeCurve{}, 0.3},
+		{"gain doubles", 0.3, config.BlendShapeCurve{Gain: 2}, 0.6},
+		{"gain clamps", 0.7, config.BlendShapeCurve{Gain: 2}, 1},
+		{"bias", 0.3, config.BlendShapeCurve{Bias: 0.2}, 0.5},
+		{"negative bias clamps", 0.1, config.BlendShapeCurve{Bias: -0.2}, 0},
+		{"gamma", 0.25, config.BlendShapeCurve{Gamma: 0.5}, 0.5},
+		{"ease in", 0.5, config.BlendShapeCurve{Ease: "in"}, 0.25},
+		{"ease out", 0.5, config.BlendShapeCurve{Ease: "out"}, 0.75},
+		{"ease in-out", 0.5, config.BlendShapeCurve{Ease: "in-out"}, 0.5},
+		{"input clamps", 1.5, config.BlendShapeCurve{}, 1},
+	}
+
+	for _, tt := range tests {
+		if got := ShapeBlendShape(tt.value, tt.curve); math.Abs(got-tt.want) > 1e-9 {
+			t.Errorf("%s: expected %f, got %f", tt.name, tt.want, got)
+		}
+	}
+}
+
+func TestBlendShapeShaper(t *testing.T) {
+	s := NewBlendShapeShaper(map[string]config.BlendShapeCurve{
+		"jawOpen":        {Gain: 2},
+		"mouthSmileLeft": {Gain: 0.5},
+	})
+
+	data := &TrackingData{Face: &FaceData{BlendShapes: map[string]float64{
+		"jawOpen":        0.3,
+		"mouthSmileLeft": 0.8,
+		"eyeBlinkLeft":   0.4,
+	}}}
+	if _, err := s.Transform(data); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	want := map[string]float64{"jawOpen": 0.6, "mouthSmileLeft": 0.4, "eyeBlinkLeft": 0.4}
+	for name, w := range want {
+		if got := data.Face.BlendShapes[name]; math.Abs(got-w) > 1e-9 {
+			t.Errorf("%s: expected %f, got %f", name, w, got)
+		}
+	}
+
+	// Missing face data is a no-op
+	if _, err := s.Transform(&TrackingData{}); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
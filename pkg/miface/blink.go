@@ -0,0 +1,113 @@
+package miface
+
+import (
+	"sync"
+	"time"
+)
+
+// BlinkAssistConfig controls how BlinkAssist snaps eye-blink blend shapes.
+type BlinkAssistConfig struct {
+	// CloseThreshold is the value at or above which a blink snaps to 1.0.
+	CloseThreshold float64
+	// OpenFloor is the value below which a blink snaps to 0.0 (0 = disabled).
+	OpenFloor float64
+	// HoldTime is the minimum time a snapped-closed eye stays closed,
+	// so flicker around CloseThreshold does not stutter.
+	HoldTime time.Duration
+	// BlendShapes lists the blend shape names to process.
+	BlendShapes []string
+}
+
+// DefaultBlinkAssistConfig returns a blink-assist configuration for the
+// ARKit eyeBlinkLeft/eyeBlinkRight blend shapes.
+func DefaultBlinkAssistConfig() BlinkAssistConfig {
+	return BlinkAssistConfig{
+		CloseThreshold: 0.6,
+		OpenFloor:      0.15,
+		HoldTime:       80 * time.Millisecond,
+		BlendShapes:    []string{"eyeBlinkLeft", "eyeBlinkRight"},
+	}
+}
+
+// BlinkAssist is a blend shape post-processor that turns partial blinks into
+// full ones. Natural blinks often don't fully register, leaving the avatar
+// with half-closed eyes; BlinkAssist snaps them fully closed instead.
+//
+// BlinkAssist implements TransformStage, so it can run after a blend shape
+// estimator in a ChainProcessor.
+type BlinkAssist struct {
+	mu     sync.Mutex
+	cfg    BlinkAssistConfig
+	closed map[string]time.Time // Blend shapes currently snapped closed, by snap time
+	clock  Clock
+}
+
+// NewBlinkAssist creates a blink-assist post-processor.
+func NewBlinkAssist(cfg BlinkAssistConfig) *BlinkAssist {
+	return &BlinkAssist{
+		cfg:    cfg,
+		closed: make(map[string]time.Time),
+		clock:  realClock{},
+	}
+}
+
+// SetClock sets the clock the hold time is measured with. It defaults to
+// the system clock; pass the tracker's clock so holds follow its time,
+// e.g. a FakeClock in tests.
+func (b *BlinkAssist) SetClock(clock Clock) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.clock = clock
+}
+
+// Transform snaps the configured blend shapes in data.Face.BlendShapes.
+func (b *BlinkAssist) Transform(data *TrackingData) (*TrackingData, error) {
+	if data == nil || data.Face == nil || data.Face.BlendShapes == nil {
+		return data, nil
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	now := b.clock.Now()
+	for _, name := range b.cfg.BlendShapes {
+		value, ok := data.Face.BlendShapes[name]
+		if !ok {
+			continue
+		}
+		data.Face.BlendShapes[name] = b.snap(name, value, now)
+	}
+
+	return data, nil
+}
+
+// snap returns the post-processed value for one blend shape.
+func (b *BlinkAssist) snap(name string, value float64, now time.Time) float64 {
+	if value >= b.cfg.CloseThreshold {
+		if _, ok := b.closed[name]; !ok {
+			b.closed[name] = now
+		}
+		return 1
+	}
+
+	// Keep the eye closed until the hold time has elapsed
+	if closedAt, ok := b.closed[name]; ok {
+		if now.Sub(closedAt) < b.cfg.HoldTime {
+			return 1
+		}
+		delete(b.closed, name)
+	}
+
+	if value < b.cfg.OpenFloor {
+		return 0
+	}
+	return value
+}
+
+// Reset clears the debounce state.
+func (b *BlinkAssist) Reset() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.closed = make(map[string]time.Time)
+}
@@ -0,0 +1,82 @@
+package miface
+
+import (
+	"testing"
+	"time"
+)
+
+// newTestBlinkAssist returns a BlinkAssist whose clock advances by step on every call.
+func newTestBlinkAssist(cfg BlinkAssistConfig, step time.Duration) *BlinkAssist {
+	b := NewBlinkAssist(cfg)
+	b.SetClock(frameClock{NewFakeClock(time.Unix(0, 0)), step})
+	return b
+}
+
+func blinkFrame(value float64) *TrackingData {
+	return &TrackingData{
+		Face: &FaceData{
+			BlendShapes: map[string]float64{"eyeBlinkLeft": value, "jawOpen": value},
+		},
+	}
+}
+
+func TestBlinkAssistRamp(t *testing.T) {
+	cfg := DefaultBlinkAssistConfig()
+	cfg.CloseThreshold = 0.6
+	cfg.OpenFloor = 0.2
+	cfg.HoldTime = 50 * time.Millisecond
+	b := newTestBlinkAssist(cfg, 10*time.Millisecond)
+
+	tests := []struct {
+		input float64
+		want  float64
+	}{
+		{0.0, 0},
+		{0.1, 0},   // Below floor
+		{0.3, 0.3}, // Between floor and threshold passes through
+		{0.5, 0.5},
+		{0.6, 1}, // Crosses threshold
+		{0.8, 1},
+		{0.5, 1}, // Flicker below threshold within hold time
+		{0.3, 1},
+		{0.2, 1},
+		{0.1, 0}, // Hold time elapsed
+		{0.3, 0.3},
+	}
+
+	for i, tt := range tests {
+		data, err := b.Transform(blinkFrame(tt.input))
+		if err != nil {
+			t.Fatalf("frame %d: unexpected error: %v", i, err)
+		}
+		if got := data.Face.BlendShapes["eyeBlinkLeft"]; got != tt.want {
+			t.Errorf("frame %d: input %.1f: expected %.1f, got %.1f", i, tt.input, tt.want, got)
+		}
+		if got := data.Face.BlendShapes["jawOpen"]; got != tt.input {
+			t.Errorf("frame %d: expected jawOpen untouched at %.1f, got %.1f", i, tt.input, got)
+		}
+	}
+}
+
+func TestBlinkAssistNoFloor(t *testing.T) {
+	cfg := DefaultBlinkAssistConfig()
+	cfg.OpenFloor = 0
+	b := newTestBlinkAssist(cfg, 10*time.Millisecond)
+
+	data, _ := b.Transform(blinkFrame(0.05))
+	if got := data.Face.BlendShapes["eyeBlinkLeft"]; got != 0.05 {
+		t.Errorf("expected 0.05 to pass through with floor disabled, got %f", got)
+	}
+}
+
+func TestBlinkAssistNoFace(t *testing.T) {
+	b := NewBlinkAssist(DefaultBlinkAssistConfig())
+
+	data, err := b.Transform(&TrackingData{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if data.Face != nil {
+		t.Error("expected face to remain nil")
+	}
+}
@@ -0,0 +1,63 @@
+package miface
+
+import "math"
+
+// BoundingBox is an axis-aligned box around a set of landmarks, in the
+// landmarks' coordinates.
+type BoundingBox struct {
+	Min, Max Point3D
+}
+
+// Center returns the midpoint of the box.
+func (b BoundingBox) Center() Point3D {
+	return b.Min.Add(b.Max).Scale(0.5)
+}
+
+// LandmarkBounds returns the bounding box of the landmarks whose Visibility
+// is at least minVisibility. If no landmark qualifies it returns the zero
+// box and false.
+func LandmarkBounds(landmarks []Landmark, minVisibility float64) (BoundingBox, bool) {
+	lo := Point3D{X: math.Inf(1), Y: math.Inf(1), Z: math.Inf(1)}
+	hi := Point3D{X: math.Inf(-1), Y: math.Inf(-1), Z: math.Inf(-1)}
+	var found bool
+	for _, lm := range landmarks {
+		if lm.Visibility < minVisibility {
+			continue
+		}
+		p := lm.Point
+		lo = Point3D{X: math.Min(lo.X, p.X), Y: math.Min(lo.Y, p.Y), Z: math.Min(lo.Z, p.Z)}
+		hi = Point3D{X: math.Max(hi.X, p.X), Y: math.Max(hi.Y, p.Y), Z: math.Max(hi.Z, p.Z)}
+		found = true
+	}
+	if !found {
+		return BoundingBox{}, false
+	}
+	return BoundingBox{Min: lo, Max: hi}, true
+}
+
+// BoundingBox returns the bounds of the face landmarks with at least
+// minVisibility; see LandmarkBounds.
+func (f *FaceData) BoundingBox(minVisibility float64) (BoundingBox, bool) {
+	if f == nil {
+		return BoundingBox{}, false
+	}
+	return LandmarkBounds(f.Landmarks, minVisibility)
+}
+
+// BoundingBox returns the bounds of the hand landmarks with at least
+// minVisibility; see LandmarkBounds.
+func (h *HandData) BoundingBox(minVisibility float64) (BoundingBox, bool) {
+	if h == nil {
+		return BoundingBox{}, false
+	}
+	return LandmarkBounds(h.Landmarks, minVisibility)
+}
+
+// BoundingBox returns the bounds of the pose landmarks with at least
+// minVisibility; see LandmarkBounds.
+func (p *PoseData) BoundingBox(minVisibility float64) (BoundingBox, bool) {
+	if p == nil {
+		return BoundingBox{}, false
+	}
+	return LandmarkBounds(p.Landmarks, minVisibility)
+}
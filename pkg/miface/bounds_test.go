@@ -0,0 +1,74 @@
+package miface
+
+import "testing"
+
+func TestLandmarkBounds(t *testing.T) {
+	landmarks := []Landmark{
+		{Point: Point3D{X: 0.2, Y: 0.6, Z: -0.1}, Visibility: 1},
+		{Point: Point3D{X: 0.4, Y: 0.3, Z: 0.05}, Visibility: 0.9},
+		{Point: Point3D{X: 0.3, Y: 0.5, Z: 0.1}, Visibility: 0.6},
+		// Hidden outlier, dropped at the 0.5 threshold
+		{Point: Point3D{X: 0.9, Y: 0.1, Z: 0.5}, Visibility: 0.2},
+	}
+
+	box, ok := LandmarkBounds(landmarks, 0.5)
+	if !ok {
+		t.Fatal("expected a bounding box")
+	}
+	want := BoundingBox{Min: Point3D{X: 0.2, Y: 0.3, Z: -0.1}, Max: Point3D{X: 0.4, Y: 0.6, Z: 0.1}}
+	if box != want {
+		t.Errorf("expected %+v, got %+v", want, box)
+	}
+	if got, want := box.Center(), (Point3D{X: 0.3, Y: 0.45, Z: 0}); !pointsClose(got, want) {
+		t.Errorf("expected center %+v, got %+v", want, got)
+	}
+
+	// Without a threshold the outlier widens the box
+	box, _ = LandmarkBounds(landmarks, 0)
+	if box.Max.X != 0.9 || box.Min.Y != 0.1 {
+		t.Errorf("expected the outlier to be included, got %+v", box)
+	}
+}
+
+func TestLandmarkBoundsEmpty(t *testing.T) {
+	if box, ok := LandmarkBounds(nil, 0); ok || box != (BoundingBox{}) {
+		t.Errorf("expected no box for no landmarks, got %+v, %v", box, ok)
+	}
+
+	hidden := []Landmark{{Point: Point3D{X: 0.5, Y: 0.5}, Visibility: 0.1}}
+	if box, ok := LandmarkBounds(hidden, 0.5); ok || box != (BoundingBox{}) {
+		t.Errorf("expected no box for invisible landmarks, got %+v, %v", box, ok)
+	}
+}
+
+func TestTrackingDataBoundingBox(t *testing.T) {
+	hand := testHand(true, 1)
+	box, ok := hand.BoundingBox(0.5)
+	if !ok {
+		t.Fatal("expected a hand bounding box")
+	}
+	// testHand lays the landmarks out along X at their index
+	if box.Min.X != 0 || box.Max.X != 20 || box.Center().X != 10 {
+		t.Errorf("expected hand box from X 0 to 20, got %+v", box)
+	}
+
+	pose := testPose()
+	box, ok = pose.BoundingBox(0.5)
+	if !ok || box.Min.Y != 0 || box.Max.Y != 32 {
+		t.Errorf("expected pose box from Y 0 to 32, got %+v, %v", box, ok)
+	}
+
+	face := &FaceData{Landmarks: []Landmark{
+		{Point: Point3D{X: 0.4, Y: 0.3}, Visibility: 1},
+		{Point: Point3D{X: 0.6, Y: 0.5}, Visibility: 1},
+	}}
+	box, ok = face.BoundingBox(0)
+	if !ok || box.Center() != (Point3D{X: 0.5, Y: 0.4}) {
+		t.Errorf("expected face box centered at (0.5, 0.4), got %+v, %v", box, ok)
+	}
+
+	var missing *FaceData
+	if _, ok := missing.BoundingBox(0); ok {
+		t.Error("expected no box for a nil face")
+	}
+}
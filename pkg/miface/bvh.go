@@ -0,0 +1,193 @@
+package miface
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"strings"
+	"time"
+)
+
+// DefaultBVHFrameTime is the frame time ExportBVH writes.
+const DefaultBVHFrameTime = time.Second / 30
+
+// bvhUnitsPerMeter converts skeleton offsets to BVH units (centimeters).
+const bvhUnitsPerMeter = 100
+
+// bvhJoint is a joint in the exported BVH hierarchy.
+type bvhJoint struct {
+	name   string     // BVH joint name
+	bone   string     // VRM humanoid bone the offset is read from
+	parent int        // Index of the parent joint, -1 for the root
+	rest   [3]float64 // Offset from the parent in meters without a skeleton
+	end    [3]float64 // End site offset in meters, zero for inner joints
+}
+
+// bvhJoints is the upper body hierarchy written by ExportBVH, in the order
+// its channels appear in each frame.
+var bvhJoints = []bvhJoint{
+	{name: "Hips", bone: "hips", parent: -1, rest: [3]float64{0, 1, 0}},
+	{name: "Spine", bone: "spine", parent: 0, rest: [3]float64{0, 0.1, 0}},
+	{name: "Chest", bone: "chest", parent: 1, rest: [3]float64{0, 0.15, 0}},
+	{name: "Neck", bone: "neck", parent: 2, rest: [3]float64{0, 0.2, 0}},
+	{name: "Head", bone: "head", parent: 3, rest: [3]float64{0, 0.1, 0}, end: [3]float64{0, 0.15, 0}},
+	{name: "LeftShoulder", bone: "leftShoulder", parent: 2, rest: [3]float64{0.02, 0.17, 0}},
+	{name: "LeftUpperArm", bone: "leftUpperArm", parent: 5, rest: [3]float64{0.08, 0, 0}},
+	{name: "LeftLowerArm", bone: "leftLowerArm", parent: 6, rest: [3]float64{0.28, 0, 0}},
+	{name: "LeftHand", bone: "leftHand", parent: 7, rest: [3]float64{0.25, 0, 0}, end: [3]float64{0.08, 0, 0}},
+	{name: "RightShoulder", bone: "rightShoulder", parent: 2, rest: [3]float64{-0.02, 0.17, 0}},
+	{name: "RightUpperArm", bone: "rightUpperArm", parent: 9, rest: [3]float64{-0.08, 0, 0}},
+	{name: "RightLowerArm", bone: "rightLowerArm", parent: 10, rest: [3]float64{-0.28, 0, 0}},
+	{name: "RightHand", bone: "rightHand", parent: 11, rest: [3]float64{-0.25, 0, 0}, end: [3]float64{-0.08, 0, 0}},
+}
+
+// ExportBVH writes frames as an upper body BVH motion file at
+// DefaultBVHFrameTime. See ExportBVHWithFrameTime.
+func ExportBVH(frames []*TrackingData, skeleton *VRMSkeleton, w io.Writer) error {
+	return ExportBVHWithFrameTime(frames, skeleton, DefaultBVHFrameTime, w)
+}
+
+// ExportBVHWithFrameTime writes frames as an upper body BVH motion file with
+// one frame per TrackingData, frameTime apart. The joint offsets are the
+// bones' local translations in skeleton, in centimeters; joints missing from
+// skeleton, or all joints if it is nil, use a generic rest pose.
+//
+// The spine rotation comes from EstimateSpineRotation, the head rotation
+// from the face and the arm rotations from a Retargeter with the skeleton's
+// proportions. A joint missing from a frame (or a nil frame) holds its
+// previous rotation.
+func ExportBVHWithFrameTime(frames []*TrackingData, skeleton *VRMSkeleton, frameTime time.Duration, w io.Writer) error {
+	if len(frames) == 0 {
+		return errors.New("no frames to export")
+	}
+	if frameTime <= 0 {
+		return fmt.Errorf("frame time must be positive, got %v", frameTime)
+	}
+
+	offsets := make([][3]float64, len(bvhJoints))
+	for i, joint := range bvhJoints {
+		offsets[i] = joint.rest
+		if skeleton != nil {
+			if p, ok := skeleton.GetBonePosition(joint.bone); ok {
+				offsets[i] = [3]float64{p.X, p.Y, p.Z}
+			}
+		}
+	}
+
+	var proportions *BoneProportions
+	if skeleton != nil {
+		proportions = skeleton.GetProportions()
+	}
+	retargeter := NewRetargeter(proportions)
+
+	bw := bufio.NewWriter(w)
+	writeBVHHierarchy(bw, offsets)
+
+	fmt.Fprintf(bw, "MOTION\nFrames: %d\nFrame Time: %.6f\n", len(frames), frameTime.Seconds())
+	rotations := make([]Quaternion, len(bvhJoints))
+	for i := range rotations {
+		rotations[i] = Quaternion{W: 1}
+	}
+	for _, data := range frames {
+		if data != nil {
+			bvhRotations(data, retargeter, rotations)
+		}
+
+		root := offsets[0]
+		values := []float64{
+			root[0] * bvhUnitsPerMeter, root[1] * bvhUnitsPerMeter, root[2] * bvhUnitsPerMeter,
+		}
+		for _, q := range rotations {
+			z, x, y := quaternionToEulerZXY(q)
+			values = append(values, z, x, y)
+		}
+
+		fields := make([]string, len(values))
+		for i, v := range values {
+			fields[i] = fmt.Sprintf("%.4f", v)
+		}
+		fmt.Fprintln(bw, strings.Join(fields, " "))
+	}
+
+	if err := bw.Flush(); err != nil {
+		return fmt.Errorf("writing BVH: %w", err)
+	}
+	return nil
+}
+
+// writeBVHHierarchy writes the HIERARCHY section with the given offsets.
+func writeBVHHierarchy(w io.Writer, offsets [][3]float64) {
+	fmt.Fprintln(w, "HIERARCHY")
+
+	var writeJoint func(index, depth int)
+	writeJoint = func(index, depth int) {
+		joint := bvhJoints[index]
+		indent := strings.Repeat("\t", depth)
+		offset := offsets[index]
+
+		if joint.parent < 0 {
+			fmt.Fprintf(w, "%sROOT %s\n", indent, joint.name)
+		} else {
+			fmt.Fprintf(w, "%sJOINT %s\n", indent, joint.name)
+		}
+		fmt.Fprintf(w, "%s{\n", indent)
+		fmt.Fprintf(w, "%s\tOFFSET %.4f %.4f %.4f\n", indent,
+			offset[0]*bvhUnitsPerMeter, offset[1]*bvhUnitsPerMeter, offset[2]*bvhUnitsPerMeter)
+		if joint.parent < 0 {
+			fmt.Fprintf(w, "%s\tCHANNELS 6 Xposition Yposition Zposition Zrotation Xrotation Yrotation\n", indent)
+		} else {
+			fmt.Fprintf(w, "%s\tCHANNELS 3 Zrotation Xrotation Yrotation\n", indent)
+		}
+
+		var hasChildren bool
+		for child, j := range bvhJoints {
+			if j.parent == index {
+				hasChildren = true
+				writeJoint(child, depth+1)
+			}
+		}
+		if !hasChildren {
+			fmt.Fprintf(w, "%s\tEnd Site\n%s\t{\n", indent, indent)
+			fmt.Fprintf(w, "%s\t\tOFFSET %.4f %.4f %.4f\n", indent,
+				joint.end[0]*bvhUnitsPerMeter, joint.end[1]*bvhUnitsPerMeter, joint.end[2]*bvhUnitsPerMeter)
+			fmt.Fprintf(w, "%s\t}\n", indent)
+		}
+		fmt.Fprintf(w, "%s}\n", indent)
+	}
+	writeJoint(0, 0)
+}
+
+// bvhRotations updates the joint rotations, indexed like bvhJoints, with
+// the rotations tracked in data.
+func bvhRotations(data *TrackingData, retargeter *Retargeter, rotations []Quaternion) {
+	if data.Pose != nil && len(data.Pose.Landmarks) > PoseRightShoulder {
+		rotations[1], rotations[2] = EstimateSpineRotation(data.Pose)
+	}
+	if data.Face != nil {
+		rotations[4] = data.Face.HeadRotation.Normalize()
+	}
+	if data.Pose != nil {
+		left, right := retargeter.Solve(data.Pose)
+		rotations[6], rotations[7], rotations[8] = left.UpperArm, left.LowerArm, left.Hand
+		rotations[10], rotations[11], rotations[12] = right.UpperArm, right.LowerArm, right.Hand
+	}
+}
+
+// quaternionToEulerZXY converts q to BVH Euler angles in degrees, for
+// channels in Zrotation Xrotation Yrotation order (R = Rz·Rx·Ry).
+func quaternionToEulerZXY(q Quaternion) (z, x, y float64) {
+	q = q.Normalize()
+	r01 := 2 * (q.X*q.Y - q.W*q.Z)
+	r11 := 1 - 2*(q.X*q.X+q.Z*q.Z)
+	r20 := 2 * (q.X*q.Z - q.W*q.Y)
+	r21 := 2 * (q.Y*q.Z + q.W*q.X)
+	r22 := 1 - 2*(q.X*q.X+q.Y*q.Y)
+
+	const deg = 180 / math.Pi
+	x = math.Asin(clampUnit(r21))
+	z = math.Atan2(-r01, r11)
+	y = math.Atan2(-r20, r22)
+	return z * deg, x * deg, y * deg
+}
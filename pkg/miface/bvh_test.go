@@ -0,0 +1,116 @@
+package miface
+
+import (
+	"bufio"
+	"bytes"
+	"math"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestExportBVH(t *testing.T) {
+	var frames []*TrackingData
+	for i := 0; i < 10; i++ {
+		frames = append(frames, &TrackingData{
+			Face: &FaceData{HeadRotation: QuaternionFromEuler(0, 0.05*float64(i), 0)},
+			Pose: uprightPose(),
+		})
+	}
+	// A nil frame repeats the previous one
+	frames[5] = nil
+
+	var buf bytes.Buffer
+	if err := ExportBVHWithFrameTime(frames, nil, 40*time.Millisecond, &buf); err != nil {
+		t.Fatalf("export failed: %v", err)
+	}
+
+	scanner := bufio.NewScanner(&buf)
+	var channels, joints, frameCount int
+	var frameTime float64
+	var motion [][]string
+	inMotion := false
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) == 0 {
+			continue
+		}
+		switch {
+		case fields[0] == "MOTION":
+			inMotion = true
+		case !inMotion && fields[0] == "CHANNELS":
+			n, err := strconv.Atoi(fields[1])
+			if err != nil || len(fields) != n+2 {
+				t.Fatalf("malformed CHANNELS line: %v", fields)
+			}
+			channels += n
+		case !inMotion && (fields[0] == "ROOT" || fields[0] == "JOINT"):
+			joints++
+		case inMotion && fields[0] == "Frames:":
+			frameCount, _ = strconv.Atoi(fields[1])
+		case inMotion && fields[0] == "Frame":
+			frameTime, _ = strconv.ParseFloat(fields[2], 64)
+		case inMotion:
+			motion = append(motion, fields)
+		}
+	}
+
+	if frameCount != 10 {
+		t.Errorf("expected 10 frames, got %d", frameCount)
+	}
+	if len(motion) != 10 {
+		t.Fatalf("expected 10 motion lines, got %d", len(motion))
+	}
+	if joints != len(bvhJoints) {
+		t.Errorf("expected %d joints, got %d", len(bvhJoints), joints)
+	}
+	if want := 6 + 3*(len(bvhJoints)-1); channels != want {
+		t.Errorf("expected %d channels, got %d", want, channels)
+	}
+	if math.Abs(frameTime-0.04) > 1e-9 {
+		t.Errorf("expected frame time 0.04, got %f", frameTime)
+	}
+	for i, line := range motion {
+		if len(line) != channels {
+			t.Errorf("frame %d: expected %d values, got %d", i, channels, len(line))
+		}
+	}
+	if strings.Join(motion[5], " ") != strings.Join(motion[4], " ") {
+		t.Error("expected nil frame to repeat the previous frame")
+	}
+}
+
+func TestExportBVHEmpty(t *testing.T) {
+	var buf bytes.Buffer
+	if err := ExportBVH(nil, nil, &buf); err == nil {
+		t.Error("expected error with no frames")
+	}
+}
+
+func TestQuaternionToEulerZXY(t *testing.T) {
+	tests := []struct {
+		name    string
+		axis    Point3D
+		z, x, y float64
+	}{
+		{"about X", Point3D{X: 1}, 0, 30, 0},
+		{"about Y", Point3D{Y: 1}, 0, 0, 30},
+		{"about Z", Point3D{Z: 1}, 30, 0, 0},
+	}
+	half := 15 * math.Pi / 180
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			q := Quaternion{
+				X: tt.axis.X * math.Sin(half),
+				Y: tt.axis.Y * math.Sin(half),
+				Z: tt.axis.Z * math.Sin(half),
+				W: math.Cos(half),
+			}
+			z, x, y := quaternionToEulerZXY(q)
+			if math.Abs(z-tt.z) > 1e-9 || math.Abs(x-tt.x) > 1e-9 || math.Abs(y-tt.y) > 1e-9 {
+				t.Errorf("expected (%v, %v, %v), got (%v, %v, %v)", tt.z, tt.x, tt.y, z, x, y)
+			}
+		})
+	}
+}
@@ -0,0 +1,114 @@
+package miface
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/BurntSushi/toml"
+)
+
+// Calibration holds per-user calibration data that is kept across sessions.
+type Calibration struct {
+	// NeutralBlendShapes is the blend shape baseline of the user's neutral
+	// face. It is subtracted from every frame so that a resting face sends
+	// zero weights. See Tracker.CalibrateNeutral.
+	NeutralBlendShapes map[string]float64 `toml:"neutral_blend_shapes"`
+	// Body is the user's body proportions, as measured by a
+	// TPoseDetector (nil = not measured). See Tracker.SaveBodyCalibration.
+	Body *BodyCalibration `toml:"body,omitempty"`
+}
+
+// LoadCalibration reads a calibration file.
+// If the file does not exist, it returns an empty calibration.
+func LoadCalibration(path string) (*Calibration, error) {
+	c := &Calibration{}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return c, nil
+		}
+		return nil, fmt.Errorf("reading calibration file: %w", err)
+	}
+
+	if _, err := toml.Decode(string(data), c); err != nil {
+		return nil, fmt.Errorf("parsing calibration file: %w", err)
+	}
+	return c, nil
+}
+
+// Save writes the calibration to path as TOML.
+func (c *Calibration) Save(path string) error {
+	var buf bytes.Buffer
+	if err := toml.NewEncoder(&buf).Encode(c); err != nil {
+		return fmt.Errorf("encoding calibration: %w", err)
+	}
+	if err := os.WriteFile(path, buf.Bytes(), 0o644); err != nil {
+		return fmt.Errorf("writing calibration file: %w", err)
+	}
+	return nil
+}
+
+// updateCalibrationFile loads the calibration file at path, applies update
+// and saves it, so each field can be written without erasing the others.
+func updateCalibrationFile(path string, update func(c *Calibration)) error {
+	c, err := LoadCalibration(path)
+	if err != nil {
+		return err
+	}
+	update(c)
+	return c.Save(path)
+}
+
+// SubtractNeutral subtracts the neutral baseline from shapes in place,
+// clamping the results to [0, 1]. Names without a baseline are unchanged.
+func SubtractNeutral(shapes, neutral map[string]float64) {
+	for name, value := range shapes {
+		if base, ok := neutral[name]; ok {
+			shapes[name] = clamp01(value - base)
+		}
+	}
+}
+
+// neutralCapture accumulates blend shapes for Tracker.CalibrateNeutral.
+// Its fields are only accessed by processFrame until done is closed.
+type neutralCapture struct {
+	until  time.Time
+	sums   map[string]float64
+	counts map[string]int
+	done   chan struct{}
+}
+
+// newNeutralCapture creates a capture that ends at until.
+func newNeutralCapture(until time.Time) *neutralCapture {
+	return &neutralCapture{
+		until:  until,
+		sums:   make(map[string]float64),
+		counts: make(map[string]int),
+		done:   make(chan struct{}),
+	}
+}
+
+// add accumulates one frame of blend shapes.
+func (c *neutralCapture) add(shapes map[string]float64) {
+	for name, value := range shapes {
+		c.sums[name] += value
+		c.counts[name]++
+	}
+}
+
+// baseline returns the average of each blend shape, or nil if no blend
+// shapes were captured.
+func (c *neutralCapture) baseline() map[string]float64 {
+	if len(c.sums) == 0 {
+		return nil
+	}
+
+	neutral := make(map[string]float64, len(c.sums))
+	for name, sum := range c.sums {
+		neutral[name] = clamp01(sum / float64(c.counts[name]))
+	}
+	return neutral
+}
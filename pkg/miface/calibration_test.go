@@ -0,0 +1,69 @@
+package miface
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestCalibrationSaveLoad(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "calibration.toml")
+
+	c := &Calibration{
+		NeutralBlendShapes: map[string]float64{"jawOpen": 0.05, "mouthClose": 0.1},
+		Body:               &BodyCalibration{ArmSpan: 0.68, ShoulderWidth: 0.2},
+	}
+	if err := c.Save(path); err != nil {
+		t.Fatalf("failed to save calibration: %v", err)
+	}
+
+	loaded, err := LoadCalibration(path)
+	if err != nil {
+		t.Fatalf("failed to load calibration: %v", err)
+	}
+	for name, want := range c.NeutralBlendShapes {
+		if got := loaded.NeutralBlendShapes[name]; got != want {
+			t.Errorf("%s: expected %f, got %f", name, want, got)
+		}
+	}
+	if loaded.Body == nil || *loaded.Body != *c.Body {
+		t.Errorf("expected body %+v, got %+v", c.Body, loaded.Body)
+	}
+}
+
+func TestLoadCalibrationMissingFile(t *testing.T) {
+	c, err := LoadCalibration(filepath.Join(t.TempDir(), "missing.toml"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if c.NeutralBlendShapes != nil {
+		t.Errorf("expected empty calibration, got %v", c.NeutralBlendShapes)
+	}
+}
+
+func TestLoadCalibrationInvalid(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "calibration.toml")
+	if err := os.WriteFile(path, []byte("neutral_blend_shapes = ["), 0o644); err != nil {
+		t.Fatalf("failed to write file: %v", err)
+	}
+
+	if _, err := LoadCalibration(path); err == nil {
+		t.Error("expected error for invalid calibration file")
+	}
+}
+
+func TestSubtractNeutral(t *testing.T) {
+	shapes := map[string]float64{"jawOpen": 0.5, "mouthClose": 0.05, "eyeBlinkLeft": 0.3}
+	SubtractNeutral(shapes, map[string]float64{"jawOpen": 0.1, "mouthClose": 0.1})
+
+	tests := map[string]float64{
+		"jawOpen":      0.4,
+		"mouthClose":   0, // Clamped
+		"eyeBlinkLeft": 0.3,
+	}
+	for name, want := range tests {
+		if got := shapes[name]; got < want-1e-9 || got > want+1e-9 {
+			t.Errorf("%s: expected %f, got %f", name, want, got)
+		}
+	}
+}
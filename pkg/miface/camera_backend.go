@@ -0,0 +1,60 @@
+package miface
+
+import "runtime"
+
+// CameraBackend selects the OpenCV video capture API used to open a camera.
+// Values match OpenCV's cv::VideoCaptureAPIs constants.
+type CameraBackend int
+
+const (
+	// CameraBackendAny lets OpenCV pick the first backend that works.
+	CameraBackendAny CameraBackend = 0
+	// CameraBackendV4L2 is Video4Linux2 (Linux). It avoids the GStreamer
+	// "Internal data stream error" seen with many USB webcams.
+	CameraBackendV4L2 CameraBackend = 200
+	// CameraBackendDShow is DirectShow (Windows).
+	CameraBackendDShow CameraBackend = 700
+	// CameraBackendAVFoundation is AVFoundation (macOS).
+	CameraBackendAVFoundation CameraBackend = 1200
+	// CameraBackendMSMF is Microsoft Media Foundation (Windows).
+	CameraBackendMSMF CameraBackend = 1400
+)
+
+// String returns the backend name.
+func (b CameraBackend) String() string {
+	switch b {
+	case CameraBackendAny:
+		return "any"
+	case CameraBackendV4L2:
+		return "v4l2"
+	case CameraBackendDShow:
+		return "dshow"
+	case CameraBackendAVFoundation:
+		return "avfoundation"
+	case CameraBackendMSMF:
+		return "msmf"
+	default:
+		return "unknown"
+	}
+}
+
+// DefaultCameraBackend returns the capture backend for the current OS.
+func DefaultCameraBackend() CameraBackend {
+	return defaultCameraBackend(runtime.GOOS)
+}
+
+// defaultCameraBackend returns the capture backend for goos. DirectShow is
+// preferred on Windows because MSMF is slow to open and often ignores
+// MJPEG requests.
+func defaultCameraBackend(goos string) CameraBackend {
+	switch goos {
+	case "linux":
+		return CameraBackendV4L2
+	case "windows":
+		return CameraBackendDShow
+	case "darwin", "ios":
+		return CameraBackendAVFoundation
+	default:
+		return CameraBackendAny
+	}
+}
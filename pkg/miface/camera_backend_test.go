@@ -0,0 +1,43 @@
+package miface
+
+import (
+	"runtime"
+	"testing"
+)
+
+func TestDefaultCameraBackend(t *testing.T) {
+	tests := []struct {
+		goos string
+		want CameraBackend
+	}{
+		{"linux", CameraBackendV4L2},
+		{"windows", CameraBackendDShow},
+		{"darwin", CameraBackendAVFoundation},
+		{"freebsd", CameraBackendAny},
+	}
+
+	for _, tt := range tests {
+		if got := defaultCameraBackend(tt.goos); got != tt.want {
+			t.Errorf("%s: expected %v, got %v", tt.goos, tt.want, got)
+		}
+	}
+}
+
+func TestDefaultCameraBackendMatchesOS(t *testing.T) {
+	got := DefaultCameraBackend()
+
+	var want CameraBackend
+	switch runtime.GOOS {
+	case "linux":
+		want = CameraBackendV4L2
+	case "windows":
+		want = CameraBackendDShow
+	case "darwin", "ios":
+		want = CameraBackendAVFoundation
+	default:
+		want = CameraBackendAny
+	}
+	if got != want {
+		t.Errorf("expected %v on %s, got %v", want, runtime.GOOS, got)
+	}
+}
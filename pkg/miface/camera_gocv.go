@@ -5,7 +5,10 @@ package miface
 
 import (
 	"fmt"
+	"path/filepath"
+	"strings"
 	"sync"
+	"time"
 
 	"gocv.io/x/gocv"
 )
@@ -15,15 +18,71 @@ const (
 	// MJPEG is widely supported by USB webcams and provides good compression.
 	// FourCC codes are 4-byte identifiers: 'MJPG' = 0x47504A4D
 	fourccMJPEG = 0x47504A4D
+
+	// DefaultWarmupFrames is the default maximum number of frames Open reads
+	// while waiting for the camera to deliver a usable image.
+	DefaultWarmupFrames = 30
+	// DefaultWarmupTimeout is the default upper bound on the time Open spends
+	// warming up the camera.
+	DefaultWarmupTimeout = 2 * time.Second
+
+	// warmupRetryDelay is the pause after a failed warm-up read, so a camera
+	// that isn't streaming yet isn't polled in a tight loop.
+	warmupRetryDelay = 10 * time.Millisecond
+	// warmupBlackLevel is the brightest channel value still considered black.
+	// Cameras with unsettled exposure deliver frames of near-zero noise.
+	warmupBlackLevel = 16
+
+	// drainMaxReads bounds how many frames DrainAndRead reads, a few more
+	// than the 4 buffers V4L2 queues by default.
+	drainMaxReads = 8
 )
 
+// videoCapture is the subset of *gocv.VideoCapture used by OpenCVCamera.
+type videoCapture interface {
+	IsOpened() bool
+	Set(prop gocv.VideoCaptureProperties, param float64)
+	Get(prop gocv.VideoCaptureProperties) float64
+	Read(m *gocv.Mat) bool
+	Close() error
+}
+
+// openVideoCapture opens deviceID with the given backend. If the open fails,
+// the error wraps ErrCameraBackendUnsupported when OpenCV has no such camera
+// backend and ErrCameraNotFound otherwise.
+func openVideoCapture(deviceID int, backend CameraBackend) (videoCapture, error) {
+	webcam, err := gocv.OpenVideoCaptureWithAPI(deviceID, gocv.VideoCaptureAPI(backend))
+	if err != nil {
+		if webcam != nil {
+			webcam.Close()
+		}
+		return nil, captureOpenError(err, backend, gocv.VideoRegistry.GetCameraBackends())
+	}
+	return webcam, nil
+}
+
+// captureOpenError classifies a failed open with backend given the camera
+// backends OpenCV was built with. CameraBackendAny is always available.
+func captureOpenError(err error, backend CameraBackend, available []gocv.VideoCaptureAPI) error {
+	if backend == CameraBackendAny {
+		return fmt.Errorf("%w: %v", ErrCameraNotFound, err)
+	}
+	for _, api := range available {
+		if CameraBackend(api) == backend {
+			return fmt.Errorf("%w: %v", ErrCameraNotFound, err)
+		}
+	}
+	return fmt.Errorf("%w: %s: %v", ErrCameraBackendUnsupported, backend, err)
+}
+
 // OpenCVCamera implements CameraSource using OpenCV via GoCV.
 //
 // Implementation notes:
-// - Uses V4L2 backend on Linux to avoid GStreamer "Internal data stream error"
+// - Uses a per-OS capture backend (V4L2 on Linux to avoid GStreamer "Internal data stream error")
 // - Sets MJPEG codec explicitly for maximum USB webcam compatibility
-// - Applies BGR→RGB conversion since MediaPipe expects RGB24 format
+// - Converts BGR, BGRA, YUYV and 8/16-bit grayscale frames to RGB24 in place since MediaPipe expects RGB24
 // - Supports horizontal flip (mirror mode) for natural VTubing experience
+// - Reuses its frame Mats and output buffer across reads to limit GC pressure
 // - Thread-safe: mu protects all fields and camera operations
 type OpenCVCamera struct {
 	mu sync.Mutex // Use Mutex instead of RWMutex - all ops modify state
@@ -36,18 +95,83 @@ type OpenCVCamera struct {
 	// Mirror enables horizontal flip for VTubing (user sees themselves mirrored)
 	mirror bool
 
-	webcam *gocv.VideoCapture
+	// backend is the OpenCV capture API used by Open
+	backend CameraBackend
+
+	webcam videoCapture
 	opened bool
+
+	// Warm-up limits applied by Open
+	warmupFrames  int
+	warmupTimeout time.Duration
+
+	// bufferSize is the number of frames the driver queues (0 = its default)
+	bufferSize int
+
+	// openCapture opens the capture device; replaced in tests
+	openCapture func(deviceID int, backend CameraBackend) (videoCapture, error)
+	// now times reads; replaced in tests
+	now func() time.Time
+
+	// Reused across reads to avoid per-frame allocations
+	frame gocv.Mat // Captured BGR frame, converted to RGB in place
+	buf   []byte   // RGB24 bytes returned by Read
+
+	// capturedAt estimates when frame was captured; see readFrame
+	capturedAt time.Time
 }
 
 // NewOpenCVCamera creates a new OpenCV-based camera source.
 // Set mirror=true to flip the image horizontally (typical for VTubing).
+// The capture backend is DefaultCameraBackend for the current OS.
 func NewOpenCVCamera(mirror bool) *OpenCVCamera {
+	return NewOpenCVCameraWithBackend(mirror, DefaultCameraBackend())
+}
+
+// NewOpenCVCameraWithBackend creates a new OpenCV-based camera source that
+// opens devices with the given capture backend.
+func NewOpenCVCameraWithBackend(mirror bool, backend CameraBackend) *OpenCVCamera {
 	return &OpenCVCamera{
-		mirror: mirror,
+		mirror:        mirror,
+		backend:       backend,
+		warmupFrames:  DefaultWarmupFrames,
+		warmupTimeout: DefaultWarmupTimeout,
+		openCapture:   openVideoCapture,
+		now:           time.Now,
 	}
 }
 
+// SetWarmup sets how long Open waits for the camera to settle. Open reads
+// up to frames frames, stopping early at the first non-empty, non-black
+// one, and gives up after timeout. Open succeeds even if no usable frame
+// arrived in time. frames <= 0 disables the warm-up.
+// Takes effect on the next Open.
+func (c *OpenCVCamera) SetWarmup(frames int, timeout time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.warmupFrames = frames
+	c.warmupTimeout = timeout
+}
+
+// SetBufferSize sets how many frames the capture driver queues. Drivers
+// such as V4L2 queue several, so when processing falls behind, Read
+// returns a frame that has waited in the queue; a size of 1 keeps only the
+// newest. Not every backend supports it. A size of 0 leaves the driver's
+// default. Applies immediately if the camera is open, and on every Open.
+func (c *OpenCVCamera) SetBufferSize(n int) error {
+	if n < 0 {
+		return fmt.Errorf("buffer size must not be negative, got %d", n)
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.bufferSize = n
+	if c.opened && n > 0 {
+		c.webcam.Set(gocv.VideoCaptureBufferSize, float64(n))
+	}
+	return nil
+}
+
 // Open initializes the camera with the given configuration.
 func (c *OpenCVCamera) Open(deviceID, width, height, fps int) error {
 	c.mu.Lock()
@@ -56,17 +180,20 @@ func (c *OpenCVCamera) Open(deviceID, width, height, fps int) error {
 	if c.opened {
 		return fmt.Errorf("camera already opened")
 	}
+	if deviceID < 0 {
+		return fmt.Errorf("invalid camera device %d: %w", deviceID, ErrCameraNotFound)
+	}
 
-	// Open video capture device with V4L2 backend (Linux)
-	// This avoids GStreamer issues and provides better compatibility
-	webcam, err := gocv.OpenVideoCaptureWithAPI(deviceID, gocv.VideoCaptureV4L2)
+	// Open video capture device with the configured backend
+	// On Linux this is V4L2, which avoids GStreamer issues
+	webcam, err := c.openCapture(deviceID, c.backend)
 	if err != nil {
-		return fmt.Errorf("failed to open camera device %d: %w", deviceID, err)
+		return fmt.Errorf("failed to open camera device %d with %s backend: %w", deviceID, c.backend, err)
 	}
 
 	if !webcam.IsOpened() {
 		webcam.Close()
-		return fmt.Errorf("camera device %d not found or unavailable", deviceID)
+		return fmt.Errorf("camera device %d: %w", deviceID, ErrCameraNotFound)
 	}
 
 	// Set MJPEG codec for better compatibility with USB webcams
@@ -82,6 +209,9 @@ func (c *OpenCVCamera) Open(deviceID, width, height, fps int) error {
 	if fps > 0 {
 		webcam.Set(gocv.VideoCaptureFPS, float64(fps))
 	}
+	if c.bufferSize > 0 {
+		webcam.Set(gocv.VideoCaptureBufferSize, float64(c.bufferSize))
+	}
 
 	// Verify actual resolution
 	actualWidth := webcam.Get(gocv.VideoCaptureFrameWidth)
@@ -93,87 +223,263 @@ func (c *OpenCVCamera) Open(deviceID, width, height, fps int) error {
 	c.height = int(actualHeight)
 	c.fps = int(actualFPS)
 	c.webcam = webcam
+	c.frame = gocv.NewMat()
+	c.capturedAt = time.Time{}
 	c.opened = true
 
-	// Warm up camera - read and discard first frame
-	// Some cameras need a moment to initialize
-	warmupMat := gocv.NewMat()
-	c.webcam.Read(&warmupMat)
-	warmupMat.Close()
+	// Warm up camera - many cameras deliver empty or black frames until
+	// exposure settles, which makes MediaPipe fail detection
+	c.warmup()
 
 	return nil
 }
 
+// warmup reads and discards frames until one is usable, warmupFrames have
+// been read, or warmupTimeout has elapsed. Must be called with c.mu held.
+func (c *OpenCVCamera) warmup() {
+	deadline := time.Now().Add(c.warmupTimeout)
+	for i := 0; i < c.warmupFrames && time.Now().Before(deadline); i++ {
+		if !c.webcam.Read(&c.frame) || c.frame.Empty() {
+			time.Sleep(warmupRetryDelay)
+			continue
+		}
+		if !isBlackFrame(c.frame) {
+			return
+		}
+	}
+}
+
+// isBlackFrame reports whether every sampled pixel value of m is at or
+// below warmupBlackLevel. A sparse sample is enough to tell a live image
+// from a dark startup frame.
+func isBlackFrame(m gocv.Mat) bool {
+	pixels, err := m.DataPtrUint8()
+	if err != nil || len(pixels) == 0 {
+		return true
+	}
+	step := len(pixels)/4096 + 1
+	if step%3 == 0 {
+		step++ // Don't sample a single channel of RGB data
+	}
+	for i := 0; i < len(pixels); i += step {
+		if pixels[i] > warmupBlackLevel {
+			return false
+		}
+	}
+	return true
+}
+
 // Read captures a single frame from the camera.
 // Returns the frame data as RGB24 bytes, along with width and height.
+//
+// The returned slice is a buffer owned by the camera and reused across
+// calls: it is only valid until the next Read, so callers must consume or
+// copy it first. Use ReadInto to supply your own buffer.
 func (c *OpenCVCamera) Read() ([]byte, int, int, error) {
 	c.mu.Lock()
 	defer c.mu.Unlock()
 
+	frameData, width, height, err := c.readInto(c.buf)
+	if err != nil {
+		return nil, 0, 0, err
+	}
+	c.buf = frameData
+
+	return frameData, width, height, nil
+}
+
+// DrainAndRead reads the frames queued in the capture driver and returns
+// the newest, like Read. OpenCV can't tell how many frames are queued, so
+// reads continue until one takes at least half a frame interval, meaning
+// it waited for the camera rather than coming from the queue, or until
+// drainMaxReads frames were read.
+//
+// The returned slice is reused across calls, as with Read.
+func (c *OpenCVCamera) DrainAndRead() ([]byte, int, int, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
 	if !c.opened {
 		return nil, 0, 0, fmt.Errorf("camera not opened")
 	}
 
-	// Create fresh Mat for this read (thread-safe)
-	mat := gocv.NewMat()
-	defer mat.Close()
+	for i := 0; i < drainMaxReads; i++ {
+		waited, err := c.readFrame()
+		if err != nil {
+			return nil, 0, 0, err
+		}
+		if waited {
+			break
+		}
+	}
+	if c.frame.Empty() {
+		return nil, 0, 0, fmt.Errorf("captured frame is empty")
+	}
+
+	frameData, width, height, err := c.convertFrame(c.buf)
+	if err != nil {
+		return nil, 0, 0, err
+	}
+	c.buf = frameData
+	return frameData, width, height, nil
+}
 
-	// Read frame into Mat
-	if ok := c.webcam.Read(&mat); !ok {
-		return nil, 0, 0, fmt.Errorf("failed to read frame from camera")
+// ReadTimestamped captures a frame like Read and also returns when it was
+// captured, implementing TimestampedSource. OpenCV doesn't report capture
+// times portably, so it is estimated from how long reads wait, as in
+// DrainAndRead: a frame from the driver's queue is taken to be one frame
+// interval newer than the one before it. Frames the driver dropped from a
+// full queue make the estimate too recent.
+//
+// The returned slice is reused across calls, as with Read.
+func (c *OpenCVCamera) ReadTimestamped() ([]byte, int, int, time.Time, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	frameData, width, height, err := c.readInto(c.buf)
+	if err != nil {
+		return nil, 0, 0, time.Time{}, err
 	}
+	c.buf = frameData
 
-	if mat.Empty() {
+	return frameData, width, height, c.capturedAt, nil
+}
+
+// ReadInto captures a single frame into dst, growing it if it is too small,
+// and returns the filled slice with the frame width and height.
+//
+// The returned slice aliases dst whenever dst has enough capacity, so
+// passing the previous result back in avoids any per-frame allocation.
+// The caller owns the buffer; the camera does not retain it.
+func (c *OpenCVCamera) ReadInto(dst []byte) ([]byte, int, int, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.readInto(dst)
+}
+
+// readInto captures a frame into the persistent Mats and copies the RGB
+// pixels into dst. Must be called with c.mu held.
+func (c *OpenCVCamera) readInto(dst []byte) ([]byte, int, int, error) {
+	if !c.opened {
+		return nil, 0, 0, fmt.Errorf("camera not opened")
+	}
+
+	// Read frame into the reused Mat
+	if _, err := c.readFrame(); err != nil {
+		return nil, 0, 0, err
+	}
+
+	if c.frame.Empty() {
 		return nil, 0, 0, fmt.Errorf("captured frame is empty")
 	}
 
-	// Apply horizontal flip if mirror mode enabled
-	if c.mirror {
-		gocv.Flip(mat, &mat, 1) //nolint:errcheck // gocv.Flip doesn't return error
+	return c.convertFrame(dst)
+}
+
+// readFrame reads the next frame into c.frame and reports whether the read
+// waited at least half a frame interval, meaning the camera had to capture
+// a new frame rather than it coming from the driver's queue. It updates
+// c.capturedAt: a frame that was waited for was just captured, and a
+// queued one one frame interval after the previous frame.
+// Must be called with c.mu held.
+func (c *OpenCVCamera) readFrame() (bool, error) {
+	fps := c.fps
+	if fps <= 0 {
+		fps = 30
 	}
+	interval := time.Second / time.Duration(fps)
 
-	// Convert BGR to RGB (OpenCV uses BGR by default)
-	rgbMat := gocv.NewMat()
-	defer rgbMat.Close()
-	gocv.CvtColor(mat, &rgbMat, gocv.ColorBGRToRGB) //nolint:errcheck // gocv.CvtColor doesn't return error
+	start := c.now()
+	if ok := c.webcam.Read(&c.frame); !ok {
+		return false, fmt.Errorf("failed to read frame from camera")
+	}
+	end := c.now()
+
+	waited := end.Sub(start) >= interval/2
+	switch next := c.capturedAt.Add(interval); {
+	case waited || c.capturedAt.IsZero() || next.After(end):
+		c.capturedAt = end
+	default:
+		c.capturedAt = next
+	}
+	return waited, nil
+}
 
-	// Get frame dimensions
-	width := rgbMat.Cols()
-	height := rgbMat.Rows()
+// convertFrame converts c.frame to RGB in place, mirrors it if enabled and
+// copies the pixels into dst. Converting in place avoids a second full-frame
+// Mat, since BGR→RGB is only a channel swap. Must be called with c.mu held.
+func (c *OpenCVCamera) convertFrame(dst []byte) ([]byte, int, int, error) {
+	if err := convertFrameColor(&c.frame, true); err != nil {
+		return nil, 0, 0, err
+	}
+
+	// Flip after the conversion: flipping packed YUYV would mix up the
+	// chroma of neighboring pixels
+	if c.mirror {
+		gocv.Flip(c.frame, &c.frame, 1) //nolint:errcheck // gocv.Flip doesn't return error
+	}
 
-	// Convert Mat to byte slice
 	// MediaPipe expects continuous RGB24 data
-	frameData := rgbMat.ToBytes()
+	pixels, err := c.frame.DataPtrUint8()
+	if err != nil {
+		return nil, 0, 0, fmt.Errorf("accessing frame data: %w", err)
+	}
 
-	return frameData, width, height, nil
+	if cap(dst) < len(pixels) {
+		dst = make([]byte, len(pixels))
+	}
+	dst = dst[:len(pixels)]
+	copy(dst, pixels)
+
+	return dst, c.frame.Cols(), c.frame.Rows(), nil
 }
 
-// ReadMat captures a frame and returns it as a gocv.Mat for preview.
+// ReadMat captures a frame and returns it as a BGR gocv.Mat for preview.
 // The returned Mat should be closed by the caller.
 // This is separate from Read() to avoid unnecessary conversions for preview.
 func (c *OpenCVCamera) ReadMat() (gocv.Mat, error) {
 	c.mu.Lock()
 	defer c.mu.Unlock()
 
+	return c.readMat()
+}
+
+// ReadMatTimestamped captures a frame like ReadMat and also returns when it
+// was captured, estimated as for ReadTimestamped. It implements
+// TimestampedMatSource.
+func (c *OpenCVCamera) ReadMatTimestamped() (gocv.Mat, time.Time, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	m, err := c.readMat()
+	if err != nil {
+		return m, time.Time{}, err
+	}
+	return m, c.capturedAt, nil
+}
+
+// readMat implements ReadMat. Must be called with c.mu held.
+func (c *OpenCVCamera) readMat() (gocv.Mat, error) {
 	if !c.opened {
 		return gocv.NewMat(), fmt.Errorf("camera not opened")
 	}
 
-	// Create fresh Mat for this read (thread-safe)
-	mat := gocv.NewMat()
-	defer mat.Close()
-
-	// Read frame into Mat
-	if ok := c.webcam.Read(&mat); !ok {
-		return gocv.NewMat(), fmt.Errorf("failed to read frame from camera")
+	// Read frame into the reused Mat
+	if _, err := c.readFrame(); err != nil {
+		return gocv.NewMat(), err
 	}
 
-	if mat.Empty() {
+	if c.frame.Empty() {
 		return gocv.NewMat(), fmt.Errorf("captured frame is empty")
 	}
 
 	// Clone for return value
-	result := mat.Clone()
+	result := c.frame.Clone()
+	if err := convertFrameColor(&result, false); err != nil {
+		result.Close()
+		return gocv.NewMat(), err
+	}
 
 	// Apply horizontal flip if mirror mode enabled
 	if c.mirror {
@@ -183,6 +489,88 @@ func (c *OpenCVCamera) ReadMat() (gocv.Mat, error) {
 	return result, nil
 }
 
+// convertFrameColor converts a captured frame in place to RGB24, or to
+// BGR24 if rgb is false. OpenCV decodes most streams to BGR (CV_8UC3), but
+// raw BGRA (CV_8UC4), YUYV (CV_8UC2) and 8- or 16-bit grayscale (CV_8UC1,
+// CV_16UC1) frames arrive unconverted. Other types return
+// ErrUnsupportedFrameType.
+func convertFrameColor(frame *gocv.Mat, rgb bool) error {
+	pick := func(toRGB, toBGR gocv.ColorConversionCode) gocv.ColorConversionCode {
+		if rgb {
+			return toRGB
+		}
+		return toBGR
+	}
+
+	var err error
+	switch frame.Type() {
+	case gocv.MatTypeCV8UC3:
+		if rgb {
+			err = gocv.CvtColor(*frame, frame, gocv.ColorBGRToRGB)
+		}
+	case gocv.MatTypeCV8UC4:
+		err = gocv.CvtColor(*frame, frame, pick(gocv.ColorBGRAToRGB, gocv.ColorBGRAToBGR))
+	case gocv.MatTypeCV8UC2:
+		err = gocv.CvtColor(*frame, frame, pick(gocv.ColorYUVToRGBYUY2, gocv.ColorYUVToBGRYUY2))
+	case gocv.MatTypeCV16UC1:
+		// Keep the high byte of each 16-bit sample
+		if err := frame.ConvertToWithParams(frame, gocv.MatTypeCV8U, 1.0/256, 0); err != nil {
+			return fmt.Errorf("converting 16-bit frame: %w", err)
+		}
+		err = gocv.CvtColor(*frame, frame, pick(gocv.ColorGrayToRGB, gocv.ColorGrayToBGR))
+	case gocv.MatTypeCV8UC1:
+		err = gocv.CvtColor(*frame, frame, pick(gocv.ColorGrayToRGB, gocv.ColorGrayToBGR))
+	default:
+		return fmt.Errorf("%w: Mat type %d with %d channels", ErrUnsupportedFrameType, frame.Type(), frame.Channels())
+	}
+	if err != nil {
+		return fmt.Errorf("converting frame color: %w", err)
+	}
+	return nil
+}
+
+// snapshotFormats are the image file extensions Snapshot can write.
+var snapshotFormats = map[string]bool{
+	".png":  true,
+	".jpg":  true,
+	".jpeg": true,
+	".bmp":  true,
+}
+
+// Snapshot captures a single frame and writes it to path, honoring the
+// mirror setting. The image format follows the file extension (.png, .jpg,
+// .jpeg or .bmp).
+func (c *OpenCVCamera) Snapshot(path string) error {
+	ext := strings.ToLower(filepath.Ext(path))
+	if !snapshotFormats[ext] {
+		return fmt.Errorf("unsupported snapshot format %q", ext)
+	}
+
+	mat, err := c.ReadMat()
+	if err != nil {
+		return fmt.Errorf("capturing snapshot: %w", err)
+	}
+	defer mat.Close()
+
+	// ReadMat returns BGR, which is what IMWrite expects
+	if !gocv.IMWrite(path, mat) {
+		return fmt.Errorf("writing snapshot to %s", path)
+	}
+	return nil
+}
+
+// CaptureSnapshot opens camera deviceID with the default backend, writes a
+// single frame to path (see OpenCVCamera.Snapshot) and closes the camera.
+func CaptureSnapshot(deviceID int, path string) error {
+	camera := NewOpenCVCamera(false)
+	if err := camera.Open(deviceID, 0, 0, 0); err != nil {
+		return err
+	}
+	defer camera.Close()
+
+	return camera.Snapshot(path)
+}
+
 // Close releases camera resources.
 func (c *OpenCVCamera) Close() error {
 	c.mu.Lock()
@@ -192,6 +580,9 @@ func (c *OpenCVCamera) Close() error {
 		return nil
 	}
 
+	c.frame.Close()
+	c.buf = nil
+
 	if c.webcam != nil {
 		if err := c.webcam.Close(); err != nil {
 			c.opened = false
@@ -233,10 +624,18 @@ func (c *OpenCVCamera) GetActualFPS() int {
 	return c.fps
 }
 
-// EnumerateCameras attempts to detect available camera devices.
+// Backend returns the capture backend used by Open.
+func (c *OpenCVCamera) Backend() CameraBackend {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.backend
+}
+
+// EnumerateCameras attempts to detect available camera devices using the
+// given capture backend (see DefaultCameraBackend).
 // Returns a list of device IDs that can be opened.
 // This is a best-effort function and may not work on all systems.
-func EnumerateCameras(maxDevices int) []int {
+func EnumerateCameras(maxDevices int, backend CameraBackend) []int {
 	var devices []int
 
 	if maxDevices <= 0 {
@@ -244,8 +643,7 @@ func EnumerateCameras(maxDevices int) []int {
 	}
 
 	for i := 0; i < maxDevices; i++ {
-		// Use V4L2 backend for consistency with Open()
-		cam, err := gocv.OpenVideoCaptureWithAPI(i, gocv.VideoCaptureV4L2)
+		cam, err := gocv.OpenVideoCaptureWithAPI(i, gocv.VideoCaptureAPI(backend))
 		if err != nil {
 			continue
 		}
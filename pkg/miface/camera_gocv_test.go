@@ -4,8 +4,17 @@
 package miface
 
 import (
+	"bytes"
+	"errors"
+	"image/png"
+	"log/slog"
+	"os"
+	"path/filepath"
 	"testing"
 	"time"
+
+	"github.com/MiFaceDEV/miface/internal/config"
+	"gocv.io/x/gocv"
 )
 
 func TestOpenCVCamera_Open(t *testing.T) {
@@ -142,13 +151,287 @@ func TestOpenCVCamera_Close(t *testing.T) {
 	}
 }
 
+func TestOpenCVCamera_ConvertFrameReusesBuffer(t *testing.T) {
+	camera := NewOpenCVCamera(true)
+	camera.frame = gocv.NewMatWithSize(480, 640, gocv.MatTypeCV8UC3)
+	defer camera.frame.Close()
+
+	dst := make([]byte, 0, 640*480*3)
+	out, width, height, err := camera.convertFrame(dst)
+	if err != nil {
+		t.Fatalf("convertFrame failed: %v", err)
+	}
+	if width != 640 || height != 480 {
+		t.Errorf("expected 640x480, got %dx%d", width, height)
+	}
+	if len(out) != 640*480*3 {
+		t.Fatalf("expected %d bytes, got %d", 640*480*3, len(out))
+	}
+	if &out[0] != &dst[:1][0] {
+		t.Error("expected output to reuse the provided buffer")
+	}
+
+	// A buffer that is too small is replaced
+	small := make([]byte, 16)
+	out, _, _, err = camera.convertFrame(small)
+	if err != nil {
+		t.Fatalf("convertFrame failed: %v", err)
+	}
+	if len(out) != 640*480*3 {
+		t.Errorf("expected grown buffer of %d bytes, got %d", 640*480*3, len(out))
+	}
+}
+
+func TestOpenCVCamera_ConvertFrameInPlaceMatchesCvtColor(t *testing.T) {
+	const width, height = 4, 2
+	bgr := make([]byte, width*height*3)
+	for i := range bgr {
+		bgr[i] = byte(i * 7)
+	}
+
+	// Previous path: convert into a separate Mat, then copy out
+	src, err := gocv.NewMatFromBytes(height, width, gocv.MatTypeCV8UC3, append([]byte(nil), bgr...))
+	if err != nil {
+		t.Fatalf("NewMatFromBytes failed: %v", err)
+	}
+	defer src.Close()
+	rgbMat := gocv.NewMat()
+	defer rgbMat.Close()
+	gocv.CvtColor(src, &rgbMat, gocv.ColorBGRToRGB) //nolint:errcheck
+	want := rgbMat.ToBytes()
+
+	// In-place path
+	camera := NewOpenCVCamera(false)
+	camera.frame, err = gocv.NewMatFromBytes(height, width, gocv.MatTypeCV8UC3, append([]byte(nil), bgr...))
+	if err != nil {
+		t.Fatalf("NewMatFromBytes failed: %v", err)
+	}
+	defer camera.frame.Close()
+	got, w, h, err := camera.convertFrame(nil)
+	if err != nil {
+		t.Fatalf("convertFrame failed: %v", err)
+	}
+
+	if w != width || h != height || len(got) != width*height*3 {
+		t.Fatalf("expected %dx%d with %d bytes, got %dx%d with %d bytes", width, height, width*height*3, w, h, len(got))
+	}
+	for i := 0; i < 4*3; i++ {
+		if got[i] != want[i] {
+			t.Errorf("byte %d: expected %d, got %d", i, want[i], got[i])
+		}
+	}
+	if got[0] != bgr[2] || got[2] != bgr[0] {
+		t.Errorf("expected first pixel channels swapped, got %v from %v", got[:3], bgr[:3])
+	}
+}
+
+func TestOpenCVCamera_ConvertFrameTypes(t *testing.T) {
+	const width, height = 4, 2
+	tests := []struct {
+		name    string
+		matType gocv.MatType
+		pixel   []byte // One source pixel, repeated over the frame
+		want    []byte // The first RGB pixel
+	}{
+		{"BGR", gocv.MatTypeCV8UC3, []byte{10, 20, 30}, []byte{30, 20, 10}},
+		{"BGRA", gocv.MatTypeCV8UC4, []byte{10, 20, 30, 255}, []byte{30, 20, 10}},
+		{"grayscale", gocv.MatTypeCV8UC1, []byte{77}, []byte{77, 77, 77}},
+		{"16-bit grayscale", gocv.MatTypeCV16UC1, []byte{0x00, 0x80}, []byte{128, 128, 128}}, // Little-endian 0x8000
+		{"YUYV", gocv.MatTypeCV8UC2, []byte{90, 128}, nil},                                   // Neutral chroma: gray, exact value depends on OpenCV
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data := bytes.Repeat(tt.pixel, width*height)
+			camera := NewOpenCVCamera(true)
+			var err error
+			camera.frame, err = gocv.NewMatFromBytes(height, width, tt.matType, data)
+			if err != nil {
+				t.Fatalf("NewMatFromBytes failed: %v", err)
+			}
+			defer camera.frame.Close()
+
+			got, w, h, err := camera.convertFrame(nil)
+			if err != nil {
+				t.Fatalf("convertFrame failed: %v", err)
+			}
+			if w != width || h != height || len(got) != width*height*3 {
+				t.Fatalf("expected %dx%d RGB24 (%d bytes), got %dx%d with %d bytes",
+					width, height, width*height*3, w, h, len(got))
+			}
+			if tt.want != nil && !bytes.Equal(got[:3], tt.want) {
+				t.Errorf("expected first pixel %v, got %v", tt.want, got[:3])
+			}
+			if tt.want == nil && (got[0] != got[1] || got[1] != got[2]) {
+				t.Errorf("expected a gray pixel, got %v", got[:3])
+			}
+		})
+	}
+}
+
+func TestOpenCVCamera_ConvertFrameUnsupportedType(t *testing.T) {
+	camera := NewOpenCVCamera(false)
+	camera.frame = gocv.NewMatWithSize(2, 4, gocv.MatTypeCV16UC3)
+	defer camera.frame.Close()
+
+	if _, _, _, err := camera.convertFrame(nil); !errors.Is(err, ErrUnsupportedFrameType) {
+		t.Errorf("expected ErrUnsupportedFrameType, got %v", err)
+	}
+}
+
 func TestEnumerateCameras(t *testing.T) {
-	devices := EnumerateCameras(5)
+	devices := EnumerateCameras(5, DefaultCameraBackend())
 
 	// We can't guarantee any cameras exist, but the function should not panic
 	t.Logf("Found %d camera device(s): %v", len(devices), devices)
 }
 
+func TestNewOpenCVCameraWithBackend(t *testing.T) {
+	if got := NewOpenCVCamera(false).Backend(); got != DefaultCameraBackend() {
+		t.Errorf("expected default backend %v, got %v", DefaultCameraBackend(), got)
+	}
+	if got := NewOpenCVCameraWithBackend(false, CameraBackendAny).Backend(); got != CameraBackendAny {
+		t.Errorf("expected backend %v, got %v", CameraBackendAny, got)
+	}
+}
+
+// warmupCapture is a videoCapture that fails a number of reads, then
+// returns black frames, then returns a lit frame.
+type warmupCapture struct {
+	failures int
+	blacks   int
+	reads    int
+}
+
+func (w *warmupCapture) IsOpened() bool                                  { return true }
+func (w *warmupCapture) Set(prop gocv.VideoCaptureProperties, v float64) {}
+func (w *warmupCapture) Get(prop gocv.VideoCaptureProperties) float64    { return 0 }
+func (w *warmupCapture) Close() error                                    { return nil }
+
+func (w *warmupCapture) Read(m *gocv.Mat) bool {
+	w.reads++
+	if w.reads <= w.failures {
+		return false
+	}
+	frame := gocv.NewMatWithSize(4, 4, gocv.MatTypeCV8UC3)
+	defer frame.Close()
+	if w.reads > w.failures+w.blacks {
+		pixels, _ := frame.DataPtrUint8()
+		for i := range pixels {
+			pixels[i] = 128
+		}
+	}
+	frame.CopyTo(m)
+	return true
+}
+
+func openWarmupCamera(t *testing.T, capture *warmupCapture, frames int, timeout time.Duration) *OpenCVCamera {
+	t.Helper()
+	camera := NewOpenCVCamera(false)
+	camera.openCapture = func(int, CameraBackend) (videoCapture, error) {
+		return capture, nil
+	}
+	camera.SetWarmup(frames, timeout)
+	if err := camera.Open(0, 4, 4, 30); err != nil {
+		t.Fatalf("Open failed: %v", err)
+	}
+	t.Cleanup(func() { camera.Close() })
+	return camera
+}
+
+func TestOpenCVCamera_WarmupWaitsForUsableFrame(t *testing.T) {
+	capture := &warmupCapture{failures: 2, blacks: 3}
+	openWarmupCamera(t, capture, 30, time.Second)
+
+	// 2 failed reads, 3 black frames, then the lit frame
+	if capture.reads != 6 {
+		t.Errorf("expected 6 warm-up reads, got %d", capture.reads)
+	}
+}
+
+func TestOpenCVCamera_WarmupFrameLimit(t *testing.T) {
+	capture := &warmupCapture{blacks: 100}
+	openWarmupCamera(t, capture, 5, time.Second)
+
+	if capture.reads != 5 {
+		t.Errorf("expected 5 warm-up reads, got %d", capture.reads)
+	}
+}
+
+func TestOpenCVCamera_WarmupTimeout(t *testing.T) {
+	capture := &warmupCapture{failures: 1000}
+
+	start := time.Now()
+	openWarmupCamera(t, capture, 1000, 50*time.Millisecond)
+	elapsed := time.Since(start)
+
+	if elapsed > time.Second {
+		t.Errorf("expected Open to give up after the warm-up timeout, took %v", elapsed)
+	}
+	if capture.reads >= 1000 {
+		t.Errorf("expected the timeout to stop warm-up early, got %d reads", capture.reads)
+	}
+}
+
+func TestOpenCVCamera_WarmupDisabled(t *testing.T) {
+	capture := &warmupCapture{blacks: 100}
+	openWarmupCamera(t, capture, 0, time.Second)
+
+	if capture.reads != 0 {
+		t.Errorf("expected no warm-up reads, got %d", capture.reads)
+	}
+}
+
+func TestOpenCVCamera_Snapshot(t *testing.T) {
+	capture := &warmupCapture{}
+	camera := openWarmupCamera(t, capture, 0, 0)
+
+	path := filepath.Join(t.TempDir(), "snapshot.png")
+	if err := camera.Snapshot(path); err != nil {
+		t.Fatalf("Snapshot failed: %v", err)
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatalf("opening snapshot: %v", err)
+	}
+	defer f.Close()
+
+	img, err := png.Decode(f)
+	if err != nil {
+		t.Fatalf("expected a valid PNG, got: %v", err)
+	}
+	if b := img.Bounds(); b.Dx() != 4 || b.Dy() != 4 {
+		t.Errorf("expected 4x4 image, got %dx%d", b.Dx(), b.Dy())
+	}
+}
+
+func TestOpenCVCamera_SnapshotErrors(t *testing.T) {
+	dir := t.TempDir()
+
+	camera := NewOpenCVCamera(false)
+	if err := camera.Snapshot(filepath.Join(dir, "closed.png")); err == nil {
+		t.Error("expected error when camera not opened")
+	}
+
+	opened := openWarmupCamera(t, &warmupCapture{}, 0, 0)
+	for _, name := range []string{"snapshot.gif", "snapshot"} {
+		if err := opened.Snapshot(filepath.Join(dir, name)); err == nil {
+			t.Errorf("%s: expected unsupported format error", name)
+		}
+	}
+}
+
+func TestCaptureSnapshot_InvalidDevice(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "snapshot.png")
+	if err := CaptureSnapshot(999, path); err == nil {
+		t.Error("expected error for invalid device")
+	}
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Error("expected no snapshot to be written")
+	}
+}
+
 // Benchmark camera read performance
 func BenchmarkOpenCVCamera_Read(b *testing.B) {
 	camera := NewOpenCVCamera(false)
@@ -171,3 +454,361 @@ func BenchmarkOpenCVCamera_Read(b *testing.B) {
 		}
 	}
 }
+
+// benchmarkFrame returns a synthetic 1080p BGR frame.
+func benchmarkFrame() gocv.Mat {
+	return gocv.NewMatWithSize(1080, 1920, gocv.MatTypeCV8UC3)
+}
+
+// BenchmarkConvertFrame_Allocating measures the previous per-frame path:
+// fresh Mats and a new byte slice on every read.
+func BenchmarkConvertFrame_Allocating(b *testing.B) {
+	src := benchmarkFrame()
+	defer src.Close()
+
+	b.SetBytes(1920 * 1080 * 3)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		mat := src.Clone()
+		gocv.Flip(mat, &mat, 1) //nolint:errcheck
+		rgbMat := gocv.NewMat()
+		gocv.CvtColor(mat, &rgbMat, gocv.ColorBGRToRGB) //nolint:errcheck
+		_ = rgbMat.ToBytes()
+		rgbMat.Close()
+		mat.Close()
+	}
+}
+
+// BenchmarkConvertFrame_SeparateMat measures converting into a second
+// reused Mat before copying out.
+func BenchmarkConvertFrame_SeparateMat(b *testing.B) {
+	src := benchmarkFrame()
+	defer src.Close()
+
+	frame := gocv.NewMat()
+	rgbMat := gocv.NewMat()
+	defer frame.Close()
+	defer rgbMat.Close()
+
+	buf := make([]byte, 1920*1080*3)
+	b.SetBytes(int64(len(buf)))
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		src.CopyTo(&frame)
+		gocv.Flip(frame, &frame, 1)                       //nolint:errcheck
+		gocv.CvtColor(frame, &rgbMat, gocv.ColorBGRToRGB) //nolint:errcheck
+		pixels, _ := rgbMat.DataPtrUint8()
+		copy(buf, pixels)
+	}
+}
+
+// BenchmarkConvertFrame_Reuse measures the in-place path used by Read and ReadInto.
+func BenchmarkConvertFrame_Reuse(b *testing.B) {
+	src := benchmarkFrame()
+	defer src.Close()
+
+	camera := NewOpenCVCamera(true)
+	camera.frame = gocv.NewMat()
+	defer camera.frame.Close()
+
+	var buf []byte
+	b.SetBytes(1920 * 1080 * 3)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		src.CopyTo(&camera.frame)
+		var err error
+		buf, _, _, err = camera.convertFrame(buf)
+		if err != nil {
+			b.Fatalf("convertFrame failed: %v", err)
+		}
+	}
+}
+
+// BenchmarkOpenCVCamera_ReadInto measures camera reads into a reused buffer.
+func BenchmarkOpenCVCamera_ReadInto(b *testing.B) {
+	camera := NewOpenCVCamera(false)
+
+	err := camera.Open(0, 640, 480, 30)
+	if err != nil {
+		b.Skipf("Skipping benchmark: no camera available: %v", err)
+	}
+	defer camera.Close()
+
+	buf, _, _, _ := camera.ReadInto(nil)
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		buf, _, _, err = camera.ReadInto(buf)
+		if err != nil {
+			b.Fatalf("ReadInto failed: %v", err)
+		}
+	}
+}
+
+// closedCapture is a videoCapture whose device failed to open.
+type closedCapture struct {
+	warmupCapture
+	closed bool
+}
+
+func (c *closedCapture) IsOpened() bool { return false }
+func (c *closedCapture) Close() error   { c.closed = true; return nil }
+
+func TestOpenCVCamera_OpenNotFound(t *testing.T) {
+	capture := &closedCapture{}
+	camera := NewOpenCVCamera(false)
+	camera.openCapture = func(int, CameraBackend) (videoCapture, error) {
+		return capture, nil
+	}
+
+	err := camera.Open(3, 640, 480, 30)
+	if !errors.Is(err, ErrCameraNotFound) {
+		t.Errorf("expected ErrCameraNotFound, got %v", err)
+	}
+	if errors.Is(err, ErrCameraBackendUnsupported) {
+		t.Errorf("expected a missing device not to be a backend error, got %v", err)
+	}
+	if !capture.closed {
+		t.Error("expected the unopened capture to be closed")
+	}
+	if camera.opened {
+		t.Error("expected camera to stay closed")
+	}
+}
+
+func TestOpenCVCamera_OpenErrorClassification(t *testing.T) {
+	openErr := errors.New("open failed")
+	available := []gocv.VideoCaptureAPI{gocv.VideoCaptureAPI(CameraBackendV4L2)}
+
+	tests := []struct {
+		backend CameraBackend
+		want    error
+	}{
+		{CameraBackendV4L2, ErrCameraNotFound},
+		{CameraBackendAny, ErrCameraNotFound},
+		{CameraBackendDShow, ErrCameraBackendUnsupported},
+	}
+	for _, tt := range tests {
+		camera := NewOpenCVCameraWithBackend(false, tt.backend)
+		camera.openCapture = func(deviceID int, backend CameraBackend) (videoCapture, error) {
+			return nil, captureOpenError(openErr, backend, available)
+		}
+
+		err := camera.Open(0, 640, 480, 30)
+		if !errors.Is(err, tt.want) {
+			t.Errorf("%s: expected %v, got %v", tt.backend, tt.want, err)
+		}
+	}
+}
+
+func TestOpenCVCamera_OpenInvalidDevice(t *testing.T) {
+	camera := NewOpenCVCamera(false)
+	camera.openCapture = func(int, CameraBackend) (videoCapture, error) {
+		t.Fatal("expected an invalid device not to be opened")
+		return nil, nil
+	}
+
+	if err := camera.Open(-1, 640, 480, 30); !errors.Is(err, ErrCameraNotFound) {
+		t.Errorf("expected ErrCameraNotFound, got %v", err)
+	}
+}
+
+// queuedCapture is a videoCapture holding queued frames whose pixels are
+// their sequence number. Reads from the queue are instant; once it is
+// empty, each read waits a frame interval on now for a new frame.
+type queuedCapture struct {
+	warmupCapture
+	queued int
+	seq    byte
+	now    time.Time
+	props  map[gocv.VideoCaptureProperties]float64
+}
+
+func (q *queuedCapture) Set(prop gocv.VideoCaptureProperties, v float64) {
+	if q.props == nil {
+		q.props = map[gocv.VideoCaptureProperties]float64{}
+	}
+	q.props[prop] = v
+}
+
+func (q *queuedCapture) Read(m *gocv.Mat) bool {
+	q.reads++
+	if q.queued > 0 {
+		q.queued--
+	} else {
+		q.now = q.now.Add(33 * time.Millisecond)
+	}
+	q.seq++
+	frame := gocv.NewMatWithSize(2, 2, gocv.MatTypeCV8UC3)
+	defer frame.Close()
+	pixels, _ := frame.DataPtrUint8()
+	for i := range pixels {
+		pixels[i] = q.seq
+	}
+	frame.CopyTo(m)
+	return true
+}
+
+func TestOpenCVCamera_SetBufferSize(t *testing.T) {
+	capture := &queuedCapture{}
+	camera := NewOpenCVCamera(false)
+	camera.openCapture = func(int, CameraBackend) (videoCapture, error) {
+		return capture, nil
+	}
+	camera.SetWarmup(0, 0)
+	if err := camera.SetBufferSize(2); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := camera.Open(0, 2, 2, 30); err != nil {
+		t.Fatalf("Open failed: %v", err)
+	}
+	defer camera.Close()
+
+	if got := capture.props[gocv.VideoCaptureBufferSize]; got != 2 {
+		t.Errorf("expected buffer size 2 on open, got %v", got)
+	}
+	if err := camera.SetBufferSize(1); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := capture.props[gocv.VideoCaptureBufferSize]; got != 1 {
+		t.Errorf("expected buffer size 1 on an open camera, got %v", got)
+	}
+	if err := camera.SetBufferSize(-1); err == nil {
+		t.Error("expected error for a negative buffer size")
+	}
+}
+
+func TestOpenCVCamera_DrainAndRead(t *testing.T) {
+	capture := &queuedCapture{queued: 3}
+	camera := NewOpenCVCamera(false)
+	camera.openCapture = func(int, CameraBackend) (videoCapture, error) {
+		return capture, nil
+	}
+	camera.now = func() time.Time { return capture.now }
+	camera.SetWarmup(0, 0)
+	if err := camera.Open(0, 2, 2, 30); err != nil {
+		t.Fatalf("Open failed: %v", err)
+	}
+	defer camera.Close()
+
+	// The 3 queued frames are read through to the first live one
+	frame, width, height, err := camera.DrainAndRead()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if width != 2 || height != 2 || frame[0] != 4 {
+		t.Errorf("expected the newest frame 4, got frame %d (%dx%d)", frame[0], width, height)
+	}
+	if capture.reads != 4 {
+		t.Errorf("expected 4 reads, got %d", capture.reads)
+	}
+
+	// With nothing queued a single read suffices
+	if frame, _, _, _ = camera.DrainAndRead(); frame[0] != 5 || capture.reads != 5 {
+		t.Errorf("expected frame 5 after 5 reads, got frame %d after %d", frame[0], capture.reads)
+	}
+}
+
+// openQueuedCamera opens an OpenCVCamera on capture, timed by now.
+func openQueuedCamera(t *testing.T, capture *queuedCapture, now func() time.Time) *OpenCVCamera {
+	t.Helper()
+	camera := NewOpenCVCamera(false)
+	camera.openCapture = func(int, CameraBackend) (videoCapture, error) {
+		return capture, nil
+	}
+	camera.now = now
+	camera.SetWarmup(0, 0)
+	if err := camera.Open(0, 2, 2, 30); err != nil {
+		t.Fatalf("Open failed: %v", err)
+	}
+	t.Cleanup(func() { camera.Close() })
+	return camera
+}
+
+func TestOpenCVCamera_ReadTimestamped(t *testing.T) {
+	capture := &queuedCapture{}
+	camera := openQueuedCamera(t, capture, func() time.Time { return capture.now })
+
+	// A read that waits for the camera returns a frame captured just now
+	_, _, _, capturedAt, err := camera.ReadTimestamped()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !capturedAt.Equal(capture.now) {
+		t.Errorf("expected a fresh frame at %v, got %v", capture.now, capturedAt)
+	}
+
+	// Frames queued while processing is behind follow a frame interval apart
+	first := capturedAt
+	capture.queued = 3
+	capture.now = capture.now.Add(100 * time.Millisecond)
+	for i := 1; i <= 3; i++ {
+		if _, _, _, capturedAt, err = camera.ReadTimestamped(); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if want := first.Add(time.Duration(i) * (time.Second / 30)); !capturedAt.Equal(want) {
+			t.Errorf("queued frame %d: expected capture at %v, got %v", i, want, capturedAt)
+		}
+	}
+
+	mat, capturedAt, err := camera.ReadMatTimestamped()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer mat.Close()
+	if !capturedAt.Equal(capture.now) {
+		t.Errorf("expected the Mat path to time a fresh frame at %v, got %v", capture.now, capturedAt)
+	}
+}
+
+func TestTrackerSkipsStaleOpenCVFrames(t *testing.T) {
+	cfg := config.Default()
+	cfg.Tracking.MaxFrameAgeMS = 50
+	tracker, err := NewTracker(cfg)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer tracker.Close()
+
+	clock := NewFakeClock(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC))
+	tracker.SetClock(clock)
+	proc := &frameProcessor{}
+	if err := tracker.SetProcessor(proc); err != nil {
+		t.Fatalf("failed to set processor: %v", err)
+	}
+
+	// Every frame comes from the driver's queue, so none of the reads wait
+	capture := &queuedCapture{queued: 100}
+	camera := openQueuedCamera(t, capture, clock.Now)
+	if err := tracker.SetCameraSource(camera); err != nil {
+		t.Fatalf("failed to set camera: %v", err)
+	}
+
+	tracker.processFrame()
+	if proc.frame[0] != 1 {
+		t.Fatalf("expected the first frame, got %d", proc.frame[0])
+	}
+
+	// Processing fell 100ms behind: frame 2 was captured 67ms ago and is
+	// skipped, frame 3 34ms ago is used
+	clock.Advance(100 * time.Millisecond)
+	tracker.processFrame()
+	if proc.frame[0] != 3 || capture.reads != 3 {
+		t.Errorf("expected frame 3 after 3 reads, got frame %d after %d", proc.frame[0], capture.reads)
+	}
+
+	// The Mat path used with a preview skips stale frames too: 4 and 5
+	clock.Advance(100 * time.Millisecond)
+	mat, err := readFreshMat(camera, 50*time.Millisecond, clock, slog.New(slog.DiscardHandler))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer mat.Close()
+	if pixels, _ := mat.DataPtrUint8(); pixels[0] != 6 || capture.reads != 6 {
+		t.Errorf("expected Mat frame 6 after 6 reads, got %d after %d", pixels[0], capture.reads)
+	}
+}
@@ -0,0 +1,87 @@
+package miface
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// TransformStage is a post-processing step in a ChainProcessor.
+// It receives the tracking data produced by the previous stage and returns
+// the (possibly modified or replaced) data for the next stage.
+type TransformStage interface {
+	Transform(data *TrackingData) (*TrackingData, error)
+}
+
+// TransformFunc adapts an ordinary function to the TransformStage interface.
+type TransformFunc func(data *TrackingData) (*TrackingData, error)
+
+// Transform calls f(data).
+func (f TransformFunc) Transform(data *TrackingData) (*TrackingData, error) {
+	return f(data)
+}
+
+// ChainProcessor composes a frame Processor with an ordered list of
+// TransformStages. The source processor turns a frame into TrackingData,
+// and each stage then transforms the result in turn.
+//
+// ChainProcessor implements Processor, so a chain can be passed to
+// Tracker.SetProcessor like any single processor.
+type ChainProcessor struct {
+	source Processor
+	stages []TransformStage
+}
+
+// NewChainProcessor creates a processor that runs source followed by stages.
+func NewChainProcessor(source Processor, stages ...TransformStage) *ChainProcessor {
+	return &ChainProcessor{
+		source: source,
+		stages: stages,
+	}
+}
+
+// Process runs the source processor on the frame, then applies each stage in order.
+// Processing stops at the first stage that returns an error.
+func (c *ChainProcessor) Process(ctx context.Context, frame []byte, width, height int) (*TrackingData, error) {
+	if c.source == nil {
+		return nil, errors.New("chain has no source processor")
+	}
+
+	data, err := c.source.Process(ctx, frame, width, height)
+	if err != nil {
+		return nil, err
+	}
+
+	for i, stage := range c.stages {
+		data, err = stage.Transform(data)
+		if err != nil {
+			return nil, fmt.Errorf("transform stage %d: %w", i, err)
+		}
+		if data == nil {
+			return nil, fmt.Errorf("transform stage %d returned no data", i)
+		}
+	}
+
+	return data, nil
+}
+
+// Close releases the source processor and any stages that implement io.Closer.
+func (c *ChainProcessor) Close() error {
+	var errs []error
+
+	if c.source != nil {
+		if err := c.source.Close(); err != nil {
+			errs = append(errs, fmt.Errorf("closing source processor: %w", err))
+		}
+	}
+	for i, stage := range c.stages {
+		if closer, ok := stage.(io.Closer); ok {
+			if err := closer.Close(); err != nil {
+				errs = append(errs, fmt.Errorf("closing transform stage %d: %w", i, err))
+			}
+		}
+	}
+
+	return errors.Join(errs...)
+}
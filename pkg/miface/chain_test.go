@@ -0,0 +1,142 @@
+package miface
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+// mockMediaPipeStage produces face landmarks with the lips a fixed distance apart.
+type mockMediaPipeStage struct {
+	lipGap float64
+	closed bool
+}
+
+func (m *mockMediaPipeStage) Process(ctx context.Context, frame []byte, width, height int) (*TrackingData, error) {
+	landmarks := make([]Landmark, 468)
+	landmarks[13] = Landmark{Point: Point3D{Y: 0.5}, Visibility: 1}            // Upper lip
+	landmarks[14] = Landmark{Point: Point3D{Y: 0.5 + m.lipGap}, Visibility: 1} // Lower lip
+	return &TrackingData{
+		Face: &FaceData{
+			Landmarks:    landmarks,
+			HeadRotation: Quaternion{W: 1},
+		},
+	}, nil
+}
+
+func (m *mockMediaPipeStage) Close() error {
+	m.closed = true
+	return nil
+}
+
+// blendshapeStage estimates jawOpen from the vertical lip distance.
+type blendshapeStage struct {
+	closed bool
+}
+
+func (b *blendshapeStage) Transform(data *TrackingData) (*TrackingData, error) {
+	if data.Face == nil {
+		return data, nil
+	}
+	gap := data.Face.Landmarks[14].Point.Y - data.Face.Landmarks[13].Point.Y
+	if data.Face.BlendShapes == nil {
+		data.Face.BlendShapes = make(map[string]float64)
+	}
+	data.Face.BlendShapes["jawOpen"] = gap * 10
+	return data, nil
+}
+
+func (b *blendshapeStage) Close() error {
+	b.closed = true
+	return nil
+}
+
+func TestChainProcessor(t *testing.T) {
+	source := &mockMediaPipeStage{lipGap: 0.05}
+	blend := &blendshapeStage{}
+
+	var order []string
+	chain := NewChainProcessor(source,
+		TransformFunc(func(data *TrackingData) (*TrackingData, error) {
+			order = append(order, "first")
+			return data, nil
+		}),
+		blend,
+		TransformFunc(func(data *TrackingData) (*TrackingData, error) {
+			order = append(order, "last")
+			return data, nil
+		}),
+	)
+
+	data, err := chain.Process(context.Background(), nil, 640, 480)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if data.Face == nil {
+		t.Fatal("expected face data")
+	}
+	if got := data.Face.BlendShapes["jawOpen"]; got < 0.49 || got > 0.51 {
+		t.Errorf("expected jawOpen ~0.5, got %f", got)
+	}
+	if len(order) != 2 || order[0] != "first" || order[1] != "last" {
+		t.Errorf("stages ran in wrong order: %v", order)
+	}
+
+	if err := chain.Close(); err != nil {
+		t.Fatalf("unexpected close error: %v", err)
+	}
+	if !source.closed {
+		t.Error("expected source processor to be closed")
+	}
+	if !blend.closed {
+		t.Error("expected closable stage to be closed")
+	}
+}
+
+func TestChainProcessorStageError(t *testing.T) {
+	stageErr := errors.New("stage failed")
+	chain := NewChainProcessor(&mockMediaPipeStage{},
+		TransformFunc(func(data *TrackingData) (*TrackingData, error) {
+			return nil, stageErr
+		}),
+	)
+
+	_, err := chain.Process(context.Background(), nil, 640, 480)
+	if !errors.Is(err, stageErr) {
+		t.Errorf("expected stage error, got %v", err)
+	}
+}
+
+// failingCloseStage is a pass-through stage whose Close fails.
+type failingCloseStage struct {
+	err error
+}
+
+func (f *failingCloseStage) Transform(data *TrackingData) (*TrackingData, error) {
+	return data, nil
+}
+
+func (f *failingCloseStage) Close() error {
+	return f.err
+}
+
+func TestChainProcessorCloseErrors(t *testing.T) {
+	first := errors.New("first close failed")
+	second := errors.New("second close failed")
+	chain := NewChainProcessor(&mockMediaPipeStage{},
+		&failingCloseStage{err: first},
+		&failingCloseStage{err: second},
+	)
+
+	err := chain.Close()
+	if !errors.Is(err, first) || !errors.Is(err, second) {
+		t.Errorf("expected both close errors, got %v", err)
+	}
+}
+
+func TestChainProcessorNoSource(t *testing.T) {
+	chain := NewChainProcessor(nil)
+	if _, err := chain.Process(context.Background(), nil, 640, 480); err == nil {
+		t.Error("expected error for chain without source")
+	}
+}
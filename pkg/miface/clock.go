@@ -0,0 +1,119 @@
+package miface
+
+import (
+	"sync"
+	"time"
+)
+
+// Clock is the source of time for the tracker: timestamps and frame pacing.
+// The default uses the system clock; FakeClock lets tests step frames
+// deterministically.
+type Clock interface {
+	// Now returns the current time.
+	Now() time.Time
+	// NewTicker returns a ticker that fires every d.
+	NewTicker(d time.Duration) Ticker
+}
+
+// Ticker delivers ticks on a channel, like time.Ticker.
+type Ticker interface {
+	// C returns the channel ticks are delivered on.
+	C() <-chan time.Time
+	// Stop turns off the ticker. No more ticks are sent after it returns.
+	Stop()
+}
+
+// realClock implements Clock with the time package.
+type realClock struct{}
+
+func (realClock) Now() time.Time { return time.Now() }
+
+func (realClock) NewTicker(d time.Duration) Ticker {
+	return realTicker{time.NewTicker(d)}
+}
+
+// realTicker adapts *time.Ticker to Ticker.
+type realTicker struct {
+	t *time.Ticker
+}
+
+func (r realTicker) C() <-chan time.Time { return r.t.C }
+func (r realTicker) Stop()               { r.t.Stop() }
+
+// FakeClock is a Clock whose time only moves when Advance is called.
+// Its tickers deliver each tick synchronously: Advance blocks until every
+// due tick has been received (or the ticker stopped), so a test knows the
+// tick reached the tracker loop before Advance returns.
+type FakeClock struct {
+	mu      sync.Mutex
+	now     time.Time
+	tickers []*fakeTicker
+}
+
+// NewFakeClock creates a fake clock set to now.
+func NewFakeClock(now time.Time) *FakeClock {
+	return &FakeClock{now: now}
+}
+
+// Now returns the fake clock's current time.
+func (f *FakeClock) Now() time.Time {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.now
+}
+
+// NewTicker returns a ticker that fires each time the clock advances past
+// a multiple of d after the current time.
+func (f *FakeClock) NewTicker(d time.Duration) Ticker {
+	if d <= 0 {
+		panic("miface: non-positive interval for FakeClock.NewTicker")
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	t := &fakeTicker{
+		ch:     make(chan time.Time),
+		done:   make(chan struct{}),
+		period: d,
+		next:   f.now.Add(d),
+	}
+	f.tickers = append(f.tickers, t)
+	return t
+}
+
+// Advance moves the clock forward by d and delivers the ticks that fall
+// due, in order. Stopped tickers are skipped. Advance must not be called
+// from several goroutines at once.
+func (f *FakeClock) Advance(d time.Duration) {
+	f.mu.Lock()
+	f.now = f.now.Add(d)
+	now := f.now
+	tickers := append([]*fakeTicker(nil), f.tickers...)
+	f.mu.Unlock()
+
+	for _, t := range tickers {
+		for !t.next.After(now) {
+			select {
+			case t.ch <- t.next:
+			case <-t.done:
+			}
+			t.next = t.next.Add(t.period)
+		}
+	}
+}
+
+// fakeTicker is a Ticker driven by FakeClock.Advance.
+type fakeTicker struct {
+	ch       chan time.Time
+	done     chan struct{}
+	stopOnce sync.Once
+	period   time.Duration
+	next     time.Time // Only accessed by Advance
+}
+
+func (t *fakeTicker) C() <-chan time.Time { return t.ch }
+
+func (t *fakeTicker) Stop() {
+	t.stopOnce.Do(func() { close(t.done) })
+}
@@ -0,0 +1,65 @@
+package miface
+
+import (
+	"testing"
+	"time"
+)
+
+func TestFakeClockAdvance(t *testing.T) {
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	clock := NewFakeClock(start)
+
+	if !clock.Now().Equal(start) {
+		t.Errorf("expected %v, got %v", start, clock.Now())
+	}
+
+	ticker := clock.NewTicker(10 * time.Millisecond)
+	defer ticker.Stop()
+
+	ticks := make(chan time.Time, 10)
+	go func() {
+		for tick := range ticker.C() {
+			ticks <- tick
+		}
+	}()
+
+	// Not yet due
+	clock.Advance(5 * time.Millisecond)
+	// Due once, then twice more in a single step
+	clock.Advance(5 * time.Millisecond)
+	clock.Advance(20 * time.Millisecond)
+
+	want := []time.Duration{10 * time.Millisecond, 20 * time.Millisecond, 30 * time.Millisecond}
+	for _, offset := range want {
+		select {
+		case tick := <-ticks:
+			if !tick.Equal(start.Add(offset)) {
+				t.Errorf("expected tick at %v, got %v", start.Add(offset), tick)
+			}
+		case <-time.After(time.Second):
+			t.Fatalf("expected tick at %v", start.Add(offset))
+		}
+	}
+	if got := clock.Now().Sub(start); got != 30*time.Millisecond {
+		t.Errorf("expected clock to advance 30ms, got %v", got)
+	}
+}
+
+func TestFakeClockStoppedTicker(t *testing.T) {
+	clock := NewFakeClock(time.Now())
+	ticker := clock.NewTicker(time.Millisecond)
+	ticker.Stop()
+	ticker.Stop() // Safe to call twice
+
+	done := make(chan struct{})
+	go func() {
+		clock.Advance(5 * time.Millisecond)
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("expected Advance not to block on a stopped ticker")
+	}
+}
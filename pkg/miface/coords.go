@@ -0,0 +1,294 @@
+package miface
+
+import "math"
+
+// MediaPipe reports landmark X and Y normalized to [0, 1] by the frame width
+// and height, and Z on roughly the same scale as X. Pixel space multiplies
+// X and Z by the frame width and Y by the frame height.
+
+// ToPixel converts the landmark's normalized position to pixel coordinates
+// for a frame of the given size.
+func (l Landmark) ToPixel(width, height int) Point3D {
+	return Point3D{
+		X: l.Point.X * float64(width),
+		Y: l.Point.Y * float64(height),
+		Z: l.Point.Z * float64(width),
+	}
+}
+
+// NormalizeLandmarks converts landmarks from pixel to normalized coordinates.
+// The input slice is not modified.
+func NormalizeLandmarks(landmarks []Landmark, width, height int) []Landmark {
+	return convertLandmarks(landmarks, width, height, false, true)
+}
+
+// DenormalizeLandmarks converts landmarks from normalized to pixel coordinates.
+// The input slice is not modified.
+func DenormalizeLandmarks(landmarks []Landmark, width, height int) []Landmark {
+	return convertLandmarks(landmarks, width, height, false, false)
+}
+
+// NormalizeLandmarksMirrored is like NormalizeLandmarks for pixel coordinates
+// taken from a horizontally flipped image: the result is in the coordinate
+// space of the original, unflipped image.
+func NormalizeLandmarksMirrored(landmarks []Landmark, width, height int) []Landmark {
+	return convertLandmarks(landmarks, width, height, true, true)
+}
+
+// DenormalizeLandmarksMirrored is like DenormalizeLandmarks but produces
+// pixel coordinates in a horizontally flipped image, such as the mirrored
+// camera preview.
+func DenormalizeLandmarksMirrored(landmarks []Landmark, width, height int) []Landmark {
+	return convertLandmarks(landmarks, width, height, true, false)
+}
+
+// convertLandmarks converts between normalized and pixel space, optionally
+// flipping horizontally.
+func convertLandmarks(landmarks []Landmark, width, height int, mirror, normalize bool) []Landmark {
+	if landmarks == nil {
+		return nil
+	}
+	if width <= 0 || height <= 0 {
+		return append([]Landmark(nil), landmarks...)
+	}
+
+	w, h := float64(width), float64(height)
+	result := make([]Landmark, len(landmarks))
+	for i, lm := range landmarks {
+		p := lm.Point
+		if normalize {
+			p = Point3D{X: p.X / w, Y: p.Y / h, Z: p.Z / w}
+			if mirror {
+				p.X = 1 - p.X
+			}
+		} else {
+			if mirror {
+				p.X = 1 - p.X
+			}
+			p = Point3D{X: p.X * w, Y: p.Y * h, Z: p.Z * w}
+		}
+		lm.Point = p
+		result[i] = lm
+	}
+	return result
+}
+
+// ImageToNormalized converts a normalized image position to centered,
+// right-handed axes: X and Y are shifted so the image center is the origin,
+// Y points up instead of down, and Z points toward the camera.
+// The scale is unchanged, so X spans [-0.5, 0.5] across the frame.
+func ImageToNormalized(p Point3D) Point3D {
+	return Point3D{X: p.X - 0.5, Y: 0.5 - p.Y, Z: -p.Z}
+}
+
+// MirrorTrackingData flips tracking data horizontally in place, as if the
+// camera image had been mirrored: landmark and head X coordinates are
+// inverted around 0.5, the left and right hands and pose landmarks are
+// swapped, the head rotation is reflected (yaw and roll change sign), and
+// the left and right blend shapes are swapped (see MirrorBlendShapes).
+func MirrorTrackingData(data *TrackingData) {
+	if data == nil {
+		return
+	}
+
+	if data.Face != nil {
+		mirrorLandmarks(data.Face.Landmarks)
+		data.Face.HeadPosition.X = 1 - data.Face.HeadPosition.X
+		q := data.Face.HeadRotation
+		data.Face.HeadRotation = Quaternion{X: q.X, Y: -q.Y, Z: -q.Z, W: q.W}
+		MirrorBlendShapes(data.Face.BlendShapes)
+	}
+
+	data.LeftHand, data.RightHand = data.RightHand, data.LeftHand
+	for _, hand := range []*HandData{data.LeftHand, data.RightHand} {
+		if hand != nil {
+			mirrorLandmarks(hand.Landmarks)
+			hand.IsLeft = !hand.IsLeft
+		}
+	}
+
+	if data.Pose != nil {
+		mirrorLandmarks(data.Pose.Landmarks)
+		lms := data.Pose.Landmarks
+		for _, pair := range MirroredPoseLandmarkPairs {
+			if pair[1] < len(lms) {
+				lms[pair[0]], lms[pair[1]] = lms[pair[1]], lms[pair[0]]
+			}
+		}
+	}
+}
+
+// MirroredPoseLandmarkPairs lists the pose landmark indices of the left
+// side of the body, each with its right counterpart.
+var MirroredPoseLandmarkPairs = [][2]int{
+	{PoseLeftEyeInner, PoseRightEyeInner},
+	{PoseLeftEye, PoseRightEye},
+	{PoseLeftEyeOuter, PoseRightEyeOuter},
+	{PoseLeftEar, PoseRightEar},
+	{PoseMouthLeft, PoseMouthRight},
+	{PoseLeftShoulder, PoseRightShoulder},
+	{PoseLeftElbow, PoseRightElbow},
+	{PoseLeftWrist, PoseRightWrist},
+	{PoseLeftPinky, PoseRightPinky},
+	{PoseLeftIndex, PoseRightIndex},
+	{PoseLeftThumb, PoseRightThumb},
+	{PoseLeftHip, PoseRightHip},
+	{PoseLeftKnee, PoseRightKnee},
+	{PoseLeftAnkle, PoseRightAnkle},
+	{PoseLeftHeel, PoseRightHeel},
+	{PoseLeftFootIndex, PoseRightFootIndex},
+}
+
+// mirrorLandmarks inverts normalized X coordinates in place.
+func mirrorLandmarks(landmarks []Landmark) {
+	for i := range landmarks {
+		landmarks[i].Point.X = 1 - landmarks[i].Point.X
+	}
+}
+
+// MirroredBlendShapePairs lists the ARKit blend shapes that describe one
+// side of the face or a direction across it, each with its mirror image.
+var MirroredBlendShapePairs = [][2]string{
+	{"browDownLeft", "browDownRight"},
+	{"browOuterUpLeft", "browOuterUpRight"},
+	{"cheekSquintLeft", "cheekSquintRight"},
+	{"eyeBlinkLeft", "eyeBlinkRight"},
+	{"eyeLookDownLeft", "eyeLookDownRight"},
+	{"eyeLookInLeft", "eyeLookInRight"},
+	{"eyeLookOutLeft", "eyeLookOutRight"},
+	{"eyeLookUpLeft", "eyeLookUpRight"},
+	{"eyeSquintLeft", "eyeSquintRight"},
+	{"eyeWideLeft", "eyeWideRight"},
+	{"jawLeft", "jawRight"},
+	{"mouthLeft", "mouthRight"},
+	{"mouthSmileLeft", "mouthSmileRight"},
+	{"mouthFrownLeft", "mouthFrownRight"},
+	{"mouthDimpleLeft", "mouthDimpleRight"},
+	{"mouthStretchLeft", "mouthStretchRight"},
+	{"mouthPressLeft", "mouthPressRight"},
+	{"mouthLowerDownLeft", "mouthLowerDownRight"},
+	{"mouthUpperUpLeft", "mouthUpperUpRight"},
+	{"noseSneerLeft", "noseSneerRight"},
+}
+
+// MirrorBlendShapes swaps the members of every pair in
+// MirroredBlendShapePairs in place, so a face tracked in a mirrored image
+// moves the user's real side. A member missing from shapes leaves its
+// counterpart missing too.
+func MirrorBlendShapes(shapes map[string]float64) {
+	for _, pair := range MirroredBlendShapePairs {
+		swapBlendShapes(shapes, pair[0], pair[1])
+	}
+}
+
+// swapBlendShapes exchanges the weights of blend shapes a and b in place.
+func swapBlendShapes(shapes map[string]float64, a, b string) {
+	va, hasA := shapes[a]
+	vb, hasB := shapes[b]
+	delete(shapes, a)
+	delete(shapes, b)
+	if hasB {
+		shapes[a] = vb
+	}
+	if hasA {
+		shapes[b] = va
+	}
+}
+
+// MirrorHeadYaw corrects the head pose of a face tracked in a mirrored image
+// so the avatar mirrors the user: the yaw of the head rotation changes sign
+// and the left and right blend shapes are swapped, like MirrorTrackingData
+// does for mirror_landmarks. Pitch, roll and the landmarks are unchanged. A
+// nil face is ignored.
+func MirrorHeadYaw(face *FaceData) {
+	if face == nil {
+		return
+	}
+
+	pitch, yaw, roll := face.HeadRotation.ToEuler()
+	face.HeadRotation = QuaternionFromEuler(pitch, -yaw, roll)
+
+	MirrorBlendShapes(face.BlendShapes)
+}
+
+// Centroid returns the unweighted average position of the landmarks at the
+// given indices. Out-of-range indices are skipped; if none remain, the zero
+// point is returned.
+func Centroid(landmarks []Landmark, indices []int) Point3D {
+	var sum Point3D
+	var count int
+	for _, idx := range indices {
+		if idx < 0 || idx >= len(landmarks) {
+			continue
+		}
+		p := landmarks[idx].Point
+		sum.X += p.X
+		sum.Y += p.Y
+		sum.Z += p.Z
+		count++
+	}
+	if count == 0 {
+		return Point3D{}
+	}
+	n := float64(count)
+	return Point3D{X: sum.X / n, Y: sum.Y / n, Z: sum.Z / n}
+}
+
+// VisibleCentroid returns the unweighted average position of the landmarks
+// at the given indices whose Visibility is at least minVisibility, leaving
+// out occluded and off-frame landmarks. Out-of-range indices are skipped;
+// if none remain, it returns the zero point and false.
+func VisibleCentroid(landmarks []Landmark, indices []int, minVisibility float64) (Point3D, bool) {
+	visible := make([]int, 0, len(indices))
+	for _, idx := range indices {
+		if idx >= 0 && idx < len(landmarks) && landmarks[idx].Visibility >= minVisibility {
+			visible = append(visible, idx)
+		}
+	}
+	if len(visible) == 0 {
+		return Point3D{}, false
+	}
+	return Centroid(landmarks, visible), true
+}
+
+// CentroidWeighted returns the average position of the landmarks at the
+// given indices, weighted by their Visibility. If every selected landmark
+// has zero visibility, it falls back to Centroid.
+func CentroidWeighted(landmarks []Landmark, indices []int) Point3D {
+	var sum Point3D
+	var total float64
+	for _, idx := range indices {
+		if idx < 0 || idx >= len(landmarks) {
+			continue
+		}
+		lm := landmarks[idx]
+		w := math.Max(lm.Visibility, 0)
+		sum.X += lm.Point.X * w
+		sum.Y += lm.Point.Y * w
+		sum.Z += lm.Point.Z * w
+		total += w
+	}
+	if total == 0 {
+		return Centroid(landmarks, indices)
+	}
+	return Point3D{X: sum.X / total, Y: sum.Y / total, Z: sum.Z / total}
+}
+
+// HandConfidence estimates how confidently a hand is detected from its
+// landmarks: the mean over the landmarks of the higher of their Presence
+// and Visibility. A hand whose landmarks report neither score is assumed
+// to be detected, with confidence 1; a hand without landmarks has 0.
+func HandConfidence(landmarks []Landmark) float64 {
+	if len(landmarks) == 0 {
+		return 0
+	}
+
+	var sum float64
+	for _, lm := range landmarks {
+		sum += clamp01(math.Max(lm.Presence, lm.Visibility))
+	}
+	if sum == 0 {
+		return 1
+	}
+	return sum / float64(len(landmarks))
+}
@@ -0,0 +1,310 @@
+package miface
+
+import (
+	"math"
+	"testing"
+)
+
+func pointsClose(a, b Point3D) bool {
+	const eps = 1e-9
+	return math.Abs(a.X-b.X) < eps && math.Abs(a.Y-b.Y) < eps && math.Abs(a.Z-b.Z) < eps
+}
+
+func TestLandmarkToPixel(t *testing.T) {
+	lm := Landmark{Point: Point3D{X: 0.25, Y: 0.5, Z: -0.1}}
+	got := lm.ToPixel(640, 480)
+	want := Point3D{X: 160, Y: 240, Z: -64}
+	if !pointsClose(got, want) {
+		t.Errorf("expected %+v, got %+v", want, got)
+	}
+}
+
+func TestImageToNormalized(t *testing.T) {
+	tests := []struct {
+		in, want Point3D
+	}{
+		{Point3D{X: 0.5, Y: 0.5}, Point3D{}},
+		{Point3D{X: 0, Y: 0, Z: 0.2}, Point3D{X: -0.5, Y: 0.5, Z: -0.2}},
+		{Point3D{X: 1, Y: 1}, Point3D{X: 0.5, Y: -0.5}},
+	}
+	for _, tt := range tests {
+		if got := ImageToNormalized(tt.in); !pointsClose(got, tt.want) {
+			t.Errorf("ImageToNormalized(%+v): expected %+v, got %+v", tt.in, tt.want, got)
+		}
+	}
+}
+
+func TestNormalizeRoundTrip(t *testing.T) {
+	original := []Landmark{
+		{Point: Point3D{X: 0.1, Y: 0.2, Z: -0.05}, Visibility: 0.9, Presence: 0.8},
+		{Point: Point3D{X: 0.75, Y: 0.6, Z: 0.02}, Visibility: 0.5, Presence: 0.4},
+	}
+
+	resolutions := []struct {
+		width, height int
+	}{
+		{640, 480},
+		{1920, 1080},
+	}
+
+	for _, res := range resolutions {
+		pixels := DenormalizeLandmarks(original, res.width, res.height)
+		if !pointsClose(pixels[1].Point, original[1].ToPixel(res.width, res.height)) {
+			t.Errorf("%dx%d: expected DenormalizeLandmarks to match ToPixel, got %+v", res.width, res.height, pixels[1].Point)
+		}
+
+		back := NormalizeLandmarks(pixels, res.width, res.height)
+		mirrored := NormalizeLandmarksMirrored(DenormalizeLandmarksMirrored(original, res.width, res.height), res.width, res.height)
+
+		for i := range original {
+			if !pointsClose(back[i].Point, original[i].Point) {
+				t.Errorf("%dx%d: landmark %d: expected %+v, got %+v", res.width, res.height, i, original[i].Point, back[i].Point)
+			}
+			if !pointsClose(mirrored[i].Point, original[i].Point) {
+				t.Errorf("%dx%d: mirrored landmark %d: expected %+v, got %+v", res.width, res.height, i, original[i].Point, mirrored[i].Point)
+			}
+			if back[i].Visibility != original[i].Visibility || back[i].Presence != original[i].Presence {
+				t.Errorf("%dx%d: landmark %d: scores not preserved", res.width, res.height, i)
+			}
+		}
+	}
+}
+
+func TestDenormalizeLandmarksMirrored(t *testing.T) {
+	lms := []Landmark{{Point: Point3D{X: 0.25, Y: 0.5}}}
+	got := DenormalizeLandmarksMirrored(lms, 640, 480)
+	if !pointsClose(got[0].Point, Point3D{X: 480, Y: 240}) {
+		t.Errorf("expected mirrored pixel (480, 240), got %+v", got[0].Point)
+	}
+	if lms[0].Point.X != 0.25 {
+		t.Error("expected input to be left unmodified")
+	}
+}
+
+func TestMirrorTrackingData(t *testing.T) {
+	data := &TrackingData{
+		Face: &FaceData{
+			Landmarks:    []Landmark{{Point: Point3D{X: 0.3, Y: 0.4}}},
+			HeadRotation: Quaternion{X: 0.1, Y: 0.2, Z: 0.05, W: 0.97},
+			HeadPosition: Point3D{X: 0.45, Y: 0.4},
+		},
+		LeftHand: &HandData{
+			Landmarks: []Landmark{{Point: Point3D{X: 0.2, Y: 0.7}}},
+			IsLeft:    true,
+		},
+		Pose: &PoseData{
+			Landmarks: []Landmark{{Point: Point3D{X: 0.9, Y: 0.1}}},
+		},
+	}
+
+	MirrorTrackingData(data)
+
+	if data.LeftHand != nil {
+		t.Error("expected left hand to move to the right")
+	}
+	if data.RightHand == nil {
+		t.Fatal("expected right hand after mirroring")
+	}
+	if data.RightHand.IsLeft {
+		t.Error("expected swapped hand to be marked right")
+	}
+	if got := data.RightHand.Landmarks[0].Point; !pointsClose(got, Point3D{X: 0.8, Y: 0.7}) {
+		t.Errorf("expected hand point (0.8, 0.7), got %+v", got)
+	}
+	if got := data.Face.Landmarks[0].Point; !pointsClose(got, Point3D{X: 0.7, Y: 0.4}) {
+		t.Errorf("expected face point (0.7, 0.4), got %+v", got)
+	}
+	if got := data.Pose.Landmarks[0].Point.X; math.Abs(got-0.1) > 1e-9 {
+		t.Errorf("expected pose X 0.1, got %f", got)
+	}
+	if got := data.Face.HeadPosition.X; math.Abs(got-0.55) > 1e-9 {
+		t.Errorf("expected head X 0.55, got %f", got)
+	}
+	want := Quaternion{X: 0.1, Y: -0.2, Z: -0.05, W: 0.97}
+	if data.Face.HeadRotation != want {
+		t.Errorf("expected head rotation %+v, got %+v", want, data.Face.HeadRotation)
+	}
+}
+
+func TestMirrorTrackingDataPoseSides(t *testing.T) {
+	pose := testPose()
+	pose.Landmarks[PoseLeftWrist].Point = Point3D{X: 0.8, Y: 0.5}
+	pose.Landmarks[PoseRightWrist].Point = Point3D{X: 0.3, Y: 0.6}
+	pose.Landmarks[PoseLeftHip].Visibility = 0.2
+	pose.Landmarks[PoseNose].Point = Point3D{X: 0.4}
+
+	MirrorTrackingData(&TrackingData{Pose: pose})
+
+	// The landmarks on the right of the mirrored image are the right side's
+	if got := pose.Landmarks[PoseRightWrist].Point; !pointsClose(got, Point3D{X: 0.2, Y: 0.5}) {
+		t.Errorf("expected right wrist (0.2, 0.5), got %+v", got)
+	}
+	if got := pose.Landmarks[PoseLeftWrist].Point; !pointsClose(got, Point3D{X: 0.7, Y: 0.6}) {
+		t.Errorf("expected left wrist (0.7, 0.6), got %+v", got)
+	}
+	if got := pose.Landmarks[PoseRightHip].Visibility; got != 0.2 {
+		t.Errorf("expected the visibility to move with the hip, got %f", got)
+	}
+	if got := pose.Landmarks[PoseNose].Point.X; math.Abs(got-0.6) > 1e-9 {
+		t.Errorf("expected nose X 0.6, got %f", got)
+	}
+}
+
+func TestMirrorBlendShapes(t *testing.T) {
+	shapes := map[string]float64{
+		"eyeBlinkLeft":    0.9,
+		"eyeBlinkRight":   0.1,
+		"mouthSmileRight": 0.7,
+		"jawLeft":         0.2,
+		"jawOpen":         0.5,
+	}
+
+	MirrorBlendShapes(shapes)
+
+	want := map[string]float64{
+		"eyeBlinkLeft":   0.1,
+		"eyeBlinkRight":  0.9,
+		"mouthSmileLeft": 0.7,
+		"jawRight":       0.2,
+		"jawOpen":        0.5,
+	}
+	if len(shapes) != len(want) {
+		t.Errorf("expected blend shapes %v, got %v", want, shapes)
+	}
+	for name, value := range want {
+		if got, ok := shapes[name]; !ok || got != value {
+			t.Errorf("expected %s %f, got %f (present=%v)", name, value, got, ok)
+		}
+	}
+
+	for _, pair := range MirroredBlendShapePairs {
+		for _, name := range pair {
+			if !isARKitBlendShape(name) {
+				t.Errorf("expected %s to be an ARKit blend shape", name)
+			}
+		}
+	}
+}
+
+// isARKitBlendShape reports whether name is one of the 52 ARKit names.
+func isARKitBlendShape(name string) bool {
+	for _, n := range arkitBlendShapes {
+		if n == name {
+			return true
+		}
+	}
+	return false
+}
+
+func TestMirrorHeadYaw(t *testing.T) {
+	face := &FaceData{
+		HeadRotation: QuaternionFromEuler(0.1, 0.4, -0.2),
+		BlendShapes: map[string]float64{
+			"eyeLookInLeft":   0.6,
+			"eyeLookOutLeft":  0.1,
+			"eyeLookUpRight":  0.3,
+			"eyeBlinkLeft":    0.8,
+			"mouthSmileRight": 0.2,
+			"jawOpen":         0.5,
+		},
+	}
+
+	MirrorHeadYaw(face)
+
+	pitch, yaw, roll := face.HeadRotation.ToEuler()
+	if math.Abs(pitch-0.1) > 1e-9 || math.Abs(yaw+0.4) > 1e-9 || math.Abs(roll+0.2) > 1e-9 {
+		t.Errorf("expected pitch 0.1, yaw -0.4, roll -0.2, got %f, %f, %f", pitch, yaw, roll)
+	}
+
+	want := map[string]float64{
+		"eyeLookInRight":  0.6,
+		"eyeLookOutRight": 0.1,
+		"eyeLookUpLeft":   0.3,
+		"eyeBlinkRight":   0.8,
+		"mouthSmileLeft":  0.2,
+		"jawOpen":         0.5,
+	}
+	if len(face.BlendShapes) != len(want) {
+		t.Errorf("expected blend shapes %v, got %v", want, face.BlendShapes)
+	}
+	for name, value := range want {
+		if got, ok := face.BlendShapes[name]; !ok || got != value {
+			t.Errorf("expected %s %f, got %f (present=%v)", name, value, got, ok)
+		}
+	}
+
+	// Nil faces and blend shapes are ignored
+	MirrorHeadYaw(nil)
+	MirrorHeadYaw(&FaceData{HeadRotation: Quaternion{W: 1}})
+}
+
+func TestCentroid(t *testing.T) {
+	lms := []Landmark{
+		{Point: Point3D{X: 0, Y: 0, Z: 0}, Visibility: 1},
+		{Point: Point3D{X: 1, Y: 2, Z: 3}, Visibility: 0},
+		{Point: Point3D{X: 2, Y: 4, Z: 6}, Visibility: 3},
+	}
+
+	tests := []struct {
+		name    string
+		indices []int
+		fn      func([]Landmark, []int) Point3D
+		want    Point3D
+	}{
+		{"unweighted", []int{0, 1, 2}, Centroid, Point3D{X: 1, Y: 2, Z: 3}},
+		{"weighted", []int{0, 1, 2}, CentroidWeighted, Point3D{X: 1.5, Y: 3, Z: 4.5}},
+		{"all zero visibility falls back", []int{1, 1}, CentroidWeighted, Point3D{X: 1, Y: 2, Z: 3}},
+		{"out of range skipped", []int{0, 2, 99, -1}, Centroid, Point3D{X: 1, Y: 2, Z: 3}},
+		{"empty unweighted", nil, Centroid, Point3D{}},
+		{"empty weighted", []int{}, CentroidWeighted, Point3D{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.fn(lms, tt.indices); !pointsClose(got, tt.want) {
+				t.Errorf("expected %+v, got %+v", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestVisibleCentroid(t *testing.T) {
+	lms := []Landmark{
+		{Point: Point3D{X: 0, Y: 0, Z: 0}, Visibility: 0.9},
+		{Point: Point3D{X: 5, Y: 5, Z: 5}, Visibility: 0.1}, // Occluded
+		{Point: Point3D{X: 2, Y: 4, Z: 6}, Visibility: 0.6},
+		{Point: Point3D{X: -3, Y: 1, Z: 0}, Visibility: 0.2}, // Off-frame
+	}
+
+	got, ok := VisibleCentroid(lms, []int{0, 1, 2, 3, 99}, 0.5)
+	if !ok {
+		t.Fatal("expected a centroid of the visible landmarks")
+	}
+	if want := (Point3D{X: 1, Y: 2, Z: 3}); !pointsClose(got, want) {
+		t.Errorf("expected low-visibility landmarks excluded, giving %+v, got %+v", want, got)
+	}
+
+	if _, ok := VisibleCentroid(lms, []int{1, 3}, 0.5); ok {
+		t.Error("expected no centroid when no landmark is visible")
+	}
+}
+
+func TestHandConfidence(t *testing.T) {
+	tests := []struct {
+		name      string
+		landmarks []Landmark
+		want      float64
+	}{
+		{"empty", nil, 0},
+		{"no scores", []Landmark{{}, {}}, 1},
+		{"presence", []Landmark{{Presence: 0.2}, {Presence: 0.4}}, 0.3},
+		{"higher score", []Landmark{{Presence: 0.2, Visibility: 0.6}, {Presence: 0.8}}, 0.7},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := HandConfidence(tt.landmarks); math.Abs(got-tt.want) > 1e-9 {
+				t.Errorf("expected %f, got %f", tt.want, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,169 @@
+package miface
+
+import (
+	"context"
+	"math"
+	"sync"
+)
+
+// DefaultCropPadding is the default margin around the face bounding box,
+// as a fraction of the box size on each side.
+const DefaultCropPadding = 0.5
+
+// cropRect is a pixel-space region of a frame.
+type cropRect struct {
+	x, y          int
+	width, height int
+}
+
+// CropProcessor speeds up face tracking by feeding the wrapped processor only
+// a padded region around the previous frame's face instead of the full frame.
+// Landmarks returned for the crop are remapped into full-frame normalized
+// coordinates. Whenever no face is found, the next frame is processed in
+// full so detection can recover.
+//
+// Hands and pose are only tracked within the face region while cropping, so
+// CropProcessor is best suited to face-only tracking.
+type CropProcessor struct {
+	inner   Processor
+	padding float64
+
+	mu     sync.Mutex
+	roi    *cropRect
+	frameW int // Frame size the ROI was computed for
+	frameH int
+	buf    []byte
+}
+
+// NewCropProcessor wraps inner with face-ROI cropping. padding is the margin
+// added around the face bounding box as a fraction of its size on each side;
+// use DefaultCropPadding if unsure.
+func NewCropProcessor(inner Processor, padding float64) *CropProcessor {
+	return &CropProcessor{
+		inner:   inner,
+		padding: padding,
+	}
+}
+
+// Process runs the wrapped processor on the face region of the frame, or on
+// the full frame when there is no face to follow.
+func (c *CropProcessor) Process(ctx context.Context, frame []byte, width, height int) (*TrackingData, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	roi := c.roi
+	if roi != nil && (width != c.frameW || height != c.frameH || len(frame) < width*height*3) {
+		roi = nil
+	}
+
+	var data *TrackingData
+	var err error
+	if roi != nil {
+		c.buf = cropRGB(c.buf, frame, width, *roi)
+		data, err = c.inner.Process(ctx, c.buf, roi.width, roi.height)
+		if err == nil {
+			roi.remap(data, width, height)
+		}
+	} else {
+		data, err = c.inner.Process(ctx, frame, width, height)
+	}
+	if err != nil {
+		c.roi = nil
+		return nil, err
+	}
+
+	c.roi = nil
+	if data != nil && data.Face != nil {
+		c.roi = faceROI(data.Face.Landmarks, width, height, c.padding)
+		c.frameW, c.frameH = width, height
+	}
+
+	return data, nil
+}
+
+// Close closes the wrapped processor.
+func (c *CropProcessor) Close() error {
+	return c.inner.Close()
+}
+
+// faceROI returns the padded face bounding box in pixels, or nil if there is
+// no usable box or it would cover the whole frame anyway.
+func faceROI(landmarks []Landmark, width, height int, padding float64) *cropRect {
+	if width <= 0 || height <= 0 {
+		return nil
+	}
+	box, ok := LandmarkBounds(landmarks, math.Inf(-1))
+	if !ok {
+		return nil
+	}
+	minX, minY := box.Min.X, box.Min.Y
+	maxX, maxY := box.Max.X, box.Max.Y
+
+	padX := (maxX - minX) * padding
+	padY := (maxY - minY) * padding
+	x0 := max(int(math.Floor((minX-padX)*float64(width))), 0)
+	y0 := max(int(math.Floor((minY-padY)*float64(height))), 0)
+	x1 := min(int(math.Ceil((maxX+padX)*float64(width))), width)
+	y1 := min(int(math.Ceil((maxY+padY)*float64(height))), height)
+
+	if x1 <= x0 || y1 <= y0 {
+		return nil
+	}
+	if x0 == 0 && y0 == 0 && x1 == width && y1 == height {
+		return nil
+	}
+
+	return &cropRect{x: x0, y: y0, width: x1 - x0, height: y1 - y0}
+}
+
+// cropRGB copies the region r of an RGB24 frame into dst, growing it if needed.
+func cropRGB(dst, frame []byte, frameWidth int, r cropRect) []byte {
+	rowBytes := r.width * 3
+	size := rowBytes * r.height
+	if cap(dst) < size {
+		dst = make([]byte, size)
+	}
+	dst = dst[:size]
+
+	for row := 0; row < r.height; row++ {
+		src := ((r.y+row)*frameWidth + r.x) * 3
+		copy(dst[row*rowBytes:(row+1)*rowBytes], frame[src:src+rowBytes])
+	}
+	return dst
+}
+
+// remap converts all landmarks in data from crop-normalized to
+// frame-normalized coordinates.
+func (r cropRect) remap(data *TrackingData, frameWidth, frameHeight int) {
+	if data == nil {
+		return
+	}
+	if data.Face != nil {
+		r.remapLandmarks(data.Face.Landmarks, frameWidth, frameHeight)
+	}
+	if data.LeftHand != nil {
+		r.remapLandmarks(data.LeftHand.Landmarks, frameWidth, frameHeight)
+	}
+	if data.RightHand != nil {
+		r.remapLandmarks(data.RightHand.Landmarks, frameWidth, frameHeight)
+	}
+	if data.Pose != nil {
+		r.remapLandmarks(data.Pose.Landmarks, frameWidth, frameHeight)
+	}
+}
+
+// remapLandmarks converts landmarks in place. Z shares the scale of X, so it
+// is rescaled by the crop width.
+func (r cropRect) remapLandmarks(landmarks []Landmark, frameWidth, frameHeight int) {
+	sx := float64(r.width) / float64(frameWidth)
+	sy := float64(r.height) / float64(frameHeight)
+	ox := float64(r.x) / float64(frameWidth)
+	oy := float64(r.y) / float64(frameHeight)
+
+	for i := range landmarks {
+		p := &landmarks[i].Point
+		p.X = p.X*sx + ox
+		p.Y = p.Y*sy + oy
+		p.Z *= sx
+	}
+}
@@ -0,0 +1,108 @@
+package miface
+
+import (
+	"context"
+	"math"
+	"testing"
+)
+
+// fixedFaceProcessor reports a face with two landmarks at fixed normalized
+// positions and records the size of every frame it receives.
+type fixedFaceProcessor struct {
+	points  []Point3D
+	noFace  bool
+	sizes   [][2]int
+	firstPx []byte
+}
+
+func (p *fixedFaceProcessor) Process(ctx context.Context, frame []byte, width, height int) (*TrackingData, error) {
+	p.sizes = append(p.sizes, [2]int{width, height})
+	p.firstPx = append([]byte(nil), frame[:3]...)
+	if p.noFace {
+		return &TrackingData{}, nil
+	}
+
+	landmarks := make([]Landmark, len(p.points))
+	for i, pt := range p.points {
+		landmarks[i] = Landmark{Point: pt, Visibility: 1}
+	}
+	return &TrackingData{Face: &FaceData{Landmarks: landmarks}}, nil
+}
+
+func (p *fixedFaceProcessor) Close() error {
+	return nil
+}
+
+// gradientFrame returns an RGB24 frame whose pixel (x, y) is (x, y, 0).
+func gradientFrame(width, height int) []byte {
+	frame := make([]byte, width*height*3)
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			i := (y*width + x) * 3
+			frame[i] = byte(x)
+			frame[i+1] = byte(y)
+		}
+	}
+	return frame
+}
+
+func TestCropProcessorRemap(t *testing.T) {
+	const width, height = 200, 100
+	inner := &fixedFaceProcessor{points: []Point3D{{X: 0.4, Y: 0.4}, {X: 0.6, Y: 0.6, Z: 0.1}}}
+	crop := NewCropProcessor(inner, 0.5)
+	frame := gradientFrame(width, height)
+
+	// First frame: no ROI yet, full frame is processed
+	data, err := crop.Process(context.Background(), frame, width, height)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if inner.sizes[0] != [2]int{width, height} {
+		t.Fatalf("expected full frame first, got %v", inner.sizes[0])
+	}
+	if got := data.Face.Landmarks[0].Point.X; got != 0.4 {
+		t.Errorf("expected unmapped X 0.4, got %f", got)
+	}
+
+	// Face box is x 80..120, y 40..60; padded by half its size on each side
+	// gives x 60..140, y 30..70.
+	data, err = crop.Process(context.Background(), frame, width, height)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if inner.sizes[1] != [2]int{80, 40} {
+		t.Fatalf("expected 80x40 crop, got %v", inner.sizes[1])
+	}
+	if inner.firstPx[0] != 60 || inner.firstPx[1] != 30 {
+		t.Errorf("expected crop to start at pixel (60, 30), got (%d, %d)", inner.firstPx[0], inner.firstPx[1])
+	}
+
+	// Crop-normalized (0.4, 0.4) → pixel (60+32, 30+16) → (0.46, 0.46)
+	want := []Point3D{{X: 0.46, Y: 0.46}, {X: 0.54, Y: 0.54, Z: 0.04}}
+	for i, lm := range data.Face.Landmarks {
+		if math.Abs(lm.Point.X-want[i].X) > 1e-9 ||
+			math.Abs(lm.Point.Y-want[i].Y) > 1e-9 ||
+			math.Abs(lm.Point.Z-want[i].Z) > 1e-9 {
+			t.Errorf("landmark %d: expected %+v, got %+v", i, want[i], lm.Point)
+		}
+	}
+}
+
+func TestCropProcessorFallsBackWhenLost(t *testing.T) {
+	const width, height = 200, 100
+	inner := &fixedFaceProcessor{points: []Point3D{{X: 0.4, Y: 0.4}, {X: 0.6, Y: 0.6}}}
+	crop := NewCropProcessor(inner, 0.5)
+	frame := gradientFrame(width, height)
+
+	crop.Process(context.Background(), frame, width, height)
+	inner.noFace = true
+	crop.Process(context.Background(), frame, width, height) // Cropped, face lost
+	crop.Process(context.Background(), frame, width, height) // Full frame again
+
+	if inner.sizes[1] == [2]int{width, height} {
+		t.Error("expected second frame to be cropped")
+	}
+	if inner.sizes[2] != [2]int{width, height} {
+		t.Errorf("expected full frame after losing the face, got %v", inner.sizes[2])
+	}
+}
@@ -0,0 +1,173 @@
+package miface
+
+import (
+	"context"
+	"fmt"
+	"image/color"
+	"math"
+	"time"
+)
+
+// FallbackConfig controls FallbackProcessor.
+type FallbackConfig struct {
+	// EnableFace reports a face mesh and head position at the detected face.
+	EnableFace bool
+	// EnablePose reports an upper-body pose below the detected face.
+	EnablePose bool
+	// SampleStep is the stride in pixels between scanned pixels, in both
+	// directions. Larger steps are faster but locate the face more coarsely.
+	SampleStep int
+	// MinSkinFraction is the fraction of scanned pixels that must be
+	// skin-toned before a face is reported.
+	MinSkinFraction float64
+}
+
+// DefaultFallbackConfig returns a fallback configuration with face and pose
+// enabled, scanning every fourth pixel and requiring 1% skin.
+func DefaultFallbackConfig() FallbackConfig {
+	return FallbackConfig{
+		EnableFace:      true,
+		EnablePose:      true,
+		SampleStep:      4,
+		MinSkinFraction: 0.01,
+	}
+}
+
+// FallbackProcessor implements Processor in pure Go, so the tracking
+// pipeline can be developed and tested on machines without the MediaPipe
+// bridge. It locates the skin-toned pixels in the frame and places a generic
+// face mesh and upper-body pose over them.
+//
+// It is no substitute for MediaPipe:
+//   - The face is found by color alone, so hands, bare arms, warm lighting
+//     or wooden furniture pull it off the head, and several people merge.
+//   - The head rotation is always neutral and no blend shapes are reported,
+//     so head turns, blinks and expressions are not tracked.
+//   - Hands are never reported, and the pose only follows the head.
+//   - The face landmarks are a template like StubProcessor's, not the
+//     MediaPipe face mesh, so landmark-based estimates are meaningless.
+type FallbackProcessor struct {
+	cfg FallbackConfig
+}
+
+// NewFallbackProcessor creates a fallback processor with the given
+// configuration. A SampleStep below 1 uses the default.
+func NewFallbackProcessor(cfg FallbackConfig) *FallbackProcessor {
+	if cfg.SampleStep < 1 {
+		cfg.SampleStep = DefaultFallbackConfig().SampleStep
+	}
+	return &FallbackProcessor{cfg: cfg}
+}
+
+// Process locates the face in an RGB24 frame. If too few pixels are
+// skin-toned, the returned data has no face or pose.
+func (p *FallbackProcessor) Process(ctx context.Context, frame []byte, width, height int) (*TrackingData, error) {
+	if len(frame) < width*height*3 {
+		return nil, fmt.Errorf("frame has %d bytes, want %d for %dx%d RGB24", len(frame), width*height*3, width, height)
+	}
+
+	data := &TrackingData{Timestamp: time.Now()}
+	box, ok := p.skinBounds(frame, width, height)
+	if !ok {
+		return data, nil
+	}
+	if p.cfg.EnableFace {
+		data.Face = fallbackFace(box)
+	}
+	if p.cfg.EnablePose {
+		data.Pose = fallbackPose(box)
+	}
+	return data, nil
+}
+
+// Close is a no-op for the fallback processor.
+func (p *FallbackProcessor) Close() error {
+	return nil
+}
+
+// skinBounds returns the normalized box spanned by the skin-toned pixels,
+// estimated from their mean and spread so stray pixels barely move it.
+func (p *FallbackProcessor) skinBounds(frame []byte, width, height int) (BoundingBox, bool) {
+	step := p.cfg.SampleStep
+	var n, samples int
+	var sumX, sumY, sumXX, sumYY float64
+	for y := 0; y < height; y += step {
+		for x := 0; x < width; x += step {
+			samples++
+			i := (y*width + x) * 3
+			if !isSkin(frame[i], frame[i+1], frame[i+2]) {
+				continue
+			}
+			fx, fy := float64(x), float64(y)
+			n++
+			sumX += fx
+			sumY += fy
+			sumXX += fx * fx
+			sumYY += fy * fy
+		}
+	}
+	if n == 0 || float64(n) < p.cfg.MinSkinFraction*float64(samples) {
+		return BoundingBox{}, false
+	}
+
+	meanX, meanY := sumX/float64(n), sumY/float64(n)
+	// A uniform region of half-width h has a standard deviation of h/√3
+	halfW := math.Sqrt(3*math.Max(sumXX/float64(n)-meanX*meanX, 0)) + float64(step)/2
+	halfH := math.Sqrt(3*math.Max(sumYY/float64(n)-meanY*meanY, 0)) + float64(step)/2
+
+	w, h := float64(width), float64(height)
+	return BoundingBox{
+		Min: Point3D{X: (meanX - halfW) / w, Y: (meanY - halfH) / h},
+		Max: Point3D{X: (meanX + halfW) / w, Y: (meanY + halfH) / h},
+	}, true
+}
+
+// isSkin reports whether an RGB color falls in the usual YCbCr skin range.
+func isSkin(r, g, b uint8) bool {
+	_, cb, cr := color.RGBToYCbCr(r, g, b)
+	return cb >= 77 && cb <= 127 && cr >= 133 && cr <= 173
+}
+
+// Extent of the stub face template, which fallbackFace fits to the box.
+const (
+	stubFaceCenterX = 0.5
+	stubFaceCenterY = 0.4
+	stubFaceHalfW   = 0.12
+	stubFaceHalfH   = 0.16
+)
+
+// fallbackFace fits the stub face template to box, facing the camera with
+// a neutral expression.
+func fallbackFace(box BoundingBox) *FaceData {
+	face := stubFace(0, 0)
+	center := box.Center()
+	sx := (box.Max.X - box.Min.X) / 2 / stubFaceHalfW
+	sy := (box.Max.Y - box.Min.Y) / 2 / stubFaceHalfH
+	for i := range face.Landmarks {
+		p := &face.Landmarks[i].Point
+		p.X = center.X + (p.X-stubFaceCenterX)*sx
+		p.Y = center.Y + (p.Y-stubFaceCenterY)*sy
+		p.Z *= sx
+	}
+	face.BlendShapes = map[string]float64{}
+	face.HeadPosition = Point3D{X: center.X, Y: center.Y}
+	return face
+}
+
+// fallbackPose places the stub rest pose under the face in box, scaled to
+// the face width.
+func fallbackPose(box BoundingBox) *PoseData {
+	pose := stubPose(0)
+	center := box.Center()
+	nose := stubPoseRest[PoseNose]
+	s := (box.Max.X - box.Min.X) / 2 / stubFaceHalfW
+	for i := range pose.Landmarks {
+		p := &pose.Landmarks[i].Point
+		*p = Point3D{
+			X: center.X + (p.X-nose.X)*s,
+			Y: center.Y + (p.Y-nose.Y)*s,
+			Z: p.Z * s,
+		}
+	}
+	return pose
+}
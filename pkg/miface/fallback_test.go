@@ -0,0 +1,137 @@
+package miface
+
+import (
+	"context"
+	"math"
+	"testing"
+)
+
+// skinFrame returns a blue RGB24 frame with a skin-toned rectangle from
+// (x0, y0) up to (x1, y1).
+func skinFrame(width, height, x0, y0, x1, y1 int) []byte {
+	frame := make([]byte, width*height*3)
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			px := frame[(y*width+x)*3:]
+			if x >= x0 && x < x1 && y >= y0 && y < y1 {
+				px[0], px[1], px[2] = 224, 172, 140
+			} else {
+				px[0], px[1], px[2] = 40, 60, 200
+			}
+		}
+	}
+	return frame
+}
+
+func TestFallbackProcessorFindsFace(t *testing.T) {
+	p := NewFallbackProcessor(DefaultFallbackConfig())
+	frame := skinFrame(160, 120, 60, 20, 100, 70)
+
+	data, err := p.Process(context.Background(), frame, 160, 120)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if data.Face == nil || data.Pose == nil {
+		t.Fatalf("expected face and pose, got %+v", data)
+	}
+	if data.LeftHand != nil || data.RightHand != nil {
+		t.Error("expected no hands")
+	}
+
+	head := data.Face.HeadPosition
+	if math.Abs(head.X-0.5) > 0.02 || math.Abs(head.Y-0.375) > 0.02 {
+		t.Errorf("expected head near (0.5, 0.375), got %+v", head)
+	}
+	if len(data.Face.Landmarks) != numFaceLandmarks {
+		t.Errorf("expected %d face landmarks, got %d", numFaceLandmarks, len(data.Face.Landmarks))
+	}
+	box, _ := data.Face.BoundingBox(0)
+	if w := box.Max.X - box.Min.X; math.Abs(w-0.25) > 0.03 {
+		t.Errorf("expected the mesh to span the 0.25 wide face, got %f", w)
+	}
+	if data.Face.HeadRotation != (Quaternion{W: 1}) {
+		t.Errorf("expected a neutral head rotation, got %+v", data.Face.HeadRotation)
+	}
+
+	// The pose hangs below the face
+	nose := data.Pose.Landmarks[PoseNose].Point
+	if !pointsClose(nose, Point3D{X: head.X, Y: head.Y}) {
+		t.Errorf("expected the nose at the head %+v, got %+v", head, nose)
+	}
+	if data.Pose.Landmarks[PoseLeftShoulder].Point.Y <= head.Y {
+		t.Error("expected the shoulders below the head")
+	}
+}
+
+func TestFallbackProcessorNoFace(t *testing.T) {
+	p := NewFallbackProcessor(DefaultFallbackConfig())
+
+	data, err := p.Process(context.Background(), skinFrame(64, 48, 0, 0, 0, 0), 64, 48)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if hasTracking(data) {
+		t.Errorf("expected no tracking without skin, got %+v", data)
+	}
+
+	if _, err := p.Process(context.Background(), make([]byte, 10), 64, 48); err == nil {
+		t.Error("expected error for a short frame")
+	}
+}
+
+func TestFallbackProcessorDisabledModalities(t *testing.T) {
+	cfg := DefaultFallbackConfig()
+	cfg.EnablePose = false
+	p := NewFallbackProcessor(cfg)
+
+	data, err := p.Process(context.Background(), skinFrame(64, 48, 20, 10, 40, 30), 64, 48)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if data.Face == nil || data.Pose != nil {
+		t.Errorf("expected only a face, got face=%v pose=%v", data.Face != nil, data.Pose != nil)
+	}
+}
+
+// skinCamera is a CameraSource returning a frame with a face-sized skin patch.
+type skinCamera struct{}
+
+func (skinCamera) Open(deviceID, width, height, fps int) error { return nil }
+
+func (skinCamera) Read() ([]byte, int, int, error) {
+	return skinFrame(160, 120, 60, 20, 100, 70), 160, 120, nil
+}
+
+func (skinCamera) Close() error { return nil }
+
+func TestTrackerWithFallbackProcessor(t *testing.T) {
+	tracker, err := NewTracker(nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer tracker.Close()
+
+	sender, conn := newTestVMCSender(t)
+	if err := tracker.SetVMCSender(sender); err != nil {
+		t.Fatalf("failed to set sender: %v", err)
+	}
+	if err := tracker.SetCameraSource(skinCamera{}); err != nil {
+		t.Fatalf("failed to set camera: %v", err)
+	}
+	if err := tracker.SetProcessor(NewFallbackProcessor(DefaultFallbackConfig())); err != nil {
+		t.Fatalf("failed to set processor: %v", err)
+	}
+
+	tracker.processFrame()
+
+	msgs := readOSCMessages(t, conn)
+	var head bool
+	for _, name := range boneNames(msgs) {
+		if name == "Head" {
+			head = true
+		}
+	}
+	if !head {
+		t.Errorf("expected a VMC Head bone, got %d messages", len(msgs))
+	}
+}
@@ -0,0 +1,481 @@
+package miface
+
+import (
+	"math"
+	"sync"
+)
+
+// Filter is a 1D smoothing filter applied to successive measurements.
+// KalmanFilter, DoubleExponentialFilter, OneEuroFilter and DeadZoneFilter
+// implement Filter.
+type Filter interface {
+	// Update processes a new measurement and returns the filtered value.
+	Update(measurement float64) float64
+	// Reset clears the filter state.
+	Reset()
+}
+
+// FilterFactory creates a new, independent Filter instance.
+type FilterFactory func() Filter
+
+// tunableFilter is a Filter that can take over the parameters of another
+// filter of the same kind while keeping its state.
+type tunableFilter interface {
+	Filter
+	// tune copies the parameters of other and reports whether other is
+	// the same kind of filter; if not, the filter is left unchanged.
+	tune(other Filter) bool
+}
+
+// retuneFilter returns f with the parameters of a filter from newFilter,
+// keeping its state. If f is a different kind of filter, it returns the new
+// filter instead, primed with last if hasLast, so the output continues from
+// where f left it rather than snapping to the next measurement.
+func retuneFilter(f Filter, newFilter FilterFactory, last float64, hasLast bool) Filter {
+	next := newFilter()
+	if t, ok := f.(tunableFilter); ok && t.tune(next) {
+		return f
+	}
+	if hasLast {
+		next.Update(last)
+	}
+	return next
+}
+
+// FilterReferenceInterval is the frame interval, in seconds, at which the
+// per-frame parameters of KalmanFilter and DoubleExponentialFilter are
+// defined: the default camera rate of 30 fps. Their UpdateDt methods scale
+// the parameters by dt relative to it, so a filter smooths the same per
+// second at any frame rate.
+const FilterReferenceInterval = 1.0 / 30
+
+// TimedFilter is a 1D smoothing filter that accounts for the time between
+// measurements. KalmanFilterCV implements TimedFilter; NewTimedFilter adapts
+// a Filter.
+type TimedFilter interface {
+	// Update processes a measurement taken dt seconds after the previous
+	// one and returns the filtered value.
+	Update(measurement, dt float64) float64
+	// Reset clears the filter state.
+	Reset()
+}
+
+// dtFilter is a Filter that can scale its smoothing by elapsed time.
+type dtFilter interface {
+	Filter
+	UpdateDt(measurement, dt float64) float64
+}
+
+// timedFilter adapts a Filter to TimedFilter.
+type timedFilter struct {
+	f Filter
+}
+
+// NewTimedFilter adapts f to TimedFilter. Filters with an UpdateDt method
+// (KalmanFilter, DoubleExponentialFilter, OneEuroFilter) use it; others, such as
+// DeadZoneFilter, don't depend on time and ignore dt.
+func NewTimedFilter(f Filter) TimedFilter {
+	return timedFilter{f: f}
+}
+
+// Update processes a measurement taken dt seconds after the previous one.
+func (t timedFilter) Update(measurement, dt float64) float64 {
+	if f, ok := t.f.(dtFilter); ok {
+		return f.UpdateDt(measurement, dt)
+	}
+	return t.f.Update(measurement)
+}
+
+// Reset clears the filter state.
+func (t timedFilter) Reset() {
+	t.f.Reset()
+}
+
+// updateDt updates f with a measurement taken dt seconds after the previous
+// one, through UpdateDt if f has it and Update otherwise.
+func updateDt(f Filter, measurement, dt float64) float64 {
+	return timedFilter{f: f}.Update(measurement, dt)
+}
+
+// timeScale returns dt in reference intervals, or 0 if dt is not positive.
+func timeScale(dt float64) float64 {
+	if dt <= 0 {
+		return 0
+	}
+	return dt / FilterReferenceInterval
+}
+
+// scaleGain returns the gain that has the same effect over s reference
+// intervals as gain has over one: 1 - (1-gain)^s.
+func scaleGain(gain, s float64) float64 {
+	return 1 - math.Pow(1-gain, s)
+}
+
+// KalmanFilterFactory returns a factory for Kalman filters with the given smoothing factor.
+func KalmanFilterFactory(smoothingFactor float64) FilterFactory {
+	return func() Filter {
+		return NewKalmanFilter(smoothingFactor)
+	}
+}
+
+// DoubleExponentialFilterFactory returns a factory for double-exponential
+// filters with the given parameters.
+func DoubleExponentialFilterFactory(alpha, beta, lookahead float64) FilterFactory {
+	return func() Filter {
+		f := NewDoubleExponentialFilter(alpha, beta)
+		f.SetLookahead(lookahead)
+		return f
+	}
+}
+
+// Filter3D applies an independent 1D filter to each axis of a 3D point.
+type Filter3D struct {
+	x, y, z Filter
+}
+
+// NewFilter3D creates a 3D filter using newFilter for each axis.
+func NewFilter3D(newFilter FilterFactory) *Filter3D {
+	return &Filter3D{
+		x: newFilter(),
+		y: newFilter(),
+		z: newFilter(),
+	}
+}
+
+// Update processes a new 3D measurement and returns the filtered point.
+func (f *Filter3D) Update(point Point3D) Point3D {
+	return Point3D{
+		X: f.x.Update(point.X),
+		Y: f.y.Update(point.Y),
+		Z: f.z.Update(point.Z),
+	}
+}
+
+// UpdateDt is like Update for a measurement taken dt seconds after the
+// previous one; see NewTimedFilter. A dt of 0 or less is treated as one
+// FilterReferenceInterval.
+func (f *Filter3D) UpdateDt(point Point3D, dt float64) Point3D {
+	return Point3D{
+		X: updateDt(f.x, point.X, dt),
+		Y: updateDt(f.y, point.Y, dt),
+		Z: updateDt(f.z, point.Z, dt),
+	}
+}
+
+// retune switches each axis to the parameters of a filter from newFilter,
+// keeping its state; see retuneFilter.
+func (f *Filter3D) retune(newFilter FilterFactory, last Point3D, hasLast bool) {
+	f.x = retuneFilter(f.x, newFilter, last.X, hasLast)
+	f.y = retuneFilter(f.y, newFilter, last.Y, hasLast)
+	f.z = retuneFilter(f.z, newFilter, last.Z, hasLast)
+}
+
+// Reset clears all axis filter states.
+func (f *Filter3D) Reset() {
+	f.x.Reset()
+	f.y.Reset()
+	f.z.Reset()
+}
+
+// DoubleExponentialFilter implements Holt's double-exponential smoothing.
+// It tracks both a level and a trend, so unlike KalmanFilter it follows
+// constant-velocity motion (e.g. a slow head pan) without steady-state lag.
+// With a lookahead it extrapolates along the trend to compensate for
+// pipeline latency.
+type DoubleExponentialFilter struct {
+	mu sync.Mutex
+
+	// Level smoothing factor (0.0-1.0, higher = more responsive)
+	alpha float64
+	// Trend smoothing factor (0.0-1.0, higher = more responsive)
+	beta float64
+	// Number of update intervals to extrapolate the output
+	lookahead float64
+
+	level       float64
+	trend       float64
+	initialized bool
+}
+
+// NewDoubleExponentialFilter creates a filter with level smoothing alpha and
+// trend smoothing beta, both in the range 0.0-1.0. Higher values respond
+// faster; lower values smooth more.
+func NewDoubleExponentialFilter(alpha, beta float64) *DoubleExponentialFilter {
+	return &DoubleExponentialFilter{
+		alpha: clamp01(alpha),
+		beta:  clamp01(beta),
+	}
+}
+
+// SetLookahead sets how many update intervals the output is extrapolated
+// along the current trend. 0 disables prediction.
+func (f *DoubleExponentialFilter) SetLookahead(steps float64) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if steps < 0 {
+		steps = 0
+	}
+	f.lookahead = steps
+}
+
+// Update processes a new measurement and returns the filtered value.
+func (f *DoubleExponentialFilter) Update(measurement float64) float64 {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if !f.initialized {
+		f.level = measurement
+		f.trend = 0
+		f.initialized = true
+		return measurement
+	}
+
+	prevLevel := f.level
+	f.level = f.alpha*measurement + (1-f.alpha)*(prevLevel+f.trend)
+	f.trend = f.beta*(f.level-prevLevel) + (1-f.beta)*f.trend
+
+	return f.level + f.lookahead*f.trend
+}
+
+// UpdateDt processes a measurement taken dt seconds after the previous one.
+// Alpha and beta are scaled so that they smooth as much per second as they
+// do per frame at FilterReferenceInterval, and the trend and lookahead stay
+// in reference intervals. A dt of 0 or less is treated as one reference
+// interval, like Update.
+func (f *DoubleExponentialFilter) UpdateDt(measurement, dt float64) float64 {
+	s := timeScale(dt)
+	if s == 0 {
+		return f.Update(measurement)
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if !f.initialized {
+		f.level = measurement
+		f.trend = 0
+		f.initialized = true
+		return measurement
+	}
+
+	alpha, beta := scaleGain(f.alpha, s), scaleGain(f.beta, s)
+	prevLevel := f.level
+	f.level = alpha*measurement + (1-alpha)*(prevLevel+f.trend*s)
+	f.trend = beta*(f.level-prevLevel)/s + (1-beta)*f.trend
+
+	return f.level + f.lookahead*f.trend
+}
+
+// tune copies the alpha, beta and lookahead of other if it is a
+// DoubleExponentialFilter.
+func (f *DoubleExponentialFilter) tune(other Filter) bool {
+	o, ok := other.(*DoubleExponentialFilter)
+	if !ok {
+		return false
+	}
+	o.mu.Lock()
+	alpha, beta, lookahead := o.alpha, o.beta, o.lookahead
+	o.mu.Unlock()
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.alpha, f.beta, f.lookahead = alpha, beta, lookahead
+	return true
+}
+
+// Reset clears the filter state.
+func (f *DoubleExponentialFilter) Reset() {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.level = 0
+	f.trend = 0
+	f.initialized = false
+}
+
+// OneEuroFilter implements the 1€ filter (Casiez et al., 2012): a low-pass
+// filter whose cutoff frequency rises with the measured speed, so it removes
+// jitter at rest while following fast motion with little lag.
+type OneEuroFilter struct {
+	mu sync.Mutex
+
+	// Cutoff frequency at rest, in Hz
+	minCutoff float64
+	// How much the cutoff rises per unit of speed
+	beta float64
+	// Cutoff frequency for the speed estimate, in Hz
+	dCutoff float64
+
+	x           float64
+	dx          float64
+	initialized bool
+}
+
+// NewOneEuroFilter creates a 1€ filter. Lower minCutoff smooths more at
+// rest; higher beta lags less in fast motion.
+func NewOneEuroFilter(minCutoff, beta, dCutoff float64) *OneEuroFilter {
+	return &OneEuroFilter{
+		minCutoff: minCutoff,
+		beta:      beta,
+		dCutoff:   dCutoff,
+	}
+}
+
+// OneEuroFilterFactory returns a factory for 1€ filters with the given parameters.
+func OneEuroFilterFactory(minCutoff, beta, dCutoff float64) FilterFactory {
+	return func() Filter {
+		return NewOneEuroFilter(minCutoff, beta, dCutoff)
+	}
+}
+
+// Update processes a new measurement taken FilterReferenceInterval after
+// the previous one and returns the filtered value.
+func (f *OneEuroFilter) Update(measurement float64) float64 {
+	return f.UpdateDt(measurement, FilterReferenceInterval)
+}
+
+// UpdateDt processes a measurement taken dt seconds after the previous one.
+// A dt of 0 or less is treated as FilterReferenceInterval.
+func (f *OneEuroFilter) UpdateDt(measurement, dt float64) float64 {
+	if dt <= 0 {
+		dt = FilterReferenceInterval
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if !f.initialized {
+		f.x = measurement
+		f.dx = 0
+		f.initialized = true
+		return measurement
+	}
+
+	speed := (measurement - f.x) / dt
+	f.dx += lowPassGain(f.dCutoff, dt) * (speed - f.dx)
+	cutoff := f.minCutoff + f.beta*math.Abs(f.dx)
+	f.x += lowPassGain(cutoff, dt) * (measurement - f.x)
+	return f.x
+}
+
+// tune copies the cutoffs and beta of other if it is a OneEuroFilter.
+func (f *OneEuroFilter) tune(other Filter) bool {
+	o, ok := other.(*OneEuroFilter)
+	if !ok {
+		return false
+	}
+	o.mu.Lock()
+	minCutoff, beta, dCutoff := o.minCutoff, o.beta, o.dCutoff
+	o.mu.Unlock()
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.minCutoff, f.beta, f.dCutoff = minCutoff, beta, dCutoff
+	return true
+}
+
+// Reset clears the filter state.
+func (f *OneEuroFilter) Reset() {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.x = 0
+	f.dx = 0
+	f.initialized = false
+}
+
+// lowPassGain returns the smoothing gain of a first-order low-pass filter
+// with the given cutoff frequency, sampled every dt seconds.
+func lowPassGain(cutoff, dt float64) float64 {
+	if cutoff <= 0 {
+		return 0
+	}
+	tau := 1 / (2 * math.Pi * cutoff)
+	return 1 / (1 + tau/dt)
+}
+
+// clamp01 limits v to the range 0.0-1.0.
+func clamp01(v float64) float64 {
+	if v < 0 {
+		return 0
+	}
+	if v > 1 {
+		return 1
+	}
+	return v
+}
+
+// DeadZoneFilter holds its output until a measurement moves more than
+// threshold away from it, then jumps to follow. It removes micro-jitter
+// when the tracked subject is still.
+type DeadZoneFilter struct {
+	mu sync.Mutex
+
+	threshold   float64
+	held        float64
+	initialized bool
+}
+
+// NewDeadZoneFilter creates a dead-zone filter with the given threshold.
+func NewDeadZoneFilter(threshold float64) *DeadZoneFilter {
+	return &DeadZoneFilter{threshold: threshold}
+}
+
+// Update processes a new measurement and returns the held or updated value.
+func (f *DeadZoneFilter) Update(measurement float64) float64 {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if !f.initialized || math.Abs(measurement-f.held) > f.threshold {
+		f.held = measurement
+		f.initialized = true
+	}
+	return f.held
+}
+
+// Reset clears the filter state.
+func (f *DeadZoneFilter) Reset() {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.held = 0
+	f.initialized = false
+}
+
+// DeadZoneFilter3D is the 3D counterpart of DeadZoneFilter. The output is
+// frozen while the measurement stays within a sphere of radius threshold
+// around it.
+type DeadZoneFilter3D struct {
+	mu sync.Mutex
+
+	threshold   float64
+	held        Point3D
+	initialized bool
+}
+
+// NewDeadZoneFilter3D creates a 3D dead-zone filter with the given radius.
+func NewDeadZoneFilter3D(threshold float64) *DeadZoneFilter3D {
+	return &DeadZoneFilter3D{threshold: threshold}
+}
+
+// Update processes a new 3D measurement and returns the held or updated point.
+func (f *DeadZoneFilter3D) Update(point Point3D) Point3D {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	dx, dy, dz := point.X-f.held.X, point.Y-f.held.Y, point.Z-f.held.Z
+	if !f.initialized || math.Sqrt(dx*dx+dy*dy+dz*dz) > f.threshold {
+		f.held = point
+		f.initialized = true
+	}
+	return f.held
+}
+
+// Reset clears the filter state.
+func (f *DeadZoneFilter3D) Reset() {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.held = Point3D{}
+	f.initialized = false
+}
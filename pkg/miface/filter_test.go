@@ -0,0 +1,299 @@
+package miface
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+)
+
+func TestDoubleExponentialFilterFirstUpdate(t *testing.T) {
+	f := NewDoubleExponentialFilter(0.5, 0.3)
+
+	if result := f.Update(10); result != 10 {
+		t.Errorf("first update should return measurement, got %f", result)
+	}
+
+	result := f.Update(11)
+	if result <= 10 || result >= 11 {
+		t.Errorf("expected smoothed value between 10 and 11, got %f", result)
+	}
+}
+
+func TestDoubleExponentialFilterRampLag(t *testing.T) {
+	des := NewDoubleExponentialFilter(0.5, 0.3)
+	kf := NewKalmanFilter(0.5)
+
+	// Linear ramp: constant velocity of 1 unit per frame
+	var desOut, kfOut, input float64
+	for i := 0; i < 100; i++ {
+		input = float64(i)
+		desOut = des.Update(input)
+		kfOut = kf.Update(input)
+	}
+
+	desLag := input - desOut
+	kfLag := input - kfOut
+
+	if math.Abs(desLag) >= math.Abs(kfLag) {
+		t.Errorf("expected double-exponential lag (%f) < Kalman lag (%f)", desLag, kfLag)
+	}
+	if math.Abs(desLag) > 0.01 {
+		t.Errorf("expected near-zero steady-state lag, got %f", desLag)
+	}
+}
+
+func TestDoubleExponentialFilterLookahead(t *testing.T) {
+	f := NewDoubleExponentialFilter(0.5, 0.3)
+	f.SetLookahead(2)
+
+	var out, input float64
+	for i := 0; i < 100; i++ {
+		input = float64(i)
+		out = f.Update(input)
+	}
+
+	// Two frames of prediction on a unit ramp
+	if math.Abs(out-(input+2)) > 0.01 {
+		t.Errorf("expected output %f, got %f", input+2, out)
+	}
+}
+
+func TestDoubleExponentialFilterReset(t *testing.T) {
+	f := NewDoubleExponentialFilter(0.5, 0.3)
+	f.Update(100)
+	f.Update(200)
+	f.Reset()
+
+	if result := f.Update(50); result != 50 {
+		t.Errorf("after reset, expected 50, got %f", result)
+	}
+}
+
+func TestLandmarkSmootherWithFilter(t *testing.T) {
+	smoother := NewLandmarkSmootherWithFilter(DoubleExponentialFilterFactory(0.5, 0.3, 0))
+
+	var result []Landmark
+	for i := 0; i < 100; i++ {
+		result = smoother.Smooth([]Landmark{
+			{Point: Point3D{X: float64(i), Y: 2 * float64(i)}, Visibility: 1},
+		})
+	}
+
+	if math.Abs(result[0].Point.X-99) > 0.01 || math.Abs(result[0].Point.Y-198) > 0.01 {
+		t.Errorf("expected ramp to be tracked without lag, got %+v", result[0].Point)
+	}
+}
+
+func TestDeadZoneFilter(t *testing.T) {
+	f := NewDeadZoneFilter(0.1)
+
+	if result := f.Update(5); result != 5 {
+		t.Fatalf("first update should return measurement, got %f", result)
+	}
+
+	// Small noise around 5 stays within the dead zone
+	for _, m := range []float64{5.02, 4.95, 5.08, 4.91, 5.1, 5.0} {
+		if result := f.Update(m); result != 5 {
+			t.Errorf("Update(%f) = %f, want held value 5", m, result)
+		}
+	}
+
+	// Exceeding the threshold follows the measurement
+	if result := f.Update(5.3); result != 5.3 {
+		t.Errorf("expected jump to 5.3, got %f", result)
+	}
+	// New hold position
+	if result := f.Update(5.35); result != 5.3 {
+		t.Errorf("expected hold at 5.3, got %f", result)
+	}
+
+	f.Reset()
+	if result := f.Update(1); result != 1 {
+		t.Errorf("after reset, expected 1, got %f", result)
+	}
+}
+
+func TestDeadZoneFilter3D(t *testing.T) {
+	f := NewDeadZoneFilter3D(0.1)
+	origin := Point3D{X: 1, Y: 1, Z: 1}
+	f.Update(origin)
+
+	// Each axis moves less than the radius, and so does the point overall
+	for _, p := range []Point3D{{X: 1.05, Y: 1, Z: 1}, {X: 1, Y: 0.95, Z: 1.02}, {X: 1.03, Y: 1.03, Z: 0.97}} {
+		if result := f.Update(p); result != origin {
+			t.Errorf("Update(%+v) = %+v, want held %+v", p, result, origin)
+		}
+	}
+
+	// 0.08 on two axes is within per-axis limits but outside the radius
+	moved := Point3D{X: 1.08, Y: 1.08, Z: 1}
+	if result := f.Update(moved); result != moved {
+		t.Errorf("expected jump to %+v, got %+v", moved, result)
+	}
+}
+
+func TestLandmarkSmootherDeadZone(t *testing.T) {
+	smoother := NewLandmarkSmoother(0.5)
+	smoother.SetDeadZone(0.01)
+
+	first := smoother.Smooth([]Landmark{{Point: Point3D{X: 0.5, Y: 0.5}}})
+	for _, x := range []float64{0.503, 0.497, 0.505, 0.495} {
+		result := smoother.Smooth([]Landmark{{Point: Point3D{X: x, Y: 0.5}}})
+		if result[0].Point != first[0].Point {
+			t.Errorf("expected output to stay at %+v, got %+v", first[0].Point, result[0].Point)
+		}
+	}
+
+	// Larger movement passes through the dead zone into the smoother
+	result := smoother.Smooth([]Landmark{{Point: Point3D{X: 0.6, Y: 0.5}}})
+	if result[0].Point.X <= 0.5 || result[0].Point.X >= 0.6 {
+		t.Errorf("expected smoothed X between 0.5 and 0.6, got %f", result[0].Point.X)
+	}
+}
+
+func TestDeadZoneFilter3DTinyThreshold(t *testing.T) {
+	f := NewDeadZoneFilter3D(5e-4)
+	f.Update(Point3D{})
+
+	if result := f.Update(Point3D{X: 1e-4}); result != (Point3D{}) {
+		t.Errorf("expected movement within a small radius to be held, got %+v", result)
+	}
+
+	moved := Point3D{X: 6e-4}
+	if result := f.Update(moved); result != moved {
+		t.Errorf("expected movement beyond a small radius to pass, got %+v", result)
+	}
+}
+
+// smoothTrajectory samples x(t) = sin(πt) for three seconds at a jittery
+// frame interval and returns the filter output per frame time.
+func smoothTrajectory(f TimedFilter, interval float64, seed int64) (times, values []float64) {
+	rng := rand.New(rand.NewSource(seed))
+	var t, dt float64
+	for t < 3 {
+		times = append(times, t)
+		values = append(values, f.Update(math.Sin(math.Pi*t), dt))
+		dt = interval * (0.7 + 0.6*rng.Float64())
+		t += dt
+	}
+	return times, values
+}
+
+// trajectoryDistance returns the largest difference between trajectory a and
+// b (linearly interpolated at a's times) after the first second.
+func trajectoryDistance(aTimes, aValues, bTimes, bValues []float64) float64 {
+	var worst float64
+	j := 0
+	for i, t := range aTimes {
+		if t < 1 {
+			continue
+		}
+		for j+1 < len(bTimes) && bTimes[j+1] < t {
+			j++
+		}
+		if j+1 >= len(bTimes) {
+			break
+		}
+		w := (t - bTimes[j]) / (bTimes[j+1] - bTimes[j])
+		b := bValues[j] + (bValues[j+1]-bValues[j])*w
+		worst = math.Max(worst, math.Abs(aValues[i]-b))
+	}
+	return worst
+}
+
+func TestTimedFilterFrameRateIndependent(t *testing.T) {
+	tests := []struct {
+		name    string
+		factory FilterFactory
+	}{
+		{"kalman", KalmanFilterFactory(0.3)},
+		{"double exponential", DoubleExponentialFilterFactory(0.3, 0.2, 0)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			slowT, slow := smoothTrajectory(NewTimedFilter(tt.factory()), 1.0/30, 1)
+			fastT, fast := smoothTrajectory(NewTimedFilter(tt.factory()), 1.0/90, 2)
+			timed := trajectoryDistance(slowT, slow, fastT, fast)
+
+			// The same filters ignoring dt smooth three times as much per
+			// second at 30 fps as at 90 fps
+			plainSlowT, plainSlow := smoothTrajectory(plainTimed{tt.factory()}, 1.0/30, 1)
+			plainFastT, plainFast := smoothTrajectory(plainTimed{tt.factory()}, 1.0/90, 2)
+			plain := trajectoryDistance(plainSlowT, plainSlow, plainFastT, plainFast)
+
+			t.Logf("max difference: %f with dt, %f without", timed, plain)
+			if timed > 0.05 {
+				t.Errorf("expected trajectories within 0.05, got %f", timed)
+			}
+			if timed > plain/3 {
+				t.Errorf("expected dt to reduce the difference of %f well below, got %f", plain, timed)
+			}
+		})
+	}
+}
+
+// plainTimed ignores dt, as filters did before UpdateDt.
+type plainTimed struct {
+	f Filter
+}
+
+func (p plainTimed) Update(measurement, _ float64) float64 { return p.f.Update(measurement) }
+func (p plainTimed) Reset()                                { p.f.Reset() }
+
+func TestTimedFilterReferenceInterval(t *testing.T) {
+	// At the reference interval UpdateDt matches Update exactly
+	a, b := NewKalmanFilter(0.4), NewKalmanFilter(0.4)
+	c, d := NewDoubleExponentialFilter(0.4, 0.3), NewDoubleExponentialFilter(0.4, 0.3)
+	for _, m := range []float64{0, 0.5, 1, 0.8, 1.2} {
+		if x, y := a.Update(m), b.UpdateDt(m, FilterReferenceInterval); math.Abs(x-y) > 1e-12 {
+			t.Errorf("kalman: expected %f, got %f", x, y)
+		}
+		if x, y := c.Update(m), d.UpdateDt(m, FilterReferenceInterval); math.Abs(x-y) > 1e-12 {
+			t.Errorf("double exponential: expected %f, got %f", x, y)
+		}
+	}
+}
+
+func TestTimedFilterIgnoresDtForDeadZone(t *testing.T) {
+	f := NewTimedFilter(NewDeadZoneFilter(0.1))
+	f.Update(1, 0)
+	if x := f.Update(1.05, 10); x != 1 {
+		t.Errorf("expected dead zone to hold 1, got %f", x)
+	}
+}
+
+func TestOneEuroFilter(t *testing.T) {
+	f := NewOneEuroFilter(1.0, 0, 1.0)
+	if x := f.Update(10); x != 10 {
+		t.Errorf("first update should return measurement, got %f", x)
+	}
+
+	// Without beta it is a fixed low-pass filter: a step is smoothed
+	x := f.Update(11)
+	if x <= 10 || x >= 11 {
+		t.Errorf("expected smoothed value between 10 and 11, got %f", x)
+	}
+
+	f.Reset()
+	if x := f.Update(5); x != 5 {
+		t.Errorf("after reset, expected 5, got %f", x)
+	}
+}
+
+func TestOneEuroFilterSpeedRaisesCutoff(t *testing.T) {
+	// Follow a fast ramp: beta raises the cutoff and reduces lag
+	lag := func(beta float64) float64 {
+		f := NewOneEuroFilter(1.0, beta, 1.0)
+		var m, x float64
+		for i := 0; i < 60; i++ {
+			m = float64(i) * 0.1
+			x = f.Update(m)
+		}
+		return m - x
+	}
+
+	still, fast := lag(0), lag(10)
+	if fast >= still/2 {
+		t.Errorf("expected beta to cut the lag of %f by more than half, got %f", still, fast)
+	}
+}
@@ -0,0 +1,87 @@
+package miface
+
+// EyeGaze is the gaze direction of one eye.
+type EyeGaze struct {
+	// Horizontal is -1 (toward the inner corner) to 1 (toward the outer corner).
+	Horizontal float64
+	// Vertical is -1 (down) to 1 (up).
+	Vertical float64
+}
+
+// Gaze holds the gaze direction of both eyes.
+type Gaze struct {
+	Left  EyeGaze
+	Right EyeGaze
+}
+
+// EstimateGaze derives per-eye gaze from the iris position relative to the
+// eye corners and lids. It returns zero gaze when the face has no iris
+// landmarks (face mesh refinement disabled).
+func EstimateGaze(face *FaceData) Gaze {
+	if face == nil || len(face.Landmarks) < FaceLandmarkCount {
+		return Gaze{}
+	}
+
+	lm := face.Landmarks
+	return Gaze{
+		Left: eyeGaze(lm[FaceLeftIrisCenter].Point,
+			lm[FaceLeftEyeInner].Point, lm[FaceLeftEyeOuter].Point,
+			lm[FaceLeftEyeUpper].Point, lm[FaceLeftEyeLower].Point),
+		Right: eyeGaze(lm[FaceRightIrisCenter].Point,
+			lm[FaceRightEyeInner].Point, lm[FaceRightEyeOuter].Point,
+			lm[FaceRightEyeUpper].Point, lm[FaceRightEyeLower].Point),
+	}
+}
+
+// eyeGaze locates the iris within the eye opening. Projecting onto the
+// corner-to-corner axis keeps the result independent of mirroring and head roll.
+func eyeGaze(iris, inner, outer, upper, lower Point3D) EyeGaze {
+	var g EyeGaze
+
+	ax, ay := outer.X-inner.X, outer.Y-inner.Y
+	if width := ax*ax + ay*ay; width > 0 {
+		t := ((iris.X-inner.X)*ax + (iris.Y-inner.Y)*ay) / width
+		g.Horizontal = clampUnit(2*t - 1)
+	}
+
+	if height := lower.Y - upper.Y; height != 0 {
+		t := (iris.Y - upper.Y) / height
+		g.Vertical = clampUnit(1 - 2*t)
+	}
+
+	return g
+}
+
+// BlendShapes converts the gaze to ARKit eyeLook* blend shape weights.
+func (g Gaze) BlendShapes() map[string]float64 {
+	shapes := make(map[string]float64, 8)
+	for side, eye := range map[string]EyeGaze{"Left": g.Left, "Right": g.Right} {
+		shapes["eyeLookOut"+side] = max(eye.Horizontal, 0)
+		shapes["eyeLookIn"+side] = max(-eye.Horizontal, 0)
+		shapes["eyeLookUp"+side] = max(eye.Vertical, 0)
+		shapes["eyeLookDown"+side] = max(-eye.Vertical, 0)
+	}
+	return shapes
+}
+
+// NewGazeStage returns a TransformStage that estimates gaze and stores the
+// eyeLook* blend shapes in FaceData.BlendShapes.
+func NewGazeStage() TransformStage {
+	return TransformFunc(func(data *TrackingData) (*TrackingData, error) {
+		if data == nil || data.Face == nil {
+			return data, nil
+		}
+		if data.Face.BlendShapes == nil {
+			data.Face.BlendShapes = make(map[string]float64)
+		}
+		for name, value := range EstimateGaze(data.Face).BlendShapes() {
+			data.Face.BlendShapes[name] = value
+		}
+		return data, nil
+	})
+}
+
+// clampUnit clamps v to [-1, 1].
+func clampUnit(v float64) float64 {
+	return min(max(v, -1), 1)
+}
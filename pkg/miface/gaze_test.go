@@ -0,0 +1,73 @@
+package miface
+
+import (
+	"math"
+	"testing"
+)
+
+// gazeFace builds a refined face mesh with both eyes open and the irises
+// shifted horizontally by dx (image space) from the eye centers.
+func gazeFace(dx float64) *FaceData {
+	lm := make([]Landmark, FaceLandmarkCount)
+	set := func(idx int, x, y float64) {
+		lm[idx] = Landmark{Point: Point3D{X: x, Y: y}, Visibility: 1}
+	}
+
+	// Non-mirrored image: the subject's right eye appears on the left
+	set(FaceRightEyeOuter, 0.30, 0.40)
+	set(FaceRightEyeInner, 0.40, 0.40)
+	set(FaceRightEyeUpper, 0.35, 0.38)
+	set(FaceRightEyeLower, 0.35, 0.42)
+	set(FaceRightIrisCenter, 0.35+dx, 0.40)
+
+	set(FaceLeftEyeInner, 0.60, 0.40)
+	set(FaceLeftEyeOuter, 0.70, 0.40)
+	set(FaceLeftEyeUpper, 0.65, 0.38)
+	set(FaceLeftEyeLower, 0.65, 0.42)
+	set(FaceLeftIrisCenter, 0.65+dx, 0.40)
+
+	return &FaceData{Landmarks: lm}
+}
+
+func TestEstimateGazeCentered(t *testing.T) {
+	gaze := EstimateGaze(gazeFace(0))
+	for _, v := range []float64{gaze.Left.Horizontal, gaze.Left.Vertical, gaze.Right.Horizontal, gaze.Right.Vertical} {
+		if math.Abs(v) > 1e-9 {
+			t.Errorf("expected centered gaze, got %+v", gaze)
+			break
+		}
+	}
+}
+
+func TestEstimateGazeLookLeft(t *testing.T) {
+	center := EstimateGaze(gazeFace(0)).BlendShapes()
+
+	// Subject looks to their left: irises move toward image +X
+	face := gazeFace(0.03)
+	data, err := NewGazeStage().Transform(&TrackingData{Face: face})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	shapes := data.Face.BlendShapes
+
+	if shapes["eyeLookOutLeft"] <= center["eyeLookOutLeft"] {
+		t.Errorf("expected eyeLookOutLeft to rise, got %f", shapes["eyeLookOutLeft"])
+	}
+	if shapes["eyeLookInRight"] <= center["eyeLookInRight"] {
+		t.Errorf("expected eyeLookInRight to rise, got %f", shapes["eyeLookInRight"])
+	}
+	if shapes["eyeLookInLeft"] != 0 || shapes["eyeLookOutRight"] != 0 {
+		t.Errorf("expected opposite directions to stay 0, got in=%f out=%f",
+			shapes["eyeLookInLeft"], shapes["eyeLookOutRight"])
+	}
+}
+
+func TestEstimateGazeWithoutIris(t *testing.T) {
+	face := &FaceData{Landmarks: make([]Landmark, numFaceLandmarks)}
+	if gaze := EstimateGaze(face); gaze != (Gaze{}) {
+		t.Errorf("expected zero gaze without iris landmarks, got %+v", gaze)
+	}
+	if gaze := EstimateGaze(nil); gaze != (Gaze{}) {
+		t.Errorf("expected zero gaze for nil face, got %+v", gaze)
+	}
+}
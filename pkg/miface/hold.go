@@ -0,0 +1,152 @@
+package miface
+
+import (
+	"sync"
+	"time"
+)
+
+// HoldWindow limits how long a modality's last good data is held after
+// detection drops. The hold ends at whichever limit is reached first; a
+// zero limit is not checked, and a zero window disables holding.
+type HoldWindow struct {
+	// Duration is the longest time to hold, from the last detection.
+	Duration time.Duration
+	// Frames is the most frames to hold for.
+	Frames int
+}
+
+// enabled reports whether the window holds at all.
+func (w HoldWindow) enabled() bool {
+	return w.Duration > 0 || w.Frames > 0
+}
+
+// HoldConfig sets the hold window of each modality for HoldStage.
+type HoldConfig struct {
+	Face  HoldWindow
+	Hands HoldWindow // Applies to each hand separately
+	Pose  HoldWindow
+}
+
+// DefaultHoldConfig returns a hold configuration that bridges short
+// dropouts: a few frames for the face, a little longer for hands and pose,
+// which blink out more often.
+func DefaultHoldConfig() HoldConfig {
+	return HoldConfig{
+		Face:  HoldWindow{Duration: 150 * time.Millisecond},
+		Hands: HoldWindow{Duration: 250 * time.Millisecond},
+		Pose:  HoldWindow{Duration: 250 * time.Millisecond},
+	}
+}
+
+// holdSlot is the held data of one modality.
+type holdSlot struct {
+	last       *TrackingData // Holds only this slot's modality; nil if nothing is held
+	seenAt     time.Time     // When the modality was last detected
+	lostFrames int
+}
+
+// hold records and returns present, which carries only this slot's
+// modality. When present is nil it returns a copy of the last good data
+// while the window lasts, and nil after.
+func (s *holdSlot) hold(present *TrackingData, window HoldWindow, now time.Time) *TrackingData {
+	if present != nil {
+		s.last = present.Clone()
+		s.seenAt = now
+		s.lostFrames = 0
+		return present
+	}
+	if s.last == nil || !window.enabled() {
+		return nil
+	}
+
+	s.lostFrames++
+	if (window.Duration > 0 && now.Sub(s.seenAt) >= window.Duration) ||
+		(window.Frames > 0 && s.lostFrames > window.Frames) {
+		s.last = nil
+		return nil
+	}
+	return s.last.Clone()
+}
+
+// HoldStage keeps sending a modality's last good data for a short window
+// after its detection drops, so a hand missing for a frame or two doesn't
+// snap the avatar to rest and back. Once the window expires the modality
+// is reported missing, and senders fall back to rest as usual.
+//
+// HoldStage implements TransformStage for use in a ChainProcessor.
+type HoldStage struct {
+	mu  sync.Mutex
+	cfg HoldConfig
+
+	face, leftHand, rightHand, pose holdSlot
+
+	clock Clock
+}
+
+// NewHoldStage creates a hold stage with the given windows.
+func NewHoldStage(cfg HoldConfig) *HoldStage {
+	return &HoldStage{
+		cfg:   cfg,
+		clock: realClock{},
+	}
+}
+
+// SetClock sets the clock the hold windows are timed with. It defaults to
+// the system clock; pass the tracker's clock so the windows follow its
+// time, e.g. a FakeClock in tests.
+func (h *HoldStage) SetClock(clock Clock) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.clock = clock
+}
+
+// Transform fills in missing modalities with held data.
+func (h *HoldStage) Transform(data *TrackingData) (*TrackingData, error) {
+	if data == nil {
+		return data, nil
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	now := h.clock.Now()
+
+	var face, left, right, pose *TrackingData
+	if data.Face != nil {
+		face = &TrackingData{Face: data.Face}
+	}
+	if data.LeftHand != nil {
+		left = &TrackingData{LeftHand: data.LeftHand}
+	}
+	if data.RightHand != nil {
+		right = &TrackingData{RightHand: data.RightHand}
+	}
+	if data.Pose != nil {
+		pose = &TrackingData{Pose: data.Pose}
+	}
+
+	if held := h.face.hold(face, h.cfg.Face, now); held != nil {
+		data.Face = held.Face
+	}
+	if held := h.leftHand.hold(left, h.cfg.Hands, now); held != nil {
+		data.LeftHand = held.LeftHand
+	}
+	if held := h.rightHand.hold(right, h.cfg.Hands, now); held != nil {
+		data.RightHand = held.RightHand
+	}
+	if held := h.pose.hold(pose, h.cfg.Pose, now); held != nil {
+		data.Pose = held.Pose
+	}
+	return data, nil
+}
+
+// Reset forgets all held data.
+func (h *HoldStage) Reset() {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.face = holdSlot{}
+	h.leftHand = holdSlot{}
+	h.rightHand = holdSlot{}
+	h.pose = holdSlot{}
+}
@@ -0,0 +1,81 @@
+package miface
+
+import (
+	"testing"
+	"time"
+)
+
+func TestHoldStageHand(t *testing.T) {
+	hold := NewHoldStage(HoldConfig{Hands: HoldWindow{Duration: 100 * time.Millisecond}})
+	clock := NewFakeClock(time.Unix(0, 0))
+	hold.SetClock(clock)
+	step := func(hand *HandData) *TrackingData {
+		t.Helper()
+		clock.Advance(33 * time.Millisecond)
+		data, err := hold.Transform(&TrackingData{LeftHand: hand})
+		if err != nil {
+			t.Fatalf("transform failed: %v", err)
+		}
+		return data
+	}
+
+	good := testHand(true, 0.9)
+	step(good)
+
+	// Dropped for one frame: the last good hand is still sent
+	held := step(nil).LeftHand
+	if held == nil {
+		t.Fatal("expected the hand to be held")
+	}
+	if held == good || len(held.Landmarks) != len(good.Landmarks) || held.Landmarks[8] != good.Landmarks[8] {
+		t.Errorf("expected a copy of the last good hand, got %+v", held)
+	}
+
+	// Detected again: the new hand passes through
+	next := testHand(true, 0.8)
+	if got := step(next).LeftHand; got != next {
+		t.Error("expected the detected hand to pass through")
+	}
+
+	// Dropped past the window: held for 100ms, then missing
+	var frames int
+	for ; frames < 10; frames++ {
+		if step(nil).LeftHand == nil {
+			break
+		}
+	}
+	if frames != 3 {
+		t.Errorf("expected the hand held for 3 frames, got %d", frames)
+	}
+	if step(nil).LeftHand != nil {
+		t.Error("expected the hand to stay missing after the window")
+	}
+}
+
+func TestHoldStageFrames(t *testing.T) {
+	hold := NewHoldStage(HoldConfig{Pose: HoldWindow{Frames: 2}})
+	hold.Transform(&TrackingData{Pose: testPose(), Face: &FaceData{}})
+
+	for i := 0; i < 2; i++ {
+		data, _ := hold.Transform(&TrackingData{})
+		if data.Pose == nil {
+			t.Errorf("frame %d: expected the pose to be held", i+1)
+		}
+		if data.Face != nil {
+			t.Errorf("frame %d: expected the face not to be held without a window", i+1)
+		}
+	}
+	if data, _ := hold.Transform(&TrackingData{}); data.Pose != nil {
+		t.Error("expected the pose to be dropped after 2 frames")
+	}
+}
+
+func TestHoldStageReset(t *testing.T) {
+	hold := NewHoldStage(DefaultHoldConfig())
+	hold.Transform(&TrackingData{RightHand: testHand(false, 1)})
+	hold.Reset()
+
+	if data, _ := hold.Transform(&TrackingData{}); data.RightHand != nil {
+		t.Error("expected nothing held after Reset")
+	}
+}
@@ -0,0 +1,231 @@
+package miface
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"sync"
+	"time"
+)
+
+// maxInterpolationInterval is the longest gap between frames that is still
+// interpolated. Longer gaps mean the camera stalled, and frames are passed
+// through as they arrive.
+const maxInterpolationInterval = time.Second
+
+// InterpolateTrackingData returns the frame a fraction t (0 to 1) of the
+// way from a to b: rotations are interpolated with SLERP, and positions,
+// landmarks and blend shape weights linearly. A modality, landmark list or
+// blend shape that is not in both frames is taken from b. The result
+// shares no slices or maps with a or b.
+func InterpolateTrackingData(a, b *TrackingData, t float64) *TrackingData {
+	if a == nil || b == nil {
+		return b.Clone()
+	}
+
+	out := b.Clone()
+	out.Timestamp = a.Timestamp.Add(time.Duration(t * float64(b.Timestamp.Sub(a.Timestamp))))
+
+	if a.Face != nil && b.Face != nil {
+		out.Face.Landmarks = lerpLandmarks(a.Face.Landmarks, b.Face.Landmarks, t)
+		out.Face.HeadRotation = Slerp(a.Face.HeadRotation, b.Face.HeadRotation, t)
+		out.Face.HeadPosition = lerpPoint(a.Face.HeadPosition, b.Face.HeadPosition, t)
+		for name, wb := range b.Face.BlendShapes {
+			if wa, ok := a.Face.BlendShapes[name]; ok {
+				out.Face.BlendShapes[name] = wa + (wb-wa)*t
+			}
+		}
+	}
+	if a.LeftHand != nil && b.LeftHand != nil {
+		out.LeftHand.Landmarks = lerpLandmarks(a.LeftHand.Landmarks, b.LeftHand.Landmarks, t)
+		if a.LeftHand.Depth > 0 && b.LeftHand.Depth > 0 {
+			out.LeftHand.Depth = a.LeftHand.Depth + (b.LeftHand.Depth-a.LeftHand.Depth)*t
+		}
+	}
+	if a.RightHand != nil && b.RightHand != nil {
+		out.RightHand.Landmarks = lerpLandmarks(a.RightHand.Landmarks, b.RightHand.Landmarks, t)
+		if a.RightHand.Depth > 0 && b.RightHand.Depth > 0 {
+			out.RightHand.Depth = a.RightHand.Depth + (b.RightHand.Depth-a.RightHand.Depth)*t
+		}
+	}
+	if a.Pose != nil && b.Pose != nil {
+		out.Pose.Landmarks = lerpLandmarks(a.Pose.Landmarks, b.Pose.Landmarks, t)
+	}
+	return out
+}
+
+// lerpLandmarks interpolates landmark lists of equal length, or copies b.
+func lerpLandmarks(a, b []Landmark, t float64) []Landmark {
+	if len(a) != len(b) {
+		return copyLandmarks(b)
+	}
+	out := make([]Landmark, len(b))
+	for i := range b {
+		out[i] = Landmark{
+			Point:      lerpPoint(a[i].Point, b[i].Point, t),
+			Visibility: a[i].Visibility + (b[i].Visibility-a[i].Visibility)*t,
+			Presence:   a[i].Presence + (b[i].Presence-a[i].Presence)*t,
+		}
+	}
+	return out
+}
+
+// lerpPoint returns a + (b-a)*t.
+func lerpPoint(a, b Point3D, t float64) Point3D {
+	return a.Add(b.Sub(a).Scale(t))
+}
+
+// InterpolatingSender upsamples tracking output to a higher send rate, for
+// receivers that render faster than the camera runs. It wraps another
+// Sender and, at the configured rate, sends frames interpolated between
+// the last two frames it received (see InterpolateTrackingData).
+//
+// Interpolating needs the next frame, so output runs one camera interval
+// behind. The camera interval is measured from the frames' arrival; while
+// it is unknown (the first frame) or the camera stalls, frames are passed
+// through unchanged as they arrive. Frames sent to the target are numbered
+// sequentially.
+type InterpolatingSender struct {
+	target   Sender
+	interval time.Duration
+	clock    Clock
+
+	mu         sync.Mutex
+	prev       *TrackingData
+	latest     *TrackingData
+	prevAt     time.Time
+	latestAt   time.Time
+	caughtUp   bool  // The latest frame has been sent; wait for the next
+	err        error // Last error from a background send, returned by Send
+	ticker     Ticker
+	done       chan struct{}
+	wg         sync.WaitGroup
+	closed     bool
+	sendMu     sync.Mutex // Serializes sends to target
+	frameCount uint64
+}
+
+// NewInterpolatingSender creates a sender that sends to target at rate
+// frames per second.
+func NewInterpolatingSender(target Sender, rate int) (*InterpolatingSender, error) {
+	if target == nil {
+		return nil, errors.New("interpolating sender needs a target sender")
+	}
+	if rate <= 0 {
+		return nil, fmt.Errorf("send rate must be positive, got %d", rate)
+	}
+	return &InterpolatingSender{
+		target:   target,
+		interval: time.Second / time.Duration(rate),
+		clock:    realClock{},
+		done:     make(chan struct{}),
+	}, nil
+}
+
+// Send records a processed frame. It returns the error of the last failed
+// send to the target since the previous call, if any.
+func (s *InterpolatingSender) Send(data *TrackingData) error {
+	s.mu.Lock()
+	if s.closed {
+		s.mu.Unlock()
+		return errors.New("sender is closed")
+	}
+
+	now := s.clock.Now()
+	s.prev, s.prevAt = s.latest, s.latestAt
+	s.latest, s.latestAt = data.Clone(), now
+	s.caughtUp = false
+	passThrough := !s.interpolating()
+	if passThrough {
+		s.caughtUp = true
+	}
+	if s.ticker == nil {
+		s.ticker = s.clock.NewTicker(s.interval)
+		s.wg.Add(1)
+		go s.run(s.ticker)
+	}
+	err := s.err
+	s.err = nil
+	s.mu.Unlock()
+
+	if passThrough {
+		if sendErr := s.send(data); sendErr != nil {
+			return sendErr
+		}
+	}
+	return err
+}
+
+// interpolating reports whether the camera interval is known.
+// Must be called with s.mu held.
+func (s *InterpolatingSender) interpolating() bool {
+	if s.prev == nil {
+		return false
+	}
+	gap := s.latestAt.Sub(s.prevAt)
+	return gap > 0 && gap <= maxInterpolationInterval
+}
+
+// run sends an interpolated frame on every tick.
+func (s *InterpolatingSender) run(ticker Ticker) {
+	defer s.wg.Done()
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-s.done:
+			return
+		case <-ticker.C():
+			if data := s.next(); data != nil {
+				if err := s.send(data); err != nil {
+					s.mu.Lock()
+					s.err = err
+					s.mu.Unlock()
+				}
+			}
+		}
+	}
+}
+
+// next returns the frame to send now, or nil if there is nothing new.
+func (s *InterpolatingSender) next() *TrackingData {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.caughtUp || !s.interpolating() {
+		return nil
+	}
+	gap := s.latestAt.Sub(s.prevAt)
+	t := float64(s.clock.Now().Sub(s.latestAt)) / float64(gap)
+	if t >= 1 {
+		t = 1
+		s.caughtUp = true
+	}
+	return InterpolateTrackingData(s.prev, s.latest, math.Max(t, 0))
+}
+
+// send forwards data to the target with its own frame numbering.
+func (s *InterpolatingSender) send(data *TrackingData) error {
+	s.sendMu.Lock()
+	defer s.sendMu.Unlock()
+
+	s.frameCount++
+	out := *data
+	out.FrameNumber = s.frameCount
+	return s.target.Send(&out)
+}
+
+// Close stops interpolating and closes the target sender.
+func (s *InterpolatingSender) Close() error {
+	s.mu.Lock()
+	if s.closed {
+		s.mu.Unlock()
+		return nil
+	}
+	s.closed = true
+	close(s.done)
+	s.mu.Unlock()
+
+	s.wg.Wait()
+	return s.target.Close()
+}
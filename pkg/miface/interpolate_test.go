@@ -0,0 +1,165 @@
+package miface
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+// quatDistance returns the angle between rotations a and b.
+func quatDistance(a, b Quaternion) float64 {
+	d := math.Abs(a.X*b.X + a.Y*b.Y + a.Z*b.Z + a.W*b.W)
+	return 2 * math.Acos(math.Min(d, 1))
+}
+
+func TestInterpolateTrackingData(t *testing.T) {
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	a := &TrackingData{
+		Timestamp: start,
+		Face: &FaceData{
+			HeadRotation: QuaternionFromEuler(0.1, -0.4, 0),
+			HeadPosition: Point3D{X: 0.1},
+			BlendShapes:  map[string]float64{"jawOpen": 0.2, "mouthSmileLeft": 0.5},
+		},
+		LeftHand: testHand(true, 1),
+	}
+	b := &TrackingData{
+		Timestamp: start.Add(40 * time.Millisecond),
+		Face: &FaceData{
+			HeadRotation: QuaternionFromEuler(-0.2, 0.6, 0.3),
+			HeadPosition: Point3D{X: 0.3},
+			BlendShapes:  map[string]float64{"jawOpen": 0.6, "eyeBlinkLeft": 1},
+		},
+		LeftHand: testHand(true, 1),
+	}
+	b.LeftHand.Landmarks[0].Point.Y += 0.1
+
+	mid := InterpolateTrackingData(a, b, 0.25)
+
+	// The head rotation lies on the SLERP arc, a quarter of the way along
+	qa, qb, qm := a.Face.HeadRotation, b.Face.HeadRotation, mid.Face.HeadRotation
+	total := quatDistance(qa, qb)
+	if d := quatDistance(qa, qm) + quatDistance(qm, qb); math.Abs(d-total) > 1e-9 {
+		t.Errorf("expected rotation on the arc (%v), got detour %v", total, d)
+	}
+	if d := quatDistance(qa, qm); math.Abs(d-total/4) > 1e-9 {
+		t.Errorf("expected a quarter of the arc %v, got %v", total/4, d)
+	}
+
+	if want := start.Add(10 * time.Millisecond); !mid.Timestamp.Equal(want) {
+		t.Errorf("expected timestamp %v, got %v", want, mid.Timestamp)
+	}
+	if math.Abs(mid.Face.HeadPosition.X-0.15) > 1e-9 {
+		t.Errorf("expected head X 0.15, got %v", mid.Face.HeadPosition.X)
+	}
+	if math.Abs(mid.Face.BlendShapes["jawOpen"]-0.3) > 1e-9 {
+		t.Errorf("expected jawOpen 0.3, got %v", mid.Face.BlendShapes["jawOpen"])
+	}
+	if mid.Face.BlendShapes["eyeBlinkLeft"] != 1 {
+		t.Errorf("expected eyeBlinkLeft from the newer frame, got %v", mid.Face.BlendShapes["eyeBlinkLeft"])
+	}
+	if _, ok := mid.Face.BlendShapes["mouthSmileLeft"]; ok {
+		t.Error("expected blend shapes missing from the newer frame to be dropped")
+	}
+	want := a.LeftHand.Landmarks[0].Point.Y + 0.025
+	if got := mid.LeftHand.Landmarks[0].Point.Y; math.Abs(got-want) > 1e-9 {
+		t.Errorf("expected wrist Y %v, got %v", want, got)
+	}
+
+	mid.Face.BlendShapes["jawOpen"] = 0
+	if b.Face.BlendShapes["jawOpen"] != 0.6 {
+		t.Error("expected the result not to share maps with its inputs")
+	}
+}
+
+func TestSlerpShortestArc(t *testing.T) {
+	a := QuaternionFromEuler(0, 0.2, 0)
+	b := QuaternionFromEuler(0, 0.4, 0)
+	negB := Quaternion{X: -b.X, Y: -b.Y, Z: -b.Z, W: -b.W}
+
+	m := Slerp(a, negB, 0.5)
+	if d := quatDistance(m, QuaternionFromEuler(0, 0.3, 0)); d > 1e-9 {
+		t.Errorf("expected the short way round, off by %v", d)
+	}
+}
+
+// chanSender delivers sent frames on a channel.
+type chanSender struct {
+	ch chan *TrackingData
+}
+
+func (s *chanSender) Send(data *TrackingData) error {
+	s.ch <- data
+	return nil
+}
+
+func (s *chanSender) Close() error { return nil }
+
+func TestInterpolatingSender(t *testing.T) {
+	target := &chanSender{ch: make(chan *TrackingData, 10)}
+	sender, err := NewInterpolatingSender(target, 50)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	clock := NewFakeClock(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC))
+	sender.clock = clock
+	defer sender.Close()
+
+	receive := func() *TrackingData {
+		t.Helper()
+		select {
+		case data := <-target.ch:
+			return data
+		case <-time.After(time.Second):
+			t.Fatal("timeout waiting for frame")
+			return nil
+		}
+	}
+	expectNone := func() {
+		t.Helper()
+		time.Sleep(10 * time.Millisecond)
+		select {
+		case data := <-target.ch:
+			t.Errorf("expected no frame, got %+v", data.Face)
+		default:
+		}
+	}
+
+	a := &TrackingData{Face: &FaceData{HeadPosition: Point3D{X: 0}, HeadRotation: Quaternion{W: 1}}}
+	b := &TrackingData{Face: &FaceData{HeadPosition: Point3D{X: 1}, HeadRotation: Quaternion{W: 1}}}
+
+	// The camera rate is unknown for the first frame: passed through
+	if err := sender.Send(a); err != nil {
+		t.Fatalf("send failed: %v", err)
+	}
+	if got := receive(); got.Face.HeadPosition.X != 0 {
+		t.Errorf("expected the first frame passed through, got X %v", got.Face.HeadPosition.X)
+	}
+	clock.Advance(20 * time.Millisecond)
+	clock.Advance(20 * time.Millisecond)
+	expectNone()
+
+	// 40ms camera interval, 20ms send interval: one frame in between
+	if err := sender.Send(b); err != nil {
+		t.Fatalf("send failed: %v", err)
+	}
+	clock.Advance(20 * time.Millisecond)
+	if got := receive(); math.Abs(got.Face.HeadPosition.X-0.5) > 1e-9 {
+		t.Errorf("expected interpolated X 0.5, got %v", got.Face.HeadPosition.X)
+	}
+	clock.Advance(20 * time.Millisecond)
+	if got := receive(); got.Face.HeadPosition.X != 1 {
+		t.Errorf("expected the latest frame, got X %v", got.Face.HeadPosition.X)
+	}
+	clock.Advance(20 * time.Millisecond)
+	expectNone()
+}
+
+func TestNewInterpolatingSenderInvalid(t *testing.T) {
+	if _, err := NewInterpolatingSender(nil, 60); err == nil {
+		t.Error("expected error without a target")
+	}
+	if _, err := NewInterpolatingSender(&recordingSender{}, 0); err == nil {
+		t.Error("expected error with a zero rate")
+	}
+}
@@ -1,6 +1,7 @@
 package miface
 
 import (
+	"math"
 	"sync"
 )
 
@@ -25,25 +26,60 @@ type KalmanFilter struct {
 // smoothingFactor controls the trade-off between smoothness and responsiveness:
 //   - 0.0 = maximum smoothing (slow response)
 //   - 1.0 = no smoothing (instant response)
+//
+// It is a convenience for NewKalmanFilterQR with q = 0.1 and r running from
+// 1.1 (factor 0) down to 0.2 (factor 1).
 func NewKalmanFilter(smoothingFactor float64) *KalmanFilter {
 	// Map smoothing factor to process/measurement noise ratio
 	// Lower smoothing factor = higher R (more trust in prediction)
 	// Higher smoothing factor = lower R (more trust in measurement)
 	q := 0.1                                    // Process noise
-	r := 1.0 - smoothingFactor*0.9 + 0.1        // Measurement noise (0.1 to 1.0)
+	r := 1.0 - smoothingFactor*0.9 + 0.1        // Measurement noise (1.1 down to 0.2)
 
+	return NewKalmanFilterQR(q, r)
+}
+
+// NewKalmanFilterQR creates a Kalman filter with explicit noise variances.
+// Only the ratio of q to r matters for the steady state: the filter settles
+// on a gain of roughly sqrt(q/r) for small ratios, so raising r (or lowering
+// q) smooths more and lags more, and r much smaller than q follows the
+// measurements almost exactly. Negative values are treated as 0.
+func NewKalmanFilterQR(q, r float64) *KalmanFilter {
 	return &KalmanFilter{
 		p: 1.0, // Initial uncertainty
-		q: q,
-		r: r,
+		q: math.Max(q, 0),
+		r: math.Max(r, 0),
 	}
 }
 
+// SetProcessNoise sets the process noise variance q: how much the true
+// value is expected to change between updates. Negative values are treated
+// as 0. Can be called while the filter is in use.
+func (kf *KalmanFilter) SetProcessNoise(q float64) {
+	kf.mu.Lock()
+	defer kf.mu.Unlock()
+	kf.q = math.Max(q, 0)
+}
+
+// SetMeasurementNoise sets the measurement noise variance r: how noisy the
+// measurements are. Negative values are treated as 0. Can be called while
+// the filter is in use.
+func (kf *KalmanFilter) SetMeasurementNoise(r float64) {
+	kf.mu.Lock()
+	defer kf.mu.Unlock()
+	kf.r = math.Max(r, 0)
+}
+
 // Update processes a new measurement and returns the filtered value.
 func (kf *KalmanFilter) Update(measurement float64) float64 {
 	kf.mu.Lock()
 	defer kf.mu.Unlock()
+	return kf.update(measurement, kf.q, kf.r)
+}
 
+// update runs one filter step with process noise q and measurement noise r.
+// Must be called with kf.mu held.
+func (kf *KalmanFilter) update(measurement, q, r float64) float64 {
 	if !kf.initialized {
 		kf.x = measurement
 		kf.initialized = true
@@ -53,11 +89,15 @@ func (kf *KalmanFilter) Update(measurement float64) float64 {
 	// Prediction step
 	// x_pred = x (assuming constant velocity model with no control input)
 	// p_pred = p + q
-	pPred := kf.p + kf.q
+	pPred := kf.p + q
 
 	// Update step
 	// Kalman gain: k = p_pred / (p_pred + r)
-	k := pPred / (pPred + kf.r)
+	// (with no noise at all, trust the measurement)
+	k := 1.0
+	if pPred+r > 0 {
+		k = pPred / (pPred + r)
+	}
 
 	// State update: x = x_pred + k * (measurement - x_pred)
 	kf.x = kf.x + k*(measurement-kf.x)
@@ -68,6 +108,39 @@ func (kf *KalmanFilter) Update(measurement float64) float64 {
 	return kf.x
 }
 
+// UpdateDt processes a measurement taken dt seconds after the previous one.
+// q and r are taken as the noise over one FilterReferenceInterval: the
+// process noise grows with dt and the measurement noise shrinks with it, as
+// each measurement then stands for a longer stretch of the signal. This
+// keeps the smoothing per second roughly constant across frame rates. A dt
+// of 0 or less is treated as one reference interval, like Update.
+func (kf *KalmanFilter) UpdateDt(measurement, dt float64) float64 {
+	s := timeScale(dt)
+	if s == 0 {
+		return kf.Update(measurement)
+	}
+
+	kf.mu.Lock()
+	defer kf.mu.Unlock()
+	return kf.update(measurement, kf.q*s, kf.r/s)
+}
+
+// tune copies the noise variances of other if it is a KalmanFilter.
+func (kf *KalmanFilter) tune(other Filter) bool {
+	o, ok := other.(*KalmanFilter)
+	if !ok {
+		return false
+	}
+	o.mu.Lock()
+	q, r := o.q, o.r
+	o.mu.Unlock()
+
+	kf.mu.Lock()
+	defer kf.mu.Unlock()
+	kf.q, kf.r = q, r
+	return true
+}
+
 // Reset clears the filter state.
 func (kf *KalmanFilter) Reset() {
 	kf.mu.Lock()
@@ -85,6 +158,125 @@ func (kf *KalmanFilter) State() float64 {
 	return kf.x
 }
 
+// KalmanFilterCV is a 1D Kalman filter with a constant-velocity model: it
+// estimates both position and velocity, so unlike KalmanFilter it follows
+// steady motion without lag, and Predict can extrapolate a short way ahead
+// to offset pipeline latency.
+//
+// Changes in velocity are modelled as white-noise acceleration with
+// spectral density q; r is the measurement noise variance. As with
+// KalmanFilter, a larger r relative to q smooths more.
+type KalmanFilterCV struct {
+	mu sync.Mutex
+
+	// State estimate: position and velocity (per second)
+	x, v float64
+	// Estimate covariance
+	pxx, pxv, pvv float64
+	// Process noise spectral density
+	q float64
+	// Measurement noise variance
+	r float64
+	// Initialized flag
+	initialized bool
+}
+
+// NewKalmanFilterCV creates a constant-velocity Kalman filter with process
+// noise density q and measurement noise variance r. Negative values are
+// treated as 0.
+func NewKalmanFilterCV(q, r float64) *KalmanFilterCV {
+	kf := &KalmanFilterCV{
+		q: math.Max(q, 0),
+		r: math.Max(r, 0),
+	}
+	kf.resetCovariance()
+	return kf
+}
+
+// resetCovariance sets the initial uncertainty: the first measurement pins
+// the position, but nothing is known about the velocity yet.
+// Must be called with kf.mu held.
+func (kf *KalmanFilterCV) resetCovariance() {
+	kf.pxx, kf.pxv, kf.pvv = 1.0, 0, 1.0
+}
+
+// Update processes a measurement taken dt seconds after the previous one
+// and returns the filtered position. A dt of 0 or less updates without
+// predicting motion.
+func (kf *KalmanFilterCV) Update(measurement, dt float64) float64 {
+	kf.mu.Lock()
+	defer kf.mu.Unlock()
+
+	if !kf.initialized {
+		kf.x = measurement
+		kf.initialized = true
+		return measurement
+	}
+
+	// Prediction step
+	// x_pred = F x with F = [1 dt; 0 1]
+	// P_pred = F P Fᵀ + q [dt³/3 dt²/2; dt²/2 dt]
+	if dt > 0 {
+		kf.x += kf.v * dt
+		kf.pxx += 2*dt*kf.pxv + dt*dt*kf.pvv + kf.q*dt*dt*dt/3
+		kf.pxv += dt*kf.pvv + kf.q*dt*dt/2
+		kf.pvv += kf.q * dt
+	}
+
+	// Update step, measuring position only
+	// Kalman gain: k = P Hᵀ / (pxx + r)
+	// (with no noise at all, trust the measurement)
+	s := kf.pxx + kf.r
+	if s <= 0 {
+		kf.x = measurement
+		return kf.x
+	}
+	kx, kv := kf.pxx/s, kf.pxv/s
+	innovation := measurement - kf.x
+	kf.x += kx * innovation
+	kf.v += kv * innovation
+
+	// Covariance update: P = (I - K H) P
+	pxx, pxv, pvv := kf.pxx, kf.pxv, kf.pvv
+	kf.pxx = (1 - kx) * pxx
+	kf.pxv = (1 - kx) * pxv
+	kf.pvv = pvv - kv*pxv
+
+	return kf.x
+}
+
+// Predict returns the position extrapolated horizon seconds past the last
+// update along the estimated velocity.
+func (kf *KalmanFilterCV) Predict(horizon float64) float64 {
+	kf.mu.Lock()
+	defer kf.mu.Unlock()
+	return kf.x + kf.v*horizon
+}
+
+// Velocity returns the current velocity estimate, per second.
+func (kf *KalmanFilterCV) Velocity() float64 {
+	kf.mu.Lock()
+	defer kf.mu.Unlock()
+	return kf.v
+}
+
+// State returns the current position estimate.
+func (kf *KalmanFilterCV) State() float64 {
+	kf.mu.Lock()
+	defer kf.mu.Unlock()
+	return kf.x
+}
+
+// Reset clears the filter state.
+func (kf *KalmanFilterCV) Reset() {
+	kf.mu.Lock()
+	defer kf.mu.Unlock()
+
+	kf.x, kf.v = 0, 0
+	kf.resetCovariance()
+	kf.initialized = false
+}
+
 // KalmanFilter3D applies Kalman filtering to 3D points.
 type KalmanFilter3D struct {
 	x, y, z *KalmanFilter
@@ -115,45 +307,219 @@ func (kf *KalmanFilter3D) Reset() {
 	kf.z.Reset()
 }
 
-// LandmarkSmoother manages Kalman filters for a set of landmarks.
+// LandmarkSmoother manages per-landmark 3D filters for a set of landmarks.
+// By default it uses Kalman filters; see NewLandmarkSmootherWithFilter.
 type LandmarkSmoother struct {
-	mu      sync.RWMutex
-	filters map[int]*KalmanFilter3D
-	factor  float64
+	mu        sync.Mutex
+	filters   []*Filter3D // Indexed by landmark; created when first seen
+	factor    float64
+	newFilter FilterFactory
+
+	// Optional dead zone applied before filtering (0 = disabled)
+	deadZone  float64
+	deadZones []*DeadZoneFilter3D
+
+	// Optional warm-up from a neutral pose (0 frames = disabled)
+	warmupFrames  int
+	warmupNeutral []Point3D
+	warmupSeen    []int // Frames seen per landmark since lock-on
+
+	// Optional visibility floor (0 = disabled) and the last output of
+	// each landmark, held while it is below the floor
+	visibilityFloor float64
+	last            []Point3D
+	hasLast         []bool
 }
 
 // NewLandmarkSmoother creates a new landmark smoother with the given smoothing factor.
 func NewLandmarkSmoother(smoothingFactor float64) *LandmarkSmoother {
 	return &LandmarkSmoother{
-		filters: make(map[int]*KalmanFilter3D),
-		factor:  smoothingFactor,
+		factor:    smoothingFactor,
+		newFilter: KalmanFilterFactory(smoothingFactor),
 	}
 }
 
-// Smooth applies Kalman filtering to a slice of landmarks.
+// NewLandmarkSmootherWithFilter creates a landmark smoother that uses
+// newFilter to create the filter for each landmark axis, e.g.
+// DoubleExponentialFilterFactory for predictive tracking.
+func NewLandmarkSmootherWithFilter(newFilter FilterFactory) *LandmarkSmoother {
+	return &LandmarkSmoother{
+		newFilter: newFilter,
+	}
+}
+
+// SetSmoothingFactor switches the smoother to Kalman filters with the given
+// smoothing factor, as created by NewLandmarkSmoother, without dropping its
+// state; see SetFilter.
+func (ls *LandmarkSmoother) SetSmoothingFactor(smoothingFactor float64) {
+	ls.mu.Lock()
+	defer ls.mu.Unlock()
+
+	ls.factor = smoothingFactor
+	ls.setFilter(KalmanFilterFactory(smoothingFactor))
+}
+
+// SetFilter switches the smoother to filters created by newFilter without
+// dropping its state, so it can be retuned while tracking: existing filters
+// of the same kind take over the new parameters, and filters of another
+// kind are replaced by new ones that continue from the last smoothed
+// position.
+func (ls *LandmarkSmoother) SetFilter(newFilter FilterFactory) {
+	ls.mu.Lock()
+	defer ls.mu.Unlock()
+
+	ls.factor = 0
+	ls.setFilter(newFilter)
+}
+
+// setFilter retunes the existing filters to newFilter and uses it for new
+// landmarks.
+// Must be called with ls.mu held.
+func (ls *LandmarkSmoother) setFilter(newFilter FilterFactory) {
+	ls.newFilter = newFilter
+	for i, f := range ls.filters {
+		f.retune(newFilter, ls.last[i], ls.hasLast[i])
+	}
+}
+
+// SetDeadZone enables a dead zone of the given radius in front of the
+// smoothing filters: landmark movement within the radius is ignored, which
+// keeps the output perfectly still while the subject is still.
+// A radius of 0 disables the dead zone.
+func (ls *LandmarkSmoother) SetDeadZone(radius float64) {
+	ls.mu.Lock()
+	defer ls.mu.Unlock()
+
+	ls.deadZone = radius
+	ls.deadZones = nil
+}
+
+// SetWarmup makes lock-on gradual: for the first frames after a landmark is
+// first seen (or after Reset), the output blends from neutral[i] toward the
+// filtered value, reaching it fully on frame number frames. Landmarks without
+// a neutral entry start from the image center. frames <= 1 disables warm-up.
+func (ls *LandmarkSmoother) SetWarmup(frames int, neutral []Point3D) {
+	ls.mu.Lock()
+	defer ls.mu.Unlock()
+
+	ls.warmupFrames = frames
+	ls.warmupNeutral = append([]Point3D(nil), neutral...)
+	clear(ls.warmupSeen)
+}
+
+// SetVisibilityFloor makes the smoother treat landmarks whose Visibility is
+// below floor as not present: they are not fed to their filters, and their
+// output holds the last smoothed position with Visibility and Presence 0,
+// so later stages skip them. A landmark below the floor before it was
+// ever smoothed passes through unfiltered. A floor of 0 disables it.
+func (ls *LandmarkSmoother) SetVisibilityFloor(floor float64) {
+	ls.mu.Lock()
+	defer ls.mu.Unlock()
+
+	ls.visibilityFloor = floor
+}
+
+// warmup blends a filtered point for landmark i from its neutral value.
+// Must be called with ls.mu held.
+func (ls *LandmarkSmoother) warmup(i int, point Point3D) Point3D {
+	if ls.warmupFrames <= 1 {
+		return point
+	}
+
+	seen := ls.warmupSeen[i] + 1
+	if seen > ls.warmupFrames {
+		return point
+	}
+	ls.warmupSeen[i] = seen
+
+	neutral := Point3D{X: 0.5, Y: 0.5}
+	if i < len(ls.warmupNeutral) {
+		neutral = ls.warmupNeutral[i]
+	}
+	w := float64(seen) / float64(ls.warmupFrames)
+	return Point3D{
+		X: neutral.X + (point.X-neutral.X)*w,
+		Y: neutral.Y + (point.Y-neutral.Y)*w,
+		Z: neutral.Z + (point.Z-neutral.Z)*w,
+	}
+}
+
+// grow makes room for the state of n landmarks. Filters for new landmarks
+// are created fresh; existing ones are kept.
+// Must be called with ls.mu held.
+func (ls *LandmarkSmoother) grow(n int) {
+	for i := len(ls.filters); i < n; i++ {
+		ls.filters = append(ls.filters, NewFilter3D(ls.newFilter))
+		ls.warmupSeen = append(ls.warmupSeen, 0)
+		ls.last = append(ls.last, Point3D{})
+		ls.hasLast = append(ls.hasLast, false)
+	}
+	if ls.deadZone > 0 {
+		for i := len(ls.deadZones); i < n; i++ {
+			ls.deadZones = append(ls.deadZones, NewDeadZoneFilter3D(ls.deadZone))
+		}
+	}
+}
+
+// Smooth applies filtering to a slice of landmarks and returns the result
+// in a new slice.
 func (ls *LandmarkSmoother) Smooth(landmarks []Landmark) []Landmark {
 	if len(landmarks) == 0 {
 		return landmarks
 	}
+	return ls.SmoothInto(make([]Landmark, len(landmarks)), landmarks)
+}
+
+// SmoothInto is like Smooth but writes the result into dst, which is grown
+// if it is too short, and returns dst[:len(src)]. dst may be src, to smooth
+// in place. Once every landmark has been seen, smoothing into a large
+// enough dst does not allocate.
+func (ls *LandmarkSmoother) SmoothInto(dst, src []Landmark) []Landmark {
+	return ls.SmoothIntoDt(dst, src, 0)
+}
+
+// SmoothIntoDt is like SmoothInto for landmarks measured dt seconds after
+// the previous call, so that filters with an UpdateDt method smooth the
+// same per second at any frame rate. A dt of 0 or less is treated as one
+// FilterReferenceInterval, like SmoothInto.
+func (ls *LandmarkSmoother) SmoothIntoDt(dst, src []Landmark, dt float64) []Landmark {
+	if cap(dst) < len(src) {
+		dst = make([]Landmark, len(src))
+	}
+	dst = dst[:len(src)]
+	if len(src) == 0 {
+		return dst
+	}
 
 	ls.mu.Lock()
 	defer ls.mu.Unlock()
 
-	result := make([]Landmark, len(landmarks))
-	for i, lm := range landmarks {
-		filter, ok := ls.filters[i]
-		if !ok {
-			filter = NewKalmanFilter3D(ls.factor)
-			ls.filters[i] = filter
+	ls.grow(len(src))
+	for i, lm := range src {
+		if lm.Visibility < ls.visibilityFloor {
+			point := lm.Point
+			if ls.hasLast[i] {
+				point = ls.last[i]
+			}
+			dst[i] = Landmark{Point: point}
+			continue
+		}
+
+		point := lm.Point
+		if ls.deadZone > 0 {
+			point = ls.deadZones[i].Update(point)
 		}
+		point = ls.warmup(i, ls.filters[i].UpdateDt(point, dt))
+		ls.last[i], ls.hasLast[i] = point, true
 
-		result[i] = Landmark{
-			Point:      filter.Update(lm.Point),
+		dst[i] = Landmark{
+			Point:      point,
 			Visibility: lm.Visibility,
+			Presence:   lm.Presence,
 		}
 	}
 
-	return result
+	return dst
 }
 
 // Reset clears all landmark filters.
@@ -164,4 +530,93 @@ func (ls *LandmarkSmoother) Reset() {
 	for _, f := range ls.filters {
 		f.Reset()
 	}
+	for _, dz := range ls.deadZones {
+		dz.Reset()
+	}
+	clear(ls.warmupSeen)
+	clear(ls.hasLast)
+}
+
+// BlendShapeSmoother manages per-name filters for blend shape weights.
+// A blend shape missing from a frame loses its filter, so when it reappears
+// it starts again from its new value instead of the stale one.
+// By default it uses Kalman filters; see NewBlendShapeSmootherWithFilter.
+type BlendShapeSmoother struct {
+	mu        sync.Mutex
+	filters   map[string]Filter
+	last      map[string]float64 // Last filter output per name
+	newFilter FilterFactory
+}
+
+// NewBlendShapeSmoother creates a new blend shape smoother with the given smoothing factor.
+func NewBlendShapeSmoother(smoothingFactor float64) *BlendShapeSmoother {
+	return NewBlendShapeSmootherWithFilter(KalmanFilterFactory(smoothingFactor))
+}
+
+// NewBlendShapeSmootherWithFilter creates a blend shape smoother that uses
+// newFilter to create the filter for each blend shape.
+func NewBlendShapeSmootherWithFilter(newFilter FilterFactory) *BlendShapeSmoother {
+	return &BlendShapeSmoother{
+		filters:   make(map[string]Filter),
+		last:      make(map[string]float64),
+		newFilter: newFilter,
+	}
+}
+
+// SetFilter switches the smoother to filters created by newFilter without
+// dropping its state, like LandmarkSmoother.SetFilter.
+func (bs *BlendShapeSmoother) SetFilter(newFilter FilterFactory) {
+	bs.mu.Lock()
+	defer bs.mu.Unlock()
+
+	bs.newFilter = newFilter
+	for name, f := range bs.filters {
+		bs.filters[name] = retuneFilter(f, newFilter, bs.last[name], true)
+	}
+}
+
+// Smooth returns a copy of shapes with every weight filtered and clamped
+// to 0.0-1.0.
+func (bs *BlendShapeSmoother) Smooth(shapes map[string]float64) map[string]float64 {
+	return bs.SmoothDt(shapes, 0)
+}
+
+// SmoothDt is like Smooth for weights measured dt seconds after the previous
+// call; see LandmarkSmoother.SmoothIntoDt.
+func (bs *BlendShapeSmoother) SmoothDt(shapes map[string]float64, dt float64) map[string]float64 {
+	bs.mu.Lock()
+	defer bs.mu.Unlock()
+
+	// Forget blend shapes that disappeared
+	for name := range bs.filters {
+		if _, ok := shapes[name]; !ok {
+			delete(bs.filters, name)
+			delete(bs.last, name)
+		}
+	}
+
+	if shapes == nil {
+		return nil
+	}
+
+	result := make(map[string]float64, len(shapes))
+	for name, value := range shapes {
+		filter, ok := bs.filters[name]
+		if !ok {
+			filter = bs.newFilter()
+			bs.filters[name] = filter
+		}
+		filtered := updateDt(filter, value, dt)
+		bs.last[name] = filtered
+		result[name] = clamp01(filtered)
+	}
+	return result
+}
+
+// Reset clears all blend shape filters.
+func (bs *BlendShapeSmoother) Reset() {
+	bs.mu.Lock()
+	defer bs.mu.Unlock()
+	bs.filters = make(map[string]Filter)
+	bs.last = make(map[string]float64)
 }
@@ -2,6 +2,7 @@ package miface
 
 import (
 	"math"
+	"math/rand"
 	"testing"
 )
 
@@ -10,6 +11,13 @@ func TestNewKalmanFilter(t *testing.T) {
 	if kf == nil {
 		t.Fatal("expected non-nil filter")
 	}
+
+	// r runs from 1.1 down to 0.2 as documented
+	for _, tt := range []struct{ factor, r float64 }{{0, 1.1}, {1, 0.2}} {
+		if got := NewKalmanFilter(tt.factor).r; math.Abs(got-tt.r) > 1e-9 {
+			t.Errorf("expected r %f for factor %f, got %f", tt.r, tt.factor, got)
+		}
+	}
 }
 
 func TestKalmanFilterUpdate(t *testing.T) {
@@ -128,6 +136,52 @@ func TestLandmarkSmootherReset(t *testing.T) {
 	}
 }
 
+func TestLandmarkSmootherSetFilterKeepsState(t *testing.T) {
+	rest := []Landmark{{Point: Point3D{X: 0}, Visibility: 1}}
+	step := []Landmark{{Point: Point3D{X: 1}, Visibility: 1}}
+
+	tests := []struct {
+		name   string
+		change func(*LandmarkSmoother)
+	}{
+		{"same kind", func(ls *LandmarkSmoother) { ls.SetSmoothingFactor(0.9) }},
+		{"other kind", func(ls *LandmarkSmoother) { ls.SetFilter(OneEuroFilterFactory(1, 0, 1)) }},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			smoother := NewLandmarkSmoother(0.1)
+			for i := 0; i < 5; i++ {
+				smoother.Smooth(rest)
+			}
+
+			tt.change(smoother)
+
+			// Fresh filters would pass the step straight through
+			got := smoother.Smooth(step)[0].Point.X
+			if got <= 0 || got >= 1 {
+				t.Errorf("expected the step to be smoothed from the old state, got %f", got)
+			}
+		})
+	}
+}
+
+func TestLandmarkSmootherSetSmoothingFactor(t *testing.T) {
+	rest := []Landmark{{Point: Point3D{X: 0}, Visibility: 1}}
+	step := []Landmark{{Point: Point3D{X: 1}, Visibility: 1}}
+
+	slow, fast := NewLandmarkSmoother(0.1), NewLandmarkSmoother(0.1)
+	for i := 0; i < 5; i++ {
+		slow.Smooth(rest)
+		fast.Smooth(rest)
+	}
+	fast.SetSmoothingFactor(1)
+
+	if s, f := slow.Smooth(step)[0].Point.X, fast.Smooth(step)[0].Point.X; f <= s {
+		t.Errorf("expected the raised factor to respond faster: slow=%f, fast=%f", s, f)
+	}
+}
+
 func TestLandmarkSmootherEmpty(t *testing.T) {
 	smoother := NewLandmarkSmoother(0.5)
 
@@ -194,3 +248,386 @@ func TestKalmanFilterSmoothingFactors(t *testing.T) {
 		})
 	}
 }
+
+func TestLandmarkSmootherPreservesScores(t *testing.T) {
+	smoother := NewLandmarkSmoother(0.5)
+
+	landmarks := []Landmark{
+		{Point: Point3D{X: 1, Y: 1, Z: 1}, Visibility: 0.9, Presence: 0.7},
+		{Point: Point3D{X: 2, Y: 2, Z: 2}, Visibility: 0.3, Presence: 0.1},
+	}
+
+	for i := 0; i < 3; i++ {
+		result := smoother.Smooth(landmarks)
+		for j, lm := range result {
+			if lm.Visibility != landmarks[j].Visibility {
+				t.Errorf("landmark %d: expected visibility %f, got %f", j, landmarks[j].Visibility, lm.Visibility)
+			}
+			if lm.Presence != landmarks[j].Presence {
+				t.Errorf("landmark %d: expected presence %f, got %f", j, landmarks[j].Presence, lm.Presence)
+			}
+		}
+	}
+}
+
+func TestLandmarkSmootherVisibilityFloor(t *testing.T) {
+	smoother := NewLandmarkSmoother(1.0)
+	smoother.SetVisibilityFloor(0.5)
+
+	seen := []Landmark{{Point: Point3D{X: 0.4, Y: 0.4}, Visibility: 0.9, Presence: 1}}
+	first := smoother.Smooth(seen)
+
+	// The landmark drops below the floor and jumps: the last smoothed
+	// position is held and marked not present
+	occluded := []Landmark{{Point: Point3D{X: 0.9, Y: 0.1}, Visibility: 0.2, Presence: 1}}
+	for i := 0; i < 3; i++ {
+		held := smoother.Smooth(occluded)
+		if held[0].Point != first[0].Point {
+			t.Errorf("frame %d: expected %+v held, got %+v", i, first[0].Point, held[0].Point)
+		}
+		if held[0].Visibility != 0 || held[0].Presence != 0 {
+			t.Errorf("frame %d: expected a held landmark to be not present, got %+v", i, held[0])
+		}
+	}
+
+	// Back above the floor it is smoothed from where it was held
+	if got := smoother.Smooth(seen); got[0].Visibility != 0.9 || got[0].Point.X > 0.5 {
+		t.Errorf("expected tracking to resume near the held position, got %+v", got[0])
+	}
+
+	// Never seen above the floor: passed through unfiltered
+	smoother.Reset()
+	if got := smoother.Smooth(occluded); got[0].Point != occluded[0].Point {
+		t.Errorf("expected an unseen landmark to pass through, got %+v", got[0].Point)
+	}
+}
+
+func TestLandmarkSmootherWarmup(t *testing.T) {
+	const frames = 5
+	smoother := NewLandmarkSmoother(1.0)
+	neutral := Point3D{X: 0.5, Y: 0.5}
+	smoother.SetWarmup(frames, []Point3D{neutral})
+
+	measurement := []Landmark{{Point: Point3D{X: 0.9, Y: 0.1}}}
+
+	first := smoother.Smooth(measurement)[0].Point
+	if dist3(first, neutral) >= dist3(first, measurement[0].Point) {
+		t.Errorf("expected first output %+v to be closer to neutral than to the measurement", first)
+	}
+
+	prev := first
+	var last Point3D
+	for i := 2; i <= frames; i++ {
+		last = smoother.Smooth(measurement)[0].Point
+		if dist3(last, measurement[0].Point) > dist3(prev, measurement[0].Point) {
+			t.Errorf("frame %d: expected output to approach the measurement", i)
+		}
+		prev = last
+	}
+
+	if dist3(last, measurement[0].Point) > 1e-6 {
+		t.Errorf("expected convergence by frame %d, got %+v", frames, last)
+	}
+
+	// Reset restarts the warm-up
+	smoother.Reset()
+	again := smoother.Smooth(measurement)[0].Point
+	if math.Abs(again.X-first.X) > 1e-9 || math.Abs(again.Y-first.Y) > 1e-9 {
+		t.Errorf("expected warm-up to restart after reset, got %+v", again)
+	}
+}
+
+func TestBlendShapeSmootherReducesNoise(t *testing.T) {
+	smoother := NewBlendShapeSmoother(0.5)
+	rng := rand.New(rand.NewSource(1))
+
+	var in, out []float64
+	for i := 0; i < 200; i++ {
+		value := 0.3 + (rng.Float64()-0.5)*0.2
+		result := smoother.Smooth(map[string]float64{"jawOpen": value})
+		in = append(in, value)
+		out = append(out, result["jawOpen"])
+	}
+
+	// Skip the first frames while the filter settles
+	if got, raw := variance(out[20:]), variance(in[20:]); got >= raw/2 {
+		t.Errorf("expected smoothed variance below %f, got %f", raw/2, got)
+	}
+
+	// A sustained open should still be tracked
+	var last float64
+	for i := 0; i < 60; i++ {
+		value := 0.9 + (rng.Float64()-0.5)*0.2
+		last = smoother.Smooth(map[string]float64{"jawOpen": value})["jawOpen"]
+	}
+	if math.Abs(last-0.9) > 0.1 {
+		t.Errorf("expected jawOpen to settle near 0.9, got %f", last)
+	}
+}
+
+func TestBlendShapeSmootherResetsOnReappear(t *testing.T) {
+	smoother := NewBlendShapeSmoother(0.5)
+
+	for i := 0; i < 10; i++ {
+		smoother.Smooth(map[string]float64{"jawOpen": 0, "eyeBlinkLeft": 0})
+	}
+
+	// jawOpen disappears for a frame, then comes back wide open
+	smoother.Smooth(map[string]float64{"eyeBlinkLeft": 0})
+	result := smoother.Smooth(map[string]float64{"jawOpen": 1, "eyeBlinkLeft": 1})
+
+	if result["jawOpen"] != 1 {
+		t.Errorf("expected reappearing jawOpen to restart at 1, got %f", result["jawOpen"])
+	}
+	if result["eyeBlinkLeft"] >= 1 {
+		t.Errorf("expected continuous eyeBlinkLeft to be smoothed, got %f", result["eyeBlinkLeft"])
+	}
+}
+
+func TestBlendShapeSmootherSetFilterKeepsState(t *testing.T) {
+	smoother := NewBlendShapeSmoother(0.1)
+	for i := 0; i < 5; i++ {
+		smoother.Smooth(map[string]float64{"jawOpen": 0})
+	}
+
+	smoother.SetFilter(DoubleExponentialFilterFactory(0.5, 0, 0))
+	result := smoother.Smooth(map[string]float64{"jawOpen": 1})
+
+	if got := result["jawOpen"]; got != 0.5 {
+		t.Errorf("expected the new filter to continue from 0 and reach 0.5, got %f", got)
+	}
+}
+
+func TestBlendShapeSmootherClamps(t *testing.T) {
+	smoother := NewBlendShapeSmoother(0.5)
+
+	result := smoother.Smooth(map[string]float64{"jawOpen": 1.5, "mouthClose": -0.2})
+	if result["jawOpen"] != 1 {
+		t.Errorf("expected jawOpen clamped to 1, got %f", result["jawOpen"])
+	}
+	if result["mouthClose"] != 0 {
+		t.Errorf("expected mouthClose clamped to 0, got %f", result["mouthClose"])
+	}
+
+	if smoother.Smooth(nil) != nil {
+		t.Error("expected nil blend shapes to stay nil")
+	}
+}
+
+// dist3 returns the Euclidean distance between two points.
+func dist3(a, b Point3D) float64 {
+	dx, dy, dz := a.X-b.X, a.Y-b.Y, a.Z-b.Z
+	return math.Sqrt(dx*dx + dy*dy + dz*dz)
+}
+
+func TestLandmarkSmootherSmoothInto(t *testing.T) {
+	a := NewLandmarkSmoother(0.5)
+	b := NewLandmarkSmoother(0.5)
+
+	var dst []Landmark
+	for frame := 0; frame < 5; frame++ {
+		landmarks := []Landmark{
+			{Point: Point3D{X: float64(frame), Y: 1, Z: 0.5}, Visibility: 0.9},
+			{Point: Point3D{X: 2, Y: float64(frame) * 0.5, Z: 1}, Presence: 0.7},
+		}
+		expected := a.Smooth(landmarks)
+		dst = b.SmoothInto(dst, landmarks)
+		for i := range expected {
+			if dst[i] != expected[i] {
+				t.Errorf("frame %d landmark %d: expected %v, got %v", frame, i, expected[i], dst[i])
+			}
+		}
+	}
+
+	// New landmarks get fresh filters; existing ones keep their state
+	landmarks := []Landmark{{Point: Point3D{X: 10}}, {Point: Point3D{X: 10}}, {Point: Point3D{X: 7}}}
+	dst = b.SmoothInto(dst, landmarks)
+	if dst[2].Point.X != 7 {
+		t.Errorf("expected new landmark to start at 7, got %f", dst[2].Point.X)
+	}
+	if dst[0].Point.X == 10 {
+		t.Error("expected existing landmark to stay filtered")
+	}
+}
+
+func TestLandmarkSmootherSmoothIntoDt(t *testing.T) {
+	plain := NewLandmarkSmoother(0.5)
+	reference := NewLandmarkSmoother(0.5)
+	slow := NewLandmarkSmoother(0.5)
+	shapes := NewBlendShapeSmoother(0.5)
+	slowShapes := NewBlendShapeSmoother(0.5)
+
+	for _, x := range []float64{0, 0, 1} {
+		landmarks := []Landmark{{Point: Point3D{X: x}}}
+		expected := plain.Smooth(landmarks)
+		got := reference.SmoothIntoDt(nil, landmarks, FilterReferenceInterval)
+		if got[0] != expected[0] {
+			t.Errorf("expected the reference interval to match SmoothInto (%v), got %v", expected[0], got[0])
+		}
+
+		// Three reference intervals let the step through further
+		slowX := slow.SmoothIntoDt(nil, landmarks, 3*FilterReferenceInterval)[0].Point.X
+		if x == 1 && (slowX <= got[0].Point.X || slowX >= 1) {
+			t.Errorf("expected a longer dt to follow the step further than %f, got %f", got[0].Point.X, slowX)
+		}
+
+		weight := shapes.Smooth(map[string]float64{"jawOpen": x})["jawOpen"]
+		slowWeight := slowShapes.SmoothDt(map[string]float64{"jawOpen": x}, 3*FilterReferenceInterval)["jawOpen"]
+		if x == 1 && (slowWeight <= weight || slowWeight >= 1) {
+			t.Errorf("expected a longer dt to follow the blend shape step further than %f, got %f", weight, slowWeight)
+		}
+	}
+}
+
+func TestLandmarkSmootherSmoothIntoInPlace(t *testing.T) {
+	smoother := NewLandmarkSmoother(0.5)
+	landmarks := make([]Landmark, 478)
+
+	result := smoother.SmoothInto(landmarks, landmarks)
+	if &result[0] != &landmarks[0] {
+		t.Error("expected smoothing into src to reuse it")
+	}
+
+	allocs := testing.AllocsPerRun(100, func() {
+		smoother.SmoothInto(landmarks, landmarks)
+	})
+	if allocs != 0 {
+		t.Errorf("expected no allocations once filters exist, got %v", allocs)
+	}
+}
+
+// benchmarkFaceLandmarks returns a face mesh's worth of landmarks.
+func benchmarkFaceLandmarks() []Landmark {
+	landmarks := make([]Landmark, 478)
+	for i := range landmarks {
+		landmarks[i].Point = Point3D{X: float64(i) / 478, Y: 0.5, Z: 0.1}
+	}
+	return landmarks
+}
+
+// BenchmarkLandmarkSmoother_Smooth measures smoothing into a new slice per frame.
+func BenchmarkLandmarkSmoother_Smooth(b *testing.B) {
+	smoother := NewLandmarkSmoother(0.5)
+	landmarks := benchmarkFaceLandmarks()
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		smoother.Smooth(landmarks)
+	}
+}
+
+// BenchmarkLandmarkSmoother_SmoothInto measures smoothing in place, as the tracker does.
+func BenchmarkLandmarkSmoother_SmoothInto(b *testing.B) {
+	smoother := NewLandmarkSmoother(0.5)
+	landmarks := benchmarkFaceLandmarks()
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		smoother.SmoothInto(landmarks, landmarks)
+	}
+}
+
+func TestKalmanFilterQR(t *testing.T) {
+	// Step from 0 to 1 and see how far each filter gets in 5 updates
+	step := func(kf *KalmanFilter) float64 {
+		kf.Update(0)
+		var x float64
+		for i := 0; i < 5; i++ {
+			x = kf.Update(1)
+		}
+		return x
+	}
+
+	if x := step(NewKalmanFilterQR(0.1, 1e-6)); math.Abs(x-1) > 1e-4 {
+		t.Errorf("expected small r to track the measurement, got %f", x)
+	}
+	if x := step(NewKalmanFilterQR(0.1, 100)); x > 0.2 {
+		t.Errorf("expected large r to smooth heavily, got %f", x)
+	}
+	if x := step(NewKalmanFilterQR(0, 0)); x != 1 {
+		t.Errorf("expected no noise to follow the measurement, got %f", x)
+	}
+}
+
+func TestKalmanFilterSetNoise(t *testing.T) {
+	kf := NewKalmanFilter(0.5)
+	kf.Update(0)
+
+	kf.SetMeasurementNoise(1e-9)
+	if x := kf.Update(1); math.Abs(x-1) > 1e-6 {
+		t.Errorf("expected tiny r to jump to the measurement, got %f", x)
+	}
+
+	kf.SetMeasurementNoise(1000)
+	kf.SetProcessNoise(1e-6)
+	if x := kf.Update(2); x > 1.01 {
+		t.Errorf("expected huge r to barely move, got %f", x)
+	}
+}
+
+func TestNewKalmanFilterMatchesQR(t *testing.T) {
+	a := NewKalmanFilter(0.3)
+	b := NewKalmanFilterQR(0.1, 1.0-0.3*0.9+0.1)
+	for _, m := range []float64{0, 1, 0.5, 2, -1} {
+		if x, y := a.Update(m), b.Update(m); x != y {
+			t.Errorf("expected %f, got %f", y, x)
+		}
+	}
+}
+
+func TestKalmanFilterCVRamp(t *testing.T) {
+	const (
+		dt       = 1.0 / 30
+		velocity = 0.5 // Units per second
+	)
+	rw := NewKalmanFilter(0.5)
+	cv := NewKalmanFilterCV(0.1, 0.9)
+
+	var rwLag, cvLag float64
+	for i := 0; i < 300; i++ {
+		m := velocity * float64(i) * dt
+		rwLag = m - rw.Update(m)
+		cvLag = m - cv.Update(m, dt)
+	}
+
+	if rwLag <= 0 {
+		t.Fatalf("expected the random-walk filter to lag a ramp, got lag %f", rwLag)
+	}
+	if math.Abs(cvLag) > rwLag/10 {
+		t.Errorf("expected constant-velocity lag well below %f, got %f", rwLag, cvLag)
+	}
+	if v := cv.Velocity(); math.Abs(v-velocity) > 0.01 {
+		t.Errorf("expected velocity %f, got %f", velocity, v)
+	}
+
+	// Prediction extrapolates along the ramp
+	next := velocity * 300 * dt
+	if p := cv.Predict(dt); math.Abs(p-next) > 0.01 {
+		t.Errorf("expected prediction %f, got %f", next, p)
+	}
+}
+
+func TestKalmanFilterCVReset(t *testing.T) {
+	kf := NewKalmanFilterCV(0.1, 0.5)
+	kf.Update(0, 0.1)
+	kf.Update(1, 0.1)
+	kf.Reset()
+
+	if x := kf.Update(5, 0.1); x != 5 {
+		t.Errorf("after reset, expected 5, got %f", x)
+	}
+	if v := kf.Velocity(); v != 0 {
+		t.Errorf("after reset, expected velocity 0, got %f", v)
+	}
+}
+
+func TestKalmanFilterCVNoDt(t *testing.T) {
+	kf := NewKalmanFilterCV(0.1, 0.5)
+	kf.Update(1, 0)
+	x := kf.Update(1, 0)
+	if math.IsNaN(x) || x != 1 {
+		t.Errorf("expected steady input to stay at 1, got %f", x)
+	}
+}
@@ -0,0 +1,190 @@
+package miface
+
+// MediaPipe hand landmark indices. Each finger runs from the knuckle at the
+// palm (CMC for the thumb, MCP for the others) to the tip.
+const (
+	HandWrist = iota
+	HandThumbCMC
+	HandThumbMCP
+	HandThumbIP
+	HandThumbTip
+	HandIndexMCP
+	HandIndexPIP
+	HandIndexDIP
+	HandIndexTip
+	HandMiddleMCP
+	HandMiddlePIP
+	HandMiddleDIP
+	HandMiddleTip
+	HandRingMCP
+	HandRingPIP
+	HandRingDIP
+	HandRingTip
+	HandPinkyMCP
+	HandPinkyPIP
+	HandPinkyDIP
+	HandPinkyTip
+
+	// HandLandmarkCount is the number of hand landmarks.
+	HandLandmarkCount
+)
+
+// MediaPipe pose landmark indices. Left and right are the subject's.
+const (
+	PoseNose = iota
+	PoseLeftEyeInner
+	PoseLeftEye
+	PoseLeftEyeOuter
+	PoseRightEyeInner
+	PoseRightEye
+	PoseRightEyeOuter
+	PoseLeftEar
+	PoseRightEar
+	PoseMouthLeft
+	PoseMouthRight
+	PoseLeftShoulder
+	PoseRightShoulder
+	PoseLeftElbow
+	PoseRightElbow
+	PoseLeftWrist
+	PoseRightWrist
+	PoseLeftPinky
+	PoseRightPinky
+	PoseLeftIndex
+	PoseRightIndex
+	PoseLeftThumb
+	PoseRightThumb
+	PoseLeftHip
+	PoseRightHip
+	PoseLeftKnee
+	PoseRightKnee
+	PoseLeftAnkle
+	PoseRightAnkle
+	PoseLeftHeel
+	PoseRightHeel
+	PoseLeftFootIndex
+	PoseRightFootIndex
+
+	// PoseLandmarkCount is the number of pose landmarks.
+	PoseLandmarkCount
+)
+
+// Frequently used MediaPipe face mesh landmark indices. Left and right are
+// the subject's.
+const (
+	FaceForehead = 10
+	FaceNoseTip  = 1
+	FaceChin     = 152
+
+	FaceRightEyeOuter = 33
+	FaceRightEyeInner = 133
+	FaceRightEyeUpper = 159 // Upper lid
+	FaceRightEyeLower = 145 // Lower lid
+	FaceLeftEyeInner  = 362
+	FaceLeftEyeOuter  = 263
+	FaceLeftEyeUpper  = 386 // Upper lid
+	FaceLeftEyeLower  = 374 // Lower lid
+
+	FaceMouthRight = 61
+	FaceMouthLeft  = 291
+	FaceUpperLip   = 13 // Inner edge
+	FaceLowerLip   = 14 // Inner edge
+
+	FaceRightIrisCenter = 468
+	FaceLeftIrisCenter  = 473
+
+	// FaceLandmarkCount is the number of face mesh landmarks with the
+	// refined iris landmarks.
+	FaceLandmarkCount = 478
+)
+
+// HandConnections are the hand bones: each joint connected to its parent,
+// from the wrist out to the finger tips. They form a tree rooted at
+// HandWrist.
+var HandConnections = [][2]int{
+	{HandWrist, HandThumbCMC}, {HandThumbCMC, HandThumbMCP}, {HandThumbMCP, HandThumbIP}, {HandThumbIP, HandThumbTip},
+	{HandWrist, HandIndexMCP}, {HandIndexMCP, HandIndexPIP}, {HandIndexPIP, HandIndexDIP}, {HandIndexDIP, HandIndexTip},
+	{HandWrist, HandMiddleMCP}, {HandMiddleMCP, HandMiddlePIP}, {HandMiddlePIP, HandMiddleDIP}, {HandMiddleDIP, HandMiddleTip},
+	{HandWrist, HandRingMCP}, {HandRingMCP, HandRingPIP}, {HandRingPIP, HandRingDIP}, {HandRingDIP, HandRingTip},
+	{HandWrist, HandPinkyMCP}, {HandPinkyMCP, HandPinkyPIP}, {HandPinkyPIP, HandPinkyDIP}, {HandPinkyDIP, HandPinkyTip},
+}
+
+// HandPalmConnections are the edges across the knuckles that, with
+// HandConnections, make up MediaPipe's drawn hand outline.
+var HandPalmConnections = [][2]int{
+	{HandIndexMCP, HandMiddleMCP}, {HandMiddleMCP, HandRingMCP}, {HandRingMCP, HandPinkyMCP},
+}
+
+// PoseConnections are MediaPipe's pose skeleton edges, head first.
+var PoseConnections = [][2]int{
+	// Head
+	{PoseNose, PoseLeftEyeInner}, {PoseLeftEyeInner, PoseLeftEye}, {PoseLeftEye, PoseLeftEyeOuter}, {PoseLeftEyeOuter, PoseLeftEar},
+	{PoseNose, PoseRightEyeInner}, {PoseRightEyeInner, PoseRightEye}, {PoseRightEye, PoseRightEyeOuter}, {PoseRightEyeOuter, PoseRightEar},
+	{PoseMouthLeft, PoseMouthRight},
+	// Torso
+	{PoseLeftShoulder, PoseRightShoulder}, {PoseLeftShoulder, PoseLeftHip}, {PoseRightShoulder, PoseRightHip}, {PoseLeftHip, PoseRightHip},
+	// Arms
+	{PoseLeftShoulder, PoseLeftElbow}, {PoseLeftElbow, PoseLeftWrist},
+	{PoseLeftWrist, PoseLeftPinky}, {PoseLeftWrist, PoseLeftIndex}, {PoseLeftWrist, PoseLeftThumb}, {PoseLeftPinky, PoseLeftIndex},
+	{PoseRightShoulder, PoseRightElbow}, {PoseRightElbow, PoseRightWrist},
+	{PoseRightWrist, PoseRightPinky}, {PoseRightWrist, PoseRightIndex}, {PoseRightWrist, PoseRightThumb}, {PoseRightPinky, PoseRightIndex},
+	// Legs
+	{PoseLeftHip, PoseLeftKnee}, {PoseLeftKnee, PoseLeftAnkle}, {PoseLeftAnkle, PoseLeftHeel}, {PoseLeftAnkle, PoseLeftFootIndex}, {PoseLeftHeel, PoseLeftFootIndex},
+	{PoseRightHip, PoseRightKnee}, {PoseRightKnee, PoseRightAnkle}, {PoseRightAnkle, PoseRightHeel}, {PoseRightAnkle, PoseRightFootIndex}, {PoseRightHeel, PoseRightFootIndex},
+}
+
+// Face mesh contours, as in MediaPipe's face_mesh_connections.
+var (
+	FaceOvalConnections = [][2]int{
+		{10, 338}, {338, 297}, {297, 332}, {332, 284}, {284, 251}, {251, 389}, {389, 356}, {356, 454},
+		{454, 323}, {323, 361}, {361, 288}, {288, 397}, {397, 365}, {365, 379}, {379, 378}, {378, 400},
+		{400, 377}, {377, 152}, {152, 148}, {148, 176}, {176, 149}, {149, 150}, {150, 136}, {136, 172},
+		{172, 58}, {58, 132}, {132, 93}, {93, 234}, {234, 127}, {127, 162}, {162, 21}, {21, 54},
+		{54, 103}, {103, 67}, {67, 109}, {109, 10},
+	}
+	FaceLipsConnections = [][2]int{
+		// Outer
+		{61, 146}, {146, 91}, {91, 181}, {181, 84}, {84, 17}, {17, 314}, {314, 405}, {405, 321}, {321, 375}, {375, 291},
+		{61, 185}, {185, 40}, {40, 39}, {39, 37}, {37, 0}, {0, 267}, {267, 269}, {269, 270}, {270, 409}, {409, 291},
+		// Inner
+		{78, 95}, {95, 88}, {88, 178}, {178, 87}, {87, 14}, {14, 317}, {317, 402}, {402, 318}, {318, 324}, {324, 308},
+		{78, 191}, {191, 80}, {80, 81}, {81, 82}, {82, 13}, {13, 312}, {312, 311}, {311, 310}, {310, 415}, {415, 308},
+	}
+	FaceLeftEyeConnections = [][2]int{
+		{263, 249}, {249, 390}, {390, 373}, {373, 374}, {374, 380}, {380, 381}, {381, 382}, {382, 362},
+		{263, 466}, {466, 388}, {388, 387}, {387, 386}, {386, 385}, {385, 384}, {384, 398}, {398, 362},
+	}
+	FaceRightEyeConnections = [][2]int{
+		{33, 7}, {7, 163}, {163, 144}, {144, 145}, {145, 153}, {153, 154}, {154, 155}, {155, 133},
+		{33, 246}, {246, 161}, {161, 160}, {160, 159}, {159, 158}, {158, 157}, {157, 173}, {173, 133},
+	}
+	FaceLeftEyebrowConnections = [][2]int{
+		{276, 283}, {283, 282}, {282, 295}, {295, 285}, {300, 293}, {293, 334}, {334, 296}, {296, 336},
+	}
+	FaceRightEyebrowConnections = [][2]int{
+		{46, 53}, {53, 52}, {52, 65}, {65, 55}, {70, 63}, {63, 105}, {105, 66}, {66, 107},
+	}
+	FaceLeftIrisConnections = [][2]int{
+		{474, 475}, {475, 476}, {476, 477}, {477, 474},
+	}
+	FaceRightIrisConnections = [][2]int{
+		{469, 470}, {470, 471}, {471, 472}, {472, 469},
+	}
+)
+
+// FaceMeshContours returns the edges of all face contours: the oval, lips,
+// eyes, eyebrows and irises. The full triangle tesselation is not included
+// yet; until it is vendored from FACEMESH_TESSELATION in MediaPipe's
+// face_mesh_connections.py, take it from there.
+func FaceMeshContours() [][2]int {
+	var edges [][2]int
+	for _, c := range [][][2]int{
+		FaceOvalConnections, FaceLipsConnections,
+		FaceLeftEyeConnections, FaceRightEyeConnections,
+		FaceLeftEyebrowConnections, FaceRightEyebrowConnections,
+		FaceLeftIrisConnections, FaceRightIrisConnections,
+	} {
+		edges = append(edges, c...)
+	}
+	return edges
+}
@@ -0,0 +1,103 @@
+package miface
+
+import "testing"
+
+func TestHandConnectionsTree(t *testing.T) {
+	if len(HandConnections) != HandLandmarkCount-1 {
+		t.Fatalf("expected %d edges for a tree over %d landmarks, got %d",
+			HandLandmarkCount-1, HandLandmarkCount, len(HandConnections))
+	}
+
+	// Every landmark but the wrist has exactly one parent
+	parents := make(map[int]int)
+	for _, e := range HandConnections {
+		for _, idx := range e {
+			if idx < 0 || idx >= HandLandmarkCount {
+				t.Fatalf("edge %v has index out of range", e)
+			}
+		}
+		if _, ok := parents[e[1]]; ok {
+			t.Errorf("landmark %d has more than one parent", e[1])
+		}
+		parents[e[1]] = e[0]
+	}
+	if _, ok := parents[HandWrist]; ok {
+		t.Error("expected the wrist to be the root")
+	}
+
+	// Every landmark reaches the wrist without a cycle
+	for idx := 0; idx < HandLandmarkCount; idx++ {
+		node := idx
+		for steps := 0; node != HandWrist; steps++ {
+			parent, ok := parents[node]
+			if !ok {
+				t.Fatalf("landmark %d is not connected to the wrist", idx)
+			}
+			if steps > HandLandmarkCount {
+				t.Fatalf("cycle reached from landmark %d", idx)
+			}
+			node = parent
+		}
+
+		// The VMC sender's parent table agrees
+		expected := -1
+		if idx != HandWrist {
+			expected = parents[idx]
+		}
+		if got := handParentLandmark(idx); got != expected {
+			t.Errorf("landmark %d: expected parent %d, got %d", idx, expected, got)
+		}
+	}
+}
+
+func TestLandmarkConnectionsInRange(t *testing.T) {
+	tests := []struct {
+		name  string
+		edges [][2]int
+		count int
+	}{
+		{"hand palm", HandPalmConnections, HandLandmarkCount},
+		{"pose", PoseConnections, PoseLandmarkCount},
+		{"face contours", FaceMeshContours(), FaceLandmarkCount},
+	}
+	for _, tt := range tests {
+		for _, e := range tt.edges {
+			if e[0] < 0 || e[0] >= tt.count || e[1] < 0 || e[1] >= tt.count || e[0] == e[1] {
+				t.Errorf("%s: invalid edge %v", tt.name, e)
+			}
+		}
+	}
+}
+
+func TestFaceContoursClosed(t *testing.T) {
+	// The oval, eyes and irises are closed loops: every landmark has two edges
+	for name, edges := range map[string][][2]int{
+		"oval":       FaceOvalConnections,
+		"left eye":   FaceLeftEyeConnections,
+		"right eye":  FaceRightEyeConnections,
+		"left iris":  FaceLeftIrisConnections,
+		"right iris": FaceRightIrisConnections,
+	} {
+		degree := make(map[int]int)
+		for _, e := range edges {
+			degree[e[0]]++
+			degree[e[1]]++
+		}
+		for idx, d := range degree {
+			if d != 2 {
+				t.Errorf("%s: landmark %d has %d edges, expected 2", name, idx, d)
+			}
+		}
+	}
+
+	// The eye corners and lids used by auto-blink are on the eye contours
+	eyes := make(map[int]bool)
+	for _, e := range append(FaceLeftEyeConnections, FaceRightEyeConnections...) {
+		eyes[e[0]], eyes[e[1]] = true, true
+	}
+	for _, idx := range eyeLandmarkIndices {
+		if !eyes[idx] {
+			t.Errorf("expected eye landmark %d on an eye contour", idx)
+		}
+	}
+}
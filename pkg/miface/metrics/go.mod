@@ -0,0 +1,23 @@
+module github.com/MiFaceDEV/miface/pkg/miface/metrics
+
+go 1.24
+
+require github.com/MiFaceDEV/miface v0.0.0
+
+require github.com/prometheus/client_golang v1.22.0
+
+require (
+	github.com/BurntSushi/toml v1.5.0 // indirect
+	github.com/beorn7/perks v1.0.1 // indirect
+	github.com/cespare/xxhash/v2 v2.3.0 // indirect
+	github.com/munnerz/goautoneg v0.0.0-20191010083416-a7dc8b61c822 // indirect
+	github.com/prometheus/client_model v0.6.1 // indirect
+	github.com/prometheus/common v0.62.0 // indirect
+	github.com/prometheus/procfs v0.15.1 // indirect
+	gocv.io/x/gocv v0.42.0 // indirect
+	golang.org/x/sys v0.30.0 // indirect
+	google.golang.org/protobuf v1.36.5 // indirect
+)
+
+// Build against the MiFace module in this repository
+replace github.com/MiFaceDEV/miface => ../../..
@@ -0,0 +1,111 @@
+// Package metrics exports MiFace tracker statistics as Prometheus metrics.
+//
+// It lives in its own module, github.com/MiFaceDEV/miface/pkg/miface/metrics,
+// so that only programs importing it depend on the Prometheus client library.
+//
+// Usage:
+//
+//	exporter := metrics.New(tracker)
+//	http.Handle("/metrics", exporter.Handler())
+package metrics
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/MiFaceDEV/miface/pkg/miface"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// namespace prefixes every exported metric name.
+const namespace = "miface"
+
+// latencyBuckets are the processing latency histogram buckets in seconds,
+// from 1ms up to a second.
+var latencyBuckets = []float64{.001, .0025, .005, .01, .02, .033, .05, .1, .25, .5, 1}
+
+// Exporter is a prometheus.Collector for a tracker's statistics:
+//
+//   - miface_frames_processed_total: frames sent to outputs
+//   - miface_frames_dropped_total: frames not delivered to slow subscribers
+//   - miface_achieved_fps: smoothed output frame rate
+//   - miface_send_errors_total: failed sends, labelled by sender name
+//     (see miface.SenderStats)
+//   - miface_processing_latency_seconds: capture-to-output latency per frame
+//
+// The counters follow the tracker's statistics, which restart from zero
+// when the tracker is restarted; Prometheus treats that as a counter reset.
+type Exporter struct {
+	tracker  *miface.Tracker
+	registry *prometheus.Registry
+
+	processed  *prometheus.Desc
+	dropped    *prometheus.Desc
+	fps        *prometheus.Desc
+	sendErrors *prometheus.Desc
+	latency    prometheus.Histogram
+}
+
+// New creates an exporter for tracker, registered on its own registry
+// (see Handler). It adds a stats observer to the tracker to record
+// per-frame latency, alongside any other observers.
+func New(tracker *miface.Tracker) *Exporter {
+	e := &Exporter{
+		tracker:  tracker,
+		registry: prometheus.NewRegistry(),
+		processed: prometheus.NewDesc(namespace+"_frames_processed_total",
+			"Frames sent to outputs.", nil, nil),
+		dropped: prometheus.NewDesc(namespace+"_frames_dropped_total",
+			"Frames not delivered to slow subscribers.", nil, nil),
+		fps: prometheus.NewDesc(namespace+"_achieved_fps",
+			"Achieved output frame rate, smoothed over recent frames.", nil, nil),
+		sendErrors: prometheus.NewDesc(namespace+"_send_errors_total",
+			"Frames a sender failed to send.", []string{"sender"}, nil),
+		latency: prometheus.NewHistogram(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "processing_latency_seconds",
+			Help:      "Time from frame capture to output.",
+			Buckets:   latencyBuckets,
+		}),
+	}
+	e.registry.MustRegister(e)
+
+	tracker.AddStatsObserver(func(stats miface.TrackerStats) {
+		e.latency.Observe(stats.Latency.Seconds())
+	})
+	return e
+}
+
+// Registry returns the registry the exporter is registered on, for adding
+// other collectors to the same endpoint.
+func (e *Exporter) Registry() *prometheus.Registry {
+	return e.registry
+}
+
+// Handler returns an HTTP handler serving the metrics in the Prometheus
+// text format, to mount on a mux (conventionally at /metrics).
+func (e *Exporter) Handler() http.Handler {
+	return promhttp.HandlerFor(e.registry, promhttp.HandlerOpts{Timeout: 5 * time.Second})
+}
+
+// Describe implements prometheus.Collector.
+func (e *Exporter) Describe(ch chan<- *prometheus.Desc) {
+	ch <- e.processed
+	ch <- e.dropped
+	ch <- e.fps
+	ch <- e.sendErrors
+	e.latency.Describe(ch)
+}
+
+// Collect implements prometheus.Collector.
+func (e *Exporter) Collect(ch chan<- prometheus.Metric) {
+	stats := e.tracker.Stats()
+	ch <- prometheus.MustNewConstMetric(e.processed, prometheus.CounterValue, float64(stats.FrameNumber))
+	ch <- prometheus.MustNewConstMetric(e.dropped, prometheus.CounterValue, float64(stats.DroppedFrames))
+	ch <- prometheus.MustNewConstMetric(e.fps, prometheus.GaugeValue, stats.FPS)
+	for _, sender := range stats.Senders {
+		ch <- prometheus.MustNewConstMetric(e.sendErrors, prometheus.CounterValue, float64(sender.SendErrors), sender.Name)
+	}
+	e.latency.Collect(ch)
+}
@@ -0,0 +1,78 @@
+package metrics
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/MiFaceDEV/miface/pkg/miface"
+)
+
+// failingSender fails every send.
+type failingSender struct{}
+
+func (failingSender) Send(*miface.TrackingData) error { return errors.New("unreachable") }
+func (failingSender) Close() error                    { return nil }
+
+func TestExporterScrape(t *testing.T) {
+	tracker, err := miface.NewTracker(nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer tracker.Close()
+
+	clock := miface.NewFakeClock(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC))
+	if err := tracker.SetClock(clock); err != nil {
+		t.Fatalf("failed to set clock: %v", err)
+	}
+	if err := tracker.SetProcessor(miface.NewStubProcessor(miface.DefaultStubConfig())); err != nil {
+		t.Fatalf("failed to set processor: %v", err)
+	}
+	if err := tracker.AddSender(failingSender{}); err != nil {
+		t.Fatalf("failed to add sender: %v", err)
+	}
+	observed := 0
+	tracker.SetStatsObserver(func(miface.TrackerStats) { observed++ })
+	exporter := New(tracker)
+	if err := tracker.Start(); err != nil {
+		t.Fatalf("failed to start: %v", err)
+	}
+
+	interval := time.Second / time.Duration(tracker.Config().Camera.FPS)
+	for i := 0; i < 3; i++ {
+		clock.Advance(interval)
+	}
+
+	server := httptest.NewServer(exporter.Handler())
+	defer server.Close()
+	resp, err := http.Get(server.URL)
+	if err != nil {
+		t.Fatalf("scrape failed: %v", err)
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatalf("reading scrape: %v", err)
+	}
+	text := string(body)
+
+	for _, want := range []string{
+		"miface_frames_processed_total 3",
+		"miface_frames_dropped_total 0",
+		"miface_achieved_fps ",
+		`miface_send_errors_total{sender="sender1"} 3`,
+		"miface_processing_latency_seconds_count 3",
+		"miface_processing_latency_seconds_bucket{",
+	} {
+		if !strings.Contains(text, want) {
+			t.Errorf("expected %q in scrape, got:\n%s", want, text)
+		}
+	}
+	if observed != 3 {
+		t.Errorf("expected the existing stats observer to keep running, got %d calls", observed)
+	}
+}
@@ -0,0 +1,148 @@
+package miface
+
+import (
+	"sync"
+	"time"
+)
+
+// OutlierConfig sets the speed limits of OutlierRejector, in normalized
+// image units per second. A landmark moving faster than its modality's
+// limit since the last frame is treated as a bad measurement. A zero limit
+// disables rejection for that modality.
+type OutlierConfig struct {
+	Face  float64
+	Hands float64 // Applies to each hand separately
+	Pose  float64
+	// MaxRejected is how many frames in a row a landmark may be rejected
+	// before its measurement is accepted anyway, so a landmark that really
+	// did move that fast isn't stuck in place. 0 rejects indefinitely.
+	MaxRejected int
+}
+
+// DefaultOutlierConfig returns limits well above how fast a person moves
+// in front of a webcam: a head crosses the image in no less than a third
+// of a second and a hand in a fifth. A landmark is held for at most three
+// frames.
+func DefaultOutlierConfig() OutlierConfig {
+	return OutlierConfig{
+		Face:        3,
+		Hands:       5,
+		Pose:        4,
+		MaxRejected: 3,
+	}
+}
+
+// outlierSlot is the last accepted landmarks of one modality.
+type outlierSlot struct {
+	last       []Point3D
+	rejected   []int       // Frames in a row each landmark was rejected
+	acceptedAt []time.Time // When each landmark was last accepted
+}
+
+// filter replaces the points of landmarks that moved faster than maxSpeed
+// since they were last accepted with their last accepted position, in
+// place. The time a landmark may take to move is counted from its last
+// accepted frame, so a landmark that really moved is let through once
+// enough time has passed even with MaxRejected 0.
+func (s *outlierSlot) filter(landmarks []Landmark, maxSpeed float64, maxRejected int, now time.Time) {
+	if maxSpeed <= 0 {
+		return
+	}
+	if len(s.last) != len(landmarks) {
+		// First frame, or a different landmark model: nothing to compare
+		s.last = make([]Point3D, len(landmarks))
+		s.rejected = make([]int, len(landmarks))
+		s.acceptedAt = make([]time.Time, len(landmarks))
+		for i := range landmarks {
+			s.last[i] = landmarks[i].Point
+			s.acceptedAt[i] = now
+		}
+		return
+	}
+
+	for i := range landmarks {
+		p := &landmarks[i].Point
+		maxDistance := maxSpeed * now.Sub(s.acceptedAt[i]).Seconds()
+		if p.Sub(s.last[i]).Length() > maxDistance &&
+			(maxRejected == 0 || s.rejected[i] < maxRejected) {
+			*p = s.last[i]
+			s.rejected[i]++
+			continue
+		}
+		s.last[i] = *p
+		s.rejected[i] = 0
+		s.acceptedAt[i] = now
+	}
+}
+
+// OutlierRejector drops landmark measurements that jump implausibly far
+// between frames, so a single bad MediaPipe frame doesn't teleport a
+// landmark and make the smoother lunge toward it. A rejected landmark is
+// held at its last accepted position for that frame.
+//
+// It belongs before smoothing, which in a ChainProcessor passed to
+// Tracker.SetProcessor it always is. OutlierRejector implements
+// TransformStage.
+type OutlierRejector struct {
+	mu  sync.Mutex
+	cfg OutlierConfig
+
+	face, leftHand, rightHand, pose outlierSlot
+
+	clock Clock
+}
+
+// NewOutlierRejector creates an outlier rejector with the given limits.
+func NewOutlierRejector(cfg OutlierConfig) *OutlierRejector {
+	return &OutlierRejector{
+		cfg:   cfg,
+		clock: realClock{},
+	}
+}
+
+// SetClock sets the clock frame times are taken from. It defaults to the
+// system clock; pass the tracker's clock so the speed limits follow its
+// time, e.g. a FakeClock in tests.
+func (o *OutlierRejector) SetClock(clock Clock) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.clock = clock
+}
+
+// Transform replaces outlying landmarks with their last accepted
+// positions, in place.
+func (o *OutlierRejector) Transform(data *TrackingData) (*TrackingData, error) {
+	if data == nil {
+		return data, nil
+	}
+
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	now := o.clock.Now()
+	if data.Face != nil {
+		o.face.filter(data.Face.Landmarks, o.cfg.Face, o.cfg.MaxRejected, now)
+	}
+	if data.LeftHand != nil {
+		o.leftHand.filter(data.LeftHand.Landmarks, o.cfg.Hands, o.cfg.MaxRejected, now)
+	}
+	if data.RightHand != nil {
+		o.rightHand.filter(data.RightHand.Landmarks, o.cfg.Hands, o.cfg.MaxRejected, now)
+	}
+	if data.Pose != nil {
+		o.pose.filter(data.Pose.Landmarks, o.cfg.Pose, o.cfg.MaxRejected, now)
+	}
+	return data, nil
+}
+
+// Reset forgets the last accepted landmarks, so the next frame is taken
+// as is.
+func (o *OutlierRejector) Reset() {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	o.face = outlierSlot{}
+	o.leftHand = outlierSlot{}
+	o.rightHand = outlierSlot{}
+	o.pose = outlierSlot{}
+}
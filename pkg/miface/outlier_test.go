@@ -0,0 +1,118 @@
+package miface
+
+import (
+	"testing"
+	"time"
+)
+
+// frameClock is a FakeClock that advances by step before each frame.
+type frameClock struct {
+	*FakeClock
+	step time.Duration
+}
+
+func (c frameClock) Now() time.Time {
+	c.Advance(c.step)
+	return c.FakeClock.Now()
+}
+
+// newTestOutlierRejector returns a rejector whose clock advances 33ms per
+// frame.
+func newTestOutlierRejector(cfg OutlierConfig) *OutlierRejector {
+	o := NewOutlierRejector(cfg)
+	o.SetClock(frameClock{NewFakeClock(time.Unix(0, 0)), 33 * time.Millisecond})
+	return o
+}
+
+func TestOutlierRejectorIgnoresSpike(t *testing.T) {
+	o := newTestOutlierRejector(DefaultOutlierConfig())
+
+	// A steady hand drifting slowly to the right, with one frame where
+	// the index fingertip jumps across the image
+	var out []Point3D
+	for i := 0; i < 10; i++ {
+		hand := testHand(true, 1)
+		for j := range hand.Landmarks {
+			hand.Landmarks[j].Point.X = 0.3 + 0.001*float64(i)
+		}
+		if i == 5 {
+			hand.Landmarks[HandIndexTip].Point.X = 0.9
+		}
+		data, err := o.Transform(&TrackingData{LeftHand: hand})
+		if err != nil {
+			t.Fatalf("transform failed: %v", err)
+		}
+		out = append(out, data.LeftHand.Landmarks[HandIndexTip].Point)
+
+		if wrist := data.LeftHand.Landmarks[HandWrist].Point.X; wrist != hand.Landmarks[HandWrist].Point.X {
+			t.Errorf("frame %d: expected the steady wrist to pass through, got %f", i, wrist)
+		}
+	}
+
+	if out[5] != out[4] {
+		t.Errorf("expected the spike held at the previous position %+v, got %+v", out[4], out[5])
+	}
+	if want := 0.3 + 0.001*6; out[6].X != want {
+		t.Errorf("expected tracking to resume at %f, got %f", want, out[6].X)
+	}
+}
+
+func TestOutlierRejectorMaxRejected(t *testing.T) {
+	cfg := DefaultOutlierConfig()
+	cfg.MaxRejected = 2
+	o := newTestOutlierRejector(cfg)
+
+	pose := func(y float64) *TrackingData {
+		p := testPose()
+		for i := range p.Landmarks {
+			p.Landmarks[i].Point = Point3D{X: 0.5, Y: y}
+		}
+		return &TrackingData{Pose: p}
+	}
+	o.Transform(pose(0.2))
+
+	// The body really did jump: held for two frames, then accepted
+	for i, want := range []float64{0.2, 0.2, 0.8, 0.8} {
+		data, _ := o.Transform(pose(0.8))
+		if got := data.Pose.Landmarks[PoseNose].Point.Y; got != want {
+			t.Errorf("frame %d: expected Y %f, got %f", i, want, got)
+		}
+	}
+}
+
+func TestOutlierRejectorDisabledModality(t *testing.T) {
+	o := newTestOutlierRejector(OutlierConfig{Hands: 5})
+
+	face := func(x float64) *TrackingData {
+		return &TrackingData{Face: &FaceData{Landmarks: []Landmark{{Point: Point3D{X: x}}}}}
+	}
+	o.Transform(face(0.1))
+	if data, _ := o.Transform(face(0.9)); data.Face.Landmarks[0].Point.X != 0.9 {
+		t.Error("expected face landmarks to pass through with no face limit")
+	}
+}
+
+func TestOutlierRejectorCountsFromLastAccepted(t *testing.T) {
+	cfg := DefaultOutlierConfig()
+	cfg.MaxRejected = 0
+	o := newTestOutlierRejector(cfg)
+
+	pose := func(y float64) *TrackingData {
+		p := testPose()
+		for i := range p.Landmarks {
+			p.Landmarks[i].Point = Point3D{X: 0.5, Y: y}
+		}
+		return &TrackingData{Pose: p}
+	}
+	o.Transform(pose(0.2))
+
+	// Moving 0.5 at 4 units/s takes 125ms: rejected until four frames have
+	// passed since the last accepted one, then accepted even though no
+	// rejection limit is set
+	for i, want := range []float64{0.2, 0.2, 0.2, 0.7, 0.7} {
+		data, _ := o.Transform(pose(0.7))
+		if got := data.Pose.Landmarks[PoseNose].Point.Y; got != want {
+			t.Errorf("frame %d: expected Y %f, got %f", i, want, got)
+		}
+	}
+}
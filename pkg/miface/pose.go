@@ -0,0 +1,98 @@
+package miface
+
+import "math"
+
+// handednessMinVisibility is the pose wrist visibility below which
+// CorrectHandedness doesn't trust the wrist's position.
+const handednessMinVisibility = 0.5
+
+// CorrectHandedness checks the hands against the pose wrists and moves each
+// hand to the side whose wrist it is nearest, fixing frames where MediaPipe
+// labels a hand as the wrong one. With two hands they are swapped if that
+// brings both closer to their wrists overall. It does nothing unless both
+// pose wrists are visible, and reports whether the hands were reassigned.
+func CorrectHandedness(data *TrackingData) bool {
+	if data == nil || data.Pose == nil || len(data.Pose.Landmarks) <= PoseRightWrist {
+		return false
+	}
+	leftWrist, rightWrist := data.Pose.Landmarks[PoseLeftWrist], data.Pose.Landmarks[PoseRightWrist]
+	if leftWrist.Visibility < handednessMinVisibility || rightWrist.Visibility < handednessMinVisibility {
+		return false
+	}
+
+	// The 2D distance from a hand's wrist to a pose wrist; hand and pose Z
+	// aren't on the same scale
+	distance := func(hand *HandData, wrist Landmark) float64 {
+		if hand == nil || len(hand.Landmarks) <= HandWrist {
+			return 0
+		}
+		p := hand.Landmarks[HandWrist].Point
+		return math.Hypot(p.X-wrist.Point.X, p.Y-wrist.Point.Y)
+	}
+	kept := distance(data.LeftHand, leftWrist) + distance(data.RightHand, rightWrist)
+	swapped := distance(data.LeftHand, rightWrist) + distance(data.RightHand, leftWrist)
+	if swapped >= kept {
+		return false
+	}
+
+	data.LeftHand, data.RightHand = data.RightHand, data.LeftHand
+	if data.LeftHand != nil {
+		data.LeftHand.IsLeft = true
+	}
+	if data.RightHand != nil {
+		data.RightHand.IsLeft = false
+	}
+	return true
+}
+
+// Limits for the spine rotation estimated from the pose, so a bad frame
+// can't fold the model in half.
+const (
+	maxSpineRoll  = 30 * math.Pi / 180 // Sideways lean
+	maxSpineYaw   = 45 * math.Pi / 180 // Twist
+	maxSpinePitch = 30 * math.Pi / 180 // Forward/back lean
+)
+
+// EstimateSpineRotation derives the chest and upper chest rotations from the
+// shoulder and hip landmarks: sideways lean (roll) from the shoulder line's
+// tilt relative to the hips, twist (yaw) from the shoulders' depth
+// difference relative to the hips, and forward lean (pitch) from the spine's
+// depth. The total rotation is clamped and split evenly between the two bones.
+// Without hips, only the shoulder line is used. It returns identity rotations
+// if the shoulders are missing.
+func EstimateSpineRotation(pose *PoseData) (chest, upperChest Quaternion) {
+	identity := Quaternion{W: 1}
+	if pose == nil || len(pose.Landmarks) <= PoseRightShoulder {
+		return identity, identity
+	}
+
+	lms := pose.Landmarks
+	shoulder := lms[PoseLeftShoulder].Point.Sub(lms[PoseRightShoulder].Point)
+	roll := math.Atan2(shoulder.Y, shoulder.X)
+	yaw := math.Atan2(-shoulder.Z, shoulder.X)
+	var pitch float64
+
+	if len(lms) > PoseRightHip {
+		hip := lms[PoseLeftHip].Point.Sub(lms[PoseRightHip].Point)
+		roll -= math.Atan2(hip.Y, hip.X)
+		yaw -= math.Atan2(-hip.Z, hip.X)
+
+		shoulders := []int{PoseLeftShoulder, PoseRightShoulder}
+		hips := []int{PoseLeftHip, PoseRightHip}
+		spine := Centroid(lms, shoulders).Sub(Centroid(lms, hips))
+		pitch = math.Atan2(-spine.Z, -spine.Y)
+	}
+
+	roll = clampAngle(roll, maxSpineRoll)
+	yaw = clampAngle(yaw, maxSpineYaw)
+	pitch = clampAngle(pitch, maxSpinePitch)
+
+	half := QuaternionFromEuler(pitch/2, yaw/2, roll/2)
+	return half, half
+}
+
+// clampAngle wraps an angle to [-π, π] and clamps it to ±limit.
+func clampAngle(angle, limit float64) float64 {
+	angle = math.Remainder(angle, 2*math.Pi)
+	return math.Max(-limit, math.Min(limit, angle))
+}
@@ -0,0 +1,118 @@
+package miface
+
+import (
+	"math"
+	"testing"
+)
+
+// uprightPose returns a pose with level shoulders directly above level hips.
+func uprightPose() *PoseData {
+	lms := make([]Landmark, PoseLandmarkCount)
+	for i, p := range stubPoseRest {
+		lms[i] = Landmark{Point: p, Visibility: 1, Presence: 1}
+	}
+	return &PoseData{Landmarks: lms}
+}
+
+func TestEstimateSpineRotationUpright(t *testing.T) {
+	chest, upperChest := EstimateSpineRotation(uprightPose())
+	for _, q := range []Quaternion{chest, upperChest} {
+		if math.Abs(q.X) > 1e-9 || math.Abs(q.Y) > 1e-9 || math.Abs(q.Z) > 1e-9 {
+			t.Errorf("expected identity rotation for upright pose, got %+v", q)
+		}
+	}
+}
+
+func TestEstimateSpineRotationTiltedShoulders(t *testing.T) {
+	pose := uprightPose()
+	// Drop the left shoulder: the chest rolls sideways
+	pose.Landmarks[PoseLeftShoulder].Point.Y += 0.05
+
+	chest, upperChest := EstimateSpineRotation(pose)
+	if math.Abs(chest.Z) < 1e-3 {
+		t.Errorf("expected a roll component, got %+v", chest)
+	}
+	if chest != upperChest {
+		t.Errorf("expected rotation split evenly, got %+v and %+v", chest, upperChest)
+	}
+	if math.Abs(chest.X) > 1e-3 || math.Abs(chest.Y) > 1e-3 {
+		t.Errorf("expected mostly roll, got %+v", chest)
+	}
+}
+
+func TestEstimateSpineRotationClamped(t *testing.T) {
+	pose := uprightPose()
+	// A bad frame with the shoulders nearly vertical
+	pose.Landmarks[PoseLeftShoulder].Point.Y += 0.5
+	pose.Landmarks[PoseRightShoulder].Point.Y -= 0.5
+
+	chest, _ := EstimateSpineRotation(pose)
+	// Each bone carries half of the clamped roll
+	want := math.Sin(maxSpineRoll / 4)
+	if math.Abs(math.Abs(chest.Z)-want) > 1e-9 {
+		t.Errorf("expected roll clamped to |z| = %f, got %+v", want, chest)
+	}
+}
+
+func TestEstimateSpineRotationMissing(t *testing.T) {
+	chest, upperChest := EstimateSpineRotation(nil)
+	if chest != (Quaternion{W: 1}) || upperChest != (Quaternion{W: 1}) {
+		t.Errorf("expected identity for nil pose, got %+v, %+v", chest, upperChest)
+	}
+}
+
+// handednessPose returns a pose with the left wrist at leftX and the right
+// wrist at rightX, both at Y 0.6.
+func handednessPose(leftX, rightX float64) *PoseData {
+	pose := testPose()
+	pose.Landmarks[PoseLeftWrist].Point = Point3D{X: leftX, Y: 0.6}
+	pose.Landmarks[PoseRightWrist].Point = Point3D{X: rightX, Y: 0.6}
+	return pose
+}
+
+// handAt returns a hand whose wrist is at x, 0.6.
+func handAt(isLeft bool, x float64) *HandData {
+	hand := testHand(isLeft, 1)
+	hand.Landmarks[HandWrist].Point = Point3D{X: x, Y: 0.6}
+	return hand
+}
+
+func TestCorrectHandedness(t *testing.T) {
+	// Both hands mislabeled: the hand at the left wrist came back as right
+	atLeft, atRight := handAt(false, 0.71), handAt(true, 0.29)
+	data := &TrackingData{Pose: handednessPose(0.7, 0.3), LeftHand: atRight, RightHand: atLeft}
+	if !CorrectHandedness(data) {
+		t.Fatal("expected mislabeled hands to be reassigned")
+	}
+	if data.LeftHand != atLeft || data.RightHand != atRight {
+		t.Error("expected each hand moved to the side of its nearest wrist")
+	}
+	if !data.LeftHand.IsLeft || data.RightHand.IsLeft {
+		t.Error("expected IsLeft to match the new sides")
+	}
+
+	// A single hand labeled as the wrong side
+	hand := handAt(false, 0.68)
+	data = &TrackingData{Pose: handednessPose(0.7, 0.3), RightHand: hand}
+	if !CorrectHandedness(data) || data.LeftHand != hand || data.RightHand != nil || !hand.IsLeft {
+		t.Errorf("expected a lone hand at the left wrist to become the left hand, got %+v", data)
+	}
+
+	// Correct labels are kept
+	data = &TrackingData{Pose: handednessPose(0.7, 0.3), LeftHand: handAt(true, 0.7), RightHand: handAt(false, 0.3)}
+	if CorrectHandedness(data) || !data.LeftHand.IsLeft {
+		t.Error("expected correctly labeled hands to be kept")
+	}
+
+	// Wrists the pose can't see are not trusted
+	pose := handednessPose(0.7, 0.3)
+	pose.Landmarks[PoseLeftWrist].Visibility = 0.1
+	data = &TrackingData{Pose: pose, RightHand: handAt(false, 0.7)}
+	if CorrectHandedness(data) || data.RightHand == nil {
+		t.Error("expected no correction with an invisible wrist")
+	}
+
+	if CorrectHandedness(&TrackingData{LeftHand: handAt(true, 0.3)}) {
+		t.Error("expected no correction without a pose")
+	}
+}
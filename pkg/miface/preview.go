@@ -4,12 +4,34 @@
 package miface
 
 import (
+	"fmt"
+	"image"
+	"image/color"
 	"runtime"
 	"sync"
+	"time"
 
 	"gocv.io/x/gocv"
 )
 
+// MatSource is an optional interface for camera sources that can capture a
+// frame as a BGR gocv.Mat. When the camera implements it, the tracker reads
+// each frame once with ReadMat and shares it between the preview window and
+// the processor, instead of capturing once for each.
+type MatSource interface {
+	// ReadMat captures a single frame. The caller must close the Mat.
+	ReadMat() (gocv.Mat, error)
+}
+
+// TimestampedMatSource is implemented by MatSources that know when each
+// frame was captured, so the tracker can skip stale frames on the preview
+// path too; see TimestampedSource.
+type TimestampedMatSource interface {
+	// ReadMatTimestamped captures a frame like ReadMat and also returns
+	// when it was captured. The caller must close the Mat.
+	ReadMatTimestamped() (gocv.Mat, time.Time, error)
+}
+
 // PreviewWindow provides a simple debug window for camera preview.
 // OpenCV UI functions must be called from the main thread on Linux/X11.
 type PreviewWindow struct {
@@ -19,20 +41,47 @@ type PreviewWindow struct {
 	doneCh   chan struct{}
 	once     sync.Once
 	initDone chan struct{}
+
+	mu       sync.Mutex
+	hud      bool            // Draw the stats HUD in ShowWithHUD
+	handlers map[rune]func() // Key handlers registered with RegisterKey
+
+	// waitKey polls the keyboard after each frame; defaults to the window's
+	// WaitKey and is replaced in tests
+	waitKey func(delay int) int
+	// keyCh queues key handlers for the dispatcher, so a slow handler
+	// doesn't stall the display loop
+	keyCh chan func()
 }
 
+// keyQueueSize is how many key handlers can wait for the dispatcher before
+// further key presses are dropped.
+const keyQueueSize = 8
+
 // NewPreviewWindow creates a new preview window with the given title.
+// Pressing 'q' in the window closes it; see RegisterKey.
 // Must be called from the main thread.
 func NewPreviewWindow(title string) *PreviewWindow {
+	return newPreviewWindow(title, nil)
+}
+
+// newPreviewWindow creates a preview window that reads key presses from
+// waitKey, or from the window itself if waitKey is nil.
+func newPreviewWindow(title string, waitKey func(delay int) int) *PreviewWindow {
 	p := &PreviewWindow{
 		frameCh:  make(chan gocv.Mat, 1),
 		closeCh:  make(chan struct{}),
 		doneCh:   make(chan struct{}),
 		initDone: make(chan struct{}),
+		handlers: make(map[rune]func()),
+		waitKey:  waitKey,
+		keyCh:    make(chan func(), keyQueueSize),
 	}
+	p.handlers['q'] = func() { _ = p.Close() }
 
 	// Start the preview loop in a goroutine locked to OS thread
 	go p.previewLoop(title)
+	go p.dispatchKeys()
 
 	// Wait for initialization to complete
 	<-p.initDone
@@ -49,13 +98,16 @@ func (p *PreviewWindow) previewLoop(title string) {
 
 	// Create window on this thread
 	p.window = gocv.NewWindow(title)
+	if p.waitKey == nil {
+		p.waitKey = p.window.WaitKey
+	}
 	close(p.initDone)
 
 	for {
 		select {
 		case frame := <-p.frameCh:
 			_ = p.window.IMShow(frame)
-			p.window.WaitKey(1)
+			p.handleKey(p.waitKey(1))
 			frame.Close() // Close the frame after displaying
 
 		case <-p.closeCh:
@@ -68,6 +120,62 @@ func (p *PreviewWindow) previewLoop(title string) {
 	}
 }
 
+// RegisterKey sets the handler called when key is pressed in the preview
+// window, replacing any previous handler for it, including the default 'q'
+// handler that closes the window. A nil handler unregisters the key.
+// Handlers run on a separate goroutine, one at a time, so they may block or
+// call Close without stalling the display. Can be called while the window
+// is open.
+func (p *PreviewWindow) RegisterKey(key rune, handler func()) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if handler == nil {
+		delete(p.handlers, key)
+		return
+	}
+	p.handlers[key] = handler
+}
+
+// handleKey queues the handler registered for a WaitKey result, if any.
+// Called from the preview loop, so it never blocks.
+func (p *PreviewWindow) handleKey(code int) {
+	if code < 0 {
+		return
+	}
+
+	p.mu.Lock()
+	// WaitKey may report modifier bits above the key code
+	handler := p.handlers[rune(code&0xFF)]
+	p.mu.Unlock()
+	if handler == nil {
+		return
+	}
+
+	select {
+	case p.keyCh <- handler:
+	default:
+		// Drop the key press if handlers are backed up
+	}
+}
+
+// dispatchKeys runs queued key handlers until the window is closed.
+func (p *PreviewWindow) dispatchKeys() {
+	for {
+		select {
+		case handler := <-p.keyCh:
+			handler()
+		case <-p.closeCh:
+			return
+		}
+	}
+}
+
+// Done returns a channel that is closed once the window has been closed,
+// either by Close or by a key handler such as the default 'q'.
+func (p *PreviewWindow) Done() <-chan struct{} {
+	return p.doneCh
+}
+
 // Show displays a frame in the preview window.
 // The frame is cloned internally, so the caller can close the original.
 func (p *PreviewWindow) Show(frame gocv.Mat) {
@@ -86,6 +194,176 @@ func (p *PreviewWindow) Show(frame gocv.Mat) {
 	}
 }
 
+// ShowWithOverlay displays a frame with the landmarks in data drawn on top:
+// face mesh points, hand skeletons and pose connections, each in its own
+// color. Drawing happens on a clone, so frame is not modified and the
+// caller can close it. Nil data or missing modalities are skipped.
+func (p *PreviewWindow) ShowWithOverlay(frame gocv.Mat, data *TrackingData) {
+	if frame.Empty() {
+		return
+	}
+
+	cloned := frame.Clone()
+	drawOverlay(&cloned, data)
+	p.enqueue(cloned)
+}
+
+// SetHUD enables or disables the stats HUD drawn by ShowWithHUD.
+// Can be called while the window is open.
+func (p *PreviewWindow) SetHUD(enabled bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.hud = enabled
+}
+
+// HUDEnabled returns whether the stats HUD is drawn.
+func (p *PreviewWindow) HUDEnabled() bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.hud
+}
+
+// ShowWithHUD is like ShowWithOverlay, and also draws a HUD with the
+// achieved FPS, frame number and per-modality detection status from stats
+// in the top-left corner if the HUD is enabled (see SetHUD).
+func (p *PreviewWindow) ShowWithHUD(frame gocv.Mat, data *TrackingData, stats TrackerStats) {
+	if frame.Empty() {
+		return
+	}
+
+	cloned := frame.Clone()
+	drawOverlay(&cloned, data)
+	if p.HUDEnabled() {
+		drawHUD(&cloned, formatHUD(stats))
+	}
+	p.enqueue(cloned)
+}
+
+// enqueue hands an annotated frame, owned by the window from now on, to the
+// preview loop, dropping it if the previous frame hasn't been shown yet.
+func (p *PreviewWindow) enqueue(frame gocv.Mat) {
+	select {
+	case p.frameCh <- frame:
+	default:
+		frame.Close()
+	}
+}
+
+// HUD text layout.
+const (
+	hudFontScale  = 0.5
+	hudLineHeight = 18
+	hudMargin     = 10
+)
+
+var hudColor = color.RGBA{R: 255, G: 255, B: 255, A: 255}
+
+// formatHUD returns the HUD text lines for stats.
+func formatHUD(stats TrackerStats) []string {
+	status := func(detected bool) string {
+		if detected {
+			return "detected"
+		}
+		return "lost"
+	}
+	return []string{
+		fmt.Sprintf("FPS: %.1f", stats.FPS),
+		fmt.Sprintf("Frame: %d", stats.FrameNumber),
+		"Face: " + status(stats.FaceDetected),
+		fmt.Sprintf("Hands: L %s, R %s", status(stats.LeftHandDetected), status(stats.RightHandDetected)),
+		"Pose: " + status(stats.PoseDetected),
+	}
+}
+
+// drawHUD draws lines of text in the top-left corner of img.
+func drawHUD(img *gocv.Mat, lines []string) {
+	for i, line := range lines {
+		org := image.Pt(hudMargin, hudMargin+hudLineHeight*(i+1))
+		gocv.PutText(img, line, org, gocv.FontHersheySimplex, hudFontScale, hudColor, 1) //nolint:errcheck // drawing errors only affect the preview
+	}
+}
+
+// Overlay colors per modality.
+var (
+	overlayFaceColor      = color.RGBA{R: 255, G: 255, A: 255} // Yellow
+	overlayLeftHandColor  = color.RGBA{B: 255, A: 255}         // Blue
+	overlayRightHandColor = color.RGBA{R: 255, A: 255}         // Red
+	overlayPoseColor      = color.RGBA{G: 255, A: 255}         // Green
+)
+
+// overlayMinVisibility is the Visibility below which pose landmarks and
+// their connections are not drawn. The face and hand models don't report
+// visibility, so their landmarks are always drawn.
+const overlayMinVisibility = 0.5
+
+// handOverlayConnections are the hand edges drawn in the overlay: the bones
+// and the palm.
+var handOverlayConnections = append(append([][2]int(nil), HandConnections...), HandPalmConnections...)
+
+// poseOverlayConnections are the pose edges drawn in the overlay: those
+// below the head, which the face mesh already covers.
+var poseOverlayConnections = bodyConnections(PoseConnections)
+
+// bodyConnections returns the edges between landmarks from the shoulders down.
+func bodyConnections(edges [][2]int) [][2]int {
+	var body [][2]int
+	for _, e := range edges {
+		if e[0] >= PoseLeftShoulder && e[1] >= PoseLeftShoulder {
+			body = append(body, e)
+		}
+	}
+	return body
+}
+
+// drawOverlay draws the landmarks in data onto img.
+func drawOverlay(img *gocv.Mat, data *TrackingData) {
+	if data == nil {
+		return
+	}
+	width, height := img.Cols(), img.Rows()
+
+	if data.Face != nil {
+		for _, lm := range data.Face.Landmarks {
+			gocv.Circle(img, overlayPoint(lm, width, height), 1, overlayFaceColor, -1) //nolint:errcheck // drawing errors only affect the preview
+		}
+	}
+	if data.LeftHand != nil {
+		drawSkeleton(img, data.LeftHand.Landmarks, handOverlayConnections, overlayLeftHandColor, 0)
+	}
+	if data.RightHand != nil {
+		drawSkeleton(img, data.RightHand.Landmarks, handOverlayConnections, overlayRightHandColor, 0)
+	}
+	if data.Pose != nil {
+		drawSkeleton(img, data.Pose.Landmarks, poseOverlayConnections, overlayPoseColor, overlayMinVisibility)
+	}
+}
+
+// drawSkeleton draws each landmark and the connections between them,
+// skipping landmarks whose Visibility is below minVisibility.
+func drawSkeleton(img *gocv.Mat, landmarks []Landmark, connections [][2]int, c color.RGBA, minVisibility float64) {
+	width, height := img.Cols(), img.Rows()
+	visible := func(i int) bool {
+		return i < len(landmarks) && landmarks[i].Visibility >= minVisibility
+	}
+
+	for _, conn := range connections {
+		if visible(conn[0]) && visible(conn[1]) {
+			gocv.Line(img, overlayPoint(landmarks[conn[0]], width, height), overlayPoint(landmarks[conn[1]], width, height), c, 2) //nolint:errcheck // drawing errors only affect the preview
+		}
+	}
+	for i, lm := range landmarks {
+		if visible(i) {
+			gocv.Circle(img, overlayPoint(lm, width, height), 3, c, -1) //nolint:errcheck // drawing errors only affect the preview
+		}
+	}
+}
+
+// overlayPoint converts a normalized landmark to a pixel position.
+func overlayPoint(lm Landmark, width, height int) image.Point {
+	p := lm.ToPixel(width, height)
+	return image.Pt(int(p.X), int(p.Y))
+}
+
 // Close closes the preview window and releases resources.
 func (p *PreviewWindow) Close() error {
 	p.once.Do(func() {
@@ -4,6 +4,7 @@
 package miface
 
 import (
+	"context"
 	"runtime"
 	"testing"
 	"time"
@@ -73,3 +74,191 @@ func TestPreviewWindow_ShowMultiple(t *testing.T) {
 		time.Sleep(10 * time.Millisecond)
 	}
 }
+
+func TestPreviewWindow_ShowWithOverlay(t *testing.T) {
+	if runtime.GOOS == "darwin" {
+		t.Skip("Skipping GUI test on macOS: NSWindow requires main thread")
+	}
+	preview := NewPreviewWindow("Test Window")
+	defer preview.Close()
+
+	mat := gocv.NewMatWithSize(480, 640, gocv.MatTypeCV8UC3)
+	defer mat.Close()
+
+	full, err := NewStubProcessor(DefaultStubConfig()).Process(context.Background(), nil, 0, 0)
+	if err != nil {
+		t.Fatalf("stub processing failed: %v", err)
+	}
+
+	tests := []struct {
+		name string
+		data *TrackingData
+	}{
+		{"nil data", nil},
+		{"empty data", &TrackingData{}},
+		{"face only", &TrackingData{Face: full.Face}},
+		{"short landmarks", &TrackingData{
+			LeftHand: &HandData{Landmarks: full.LeftHand.Landmarks[:5]},
+			Pose:     &PoseData{Landmarks: full.Pose.Landmarks[:13]},
+		}},
+		{"all modalities", full},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// This should not panic
+			preview.ShowWithOverlay(mat, tt.data)
+			time.Sleep(10 * time.Millisecond)
+		})
+	}
+
+	// The caller's frame is never drawn on
+	pixels, err := mat.DataPtrUint8()
+	if err != nil {
+		t.Fatalf("accessing frame data: %v", err)
+	}
+	for i, v := range pixels {
+		if v != 0 {
+			t.Fatalf("expected original frame to be unmodified, got %d at %d", v, i)
+		}
+	}
+}
+
+func TestFormatHUD(t *testing.T) {
+	stats := TrackerStats{
+		FrameNumber:       42,
+		FPS:               29.96,
+		FaceDetected:      true,
+		RightHandDetected: true,
+	}
+
+	want := []string{
+		"FPS: 30.0",
+		"Frame: 42",
+		"Face: detected",
+		"Hands: L lost, R detected",
+		"Pose: lost",
+	}
+	got := formatHUD(stats)
+	if len(got) != len(want) {
+		t.Fatalf("expected %d lines, got %d: %q", len(want), len(got), got)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("line %d: expected %q, got %q", i, want[i], got[i])
+		}
+	}
+}
+
+func TestPreviewWindow_ShowWithHUD(t *testing.T) {
+	if runtime.GOOS == "darwin" {
+		t.Skip("Skipping GUI test on macOS: NSWindow requires main thread")
+	}
+	preview := NewPreviewWindow("Test Window")
+	defer preview.Close()
+
+	if preview.HUDEnabled() {
+		t.Error("expected HUD to be disabled by default")
+	}
+	preview.SetHUD(true)
+	if !preview.HUDEnabled() {
+		t.Error("expected HUD to be enabled")
+	}
+
+	mat := gocv.NewMatWithSize(480, 640, gocv.MatTypeCV8UC3)
+	defer mat.Close()
+
+	// This should not panic
+	preview.ShowWithHUD(mat, nil, TrackerStats{FPS: 30, FrameNumber: 1})
+	time.Sleep(10 * time.Millisecond)
+}
+
+// keySource feeds simulated key presses to a preview window, one per frame.
+type keySource chan int
+
+func (k keySource) waitKey(delay int) int {
+	select {
+	case code := <-k:
+		return code
+	default:
+		return -1
+	}
+}
+
+func TestPreviewWindow_RegisterKey(t *testing.T) {
+	if runtime.GOOS == "darwin" {
+		t.Skip("Skipping GUI test on macOS: NSWindow requires main thread")
+	}
+	keys := make(keySource, 1)
+	preview := newPreviewWindow("Test Window", keys.waitKey)
+	defer preview.Close()
+
+	pressed := make(chan struct{}, 1)
+	preview.RegisterKey('m', func() { pressed <- struct{}{} })
+
+	mat := gocv.NewMatWithSize(48, 64, gocv.MatTypeCV8UC3)
+	defer mat.Close()
+
+	keys <- 'm'
+	preview.Show(mat)
+
+	select {
+	case <-pressed:
+	case <-time.After(time.Second):
+		t.Fatal("expected key handler to be called")
+	}
+}
+
+func TestPreviewWindow_SlowKeyHandler(t *testing.T) {
+	if runtime.GOOS == "darwin" {
+		t.Skip("Skipping GUI test on macOS: NSWindow requires main thread")
+	}
+	keys := make(keySource, 1)
+	preview := newPreviewWindow("Test Window", keys.waitKey)
+	defer preview.Close()
+
+	release := make(chan struct{})
+	defer close(release)
+	preview.RegisterKey('p', func() { <-release })
+
+	mat := gocv.NewMatWithSize(48, 64, gocv.MatTypeCV8UC3)
+	defer mat.Close()
+
+	keys <- 'p'
+	preview.Show(mat)
+
+	// The display loop keeps consuming frames while the handler blocks
+	for i := 0; i < 5; i++ {
+		deadline := time.After(time.Second)
+		for len(preview.frameCh) > 0 {
+			select {
+			case <-deadline:
+				t.Fatal("expected display loop to keep running")
+			default:
+				time.Sleep(time.Millisecond)
+			}
+		}
+		preview.Show(mat)
+	}
+}
+
+func TestPreviewWindow_QuitKey(t *testing.T) {
+	if runtime.GOOS == "darwin" {
+		t.Skip("Skipping GUI test on macOS: NSWindow requires main thread")
+	}
+	keys := make(keySource, 1)
+	preview := newPreviewWindow("Test Window", keys.waitKey)
+	defer preview.Close()
+
+	mat := gocv.NewMatWithSize(48, 64, gocv.MatTypeCV8UC3)
+	defer mat.Close()
+
+	keys <- 'q'
+	preview.Show(mat)
+
+	select {
+	case <-preview.Done():
+	case <-time.After(time.Second):
+		t.Fatal("expected 'q' to close the window")
+	}
+}
@@ -0,0 +1,97 @@
+package miface
+
+import "math"
+
+// eulerGimbalLimit is how close |sin(pitch)| gets to 1 before ToEuler
+// treats the rotation as gimbal locked.
+const eulerGimbalLimit = 1 - 1e-9
+
+// Mul returns the rotation r followed by q (the Hamilton product q·r).
+func (q Quaternion) Mul(r Quaternion) Quaternion {
+	return Quaternion{
+		X: q.W*r.X + q.X*r.W + q.Y*r.Z - q.Z*r.Y,
+		Y: q.W*r.Y - q.X*r.Z + q.Y*r.W + q.Z*r.X,
+		Z: q.W*r.Z + q.X*r.Y - q.Y*r.X + q.Z*r.W,
+		W: q.W*r.W - q.X*r.X - q.Y*r.Y - q.Z*r.Z,
+	}
+}
+
+// Conjugate returns q with its vector part negated. For a unit quaternion
+// this is the inverse rotation.
+func (q Quaternion) Conjugate() Quaternion {
+	return Quaternion{X: -q.X, Y: -q.Y, Z: -q.Z, W: q.W}
+}
+
+// Dot returns the four-dimensional dot product of q and r.
+func (q Quaternion) Dot(r Quaternion) float64 {
+	return q.X*r.X + q.Y*r.Y + q.Z*r.Z + q.W*r.W
+}
+
+// Normalize returns q scaled to unit length, or the identity if q is zero.
+func (q Quaternion) Normalize() Quaternion {
+	n := math.Sqrt(q.Dot(q))
+	if n == 0 {
+		return Quaternion{W: 1}
+	}
+	return Quaternion{X: q.X / n, Y: q.Y / n, Z: q.Z / n, W: q.W / n}
+}
+
+// ToEuler returns the pitch (X), yaw (Y) and roll (Z) angles in radians of
+// the rotation, in the convention of QuaternionFromEuler. Pitch is in
+// [-π/2, π/2]. At ±π/2 yaw and roll are indistinguishable, so roll is
+// reported as zero.
+func (q Quaternion) ToEuler() (pitch, yaw, roll float64) {
+	q = q.Normalize()
+	sp := -2 * (q.Y*q.Z - q.W*q.X)
+	if math.Abs(sp) >= eulerGimbalLimit {
+		pitch = math.Copysign(math.Pi/2, sp)
+		r00 := 1 - 2*(q.Y*q.Y+q.Z*q.Z)
+		r01 := 2 * (q.X*q.Y - q.W*q.Z)
+		return pitch, math.Atan2(math.Copysign(1, sp)*r01, r00), 0
+	}
+	pitch = math.Asin(sp)
+	yaw = math.Atan2(2*(q.X*q.Z+q.W*q.Y), 1-2*(q.X*q.X+q.Y*q.Y))
+	roll = math.Atan2(2*(q.X*q.Y+q.W*q.Z), 1-2*(q.X*q.X+q.Z*q.Z))
+	return pitch, yaw, roll
+}
+
+// QuaternionFromEuler builds a rotation from pitch (X), yaw (Y) and roll (Z)
+// angles in radians, applied in yaw-pitch-roll order.
+func QuaternionFromEuler(pitch, yaw, roll float64) Quaternion {
+	sp, cp := math.Sincos(pitch / 2)
+	sy, cy := math.Sincos(yaw / 2)
+	sr, cr := math.Sincos(roll / 2)
+
+	return Quaternion{
+		X: cy*sp*cr + sy*cp*sr,
+		Y: sy*cp*cr - cy*sp*sr,
+		Z: cy*cp*sr - sy*sp*cr,
+		W: cy*cp*cr + sy*sp*sr,
+	}
+}
+
+// Slerp interpolates the rotations a and b a fraction t (0 to 1) of the way
+// along the shortest arc. The result is normalized.
+func Slerp(a, b Quaternion, t float64) Quaternion {
+	a, b = a.Normalize(), b.Normalize()
+	d := a.Dot(b)
+	if d < 0 {
+		// q and -q are the same rotation; take the short way round
+		b = Quaternion{X: -b.X, Y: -b.Y, Z: -b.Z, W: -b.W}
+		d = -d
+	}
+
+	wa, wb := 1-t, t
+	if d < 1-1e-9 {
+		theta := math.Acos(d)
+		sin := math.Sin(theta)
+		wa = math.Sin((1-t)*theta) / sin
+		wb = math.Sin(t*theta) / sin
+	}
+	return Quaternion{
+		X: wa*a.X + wb*b.X,
+		Y: wa*a.Y + wb*b.Y,
+		Z: wa*a.Z + wb*b.Z,
+		W: wa*a.W + wb*b.W,
+	}.Normalize()
+}
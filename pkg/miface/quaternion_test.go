@@ -0,0 +1,120 @@
+package miface
+
+import (
+	"math"
+	"testing"
+)
+
+func TestQuaternionMulIdentity(t *testing.T) {
+	identity := Quaternion{W: 1}
+	q := QuaternionFromEuler(0.3, -0.7, 1.1)
+
+	if d := quatDistance(identity.Mul(q), q); d > 1e-6 {
+		t.Errorf("expected identity·q = q, off by %v", d)
+	}
+	if d := quatDistance(q.Mul(identity), q); d > 1e-6 {
+		t.Errorf("expected q·identity = q, off by %v", d)
+	}
+	if d := quatDistance(q.Mul(q.Conjugate()), identity); d > 1e-6 {
+		t.Errorf("expected q·q* = identity, off by %v", d)
+	}
+}
+
+func TestQuaternionAxisRotations(t *testing.T) {
+	s := math.Sqrt2 / 2
+	tests := []struct {
+		name   string
+		q      Quaternion
+		in     Point3D
+		expect Point3D
+	}{
+		{"X", Quaternion{X: s, W: s}, Point3D{Y: 1}, Point3D{Z: 1}},
+		{"Y", Quaternion{Y: s, W: s}, Point3D{Z: 1}, Point3D{X: 1}},
+		{"Z", Quaternion{Z: s, W: s}, Point3D{X: 1}, Point3D{Y: 1}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := rotatePoint(tt.q, tt.in)
+			if got.Sub(tt.expect).Length() > 1e-12 {
+				t.Errorf("expected %v, got %v", tt.expect, got)
+			}
+
+			// Two quarter turns make a half turn
+			half := tt.q.Mul(tt.q)
+			got = rotatePoint(half, tt.in)
+			if got.Add(tt.in).Length() > 1e-12 {
+				t.Errorf("expected half turn to negate %v, got %v", tt.in, got)
+			}
+		})
+	}
+
+	// The same rotations from Euler angles
+	if d := quatDistance(QuaternionFromEuler(math.Pi/2, 0, 0), tests[0].q); d > 1e-6 {
+		t.Errorf("expected pitch π/2 to rotate about X, off by %v", d)
+	}
+	if d := quatDistance(QuaternionFromEuler(0, math.Pi/2, 0), tests[1].q); d > 1e-6 {
+		t.Errorf("expected yaw π/2 to rotate about Y, off by %v", d)
+	}
+	if d := quatDistance(QuaternionFromEuler(0, 0, math.Pi/2), tests[2].q); d > 1e-6 {
+		t.Errorf("expected roll π/2 to rotate about Z, off by %v", d)
+	}
+}
+
+func TestQuaternionNormalize(t *testing.T) {
+	q := Quaternion{X: 1, Y: 2, Z: 3, W: 4}.Normalize()
+	if n := q.Dot(q); math.Abs(n-1) > 1e-12 {
+		t.Errorf("expected unit length, got squared length %v", n)
+	}
+	if q := (Quaternion{}).Normalize(); q != (Quaternion{W: 1}) {
+		t.Errorf("expected zero to normalize to identity, got %v", q)
+	}
+}
+
+func TestQuaternionEulerRoundTrip(t *testing.T) {
+	angles := [][3]float64{
+		{0, 0, 0},
+		{0.3, 0, 0},
+		{0, -0.8, 0},
+		{0, 0, 1.2},
+		{0.4, -1.1, 0.7},
+		{-1.2, 2.5, -2.9},
+		{1.5, 3.0, 0.1},
+	}
+	for _, a := range angles {
+		q := QuaternionFromEuler(a[0], a[1], a[2])
+		pitch, yaw, roll := q.ToEuler()
+		if math.Abs(pitch-a[0]) > 1e-9 || math.Abs(yaw-a[1]) > 1e-9 || math.Abs(roll-a[2]) > 1e-9 {
+			t.Errorf("expected %v, got (%v, %v, %v)", a, pitch, yaw, roll)
+		}
+	}
+
+	// Gimbal lock: yaw and roll combine, but the rotation survives
+	for _, pitch := range []float64{math.Pi / 2, -math.Pi / 2} {
+		q := QuaternionFromEuler(pitch, 0.5, 0.3)
+		p, y, r := q.ToEuler()
+		if math.Abs(p-pitch) > 1e-9 {
+			t.Errorf("expected pitch %v, got %v", pitch, p)
+		}
+		if d := quatDistance(QuaternionFromEuler(p, y, r), q); d > 1e-6 {
+			t.Errorf("expected gimbal-locked round trip at pitch %v, off by %v", pitch, d)
+		}
+	}
+}
+
+func TestSlerp(t *testing.T) {
+	a := QuaternionFromEuler(0, 0, 0)
+	b := QuaternionFromEuler(0, math.Pi/2, 0)
+
+	if d := quatDistance(Slerp(a, b, 0), a); d > 1e-6 {
+		t.Errorf("expected t=0 to give a, off by %v", d)
+	}
+	if d := quatDistance(Slerp(a, b, 1), b); d > 1e-6 {
+		t.Errorf("expected t=1 to give b, off by %v", d)
+	}
+	if d := quatDistance(Slerp(a, b, 0.5), QuaternionFromEuler(0, math.Pi/4, 0)); d > 1e-6 {
+		t.Errorf("expected halfway to be a quarter of π, off by %v", d)
+	}
+	if d := quatDistance(Slerp(a, a, 0.3), a); d > 1e-6 {
+		t.Errorf("expected equal rotations to interpolate to themselves, off by %v", d)
+	}
+}
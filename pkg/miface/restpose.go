@@ -0,0 +1,73 @@
+package miface
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"time"
+)
+
+// DefaultRestPoseTimeout is how long tracking must be absent before the
+// tracker falls back to the rest pose.
+const DefaultRestPoseTimeout = 2 * time.Second
+
+// neutralBlendShapes are the ARKit blend shapes reset by the default rest pose.
+var neutralBlendShapes = []string{
+	"eyeBlinkLeft", "eyeBlinkRight",
+	"eyeLookInLeft", "eyeLookOutLeft", "eyeLookUpLeft", "eyeLookDownLeft",
+	"eyeLookInRight", "eyeLookOutRight", "eyeLookUpRight", "eyeLookDownRight",
+	"jawOpen", "mouthSmileLeft", "mouthSmileRight",
+	"browInnerUp", "browDownLeft", "browDownRight",
+}
+
+// DefaultRestPose returns a neutral idle pose: head centered and facing the
+// camera, all common blend shapes at zero, relaxed hands and a standing body.
+func DefaultRestPose() *TrackingData {
+	face := stubFace(0, 0)
+	face.BlendShapes = make(map[string]float64, len(neutralBlendShapes))
+	for _, name := range neutralBlendShapes {
+		face.BlendShapes[name] = 0
+	}
+	face.HeadRotation = Quaternion{W: 1}
+
+	return &TrackingData{
+		Face:      face,
+		LeftHand:  stubHand(true, 0),
+		RightHand: stubHand(false, 0),
+		Pose:      stubPose(0),
+	}
+}
+
+// LoadRestPose reads a rest pose for Tracker.SetRestPose from a JSON file
+// holding a TrackingData in its encoding/json form, the form JSONSender
+// writes. Only the first value in the file is read, so a line saved from
+// miface -json can be used as is. The timestamp and frame number are
+// cleared, and a face without a head rotation faces the camera.
+func LoadRestPose(path string) (*TrackingData, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("reading rest pose: %w", err)
+	}
+	defer f.Close()
+
+	var pose TrackingData
+	if err := json.NewDecoder(f).Decode(&pose); err != nil {
+		return nil, fmt.Errorf("parsing rest pose: %w", err)
+	}
+	if !hasTracking(&pose) {
+		return nil, errors.New("rest pose has no face, hands or pose")
+	}
+
+	pose.Timestamp = time.Time{}
+	pose.FrameNumber = 0
+	if pose.Face != nil && pose.Face.HeadRotation == (Quaternion{}) {
+		pose.Face.HeadRotation = Quaternion{W: 1}
+	}
+	return &pose, nil
+}
+
+// hasTracking reports whether data contains any tracked modality.
+func hasTracking(data *TrackingData) bool {
+	return data != nil && (data.Face != nil || data.LeftHand != nil || data.RightHand != nil || data.Pose != nil)
+}
@@ -0,0 +1,143 @@
+package miface
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// writeRestPose writes contents to a temporary JSON file and returns its path.
+func writeRestPose(t *testing.T, contents string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "rest.json")
+	if err := os.WriteFile(path, []byte(contents), 0o644); err != nil {
+		t.Fatalf("failed to write rest pose: %v", err)
+	}
+	return path
+}
+
+func TestLoadRestPose(t *testing.T) {
+	// Two frames as written by JSONSender; only the first is used
+	path := writeRestPose(t, `{"Timestamp":"2024-01-02T03:04:05Z","FrameNumber":7,`+
+		`"Face":{"Landmarks":[{"Point":{"X":0.5,"Y":0.4,"Z":0}}],"BlendShapes":{"jawOpen":0.1}},`+
+		`"Pose":{"Landmarks":[{"Point":{"X":0.4,"Y":0.6,"Z":0},"Visibility":1}]}}
+{"Face":{"BlendShapes":{"jawOpen":0.9}}}
+`)
+
+	pose, err := LoadRestPose(path)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if pose.Face == nil || pose.Face.BlendShapes["jawOpen"] != 0.1 {
+		t.Fatalf("expected the first frame's face, got %+v", pose.Face)
+	}
+	if pose.Face.HeadRotation != (Quaternion{W: 1}) {
+		t.Errorf("expected a missing head rotation to face the camera, got %+v", pose.Face.HeadRotation)
+	}
+	if len(pose.Pose.Landmarks) != 1 || pose.Pose.Landmarks[0].Point.Y != 0.6 {
+		t.Errorf("expected the pose landmark, got %+v", pose.Pose)
+	}
+	if !pose.Timestamp.IsZero() || pose.FrameNumber != 0 {
+		t.Errorf("expected timestamp and frame number to be cleared, got %v and %d", pose.Timestamp, pose.FrameNumber)
+	}
+}
+
+func TestLoadRestPoseErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		path string
+	}{
+		{"missing file", filepath.Join(t.TempDir(), "missing.json")},
+		{"invalid JSON", writeRestPose(t, `{"Face":`)},
+		{"no tracking", writeRestPose(t, `{"FrameNumber":1}`)},
+	}
+	for _, tt := range tests {
+		if _, err := LoadRestPose(tt.path); err == nil {
+			t.Errorf("%s: expected an error", tt.name)
+		}
+	}
+}
+
+func TestTrackerLoadedRestPose(t *testing.T) {
+	pose, err := LoadRestPose(writeRestPose(t, `{"Face":{"BlendShapes":{"mouthSmileLeft":0.3}}}`))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	tracker, err := NewTracker(nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer tracker.Close()
+
+	proc := &toggleProcessor{stub: NewStubProcessor(DefaultStubConfig()), enabled: true}
+	sender := &recordingSender{}
+	if err := tracker.SetProcessor(proc); err != nil {
+		t.Fatalf("failed to set processor: %v", err)
+	}
+	if err := tracker.SetVMCSender(sender); err != nil {
+		t.Fatalf("failed to set sender: %v", err)
+	}
+	clock := NewFakeClock(time.Unix(0, 0))
+	if err := tracker.SetClock(clock); err != nil {
+		t.Fatalf("failed to set clock: %v", err)
+	}
+	tracker.SetRestPose(pose, time.Second)
+
+	tracker.processFrame()
+
+	// Tracking is lost past the timeout: the loaded pose is sent
+	proc.enabled = false
+	clock.Advance(time.Second)
+	tracker.processFrame()
+	if len(sender.sent) != 2 {
+		t.Fatalf("expected the rest pose to be sent, got %d frames", len(sender.sent))
+	}
+	rest := sender.sent[1]
+	if rest.Face == nil || rest.Face.BlendShapes["mouthSmileLeft"] != 0.3 {
+		t.Errorf("expected the loaded rest pose, got %+v", rest.Face)
+	}
+	if rest.LeftHand != nil || rest.Pose != nil {
+		t.Error("expected only the modalities in the file")
+	}
+}
+
+func TestTrackerRestPoseAnchorsVMCHips(t *testing.T) {
+	tracker, err := NewTracker(nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer tracker.Close()
+
+	sender, err := NewVMCSender("127.0.0.1", 39543)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	vmcRestHips := func() Point3D {
+		sender.mu.Lock()
+		defer sender.mu.Unlock()
+		return sender.restHips
+	}
+	defaultHips := vmcRestHips()
+
+	rest := DefaultRestPose()
+	rest.Pose.Landmarks[PoseLeftHip].Point.Y += 0.1
+	rest.Pose.Landmarks[PoseRightHip].Point.Y += 0.1
+	want, _ := restAnchors(rest)
+
+	// A rest pose set before the sender is applied to it
+	tracker.SetRestPose(rest, time.Second)
+	if err := tracker.SetVMCSender(sender); err != nil {
+		t.Fatalf("failed to set sender: %v", err)
+	}
+	if got := vmcRestHips(); got != want {
+		t.Errorf("expected rest hips %+v, got %+v", want, got)
+	}
+
+	// Clearing it restores the default anchor on the current sender
+	tracker.SetRestPose(nil, 0)
+	if got := vmcRestHips(); got != defaultHips {
+		t.Errorf("expected default rest hips %+v, got %+v", defaultHips, got)
+	}
+}
@@ -0,0 +1,210 @@
+package miface
+
+import "math"
+
+// Human joint limits for the retargeted arm, so a bad frame can't twist
+// the avatar into an impossible pose.
+const (
+	maxShoulderSwing = 150 * math.Pi / 180 // Upper arm away from the T-pose
+	maxElbowBend     = 150 * math.Pi / 180 // Elbows don't hyperextend
+	maxWristBend     = 80 * math.Pi / 180
+)
+
+// ArmRotation holds the solved local rotations of one arm's bones. Each
+// rotation is relative to the parent bone: the upper arm to the fixed
+// shoulder, the lower arm to the upper arm and the hand to the lower arm.
+// The identity is the VRM T-pose.
+type ArmRotation struct {
+	UpperArm Quaternion
+	LowerArm Quaternion
+	Hand     Quaternion
+}
+
+// Retargeter solves arm bone rotations from pose landmarks with two-bone
+// inverse kinematics. The user's arm extension is transferred to the
+// avatar's arm lengths, and the measured elbow picks the bend plane, so the
+// result fits the avatar's proportions rather than the user's.
+type Retargeter struct {
+	upperArm, lowerArm float64
+}
+
+// NewRetargeter creates a retargeter for an avatar with the given
+// proportions, as returned by VRMSkeleton.GetProportions. Without
+// proportions (nil or zero arm lengths), equal upper and lower arms are
+// assumed.
+func NewRetargeter(proportions *BoneProportions) *Retargeter {
+	r := &Retargeter{upperArm: 1, lowerArm: 1}
+	if proportions != nil && proportions.UpperArmLength > 0 && proportions.LowerArmLength > 0 {
+		r.upperArm = proportions.UpperArmLength
+		r.lowerArm = proportions.LowerArmLength
+	}
+	return r
+}
+
+// Solve returns the rotations of both arms. An arm whose shoulder, elbow
+// or wrist is missing from the pose keeps the identity rotations.
+func (r *Retargeter) Solve(pose *PoseData) (left, right ArmRotation) {
+	left, _, right, _ = r.solve(pose, 0)
+	return left, right
+}
+
+// SolveBones returns the arm rotations keyed by VMC bone name, e.g.
+// LeftUpperArm, ready to send with /VMC/Ext/Bone/Pos.
+func (r *Retargeter) SolveBones(pose *PoseData) map[string]Quaternion {
+	return r.SolveVisibleBones(pose, 0)
+}
+
+// SolveVisibleBones is like SolveBones but only solves from landmarks
+// whose Visibility is at least floor, so occluded landmarks, or those a
+// smoother holds with Visibility 0, don't bend the arms. The bones of an
+// arm whose shoulders, elbow or wrist are below the floor are left out,
+// so the receiver keeps their last rotation; knuckles below it keep the
+// hand in line with the forearm.
+func (r *Retargeter) SolveVisibleBones(pose *PoseData, floor float64) map[string]Quaternion {
+	left, leftOK, right, rightOK := r.solve(pose, floor)
+	bones := make(map[string]Quaternion, 6)
+	if leftOK {
+		bones["LeftUpperArm"] = left.UpperArm
+		bones["LeftLowerArm"] = left.LowerArm
+		bones["LeftHand"] = left.Hand
+	}
+	if rightOK {
+		bones["RightUpperArm"] = right.UpperArm
+		bones["RightLowerArm"] = right.LowerArm
+		bones["RightHand"] = right.Hand
+	}
+	return bones
+}
+
+// solve solves both arms from the landmarks at or above floor, and reports
+// for each whether its joints were visible. A missing pose gives identity
+// rotations, reported as solved.
+func (r *Retargeter) solve(pose *PoseData, floor float64) (left ArmRotation, leftOK bool, right ArmRotation, rightOK bool) {
+	identity := ArmRotation{UpperArm: Quaternion{W: 1}, LowerArm: Quaternion{W: 1}, Hand: Quaternion{W: 1}}
+	if pose == nil {
+		return identity, true, identity, true
+	}
+
+	left, leftOK = r.solveArm(pose.Landmarks, floor, PoseRightShoulder,
+		PoseLeftShoulder, PoseLeftElbow, PoseLeftWrist, PoseLeftIndex, PoseLeftPinky)
+	right, rightOK = r.solveArm(pose.Landmarks, floor, PoseLeftShoulder,
+		PoseRightShoulder, PoseRightElbow, PoseRightWrist, PoseRightIndex, PoseRightPinky)
+	return left, leftOK, right, rightOK
+}
+
+// solveArm solves one arm. In the T-pose the arm continues the line from
+// the other shoulder through its own, which holds for mirrored and
+// unmirrored images alike. It returns false, with identity rotations, if
+// either shoulder, the elbow or the wrist is below floor.
+func (r *Retargeter) solveArm(lms []Landmark, floor float64, otherShoulderIdx, shoulderIdx, elbowIdx, wristIdx, indexIdx, pinkyIdx int) (ArmRotation, bool) {
+	rot := ArmRotation{UpperArm: Quaternion{W: 1}, LowerArm: Quaternion{W: 1}, Hand: Quaternion{W: 1}}
+	if len(lms) <= wristIdx {
+		return rot, true
+	}
+	for _, i := range []int{otherShoulderIdx, shoulderIdx, elbowIdx, wristIdx} {
+		if lms[i].Visibility < floor {
+			return rot, false
+		}
+	}
+
+	// Work with Y up so the rotations match the avatar's space
+	shoulder := ImageToNormalized(lms[shoulderIdx].Point)
+	elbow := ImageToNormalized(lms[elbowIdx].Point).Sub(shoulder)
+	wrist := ImageToNormalized(lms[wristIdx].Point).Sub(shoulder)
+	rest := shoulder.Sub(ImageToNormalized(lms[otherShoulderIdx].Point)).Normalize()
+	if rest == (Point3D{}) {
+		return rot, true
+	}
+
+	userLength := elbow.Length() + wrist.Sub(elbow).Length()
+	if userLength == 0 {
+		return rot, true
+	}
+
+	// Reach the same fraction of the avatar's arm length as the user does
+	a, b := r.upperArm, r.lowerArm
+	reach := math.Min(wrist.Length()/userLength, 1)
+	d := math.Max(reach*(a+b), math.Abs(a-b)+1e-9)
+	d = math.Min(d, a+b)
+
+	target := wrist.Normalize()
+	if target == (Point3D{}) {
+		target = elbow.Normalize()
+	}
+	if target == (Point3D{}) {
+		return rot, true
+	}
+
+	// The elbow bends toward the measured elbow, or down if the arm is straight
+	pole := elbow.Sub(target.Scale(elbow.Dot(target))).Normalize()
+	if pole == (Point3D{}) {
+		down := Point3D{Y: -1}
+		pole = down.Sub(target.Scale(down.Dot(target))).Normalize()
+	}
+	if pole == (Point3D{}) {
+		pole = Point3D{Z: 1}
+	}
+
+	// Law of cosines for the angle between the upper arm and the target
+	cosAlpha := clampUnit((a*a + d*d - b*b) / (2 * a * d))
+	alpha := math.Acos(cosAlpha)
+	upperDir := target.Scale(cosAlpha).Add(pole.Scale(math.Sin(alpha)))
+	upperDir = limitDirection(rest, upperDir, maxShoulderSwing)
+
+	foreDir := target.Scale(d).Sub(upperDir.Scale(a)).Normalize()
+	if foreDir == (Point3D{}) {
+		foreDir = upperDir
+	}
+	foreDir = limitDirection(upperDir, foreDir, maxElbowBend)
+
+	handDir := foreDir
+	if len(lms) > pinkyIdx && len(lms) > indexIdx &&
+		lms[indexIdx].Visibility >= floor && lms[pinkyIdx].Visibility >= floor {
+		knuckles := ImageToNormalized(Centroid(lms, []int{indexIdx, pinkyIdx}))
+		if dir := knuckles.Sub(shoulder).Sub(wrist).Normalize(); dir != (Point3D{}) {
+			handDir = limitDirection(foreDir, dir, maxWristBend)
+		}
+	}
+
+	upper := quatFromTo(rest, upperDir)
+	lower := quatFromTo(upperDir, foreDir).Mul(upper)
+	hand := quatFromTo(foreDir, handDir).Mul(lower)
+
+	rot.UpperArm = upper
+	rot.LowerArm = upper.Conjugate().Mul(lower)
+	rot.Hand = lower.Conjugate().Mul(hand)
+	return rot, true
+}
+
+// limitDirection rotates the unit vector to toward from, within their
+// common plane, until it is at most maxAngle away from from.
+func limitDirection(from, to Point3D, maxAngle float64) Point3D {
+	if math.Acos(clampUnit(from.Dot(to))) <= maxAngle {
+		return to
+	}
+	perp := to.Sub(from.Scale(from.Dot(to))).Normalize()
+	if perp == (Point3D{}) {
+		// Pointing straight back: any perpendicular will do
+		perp = from.Cross(Point3D{Y: 1}).Normalize()
+		if perp == (Point3D{}) {
+			perp = from.Cross(Point3D{X: 1}).Normalize()
+		}
+	}
+	return from.Scale(math.Cos(maxAngle)).Add(perp.Scale(math.Sin(maxAngle)))
+}
+
+// quatFromTo returns the shortest rotation taking unit vector from to unit
+// vector to.
+func quatFromTo(from, to Point3D) Quaternion {
+	d := from.Dot(to)
+	if d < -1+1e-9 {
+		// Opposite vectors: rotate half a turn about any perpendicular axis
+		axis := from.Cross(Point3D{Y: 1}).Normalize()
+		if axis == (Point3D{}) {
+			axis = from.Cross(Point3D{X: 1}).Normalize()
+		}
+		return Quaternion{X: axis.X, Y: axis.Y, Z: axis.Z}
+	}
+	c := from.Cross(to)
+	return Quaternion{X: c.X, Y: c.Y, Z: c.Z, W: 1 + d}.Normalize()
+}
@@ -0,0 +1,150 @@
+package miface
+
+import (
+	"math"
+	"testing"
+)
+
+// quatAngle returns the rotation angle of the unit quaternion q in radians.
+func quatAngle(q Quaternion) float64 {
+	return 2 * math.Acos(math.Min(math.Abs(q.W), 1))
+}
+
+// armPose returns an upright pose with the arms set from shoulder, elbow and
+// wrist offsets in image coordinates. The right arm mirrors the left.
+func armPose(elbow, wrist Point3D) *PoseData {
+	pose := uprightPose()
+	lms := pose.Landmarks
+	mirror := func(p Point3D) Point3D { return Point3D{X: -p.X, Y: p.Y, Z: p.Z} }
+	for _, arm := range []struct {
+		shoulder, elbow, wrist, index, pinky int
+		offset                               func(Point3D) Point3D
+	}{
+		{PoseLeftShoulder, PoseLeftElbow, PoseLeftWrist, PoseLeftIndex, PoseLeftPinky, mirror},
+		{PoseRightShoulder, PoseRightElbow, PoseRightWrist, PoseRightIndex, PoseRightPinky, func(p Point3D) Point3D { return p }},
+	} {
+		s := lms[arm.shoulder].Point
+		lms[arm.elbow].Point = s.Add(arm.offset(elbow))
+		lms[arm.wrist].Point = s.Add(arm.offset(wrist))
+		// Hand in line with the forearm
+		hand := s.Add(arm.offset(wrist.Add(wrist.Sub(elbow).Scale(0.2))))
+		lms[arm.index].Point = hand
+		lms[arm.pinky].Point = hand
+	}
+	return pose
+}
+
+func TestRetargeterTPose(t *testing.T) {
+	// Arms straight out along the shoulder line
+	pose := armPose(Point3D{X: 0.15}, Point3D{X: 0.3})
+	left, right := NewRetargeter(nil).Solve(pose)
+
+	for name, q := range map[string]Quaternion{
+		"left upper": left.UpperArm, "left lower": left.LowerArm, "left hand": left.Hand,
+		"right upper": right.UpperArm, "right lower": right.LowerArm, "right hand": right.Hand,
+	} {
+		if angle := quatAngle(q); angle > 1e-6 {
+			t.Errorf("%s: expected identity in the T-pose, got %f rad", name, angle)
+		}
+	}
+}
+
+func TestRetargeterBentArm(t *testing.T) {
+	// Upper arm straight out, forearm pointing up: a right-angle elbow
+	pose := armPose(Point3D{X: 0.15}, Point3D{X: 0.15, Y: -0.15})
+	left, right := NewRetargeter(nil).Solve(pose)
+
+	for name, arm := range map[string]ArmRotation{"left": left, "right": right} {
+		elbow := quatAngle(arm.LowerArm)
+		if elbow < 30*math.Pi/180 {
+			t.Errorf("%s: expected a non-trivial elbow rotation, got %f rad", name, elbow)
+		}
+		if elbow > maxElbowBend+1e-9 {
+			t.Errorf("%s: expected elbow within %f rad, got %f", name, maxElbowBend, elbow)
+		}
+		// Equal arm lengths keep the user's right angle
+		if math.Abs(elbow-math.Pi/2) > 1e-6 {
+			t.Errorf("%s: expected a right-angle elbow, got %f rad", name, elbow)
+		}
+		if shoulder := quatAngle(arm.UpperArm); shoulder > maxShoulderSwing+1e-9 {
+			t.Errorf("%s: expected shoulder within %f rad, got %f", name, maxShoulderSwing, shoulder)
+		}
+	}
+
+	// Both arms bend by the same amount
+	if d := math.Abs(quatAngle(left.LowerArm) - quatAngle(right.LowerArm)); d > 1e-9 {
+		t.Errorf("expected symmetric elbows, got difference %f", d)
+	}
+}
+
+func TestRetargeterClampsElbow(t *testing.T) {
+	// Wrist folded back onto the shoulder, beyond what an elbow can bend
+	pose := armPose(Point3D{X: 0.15}, Point3D{X: 0.001, Y: -0.001})
+	left, right := NewRetargeter(nil).Solve(pose)
+
+	for name, arm := range map[string]ArmRotation{"left": left, "right": right} {
+		if elbow := quatAngle(arm.LowerArm); elbow > maxElbowBend+1e-9 {
+			t.Errorf("%s: expected elbow clamped to %f rad, got %f", name, maxElbowBend, elbow)
+		}
+	}
+}
+
+func TestRetargeterProportions(t *testing.T) {
+	// The user's arm is fully extended, so the avatar's is too, whatever its lengths
+	pose := armPose(Point3D{Y: 0.15}, Point3D{Y: 0.3})
+	props := &BoneProportions{UpperArmLength: 0.3, LowerArmLength: 0.2}
+	left, _ := NewRetargeter(props).Solve(pose)
+
+	if elbow := quatAngle(left.LowerArm); elbow > 1e-6 {
+		t.Errorf("expected a straight elbow, got %f rad", elbow)
+	}
+	if shoulder := quatAngle(left.UpperArm); math.Abs(shoulder-math.Pi/2) > 1e-6 {
+		t.Errorf("expected the arm lowered by a right angle, got %f rad", shoulder)
+	}
+}
+
+func TestRetargeterMissingLandmarks(t *testing.T) {
+	r := NewRetargeter(nil)
+	for _, pose := range []*PoseData{nil, {Landmarks: make([]Landmark, 12)}} {
+		left, right := r.Solve(pose)
+		if quatAngle(left.UpperArm) != 0 || quatAngle(right.LowerArm) != 0 {
+			t.Errorf("expected identity rotations, got %+v %+v", left, right)
+		}
+	}
+
+	bones := r.SolveBones(nil)
+	if len(bones) != 6 {
+		t.Errorf("expected 6 arm bones, got %d", len(bones))
+	}
+}
+
+func TestRetargeterVisibilityFloor(t *testing.T) {
+	r := NewRetargeter(nil)
+	pose := armPose(Point3D{X: 0.15}, Point3D{X: 0.15, Y: -0.15})
+
+	// A held wrist has Visibility 0: the left arm isn't solved from it
+	pose.Landmarks[PoseLeftWrist].Visibility = 0
+	bones := r.SolveVisibleBones(pose, 0.5)
+	for _, name := range []string{"LeftUpperArm", "LeftLowerArm", "LeftHand"} {
+		if _, ok := bones[name]; ok {
+			t.Errorf("expected %s left out with its wrist below the floor", name)
+		}
+	}
+	_, right := r.Solve(pose)
+	if q, ok := bones["RightLowerArm"]; !ok || q != right.LowerArm {
+		t.Errorf("expected the visible right arm solved as %+v, got %+v (present=%v)", right.LowerArm, q, ok)
+	}
+
+	// Without a floor every landmark counts
+	if bones := r.SolveVisibleBones(pose, 0); len(bones) != 6 {
+		t.Errorf("expected 6 arm bones without a floor, got %d", len(bones))
+	}
+
+	// Hidden knuckles keep the hand in line with the forearm
+	pose.Landmarks[PoseLeftWrist].Visibility = 1
+	pose.Landmarks[PoseLeftIndex].Point.Y += 0.1
+	pose.Landmarks[PoseLeftIndex].Visibility = 0
+	if hand := r.SolveVisibleBones(pose, 0.5)["LeftHand"]; quatAngle(hand) > 1e-9 {
+		t.Errorf("expected a straight wrist with the knuckles hidden, got %f rad", quatAngle(hand))
+	}
+}
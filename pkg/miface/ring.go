@@ -0,0 +1,50 @@
+package miface
+
+// frameRing is a fixed-capacity circular buffer of frames; once full, each
+// push overwrites the oldest frame.
+type frameRing struct {
+	frames []*TrackingData
+	next   int // Index the next push writes to
+	full   bool
+}
+
+// newFrameRing creates a ring holding up to capacity frames.
+func newFrameRing(capacity int) *frameRing {
+	return &frameRing{frames: make([]*TrackingData, capacity)}
+}
+
+// push adds a frame, dropping the oldest if the ring is full.
+func (r *frameRing) push(data *TrackingData) {
+	if len(r.frames) == 0 {
+		return
+	}
+	r.frames[r.next] = data
+	r.next = (r.next + 1) % len(r.frames)
+	if r.next == 0 {
+		r.full = true
+	}
+}
+
+// snapshot returns the frames oldest first.
+func (r *frameRing) snapshot() []*TrackingData {
+	if !r.full {
+		return append([]*TrackingData(nil), r.frames[:r.next]...)
+	}
+	out := make([]*TrackingData, 0, len(r.frames))
+	out = append(out, r.frames[r.next:]...)
+	return append(out, r.frames[:r.next]...)
+}
+
+// resize returns a ring with the given capacity holding the newest frames
+// of r.
+func (r *frameRing) resize(capacity int) *frameRing {
+	resized := newFrameRing(capacity)
+	frames := r.snapshot()
+	if len(frames) > capacity {
+		frames = frames[len(frames)-capacity:]
+	}
+	for _, data := range frames {
+		resized.push(data)
+	}
+	return resized
+}
@@ -5,9 +5,72 @@ import (
 	"fmt"
 	"math"
 	"net"
+	"strconv"
 	"sync"
+	"time"
 )
 
+// CoordinateMode selects how VMCSender converts landmark positions for
+// /VMC/Ext/Bone/Pos messages.
+type CoordinateMode int
+
+const (
+	// CoordBoneLocal sends each bone's offset from its parent bone, in the
+	// axes of CoordNormalized. This matches the bone-local transforms the VMC
+	// protocol specifies and is what VSeeFace expects. Bones without a
+	// parent in the tracking data (the wrists, upper arms and hips) are sent
+	// as in CoordNormalized. This is the default.
+	CoordBoneLocal CoordinateMode = iota
+	// CoordNormalized sends positions centered on the image in the
+	// sender's axis convention, by default as ImageToNormalized converts
+	// them: Y up and Z toward the camera.
+	CoordNormalized
+	// CoordRawImage sends positions as MediaPipe reports them: X and Y
+	// normalized to [0, 1] with the origin at the top-left and Y down.
+	CoordRawImage
+)
+
+func (m CoordinateMode) String() string {
+	switch m {
+	case CoordBoneLocal:
+		return "bone-local"
+	case CoordNormalized:
+		return "normalized"
+	case CoordRawImage:
+		return "raw-image"
+	default:
+		return "unknown"
+	}
+}
+
+// Position converts the image position p of a bone without a parent,
+// in AxesMediaPipeToVRM axes.
+func (m CoordinateMode) Position(p Point3D) Point3D {
+	return m.position(p, AxesMediaPipeToVRM)
+}
+
+// LocalPosition converts the image position p of a bone whose parent bone
+// is at the image position parent, in AxesMediaPipeToVRM axes.
+func (m CoordinateMode) LocalPosition(p, parent Point3D) Point3D {
+	return m.localPosition(p, parent, AxesMediaPipeToVRM)
+}
+
+// position is Position in the given axes.
+func (m CoordinateMode) position(p Point3D, axes AxisConvention) Point3D {
+	if m == CoordRawImage {
+		return p
+	}
+	return axes.Position(p)
+}
+
+// localPosition is LocalPosition in the given axes.
+func (m CoordinateMode) localPosition(p, parent Point3D, axes AxisConvention) Point3D {
+	if m != CoordBoneLocal {
+		return m.position(p, axes)
+	}
+	return axes.Position(p).Sub(axes.Position(parent))
+}
+
 // VMCSender sends tracking data using the VMC (Virtual Motion Capture) protocol.
 // VMC is an OSC-based protocol commonly used by VTuber applications.
 type VMCSender struct {
@@ -15,11 +78,66 @@ type VMCSender struct {
 	conn    *net.UDPConn
 	addr    *net.UDPAddr
 	enabled bool
+
+	// network is the address family: "udp" (either), "udp4" or "udp6".
+	network string
+	// target is the destination "host:port", resolved again whenever the
+	// network changes.
+	target string
+	// local is the local "host:port" datagrams are sent from ("" = OS choice).
+	local string
+
+	// minPresence skips hand bones whose landmark presence is below it (0 = send all).
+	minPresence float64
+	// minHandConfidence skips hands whose detection confidence is below it (0 = send all).
+	minHandConfidence float64
+	// lockLowerBody skips leg bones and pins the hips so the avatar's lower
+	// body stays at rest.
+	lockLowerBody bool
+	// handVisibilityFloor and poseVisibilityFloor skip bones whose landmark
+	// Visibility is below them (0 = send all).
+	handVisibilityFloor, poseVisibilityFloor float64
+	// coordMode converts landmark positions for bone messages.
+	coordMode CoordinateMode
+	// axes is the axis convention of bone positions and rotations.
+	axes AxisConvention
+	// rotationAxes converts rotations from the pipeline's VRM axes to axes.
+	rotationAxes AxisConvention
+	// sendBuffer is the socket send buffer size in bytes (0 = OS default).
+	sendBuffer int
+	// writeTimeout bounds each Send so a stuck socket can't block the
+	// caller indefinitely (0 = no deadline).
+	writeTimeout time.Duration
+	// blendShapeMapper renames blend shapes before sending (nil = unchanged).
+	blendShapeMapper *BlendShapeMapper
+	// retargeter solves arm bone rotations (nil = identity rotations).
+	retargeter *Retargeter
+	// restHips and restShoulders are the hip and shoulder centers of the
+	// rest pose, which anchor the Hips bone and spine while the hips are
+	// locked or untracked.
+	restHips, restShoulders Point3D
+
+	// deltaEpsilon skips bone and blend shape messages whose values moved by
+	// no more than it since they were last sent (0 = send everything).
+	deltaEpsilon float64
+	// keyframeInterval is how many Sends apart every message is sent
+	// regardless of changes, so late-joining receivers sync.
+	keyframeInterval int
+	// sinceKeyframe counts Sends since the last keyframe.
+	sinceKeyframe int
+	// keyframe is whether the Send in progress sends every message.
+	keyframe bool
+	// lastBones and lastBlendShapes are the values last sent, by name.
+	lastBones       map[string][7]float32
+	lastBlendShapes map[string]float32
 }
 
-// NewVMCSender creates a new VMC protocol sender.
+// NewVMCSender creates a new VMC protocol sender. address may be an IPv4
+// or IPv6 literal, without brackets, or a hostname resolved to either
+// family; SetNetwork restricts it to one.
 func NewVMCSender(address string, port int) (*VMCSender, error) {
-	addr, err := net.ResolveUDPAddr("udp", fmt.Sprintf("%s:%d", address, port))
+	target := net.JoinHostPort(address, strconv.Itoa(port))
+	addr, err := net.ResolveUDPAddr("udp", target)
 	if err != nil {
 		return nil, fmt.Errorf("resolving VMC address: %w", err)
 	}
@@ -29,111 +147,626 @@ func NewVMCSender(address string, port int) (*VMCSender, error) {
 		return nil, fmt.Errorf("connecting to VMC endpoint: %w", err)
 	}
 
+	restHips, restShoulders := restAnchors(nil)
 	return &VMCSender{
-		conn:    conn,
-		addr:    addr,
-		enabled: true,
+		conn:          conn,
+		addr:          addr,
+		network:       "udp",
+		target:        target,
+		enabled:       true,
+		axes:          AxesMediaPipeToVRM,
+		rotationAxes:  AxesMediaPipeToVRM.fromVRM(),
+		restHips:      restHips,
+		restShoulders: restShoulders,
 	}, nil
 }
 
+// SetTarget redirects output to a new destination address and port.
+// The previous connection is closed once the new one is established.
+func (v *VMCSender) SetTarget(address string, port int) error {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	return v.redial(v.network, net.JoinHostPort(address, strconv.Itoa(port)), v.local)
+}
+
+// SetNetwork restricts the address family to "udp4" or "udp6", for
+// IPv6-only or dual-stack setups where a hostname resolves to both, or
+// lifts the restriction with "udp". The target and local address are
+// resolved again in the new family. The setting is kept across SetTarget.
+func (v *VMCSender) SetNetwork(network string) error {
+	switch network {
+	case "udp", "udp4", "udp6":
+	default:
+		return fmt.Errorf("VMC network must be \"udp\", \"udp4\" or \"udp6\", got %q", network)
+	}
+
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	return v.redial(network, v.target, v.local)
+}
+
+// SetLocalAddr binds the sender to a local "host:port" address, so
+// datagrams originate from a known port or interface, as some receivers
+// and firewalls require. An empty host binds all interfaces and port 0
+// picks an ephemeral port. An empty address restores the OS's choice of
+// both. The setting is kept across SetTarget.
+func (v *VMCSender) SetLocalAddr(address string) error {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	return v.redial(v.network, v.target, address)
+}
+
+// LocalAddr returns the local address datagrams are sent from, or nil if
+// the sender has no connection.
+func (v *VMCSender) LocalAddr() net.Addr {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	if v.conn == nil {
+		return nil
+	}
+	return v.conn.LocalAddr()
+}
+
+// redial resolves target and local in network and replaces the connection
+// with one between them. The previous connection is closed once the new one
+// is established, unless local has a fixed port, which the previous
+// connection may be holding; then it is closed first, and an error leaves
+// the sender without a connection until Send dials the previous settings
+// again. Must be called with v.mu held.
+func (v *VMCSender) redial(network, target, local string) error {
+	addr, err := net.ResolveUDPAddr(network, target)
+	if err != nil {
+		return fmt.Errorf("resolving VMC address: %w", err)
+	}
+	var localAddr *net.UDPAddr
+	if local != "" {
+		localAddr, err = net.ResolveUDPAddr(network, local)
+		if err != nil {
+			return fmt.Errorf("resolving VMC local address: %w", err)
+		}
+	}
+
+	if localAddr != nil && localAddr.Port != 0 && v.conn != nil {
+		_ = v.conn.Close()
+		v.conn = nil
+	}
+
+	conn, err := net.DialUDP(network, localAddr, addr)
+	if err != nil {
+		return fmt.Errorf("connecting to VMC endpoint: %w", err)
+	}
+
+	if v.sendBuffer > 0 {
+		if err := conn.SetWriteBuffer(v.sendBuffer); err != nil {
+			_ = conn.Close()
+			return fmt.Errorf("setting VMC send buffer: %w", err)
+		}
+	}
+
+	if v.conn != nil {
+		_ = v.conn.Close()
+	}
+	v.conn = conn
+	v.addr = addr
+	v.sinceKeyframe = 0
+	v.network = network
+	v.target = target
+	v.local = local
+	return nil
+}
+
+// SetSendBuffer sets the socket send buffer size in bytes. A frame is sent
+// as many datagrams, so a larger buffer avoids drops under bursts on busy
+// networks. The OS may round or cap the size. The setting is kept across
+// SetTarget. A size of 0 leaves the current buffer unchanged.
+func (v *VMCSender) SetSendBuffer(bytes int) error {
+	if bytes < 0 {
+		return fmt.Errorf("send buffer size must not be negative, got %d", bytes)
+	}
+
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	if bytes > 0 && v.conn != nil {
+		if err := v.conn.SetWriteBuffer(bytes); err != nil {
+			return fmt.Errorf("setting VMC send buffer: %w", err)
+		}
+	}
+	v.sendBuffer = bytes
+	return nil
+}
+
+// SetWriteTimeout sets how long a single Send may spend writing before it
+// fails, so a stuck socket doesn't block the tracking loop indefinitely.
+// A timeout of 0 disables the deadline.
+func (v *VMCSender) SetWriteTimeout(timeout time.Duration) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	v.writeTimeout = timeout
+}
+
+// SetEnabled enables or disables sending without closing the connection.
+func (v *VMCSender) SetEnabled(enabled bool) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	v.enabled = enabled
+}
+
+// SetMinPresence sets the landmark presence threshold for hand bones.
+// Bones whose landmark Presence is below threshold (e.g. occluded fingers)
+// are not sent. A threshold of 0 disables the check.
+func (v *VMCSender) SetMinPresence(threshold float64) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	v.minPresence = threshold
+}
+
+// SetMinHandConfidence sets the hand detection confidence threshold.
+// Hands with Confidence below threshold are not sent, so a hand that left
+// the frame is not frozen at its last low-confidence pose.
+// A threshold of 0 disables the check.
+func (v *VMCSender) SetMinHandConfidence(threshold float64) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	v.minHandConfidence = threshold
+}
+
+// SetLowerBodyLock enables or disables the lower body lock. While locked,
+// leg bones are not sent, so the receiver keeps the legs in their rest
+// pose, and the Hips bone is pinned at the rest pose's hip center instead
+// of following the hip landmarks.
+func (v *VMCSender) SetLowerBodyLock(locked bool) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	v.lockLowerBody = locked
+}
+
+// SetRestPose sets the rest pose whose hip and shoulder centers anchor the
+// Hips bone while the lower body is locked, and the spine when the hips
+// aren't tracked. A nil pose, or one without pose landmarks, restores
+// DefaultRestPose.
+func (v *VMCSender) SetRestPose(pose *TrackingData) {
+	hips, shoulders := restAnchors(pose)
+
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	v.restHips, v.restShoulders = hips, shoulders
+}
+
+// SetVisibilityFloor sets the landmark visibility below which hand and pose
+// bones are not sent, leaving the receiver's last value in place. The hips
+// are placed between the visible hip landmarks and not sent if neither is.
+// A floor of 0 sends all bones.
+func (v *VMCSender) SetVisibilityFloor(hands, pose float64) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	v.handVisibilityFloor = hands
+	v.poseVisibilityFloor = pose
+}
+
+// SetCoordinateMode sets how landmark positions are converted for bone
+// messages (default: CoordBoneLocal).
+func (v *VMCSender) SetCoordinateMode(mode CoordinateMode) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	v.coordMode = mode
+}
+
+// SetAxisConvention sets the axes bone positions and rotations are sent in
+// (default: AxesMediaPipeToVRM). Positions sent with CoordRawImage are not
+// converted.
+func (v *VMCSender) SetAxisConvention(axes AxisConvention) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	v.axes = axes
+	v.rotationAxes = axes.fromVRM()
+}
+
+// SetBlendShapeMapper sets the mapper that renames blend shapes before they
+// are sent, e.g. NewPerfectSyncMapper for VRM Perfect Sync avatars.
+// A nil mapper sends the names unchanged.
+func (v *VMCSender) SetBlendShapeMapper(mapper *BlendShapeMapper) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	v.blendShapeMapper = mapper
+}
+
+// SetRetargeter sets the retargeter that solves the arm bone rotations,
+// e.g. NewRetargeter with the avatar's VRM proportions. Arm bones are only
+// sent with a retargeter; a nil retargeter sends none. The solved wrist
+// rotations are sent on the Hand bones of tracked hands.
+func (v *VMCSender) SetRetargeter(retargeter *Retargeter) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	v.retargeter = retargeter
+}
+
+// SetDeltaMode enables sending only what changed: bone and blend shape
+// messages whose values moved by no more than epsilon since they were last
+// sent are skipped, which saves bandwidth and CPU while the user holds
+// still. Every keyframeInterval Sends, and after SetTarget, everything is
+// sent regardless so late-joining receivers sync. An epsilon of 0 sends
+// everything every frame, which is the default.
+func (v *VMCSender) SetDeltaMode(epsilon float64, keyframeInterval int) error {
+	if epsilon < 0 {
+		return fmt.Errorf("delta epsilon must not be negative, got %f", epsilon)
+	}
+	if epsilon > 0 && keyframeInterval < 1 {
+		return fmt.Errorf("keyframe interval must be positive, got %d", keyframeInterval)
+	}
+
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	v.deltaEpsilon = epsilon
+	v.keyframeInterval = keyframeInterval
+	v.sinceKeyframe = 0
+	return nil
+}
+
 // Send transmits tracking data via VMC protocol.
 func (v *VMCSender) Send(data *TrackingData) error {
 	v.mu.Lock()
 	defer v.mu.Unlock()
 
-	if !v.enabled || v.conn == nil {
+	if !v.enabled {
 		return nil
 	}
+	if v.conn == nil {
+		// A redial to a fixed local port failed after releasing the old
+		// connection; restore the last working one
+		if err := v.redial(v.network, v.target, v.local); err != nil {
+			return err
+		}
+	}
+
+	v.keyframe = v.deltaEpsilon == 0 || v.sinceKeyframe == 0
+	v.sinceKeyframe++
+	if v.deltaEpsilon > 0 && v.sinceKeyframe >= v.keyframeInterval {
+		v.sinceKeyframe = 0
+	}
+	if v.keyframe && v.deltaEpsilon > 0 {
+		v.lastBones = make(map[string][7]float32)
+		v.lastBlendShapes = make(map[string]float32)
+	}
+
+	var deadline time.Time
+	if v.writeTimeout > 0 {
+		deadline = time.Now().Add(v.writeTimeout)
+	}
+	if err := v.conn.SetWriteDeadline(deadline); err != nil {
+		return fmt.Errorf("setting write deadline: %w", err)
+	}
 
 	// Send head bone position/rotation if face data available
 	if data.Face != nil {
 		// VMC /VMC/Ext/Bone/Pos format: address, bone_name, pos_x, pos_y, pos_z, rot_x, rot_y, rot_z, rot_w
-		msg := buildOSCMessage("/VMC/Ext/Bone/Pos",
-			"Head",
-			float32(data.Face.HeadPosition.X),
-			float32(data.Face.HeadPosition.Y),
-			float32(data.Face.HeadPosition.Z),
-			float32(data.Face.HeadRotation.X),
-			float32(data.Face.HeadRotation.Y),
-			float32(data.Face.HeadRotation.Z),
-			float32(data.Face.HeadRotation.W),
-		)
-		if _, err := v.conn.Write(msg); err != nil {
+		rot := v.rotationAxes.Rotation(data.Face.HeadRotation)
+		pos := v.coordMode.position(data.Face.HeadPosition, v.axes)
+		if err := v.writeBone("Head", pos, rot); err != nil {
 			return fmt.Errorf("sending head bone: %w", err)
 		}
 
 		// Send blend shapes
-		for name, value := range data.Face.BlendShapes {
-			msg := buildOSCMessage("/VMC/Ext/Blend/Val", name, float32(value))
-			if _, err := v.conn.Write(msg); err != nil {
+		blendShapes := data.Face.BlendShapes
+		if v.blendShapeMapper != nil {
+			blendShapes = v.blendShapeMapper.Apply(blendShapes)
+		}
+		changed := v.keyframe
+		for name, value := range blendShapes {
+			sent, err := v.writeBlendShape(name, value)
+			if err != nil {
 				return fmt.Errorf("sending blend shape %s: %w", name, err)
 			}
+			changed = changed || sent
 		}
 
 		// Send blend shape apply signal
-		applyMsg := buildOSCMessage("/VMC/Ext/Blend/Apply")
-		if _, err := v.conn.Write(applyMsg); err != nil {
-			return fmt.Errorf("sending blend apply: %w", err)
+		if changed {
+			applyMsg := buildOSCMessage("/VMC/Ext/Blend/Apply")
+			if _, err := v.conn.Write(applyMsg); err != nil {
+				return fmt.Errorf("sending blend apply: %w", err)
+			}
 		}
 	}
 
+	// Solve the arms first: the wrist rotations go out with the hand bones
+	hasPose := data.Pose != nil && len(data.Pose.Landmarks) > 0
+	var armRotations map[string]Quaternion
+	if hasPose && v.retargeter != nil {
+		armRotations = v.retargeter.SolveVisibleBones(data.Pose, v.poseVisibilityFloor)
+	}
+
 	// Send hand bones if available
 	if data.LeftHand != nil && len(data.LeftHand.Landmarks) > 0 {
-		v.sendHandBones("Left", data.LeftHand)
+		if err := v.sendHandBones("Left", data.LeftHand, armRotations); err != nil {
+			return err
+		}
 	}
 	if data.RightHand != nil && len(data.RightHand.Landmarks) > 0 {
-		v.sendHandBones("Right", data.RightHand)
+		if err := v.sendHandBones("Right", data.RightHand, armRotations); err != nil {
+			return err
+		}
+	}
+
+	// Send body bones if available
+	if hasPose {
+		if err := v.sendPoseBones(data.Pose, armRotations); err != nil {
+			return err
+		}
 	}
 
 	return nil
 }
 
-// sendHandBones sends VMC bone data for a hand.
-func (v *VMCSender) sendHandBones(side string, hand *HandData) {
-	if len(hand.Landmarks) < 21 {
-		return
-	}
-
-	// Map MediaPipe hand landmarks to VMC bone names
-	// MediaPipe indices: 0=Wrist, 1-4=Thumb, 5-8=Index, 9-12=Middle, 13-16=Ring, 17-20=Pinky
-	boneNames := []string{
-		side + "Hand",         // 0: Wrist
-		side + "ThumbProximal", // 1
-		side + "ThumbIntermediate", // 2
-		side + "ThumbDistal",   // 3
-		side + "IndexProximal", // 5
-		side + "IndexIntermediate", // 6
-		side + "IndexDistal",   // 7
-		side + "MiddleProximal", // 9
-		side + "MiddleIntermediate", // 10
-		side + "MiddleDistal",  // 11
-		side + "RingProximal",  // 13
-		side + "RingIntermediate", // 14
-		side + "RingDistal",    // 15
-		side + "LittleProximal", // 17
-		side + "LittleIntermediate", // 18
-		side + "LittleDistal",  // 19
-	}
-
-	landmarkIndices := []int{0, 1, 2, 3, 5, 6, 7, 9, 10, 11, 13, 14, 15, 17, 18, 19}
-
-	for i, boneName := range boneNames {
-		idx := landmarkIndices[i]
-		if idx >= len(hand.Landmarks) {
-			continue
+// writeBone sends a /VMC/Ext/Bone/Pos message, unless delta mode skips it.
+// Must be called with v.mu held.
+func (v *VMCSender) writeBone(name string, p Point3D, q Quaternion) error {
+	// VMC /VMC/Ext/Bone/Pos format: address, bone_name, pos_x, pos_y, pos_z, rot_x, rot_y, rot_z, rot_w
+	values := [7]float32{
+		float32(p.X), float32(p.Y), float32(p.Z),
+		float32(q.X), float32(q.Y), float32(q.Z), float32(q.W),
+	}
+	if last, ok := v.lastBones[name]; ok && !v.keyframe && !v.changed(last[:], values[:]) {
+		return nil
+	}
+
+	msg := buildOSCMessage("/VMC/Ext/Bone/Pos", name,
+		values[0], values[1], values[2], values[3], values[4], values[5], values[6])
+	if _, err := v.conn.Write(msg); err != nil {
+		return err
+	}
+	if v.deltaEpsilon > 0 {
+		v.lastBones[name] = values
+	}
+	return nil
+}
+
+// writeBlendShape sends a /VMC/Ext/Blend/Val message, unless delta mode
+// skips it, and reports whether it was sent. Must be called with v.mu held.
+func (v *VMCSender) writeBlendShape(name string, value float64) (bool, error) {
+	val := float32(value)
+	if last, ok := v.lastBlendShapes[name]; ok && !v.keyframe && !v.changed([]float32{last}, []float32{val}) {
+		return false, nil
+	}
+
+	msg := buildOSCMessage("/VMC/Ext/Blend/Val", name, val)
+	if _, err := v.conn.Write(msg); err != nil {
+		return false, err
+	}
+	if v.deltaEpsilon > 0 {
+		v.lastBlendShapes[name] = val
+	}
+	return true, nil
+}
+
+// changed reports whether any value moved from last by more than the delta
+// epsilon.
+func (v *VMCSender) changed(last, values []float32) bool {
+	for i := range values {
+		if math.Abs(float64(values[i]-last[i])) > v.deltaEpsilon {
+			return true
 		}
+	}
+	return false
+}
+
+// Parent values in the pose bone tables for bones without a parent
+// landmark.
+const (
+	poseNoParent   = -1 // Root of a chain: sent as a position
+	poseHipsParent = -2 // Child of the Hips bone at the hip center
+)
+
+// poseArmBones maps VMC arm bones to the MediaPipe pose landmark at their
+// root and the landmark of their parent bone.
+var poseArmBones = []struct {
+	name          string
+	index, parent int
+}{
+	{"LeftUpperArm", PoseLeftShoulder, poseNoParent},
+	{"RightUpperArm", PoseRightShoulder, poseNoParent},
+	{"LeftLowerArm", PoseLeftElbow, PoseLeftShoulder},
+	{"RightLowerArm", PoseRightElbow, PoseRightShoulder},
+}
+
+// poseLegBones maps VMC leg bones to the MediaPipe pose landmark at their
+// root and the landmark of their parent bone. Of the pose landmarks, 0–16
+// are the head and arms, 17–22 the hand tips (tracked by the hand models),
+// 23/24 the hips, which locate the hip center, and 25–32 the legs. The
+// lower body lock leaves 23–32 unused.
+var poseLegBones = []struct {
+	name          string
+	index, parent int
+}{
+	{"LeftUpperLeg", PoseLeftHip, poseHipsParent},
+	{"RightUpperLeg", PoseRightHip, poseHipsParent},
+	{"LeftLowerLeg", PoseLeftKnee, PoseLeftHip},
+	{"RightLowerLeg", PoseRightKnee, PoseRightHip},
+	{"LeftFoot", PoseLeftAnkle, PoseLeftKnee},
+	{"RightFoot", PoseRightAnkle, PoseRightKnee},
+	{"LeftToes", PoseLeftFootIndex, PoseLeftAnkle},
+	{"RightToes", PoseRightFootIndex, PoseRightAnkle},
+}
+
+// restAnchors returns the hip and shoulder centers of rest, or of
+// DefaultRestPose if rest has no pose landmarks.
+func restAnchors(rest *TrackingData) (hips, shoulders Point3D) {
+	if rest == nil || rest.Pose == nil || len(rest.Pose.Landmarks) <= PoseRightHip {
+		rest = DefaultRestPose()
+	}
+	lms := rest.Pose.Landmarks
+	return Centroid(lms, []int{PoseLeftHip, PoseRightHip}),
+		Centroid(lms, []int{PoseLeftShoulder, PoseRightShoulder})
+}
+
+// The Chest and UpperChest bones sit on the line from the hip center to the
+// shoulder center, at these fractions of its length.
+const (
+	chestFraction      = 0.5
+	upperChestFraction = 0.75
+)
+
+// sendPoseBones sends VMC bone data for the body. Hips are placed at the
+// visibility-weighted center of the two hip landmarks, or with a visibility
+// floor at the center of those above it, or at rest while the lower body is
+// locked. The Chest and UpperChest rotations come from
+// EstimateSpineRotation and their positions from the spine between the hip
+// and shoulder centers; without tracked hips, or while the lower body is
+// locked, the spine keeps its rest-pose length and direction below the
+// shoulders. Arm bones are only sent with their rotations in
+// armRotations, as solved by the retargeter. It stops at the first bone
+// that fails to send.
+func (v *VMCSender) sendPoseBones(pose *PoseData, armRotations map[string]Quaternion) error {
+	lms := pose.Landmarks
+
+	sendBoneRot := func(name string, p Point3D, q Quaternion) error {
+		if err := v.writeBone(name, p, v.rotationAxes.Rotation(q)); err != nil {
+			return fmt.Errorf("sending %s bone: %w", name, err)
+		}
+		return nil
+	}
+	sendBone := func(name string, p Point3D) error {
+		return sendBoneRot(name, p, Quaternion{W: 1})
+	}
+
+	var hips Point3D
+	var hasHips bool
+	switch {
+	case v.lockLowerBody:
+		hips, hasHips = v.restHips, true
+	case len(lms) > PoseRightHip:
+		if v.poseVisibilityFloor > 0 {
+			hips, hasHips = VisibleCentroid(lms, []int{PoseLeftHip, PoseRightHip}, v.poseVisibilityFloor)
+		} else {
+			hips, hasHips = CentroidWeighted(lms, []int{PoseLeftHip, PoseRightHip}), true
+		}
+	}
+	present := func(index int) bool {
+		return index < len(lms) && lms[index].Presence >= v.minPresence &&
+			lms[index].Visibility >= v.poseVisibilityFloor
+	}
+	position := func(index, parent int) Point3D {
+		switch {
+		case parent == poseNoParent:
+			return v.coordMode.position(lms[index].Point, v.axes)
+		case parent == poseHipsParent:
+			return v.coordMode.localPosition(lms[index].Point, hips, v.axes)
+		default:
+			return v.coordMode.localPosition(lms[index].Point, lms[parent].Point, v.axes)
+		}
+	}
+
+	for _, bone := range poseArmBones {
+		if q, ok := armRotations[bone.name]; ok && present(bone.index) {
+			if err := sendBoneRot(bone.name, position(bone.index, bone.parent), q); err != nil {
+				return err
+			}
+		}
+	}
+
+	if hasHips {
+		if err := sendBone("Hips", v.coordMode.position(hips, v.axes)); err != nil {
+			return err
+		}
+	}
+
+	if len(lms) > PoseRightShoulder {
+		chest, upperChest := EstimateSpineRotation(pose)
+		shoulders := Centroid(lms, []int{PoseLeftShoulder, PoseRightShoulder})
+		base := hips
+		if !hasHips || v.lockLowerBody {
+			base = shoulders.Add(v.restHips.Sub(v.restShoulders))
+		}
+		spine := shoulders.Sub(base)
+		chestPoint := base.Add(spine.Scale(chestFraction))
+		upperChestPoint := base.Add(spine.Scale(upperChestFraction))
+		if err := sendBoneRot("Chest", v.coordMode.localPosition(chestPoint, base, v.axes), chest); err != nil {
+			return err
+		}
+		if err := sendBoneRot("UpperChest", v.coordMode.localPosition(upperChestPoint, chestPoint, v.axes), upperChest); err != nil {
+			return err
+		}
+	}
+
+	if v.lockLowerBody {
+		return nil
+	}
+	for _, bone := range poseLegBones {
+		if present(bone.index) {
+			if err := sendBone(bone.name, position(bone.index, bone.parent)); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// handBones maps VMC hand bones, without their "Left" or "Right" prefix,
+// to the MediaPipe hand landmark at their root.
+var handBones = []struct {
+	name  string
+	index int
+}{
+	{"Hand", HandWrist},
+	{"ThumbProximal", HandThumbCMC},
+	{"ThumbIntermediate", HandThumbMCP},
+	{"ThumbDistal", HandThumbIP},
+	{"IndexProximal", HandIndexMCP},
+	{"IndexIntermediate", HandIndexPIP},
+	{"IndexDistal", HandIndexDIP},
+	{"MiddleProximal", HandMiddleMCP},
+	{"MiddleIntermediate", HandMiddlePIP},
+	{"MiddleDistal", HandMiddleDIP},
+	{"RingProximal", HandRingMCP},
+	{"RingIntermediate", HandRingPIP},
+	{"RingDistal", HandRingDIP},
+	{"LittleProximal", HandPinkyMCP},
+	{"LittleIntermediate", HandPinkyPIP},
+	{"LittleDistal", HandPinkyDIP},
+}
+
+// sendHandBones sends VMC bone data for a hand. The Hand bone carries the
+// wrist rotation from armRotations if the retargeter solved one; the finger
+// bones are sent without rotation. It stops at the first bone that fails
+// to send.
+func (v *VMCSender) sendHandBones(side string, hand *HandData, armRotations map[string]Quaternion) error {
+	if len(hand.Landmarks) < HandLandmarkCount || hand.Confidence < v.minHandConfidence {
+		return nil
+	}
+
+	for _, bone := range handBones {
+		idx := bone.index
 		lm := hand.Landmarks[idx]
-		msg := buildOSCMessage("/VMC/Ext/Bone/Pos",
-			boneName,
-			float32(lm.Point.X),
-			float32(lm.Point.Y),
-			float32(lm.Point.Z),
-			float32(0), // rot_x
-			float32(0), // rot_y
-			float32(0), // rot_z
-			float32(1), // rot_w (identity quaternion)
-		)
-		_, _ = v.conn.Write(msg)
+		if lm.Presence < v.minPresence || lm.Visibility < v.handVisibilityFloor {
+			continue
+		}
+		p := v.coordMode.position(lm.Point, v.axes)
+		if parent := handParentLandmark(idx); parent >= 0 {
+			p = v.coordMode.localPosition(lm.Point, hand.Landmarks[parent].Point, v.axes)
+		}
+		q := Quaternion{W: 1}
+		if wrist, ok := armRotations[side+bone.name]; ok {
+			q = v.rotationAxes.Rotation(wrist)
+		}
+		if err := v.writeBone(side+bone.name, p, q); err != nil {
+			return fmt.Errorf("sending %s bone: %w", side+bone.name, err)
+		}
+	}
+	return nil
+}
+
+// handParentLandmark returns the MediaPipe hand landmark of the parent joint
+// of landmark idx, or -1 for the wrist. Each finger's first joint hangs off
+// the wrist and every other joint off the previous one.
+func handParentLandmark(idx int) int {
+	switch idx {
+	case HandWrist:
+		return -1
+	case HandThumbCMC, HandIndexMCP, HandMiddleMCP, HandRingMCP, HandPinkyMCP:
+		return HandWrist
+	default:
+		return idx - 1
 	}
 }
 
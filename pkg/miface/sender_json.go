@@ -0,0 +1,68 @@
+package miface
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+)
+
+// JSONSender writes each frame as one JSON object per line (JSON Lines),
+// for piping tracking data into tools such as jq. Frames use the default
+// encoding/json form of TrackingData.
+//
+// Output is buffered; call Flush to push buffered frames out early. Close
+// flushes but does not close the underlying writer.
+type JSONSender struct {
+	mu     sync.Mutex
+	w      *bufio.Writer
+	enc    *json.Encoder
+	closed bool
+}
+
+// NewJSONSender creates a sender writing JSON lines to w, e.g. os.Stdout.
+func NewJSONSender(w io.Writer) *JSONSender {
+	bw := bufio.NewWriter(w)
+	return &JSONSender{
+		w:   bw,
+		enc: json.NewEncoder(bw),
+	}
+}
+
+// Send writes data as a single JSON line.
+func (s *JSONSender) Send(data *TrackingData) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.closed {
+		return errors.New("sender is closed")
+	}
+	if err := s.enc.Encode(data); err != nil {
+		return fmt.Errorf("encoding frame %d: %w", data.FrameNumber, err)
+	}
+	return nil
+}
+
+// Flush writes any buffered frames to the underlying writer.
+func (s *JSONSender) Flush() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.w.Flush()
+}
+
+// Close flushes buffered frames. Later sends fail.
+func (s *JSONSender) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.closed {
+		return nil
+	}
+	s.closed = true
+	if err := s.w.Flush(); err != nil {
+		return fmt.Errorf("flushing JSON output: %w", err)
+	}
+	return nil
+}
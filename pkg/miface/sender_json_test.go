@@ -0,0 +1,70 @@
+package miface
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"testing"
+	"time"
+)
+
+func TestJSONSender(t *testing.T) {
+	var buf bytes.Buffer
+	sender := NewJSONSender(&buf)
+
+	frames := []*TrackingData{
+		{
+			Timestamp:   time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+			FrameNumber: 1,
+			Face: &FaceData{
+				BlendShapes:  map[string]float64{"jawOpen": 0.25},
+				HeadRotation: Quaternion{W: 1},
+			},
+			LeftHand: testHand(true, 0.9),
+		},
+		{FrameNumber: 2, Pose: testPose()},
+	}
+	for _, data := range frames {
+		if err := sender.Send(data); err != nil {
+			t.Fatalf("send failed: %v", err)
+		}
+	}
+	if buf.Len() != 0 {
+		t.Errorf("expected output buffered until Close, got %d bytes", buf.Len())
+	}
+	if err := sender.Close(); err != nil {
+		t.Fatalf("close failed: %v", err)
+	}
+
+	scanner := bufio.NewScanner(&buf)
+	scanner.Buffer(nil, 1<<20)
+	var got []*TrackingData
+	for scanner.Scan() {
+		var data TrackingData
+		if err := json.Unmarshal(scanner.Bytes(), &data); err != nil {
+			t.Fatalf("line %d is not a JSON object: %v", len(got)+1, err)
+		}
+		got = append(got, &data)
+	}
+	if len(got) != 2 {
+		t.Fatalf("expected 2 lines, got %d", len(got))
+	}
+
+	first := got[0]
+	if !first.Timestamp.Equal(frames[0].Timestamp) || first.FrameNumber != 1 {
+		t.Errorf("expected frame 1 at %v, got frame %d at %v", frames[0].Timestamp, first.FrameNumber, first.Timestamp)
+	}
+	if first.Face == nil || first.Face.BlendShapes["jawOpen"] != 0.25 {
+		t.Errorf("expected jawOpen 0.25, got %+v", first.Face)
+	}
+	if first.LeftHand == nil || len(first.LeftHand.Landmarks) != HandLandmarkCount {
+		t.Errorf("expected left hand with %d landmarks, got %+v", HandLandmarkCount, first.LeftHand)
+	}
+	if got[1].Face != nil || got[1].Pose == nil {
+		t.Errorf("expected only a pose in frame 2, got %+v", got[1])
+	}
+
+	if err := sender.Send(frames[0]); err == nil {
+		t.Error("expected error sending after Close")
+	}
+}
@@ -2,7 +2,18 @@ package miface
 
 import (
 	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+	"net"
+	"os"
+	"reflect"
+	"strings"
 	"testing"
+	"time"
+
+	"github.com/MiFaceDEV/miface/internal/config"
 )
 
 func TestBuildOSCMessage(t *testing.T) {
@@ -116,3 +127,804 @@ func TestVMCSenderSendDisabled(t *testing.T) {
 		t.Errorf("disabled sender should not error: %v", err)
 	}
 }
+
+// oscMessage is a decoded OSC message used to inspect sender output.
+type oscMessage struct {
+	address string
+	args    []interface{}
+}
+
+// newTestVMCSender creates a VMCSender pointed at a local UDP listener.
+func newTestVMCSender(t *testing.T) (*VMCSender, *net.UDPConn) {
+	t.Helper()
+
+	listener, err := net.ListenUDP("udp", &net.UDPAddr{IP: net.IPv4(127, 0, 0, 1)})
+	if err != nil {
+		t.Fatalf("failed to listen: %v", err)
+	}
+	t.Cleanup(func() { listener.Close() })
+
+	sender, err := NewVMCSender("127.0.0.1", listener.LocalAddr().(*net.UDPAddr).Port)
+	if err != nil {
+		t.Fatalf("failed to create sender: %v", err)
+	}
+	t.Cleanup(func() { sender.Close() })
+
+	return sender, listener
+}
+
+// readOSCMessages reads messages from conn until no packet arrives for a short while.
+func readOSCMessages(t *testing.T, conn *net.UDPConn) []oscMessage {
+	t.Helper()
+
+	var msgs []oscMessage
+	buf := make([]byte, 65536)
+	for {
+		_ = conn.SetReadDeadline(time.Now().Add(50 * time.Millisecond))
+		n, err := conn.Read(buf)
+		if err != nil {
+			return msgs
+		}
+		msgs = append(msgs, decodeOSCMessage(t, buf[:n]))
+	}
+}
+
+// decodeOSCMessage parses an OSC message containing string, int32 and float32 arguments.
+func decodeOSCMessage(t *testing.T, data []byte) oscMessage {
+	t.Helper()
+
+	readString := func() string {
+		end := bytes.IndexByte(data, 0)
+		if end < 0 {
+			t.Fatalf("unterminated OSC string")
+		}
+		s := string(data[:end])
+		data = data[(end+4)&^3:]
+		return s
+	}
+
+	msg := oscMessage{address: readString()}
+	for _, tag := range readString()[1:] {
+		switch tag {
+		case 's':
+			msg.args = append(msg.args, readString())
+		case 'i':
+			msg.args = append(msg.args, int32(binary.BigEndian.Uint32(data)))
+			data = data[4:]
+		case 'f':
+			msg.args = append(msg.args, math.Float32frombits(binary.BigEndian.Uint32(data)))
+			data = data[4:]
+		}
+	}
+	return msg
+}
+
+// boneNames returns the bone names of all /VMC/Ext/Bone/Pos messages.
+func boneNames(msgs []oscMessage) []string {
+	var names []string
+	for _, m := range msgs {
+		if m.address == "/VMC/Ext/Bone/Pos" && len(m.args) > 0 {
+			names = append(names, m.args[0].(string))
+		}
+	}
+	return names
+}
+
+// testHand returns a hand with 21 landmarks, all with the given presence.
+func testHand(isLeft bool, presence float64) *HandData {
+	landmarks := make([]Landmark, 21)
+	for i := range landmarks {
+		landmarks[i] = Landmark{Point: Point3D{X: float64(i)}, Visibility: 1, Presence: presence}
+	}
+	return &HandData{IsLeft: isLeft, Landmarks: landmarks, Confidence: 1}
+}
+
+func TestVMCSenderMinPresence(t *testing.T) {
+	sender, listener := newTestVMCSender(t)
+	sender.SetMinPresence(0.5)
+
+	hand := testHand(true, 1)
+	// Occlude the index finger
+	for _, idx := range []int{5, 6, 7} {
+		hand.Landmarks[idx].Presence = 0.1
+	}
+
+	if err := sender.Send(&TrackingData{LeftHand: hand}); err != nil {
+		t.Fatalf("send failed: %v", err)
+	}
+
+	names := boneNames(readOSCMessages(t, listener))
+	if len(names) != 13 {
+		t.Errorf("expected 13 bones, got %d: %v", len(names), names)
+	}
+	for _, name := range names {
+		if name == "LeftIndexProximal" || name == "LeftIndexIntermediate" || name == "LeftIndexDistal" {
+			t.Errorf("occluded bone %s should not be sent", name)
+		}
+	}
+}
+
+func TestVMCSenderVisibilityFloor(t *testing.T) {
+	sender, listener := newTestVMCSender(t)
+	sender.SetVisibilityFloor(0.5, 0.5)
+
+	hand := testHand(true, 1)
+	hand.Landmarks[HandThumbCMC].Visibility = 0.1
+	pose := testPose()
+	pose.Landmarks[PoseLeftElbow].Visibility = 0.1
+	pose.Landmarks[PoseLeftHip].Visibility = 0.1
+	pose.Landmarks[PoseRightHip].Visibility = 0.1
+
+	if err := sender.Send(&TrackingData{LeftHand: hand, Pose: pose}); err != nil {
+		t.Fatalf("send failed: %v", err)
+	}
+
+	for _, name := range boneNames(readOSCMessages(t, listener)) {
+		switch name {
+		case "LeftThumbProximal", "LeftLowerArm", "Hips", "LeftUpperLeg", "RightUpperLeg":
+			t.Errorf("bone %s below the visibility floor should not be sent", name)
+		}
+	}
+}
+
+func TestVMCSenderMinHandConfidence(t *testing.T) {
+	sender, listener := newTestVMCSender(t)
+	sender.SetMinHandConfidence(0.5)
+
+	left := testHand(true, 1)
+	left.Confidence = 0.2
+	right := testHand(false, 1)
+	right.Confidence = 0.9
+
+	if err := sender.Send(&TrackingData{LeftHand: left, RightHand: right}); err != nil {
+		t.Fatalf("send failed: %v", err)
+	}
+
+	names := boneNames(readOSCMessages(t, listener))
+	if len(names) != 16 {
+		t.Errorf("expected 16 right hand bones, got %d: %v", len(names), names)
+	}
+	for _, name := range names {
+		if strings.HasPrefix(name, "Left") {
+			t.Errorf("low-confidence hand bone %s should not be sent", name)
+		}
+	}
+}
+
+func TestVMCSenderDefaultHandConfidence(t *testing.T) {
+	sender, listener := newTestVMCSender(t)
+	configureVMCSender(sender, config.Default().Tracking, nil, nil)
+
+	// Processors that don't report a confidence leave it at 0
+	hand := testHand(true, 1)
+	hand.Confidence = 0
+	if err := sender.Send(&TrackingData{LeftHand: hand}); err != nil {
+		t.Fatalf("send failed: %v", err)
+	}
+
+	if names := boneNames(readOSCMessages(t, listener)); len(names) != 16 {
+		t.Errorf("expected the default config to send the hand's 16 bones, got %d: %v", len(names), names)
+	}
+}
+
+// testPose returns a full 33-landmark pose with every landmark present.
+func testPose() *PoseData {
+	landmarks := make([]Landmark, 33)
+	for i := range landmarks {
+		landmarks[i] = Landmark{Point: Point3D{Y: float64(i)}, Visibility: 1, Presence: 1}
+	}
+	return &PoseData{Landmarks: landmarks}
+}
+
+func TestVMCSenderLowerBodyLock(t *testing.T) {
+	legBones := map[string]bool{}
+	for _, bone := range poseLegBones {
+		legBones[bone.name] = true
+	}
+
+	tests := []struct {
+		name     string
+		locked   bool
+		wantLegs int
+	}{
+		{"unlocked", false, len(poseLegBones)},
+		{"locked", true, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sender, listener := newTestVMCSender(t)
+			sender.SetLowerBodyLock(tt.locked)
+
+			if err := sender.Send(&TrackingData{Pose: testPose()}); err != nil {
+				t.Fatalf("send failed: %v", err)
+			}
+
+			var legs int
+			var hips, chest bool
+			for _, name := range boneNames(readOSCMessages(t, listener)) {
+				if legBones[name] {
+					legs++
+				}
+				switch name {
+				case "Hips":
+					hips = true
+				case "Chest":
+					chest = true
+				}
+			}
+			if legs != tt.wantLegs {
+				t.Errorf("expected %d leg bones, got %d", tt.wantLegs, legs)
+			}
+			if !hips {
+				t.Error("expected hips bone to be sent")
+			}
+			if !chest {
+				t.Error("expected chest bone to be sent")
+			}
+		})
+	}
+}
+
+func TestVMCSenderSpinePositions(t *testing.T) {
+	for _, locked := range []bool{false, true} {
+		sender, listener := newTestVMCSender(t)
+		sender.SetLowerBodyLock(locked)
+
+		// Lean the shoulders sideways over the hips
+		pose := uprightPose()
+		pose.Landmarks[PoseLeftShoulder].Point.X += 0.1
+		pose.Landmarks[PoseRightShoulder].Point.X += 0.1
+		if err := sender.Send(&TrackingData{Pose: pose}); err != nil {
+			t.Fatalf("send failed: %v", err)
+		}
+		positions := bonePositions(readOSCMessages(t, listener))
+		chest, upperChest := positions["Chest"], positions["UpperChest"]
+
+		// Chest is halfway up the spine from the hips, UpperChest a
+		// quarter further, both above their parent
+		if chest.Y <= 0 || upperChest.Y <= 0 {
+			t.Errorf("locked=%v: expected the chest bones above their parents, got %+v and %+v", locked, chest, upperChest)
+		}
+		if d := chest.Sub(upperChest.Scale(2)).Length(); d > 1e-6 {
+			t.Errorf("locked=%v: expected Chest twice the UpperChest offset, got %+v and %+v", locked, chest, upperChest)
+		}
+
+		// Tracked hips follow the lean; locked hips keep the rest spine
+		if leaning := math.Abs(chest.X) > 1e-3; leaning == locked {
+			t.Errorf("locked=%v: unexpected Chest offset %+v", locked, chest)
+		}
+	}
+}
+
+func TestVMCSenderLowerBodyLockPinsHips(t *testing.T) {
+	sender, listener := newTestVMCSender(t)
+	sender.SetLowerBodyLock(true)
+
+	// The hips wobble between frames; the Hips bone must not follow
+	var positions [][]interface{}
+	for _, y := range []float64{0.6, 0.8} {
+		pose := testPose()
+		pose.Landmarks[PoseLeftHip].Point.Y = y
+		pose.Landmarks[PoseRightHip].Point.Y = y
+		if err := sender.Send(&TrackingData{Pose: pose}); err != nil {
+			t.Fatalf("send failed: %v", err)
+		}
+		for _, m := range readOSCMessages(t, listener) {
+			if m.address == "/VMC/Ext/Bone/Pos" && m.args[0] == "Hips" {
+				positions = append(positions, m.args[1:4])
+			}
+		}
+	}
+	if len(positions) != 2 {
+		t.Fatalf("expected a Hips bone per frame, got %d", len(positions))
+	}
+	if !reflect.DeepEqual(positions[0], positions[1]) {
+		t.Errorf("expected locked hips pinned at rest, got %v then %v", positions[0], positions[1])
+	}
+}
+
+func TestVMCSenderRestPoseHips(t *testing.T) {
+	sender, listener := newTestVMCSender(t)
+	sender.SetLowerBodyLock(true)
+	lockedHips := func() Point3D {
+		t.Helper()
+		if err := sender.Send(&TrackingData{Pose: testPose()}); err != nil {
+			t.Fatalf("send failed: %v", err)
+		}
+		hips, ok := bonePositions(readOSCMessages(t, listener))["Hips"]
+		if !ok {
+			t.Fatal("expected a Hips bone")
+		}
+		return hips
+	}
+	defaultHips := lockedHips()
+
+	// A lower rest pose pins the hips lower
+	rest := DefaultRestPose()
+	rest.Pose.Landmarks[PoseLeftHip].Point.Y += 0.1
+	rest.Pose.Landmarks[PoseRightHip].Point.Y += 0.1
+	sender.SetRestPose(rest)
+	if hips := lockedHips(); hips == defaultHips {
+		t.Errorf("expected the hips to follow the rest pose, got %+v", hips)
+	}
+
+	// No pose landmarks restores the default anchor
+	sender.SetRestPose(&TrackingData{})
+	if hips := lockedHips(); hips != defaultHips {
+		t.Errorf("expected the default rest hips %+v, got %+v", defaultHips, hips)
+	}
+}
+
+func TestVMCSenderRetargeter(t *testing.T) {
+	// Right-angle elbows
+	pose := armPose(Point3D{X: 0.15}, Point3D{X: 0.15, Y: -0.15})
+
+	// Without a retargeter there are no arm rotations, so no arm bones
+	sender, listener := newTestVMCSender(t)
+	if err := sender.Send(&TrackingData{Pose: pose}); err != nil {
+		t.Fatalf("send failed: %v", err)
+	}
+	for _, name := range boneNames(readOSCMessages(t, listener)) {
+		if strings.HasSuffix(name, "Arm") {
+			t.Errorf("expected no arm bones without a retargeter, got %s", name)
+		}
+	}
+
+	sender.SetRetargeter(NewRetargeter(nil))
+	if err := sender.Send(&TrackingData{Pose: pose}); err != nil {
+		t.Fatalf("send failed: %v", err)
+	}
+	var found bool
+	for _, m := range readOSCMessages(t, listener) {
+		if m.address != "/VMC/Ext/Bone/Pos" || len(m.args) < 8 || m.args[0] != "LeftLowerArm" {
+			continue
+		}
+		found = true
+		if w := float64(m.args[7].(float32)); math.Abs(w) >= 0.99 {
+			t.Errorf("expected a bent elbow, got rotation w=%f", w)
+		}
+	}
+	if !found {
+		t.Error("expected LeftLowerArm bone to be sent")
+	}
+
+	// A bent wrist is sent on the tracked hand's Hand bone
+	bent := armPose(Point3D{X: 0.15}, Point3D{X: 0.15, Y: -0.15})
+	for _, i := range []int{PoseLeftIndex, PoseLeftPinky} {
+		bent.Landmarks[i].Point = bent.Landmarks[PoseLeftWrist].Point.Add(Point3D{X: 0.05})
+	}
+	wrist := NewRetargeter(nil).SolveBones(bent)["LeftHand"]
+	if math.Abs(wrist.W) >= 0.99 {
+		t.Fatalf("expected a bent wrist, got %+v", wrist)
+	}
+	if err := sender.Send(&TrackingData{Pose: bent, LeftHand: testHand(true, 1)}); err != nil {
+		t.Fatalf("send failed: %v", err)
+	}
+	found = false
+	for _, m := range readOSCMessages(t, listener) {
+		if m.address != "/VMC/Ext/Bone/Pos" || len(m.args) < 8 || m.args[0] != "LeftHand" {
+			continue
+		}
+		found = true
+		got := Quaternion{
+			X: float64(m.args[4].(float32)), Y: float64(m.args[5].(float32)),
+			Z: float64(m.args[6].(float32)), W: float64(m.args[7].(float32)),
+		}
+		if math.Abs(got.Dot(wrist)) < 0.999 {
+			t.Errorf("expected the solved wrist rotation %+v, got %+v", wrist, got)
+		}
+	}
+	if !found {
+		t.Error("expected LeftHand bone to be sent")
+	}
+
+	// An arm held below the pose floor is not solved, so none of its bones
+	// are sent
+	sender.SetVisibilityFloor(0, 0.5)
+	pose.Landmarks[PoseLeftWrist].Visibility = 0
+	if err := sender.Send(&TrackingData{Pose: pose}); err != nil {
+		t.Fatalf("send failed: %v", err)
+	}
+	for _, name := range boneNames(readOSCMessages(t, listener)) {
+		if strings.HasPrefix(name, "LeftUpperArm") || strings.HasPrefix(name, "LeftLowerArm") {
+			t.Errorf("expected no left arm bones with the wrist below the floor, got %s", name)
+		}
+	}
+}
+
+// bonePositions returns the position of each /VMC/Ext/Bone/Pos message by bone name.
+func bonePositions(msgs []oscMessage) map[string]Point3D {
+	positions := make(map[string]Point3D)
+	for _, m := range msgs {
+		if m.address == "/VMC/Ext/Bone/Pos" && len(m.args) >= 4 {
+			positions[m.args[0].(string)] = Point3D{
+				X: float64(m.args[1].(float32)),
+				Y: float64(m.args[2].(float32)),
+				Z: float64(m.args[3].(float32)),
+			}
+		}
+	}
+	return positions
+}
+
+func TestVMCSenderCoordinateMode(t *testing.T) {
+	// Wrist at the image center, index finger base up and to the right of it
+	hand := testHand(true, 1)
+	hand.Landmarks[0].Point = Point3D{X: 0.5, Y: 0.5}
+	hand.Landmarks[5].Point = Point3D{X: 0.6, Y: 0.3, Z: -0.1}
+
+	tests := []struct {
+		mode        CoordinateMode
+		wrist, base Point3D
+	}{
+		{CoordRawImage, Point3D{X: 0.5, Y: 0.5}, Point3D{X: 0.6, Y: 0.3, Z: -0.1}},
+		{CoordNormalized, Point3D{}, Point3D{X: 0.1, Y: 0.2, Z: 0.1}},
+		// Relative to the wrist, which is at the origin in normalized axes
+		{CoordBoneLocal, Point3D{}, Point3D{X: 0.1, Y: 0.2, Z: 0.1}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.mode.String(), func(t *testing.T) {
+			sender, listener := newTestVMCSender(t)
+			sender.SetCoordinateMode(tt.mode)
+
+			if err := sender.Send(&TrackingData{LeftHand: hand}); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			positions := bonePositions(readOSCMessages(t, listener))
+			checks := map[string]Point3D{"LeftHand": tt.wrist, "LeftIndexProximal": tt.base}
+			for name, want := range checks {
+				got, ok := positions[name]
+				if !ok {
+					t.Fatalf("expected %s bone to be sent", name)
+				}
+				if math.Abs(got.X-want.X) > 1e-6 || math.Abs(got.Y-want.Y) > 1e-6 || math.Abs(got.Z-want.Z) > 1e-6 {
+					t.Errorf("%s: expected %+v, got %+v", name, want, got)
+				}
+			}
+		})
+	}
+}
+
+func TestVMCSenderAxisConvention(t *testing.T) {
+	hand := testHand(true, 1)
+	hand.Landmarks[0].Point = Point3D{X: 0.5, Y: 0.5}
+	hand.Landmarks[5].Point = Point3D{X: 0.6, Y: 0.3, Z: -0.1}
+	s := math.Sqrt(0.5)
+	face := &FaceData{HeadRotation: Quaternion{Y: s, W: s}, HeadPosition: Point3D{X: 0.6, Y: 0.3, Z: -0.1}}
+
+	sender, listener := newTestVMCSender(t)
+	sender.SetAxisConvention(AxesMediaPipeToUnity)
+	if err := sender.Send(&TrackingData{Face: face, LeftHand: hand}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	msgs := readOSCMessages(t, listener)
+
+	// Unity's Z points away from the camera, unlike VRM's
+	want := Point3D{X: 0.1, Y: 0.2, Z: -0.1}
+	got := bonePositions(msgs)["LeftIndexProximal"]
+	if math.Abs(got.X-want.X) > 1e-6 || math.Abs(got.Y-want.Y) > 1e-6 || math.Abs(got.Z-want.Z) > 1e-6 {
+		t.Errorf("expected %+v, got %+v", want, got)
+	}
+
+	// The head position is converted like every other bone position
+	head := bonePositions(msgs)["Head"]
+	if math.Abs(head.X-want.X) > 1e-6 || math.Abs(head.Y-want.Y) > 1e-6 || math.Abs(head.Z-want.Z) > 1e-6 {
+		t.Errorf("expected head position %+v, got %+v", want, head)
+	}
+
+	// A yaw in VRM axes turns the other way in left-handed Unity axes
+	for _, m := range msgs {
+		if m.address != "/VMC/Ext/Bone/Pos" || m.args[0] != "Head" {
+			continue
+		}
+		if y := m.args[5].(float32); math.Abs(float64(y)+s) > 1e-6 {
+			t.Errorf("expected head rotation Y %f, got %f", -s, y)
+		}
+	}
+}
+
+func TestCoordinateModeLocalPosition(t *testing.T) {
+	p := Point3D{X: 0.7, Y: 0.2, Z: 0.1}
+	parent := Point3D{X: 0.6, Y: 0.4, Z: 0.3}
+
+	if got := CoordRawImage.LocalPosition(p, parent); got != p {
+		t.Errorf("raw image: expected %+v, got %+v", p, got)
+	}
+	if got, want := CoordNormalized.LocalPosition(p, parent), ImageToNormalized(p); got != want {
+		t.Errorf("normalized: expected %+v, got %+v", want, got)
+	}
+	got := CoordBoneLocal.LocalPosition(p, parent)
+	want := Point3D{X: 0.1, Y: 0.2, Z: 0.2}
+	if !pointsClose(got, want) {
+		t.Errorf("bone-local: expected %+v, got %+v", want, got)
+	}
+}
+
+func TestVMCSenderWriteTimeoutBones(t *testing.T) {
+	tests := []struct {
+		name string
+		data *TrackingData
+	}{
+		{"hand", &TrackingData{LeftHand: testHand(true, 1)}},
+		{"pose", &TrackingData{Pose: testPose()}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sender, _ := newTestVMCSender(t)
+
+			// The deadline passes before the first bone is written
+			sender.SetWriteTimeout(time.Nanosecond)
+			err := sender.Send(tt.data)
+			if !errors.Is(err, os.ErrDeadlineExceeded) {
+				t.Errorf("expected a timed-out bone write to be reported, got %v", err)
+			}
+		})
+	}
+}
+
+func TestVMCSenderSendBuffer(t *testing.T) {
+	sender, listener := newTestVMCSender(t)
+
+	if err := sender.SetSendBuffer(1 << 20); err != nil {
+		t.Fatalf("failed to set send buffer: %v", err)
+	}
+	sender.SetWriteTimeout(100 * time.Millisecond)
+
+	data := &TrackingData{Face: &FaceData{HeadRotation: Quaternion{W: 1}}}
+	if err := sender.Send(data); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if names := boneNames(readOSCMessages(t, listener)); len(names) != 1 || names[0] != "Head" {
+		t.Errorf("expected head bone to be received, got %v", names)
+	}
+
+	// The buffer size carries over to a new target
+	port := listener.LocalAddr().(*net.UDPAddr).Port
+	if err := sender.SetTarget("127.0.0.1", port); err != nil {
+		t.Fatalf("failed to set target: %v", err)
+	}
+	if err := sender.Send(data); err != nil {
+		t.Fatalf("unexpected error after SetTarget: %v", err)
+	}
+	if names := boneNames(readOSCMessages(t, listener)); len(names) != 1 {
+		t.Errorf("expected head bone to be received after SetTarget, got %v", names)
+	}
+
+	if err := sender.SetSendBuffer(-1); err == nil {
+		t.Error("expected error for negative send buffer size")
+	}
+}
+
+func TestVMCSenderLocalAddr(t *testing.T) {
+	sender, listener := newTestVMCSender(t)
+
+	// Find a free port by binding and releasing it
+	free, err := net.ListenUDP("udp", &net.UDPAddr{IP: net.IPv4(127, 0, 0, 1)})
+	if err != nil {
+		t.Fatalf("failed to listen: %v", err)
+	}
+	local := free.LocalAddr().(*net.UDPAddr)
+	free.Close()
+
+	if err := sender.SetLocalAddr(local.String()); err != nil {
+		t.Fatalf("failed to set local address: %v", err)
+	}
+	if got := sender.LocalAddr().String(); got != local.String() {
+		t.Errorf("expected local address %s, got %s", local, got)
+	}
+
+	data := &TrackingData{Face: &FaceData{HeadRotation: Quaternion{W: 1}}}
+	if err := sender.Send(data); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	listener.SetReadDeadline(time.Now().Add(time.Second))
+	buf := make([]byte, 1024)
+	_, from, err := listener.ReadFromUDP(buf)
+	if err != nil {
+		t.Fatalf("failed to read: %v", err)
+	}
+	if from.Port != local.Port {
+		t.Errorf("expected datagrams from port %d, got %d", local.Port, from.Port)
+	}
+
+	// The binding carries over to a new target, even on the same fixed port
+	port := listener.LocalAddr().(*net.UDPAddr).Port
+	if err := sender.SetTarget("127.0.0.1", port); err != nil {
+		t.Fatalf("failed to set target: %v", err)
+	}
+	if got := sender.LocalAddr().String(); got != local.String() {
+		t.Errorf("expected local address %s after SetTarget, got %s", local, got)
+	}
+
+	if err := sender.SetLocalAddr("not an address"); err == nil {
+		t.Error("expected error for an invalid local address")
+	}
+	if err := sender.SetLocalAddr(""); err != nil {
+		t.Fatalf("failed to clear local address: %v", err)
+	}
+	if got := sender.LocalAddr().(*net.UDPAddr).Port; got == local.Port {
+		t.Errorf("expected an ephemeral port after clearing, got %d", got)
+	}
+}
+
+func TestVMCSenderLocalAddrTaken(t *testing.T) {
+	sender, listener := newTestVMCSender(t)
+
+	// Bind to a fixed port, then fail to move to one that is taken
+	free, err := net.ListenUDP("udp", &net.UDPAddr{IP: net.IPv4(127, 0, 0, 1)})
+	if err != nil {
+		t.Fatalf("failed to listen: %v", err)
+	}
+	local := free.LocalAddr().(*net.UDPAddr)
+	free.Close()
+	if err := sender.SetLocalAddr(local.String()); err != nil {
+		t.Fatalf("failed to set local address: %v", err)
+	}
+	taken, err := net.ListenUDP("udp", &net.UDPAddr{IP: net.IPv4(127, 0, 0, 1)})
+	if err != nil {
+		t.Fatalf("failed to listen: %v", err)
+	}
+	defer taken.Close()
+	if err := sender.SetLocalAddr(taken.LocalAddr().String()); err == nil {
+		t.Fatal("expected error binding a taken port")
+	}
+	if sender.LocalAddr() != nil {
+		t.Fatal("expected no connection after the failed redial")
+	}
+
+	// The next Send restores the previous binding instead of dropping frames
+	data := &TrackingData{Face: &FaceData{HeadRotation: Quaternion{W: 1}}}
+	if err := sender.Send(data); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	listener.SetReadDeadline(time.Now().Add(time.Second))
+	buf := make([]byte, 1024)
+	_, from, err := listener.ReadFromUDP(buf)
+	if err != nil {
+		t.Fatalf("failed to read: %v", err)
+	}
+	if from.Port != local.Port {
+		t.Errorf("expected datagrams from port %d, got %d", local.Port, from.Port)
+	}
+}
+
+func TestVMCSenderIPv6(t *testing.T) {
+	listener, err := net.ListenUDP("udp6", &net.UDPAddr{IP: net.IPv6loopback})
+	if err != nil {
+		t.Skipf("IPv6 loopback unavailable: %v", err)
+	}
+	defer listener.Close()
+	port := listener.LocalAddr().(*net.UDPAddr).Port
+
+	sender, err := NewVMCSender("::1", port)
+	if err != nil {
+		t.Fatalf("failed to create sender: %v", err)
+	}
+	defer sender.Close()
+
+	if !sender.addr.IP.Equal(net.IPv6loopback) || sender.addr.Port != port {
+		t.Errorf("expected target [::1]:%d, got %s", port, sender.addr)
+	}
+	data := &TrackingData{Face: &FaceData{HeadRotation: Quaternion{W: 1}}}
+	if err := sender.Send(data); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if names := boneNames(readOSCMessages(t, listener)); len(names) != 1 || names[0] != "Head" {
+		t.Errorf("expected head bone over IPv6, got %v", names)
+	}
+
+	if err := sender.SetNetwork("udp4"); err == nil {
+		t.Error("expected error resolving an IPv6 literal as udp4")
+	}
+}
+
+func TestVMCSenderHostname(t *testing.T) {
+	sender, _ := newTestVMCSender(t)
+
+	if err := sender.SetNetwork("udp4"); err != nil {
+		t.Fatalf("failed to set network: %v", err)
+	}
+	if err := sender.SetTarget("localhost", 39539); err != nil {
+		t.Fatalf("failed to set target: %v", err)
+	}
+	if want := (&net.UDPAddr{IP: net.IPv4(127, 0, 0, 1), Port: 39539}); !sender.addr.IP.Equal(want.IP) || sender.addr.Port != want.Port {
+		t.Errorf("expected localhost to resolve to %s, got %s", want, sender.addr)
+	}
+	if got := sender.LocalAddr().(*net.UDPAddr); got.IP.To4() == nil {
+		t.Errorf("expected an IPv4 local address, got %s", got)
+	}
+
+	if err := sender.SetNetwork("tcp"); err == nil {
+		t.Error("expected error for an unsupported network")
+	}
+}
+
+func TestNewVMCSenderIPv6Target(t *testing.T) {
+	listener, err := net.ListenUDP("udp6", &net.UDPAddr{IP: net.IPv6loopback})
+	if err != nil {
+		t.Skipf("IPv6 loopback unavailable: %v", err)
+	}
+	defer listener.Close()
+	port := listener.LocalAddr().(*net.UDPAddr).Port
+
+	sender, err := NewVMCSender("::1", port)
+	if err != nil {
+		t.Fatalf("failed to create sender: %v", err)
+	}
+	defer sender.Close()
+
+	// A plain "%s:%d" would give "::1:<port>", which doesn't parse
+	if want := fmt.Sprintf("[::1]:%d", port); sender.target != want {
+		t.Errorf("expected target %s, got %q", want, sender.target)
+	}
+	data := &TrackingData{Face: &FaceData{HeadRotation: Quaternion{W: 1}}}
+	if err := sender.Send(data); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if msgs := readOSCMessages(t, listener); len(msgs) == 0 {
+		t.Error("expected a packet on the IPv6 listener")
+	}
+}
+
+func TestVMCSenderDeltaMode(t *testing.T) {
+	sender, listener := newTestVMCSender(t)
+	if err := sender.SetDeltaMode(1e-4, 3); err != nil {
+		t.Fatalf("failed to set delta mode: %v", err)
+	}
+
+	data := &TrackingData{
+		Face:     stubFace(0.2, 0.5),
+		LeftHand: stubHand(true, 0.5),
+		Pose:     stubPose(0.2),
+	}
+	send := func() []oscMessage {
+		t.Helper()
+		if err := sender.Send(data); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		return readOSCMessages(t, listener)
+	}
+
+	keyframe := send()
+	if len(keyframe) < 20 {
+		t.Fatalf("expected a full keyframe, got %d messages", len(keyframe))
+	}
+
+	// Holding still, nothing is resent until the next keyframe
+	for i := 0; i < 2; i++ {
+		if msgs := send(); len(msgs) != 0 {
+			t.Errorf("frame %d: expected no messages for a constant pose, got %d", i+1, len(msgs))
+		}
+	}
+	if msgs := send(); len(msgs) != len(keyframe) {
+		t.Errorf("expected a keyframe of %d messages, got %d", len(keyframe), len(msgs))
+	}
+
+	// Only what changed is sent, followed by an apply
+	data.Face.BlendShapes["jawOpen"] += 0.1
+	msgs := send()
+	if len(msgs) != 2 || msgs[0].address != "/VMC/Ext/Blend/Val" || msgs[0].args[0] != "jawOpen" ||
+		msgs[1].address != "/VMC/Ext/Blend/Apply" {
+		t.Errorf("expected only jawOpen and an apply, got %+v", msgs)
+	}
+
+	// Switching delta mode off sends everything again
+	if err := sender.SetDeltaMode(0, 0); err != nil {
+		t.Fatalf("failed to disable delta mode: %v", err)
+	}
+	for i := 0; i < 2; i++ {
+		if msgs := send(); len(msgs) != len(keyframe) {
+			t.Errorf("expected %d messages with delta mode off, got %d", len(keyframe), len(msgs))
+		}
+	}
+
+	if err := sender.SetDeltaMode(-1, 3); err == nil {
+		t.Error("expected error for a negative epsilon")
+	}
+	if err := sender.SetDeltaMode(1e-4, 0); err == nil {
+		t.Error("expected error for a zero keyframe interval")
+	}
+}
@@ -0,0 +1,172 @@
+package miface
+
+// nodeTransform is a node's translation, rotation and scale.
+type nodeTransform struct {
+	translation Point3D
+	rotation    Quaternion
+	scale       Point3D
+}
+
+// applySkin picks the skin the humanoid bones belong to, records its joints
+// and skeleton root, links each bone to its parent joint and computes the
+// bones' rest positions. parentMap maps node indices to their parents.
+func (s *VRMSkeleton) applySkin(gltf *gltfDocument, parentMap map[int]int) {
+	rest := restPositions(gltf.Nodes, parentMap)
+	for _, bone := range s.Bones {
+		bone.RestPosition = rest[bone.NodeIndex]
+		bone.ParentJoint = bone.ParentIndex
+	}
+
+	skin := s.humanoidSkin(gltf.Skins)
+	if skin == nil {
+		return
+	}
+
+	joints := make(map[int]bool, len(skin.Joints))
+	for _, j := range skin.Joints {
+		if j >= 0 && j < len(gltf.Nodes) {
+			joints[j] = true
+		}
+	}
+	s.Joints = append([]int(nil), skin.Joints...)
+	if skin.Skeleton != nil && *skin.Skeleton >= 0 && *skin.Skeleton < len(gltf.Nodes) {
+		s.SkeletonRoot = *skin.Skeleton
+	} else {
+		s.SkeletonRoot = commonAncestor(skin.Joints, parentMap, len(gltf.Nodes))
+	}
+
+	for _, bone := range s.Bones {
+		bone.ParentJoint = -1
+		for i, parent := range ancestors(bone.NodeIndex, parentMap, len(gltf.Nodes)) {
+			if i > 0 && joints[parent] {
+				bone.ParentJoint = parent
+				break
+			}
+		}
+	}
+}
+
+// humanoidSkin returns the skin whose joints include the most humanoid
+// bones, or nil if there are no skins.
+func (s *VRMSkeleton) humanoidSkin(skins []gltfSkin) *gltfSkin {
+	humanNodes := make(map[int]bool, len(s.HumanBones))
+	for _, node := range s.HumanBones {
+		humanNodes[node] = true
+	}
+
+	var best *gltfSkin
+	bestCount := -1
+	for i := range skins {
+		count := 0
+		for _, j := range skins[i].Joints {
+			if humanNodes[j] {
+				count++
+			}
+		}
+		if count > bestCount {
+			best, bestCount = &skins[i], count
+		}
+	}
+	return best
+}
+
+// ancestors returns node followed by its ancestors up to the scene root.
+// A parent cycle in a malformed file ends the chain.
+func ancestors(node int, parentMap map[int]int, numNodes int) []int {
+	chain := []int{node}
+	seen := map[int]bool{node: true}
+	for len(chain) <= numNodes {
+		parent, ok := parentMap[chain[len(chain)-1]]
+		if !ok || seen[parent] {
+			break
+		}
+		seen[parent] = true
+		chain = append(chain, parent)
+	}
+	return chain
+}
+
+// commonAncestor returns the deepest node that is an ancestor of (or is)
+// every node in nodes, or -1 if they share none.
+func commonAncestor(nodes []int, parentMap map[int]int, numNodes int) int {
+	if len(nodes) == 0 {
+		return -1
+	}
+
+	// Candidates are the first node's chain, deepest first
+	candidates := ancestors(nodes[0], parentMap, numNodes)
+	for _, node := range nodes[1:] {
+		chain := make(map[int]bool)
+		for _, a := range ancestors(node, parentMap, numNodes) {
+			chain[a] = true
+		}
+		for len(candidates) > 0 && !chain[candidates[0]] {
+			candidates = candidates[1:]
+		}
+	}
+	if len(candidates) == 0 {
+		return -1
+	}
+	return candidates[0]
+}
+
+// restPositions returns every node's rest position in model space, by
+// composing the local transforms from the scene root down.
+func restPositions(nodes []gltfNode, parentMap map[int]int) []Point3D {
+	positions := make([]Point3D, len(nodes))
+	for i := range nodes {
+		chain := ancestors(i, parentMap, len(nodes))
+
+		// Compose from the root down to the node
+		world := nodeTransform{rotation: Quaternion{W: 1}, scale: Point3D{X: 1, Y: 1, Z: 1}}
+		for k := len(chain) - 1; k >= 0; k-- {
+			world = world.compose(localTransform(nodes[chain[k]]))
+		}
+		positions[i] = world.translation
+	}
+	return positions
+}
+
+// localTransform returns a node's transform, with glTF defaults for
+// missing components.
+func localTransform(node gltfNode) nodeTransform {
+	t := nodeTransform{rotation: Quaternion{W: 1}, scale: Point3D{X: 1, Y: 1, Z: 1}}
+	if len(node.Translation) >= 3 {
+		t.translation = Point3D{X: node.Translation[0], Y: node.Translation[1], Z: node.Translation[2]}
+	}
+	if len(node.Rotation) >= 4 {
+		t.rotation = Quaternion{X: node.Rotation[0], Y: node.Rotation[1], Z: node.Rotation[2], W: node.Rotation[3]}.Normalize()
+	}
+	if len(node.Scale) >= 3 {
+		t.scale = Point3D{X: node.Scale[0], Y: node.Scale[1], Z: node.Scale[2]}
+	}
+	return t
+}
+
+// compose returns the transform of a child with local transform local
+// under t. Scale is composed per axis, which is exact for the uniform
+// scales armatures use.
+func (t nodeTransform) compose(local nodeTransform) nodeTransform {
+	scaled := Point3D{
+		X: local.translation.X * t.scale.X,
+		Y: local.translation.Y * t.scale.Y,
+		Z: local.translation.Z * t.scale.Z,
+	}
+	return nodeTransform{
+		translation: t.translation.Add(rotatePoint(t.rotation, scaled)),
+		rotation:    t.rotation.Mul(local.rotation),
+		scale: Point3D{
+			X: t.scale.X * local.scale.X,
+			Y: t.scale.Y * local.scale.Y,
+			Z: t.scale.Z * local.scale.Z,
+		},
+	}
+}
+
+// rotatePoint rotates p by the unit quaternion q.
+func rotatePoint(q Quaternion, p Point3D) Point3D {
+	u := Point3D{X: q.X, Y: q.Y, Z: q.Z}
+	// p + 2w(u×p) + 2u×(u×p)
+	t := u.Cross(p).Scale(2)
+	return p.Add(t.Scale(q.W)).Add(u.Cross(t))
+}
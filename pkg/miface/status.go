@@ -0,0 +1,153 @@
+package miface
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// DefaultReadyMaxAge is how recently a frame must have been processed for
+// the status server to report the tracker ready.
+const DefaultReadyMaxAge = 2 * time.Second
+
+// StatusServer serves liveness, readiness and statistics for a tracker over
+// HTTP, for running MiFace as a background service:
+//
+//   - /healthz: 200 while the tracking loop is running
+//   - /readyz: 200 while running with a camera and a recent frame
+//   - /stats: the tracker's Stats as JSON
+//
+// Failing probes respond 503 with the reason in the body. StatusServer is
+// also an http.Handler, so it can be mounted on an existing server.
+type StatusServer struct {
+	tracker  *Tracker
+	addr     string
+	maxAge   time.Duration
+	mux      *http.ServeMux
+	mu       sync.Mutex
+	server   *http.Server
+	listener net.Listener
+}
+
+// NewStatusServer creates a status server for tracker that listens on addr
+// (e.g. "127.0.0.1:8080") once started.
+func NewStatusServer(tracker *Tracker, addr string) *StatusServer {
+	s := &StatusServer{
+		tracker: tracker,
+		addr:    addr,
+		maxAge:  DefaultReadyMaxAge,
+		mux:     http.NewServeMux(),
+	}
+	s.mux.HandleFunc("GET /healthz", s.handleHealth)
+	s.mux.HandleFunc("GET /readyz", s.handleReady)
+	s.mux.HandleFunc("GET /stats", s.handleStats)
+	return s
+}
+
+// SetReadyMaxAge sets how recently a frame must have been processed for
+// /readyz to succeed. Defaults to DefaultReadyMaxAge.
+// Must be called before Start().
+func (s *StatusServer) SetReadyMaxAge(maxAge time.Duration) {
+	s.maxAge = maxAge
+}
+
+// ServeHTTP implements http.Handler.
+func (s *StatusServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mux.ServeHTTP(w, r)
+}
+
+// Start listens on the server's address and serves requests in the
+// background until Close is called.
+func (s *StatusServer) Start() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.server != nil {
+		return errors.New("status server already started")
+	}
+
+	listener, err := net.Listen("tcp", s.addr)
+	if err != nil {
+		return fmt.Errorf("starting status server: %w", err)
+	}
+	s.listener = listener
+	s.server = &http.Server{Handler: s, ReadHeaderTimeout: 5 * time.Second}
+
+	go s.server.Serve(listener)
+	return nil
+}
+
+// Addr returns the address the server listens on, or the configured address
+// if it has not been started. Useful when listening on port 0.
+func (s *StatusServer) Addr() string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.listener != nil {
+		return s.listener.Addr().String()
+	}
+	return s.addr
+}
+
+// Close stops the server, waiting up to a second for in-flight requests.
+func (s *StatusServer) Close() error {
+	s.mu.Lock()
+	server := s.server
+	s.mu.Unlock()
+
+	if server == nil {
+		return nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	if err := server.Shutdown(ctx); err != nil {
+		return fmt.Errorf("closing status server: %w", err)
+	}
+	return nil
+}
+
+// handleHealth reports whether the tracking loop is running.
+func (s *StatusServer) handleHealth(w http.ResponseWriter, r *http.Request) {
+	if state := s.tracker.State(); state != StateRunning {
+		http.Error(w, fmt.Sprintf("tracker is %s", state), http.StatusServiceUnavailable)
+		return
+	}
+	fmt.Fprintln(w, "ok")
+}
+
+// handleReady reports whether the tracker is producing frames.
+func (s *StatusServer) handleReady(w http.ResponseWriter, r *http.Request) {
+	if state := s.tracker.State(); state != StateRunning {
+		http.Error(w, fmt.Sprintf("tracker is %s", state), http.StatusServiceUnavailable)
+		return
+	}
+	if !s.tracker.hasCamera() {
+		http.Error(w, "no camera", http.StatusServiceUnavailable)
+		return
+	}
+
+	stats := s.tracker.Stats()
+	if stats.LastFrame.IsZero() {
+		http.Error(w, "no frame processed yet", http.StatusServiceUnavailable)
+		return
+	}
+	if age := s.tracker.now().Sub(stats.LastFrame); age > s.maxAge {
+		http.Error(w, fmt.Sprintf("last frame %v ago", age), http.StatusServiceUnavailable)
+		return
+	}
+	fmt.Fprintln(w, "ok")
+}
+
+// handleStats writes the tracker's statistics as JSON.
+func (s *StatusServer) handleStats(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(s.tracker.Stats()); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
@@ -0,0 +1,142 @@
+package miface
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/MiFaceDEV/miface/internal/config"
+)
+
+func statusCode(t *testing.T, handler http.Handler, path string) int {
+	t.Helper()
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path, nil))
+	return rec.Code
+}
+
+func TestStatusServer(t *testing.T) {
+	tracker, err := NewTracker(nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer tracker.Close()
+
+	clock := NewFakeClock(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC))
+	if err := tracker.SetClock(clock); err != nil {
+		t.Fatalf("failed to set clock: %v", err)
+	}
+	if err := tracker.SetCameraSource(&MockCameraSource{}); err != nil {
+		t.Fatalf("failed to set camera: %v", err)
+	}
+	if err := tracker.SetProcessor(NewStubProcessor(DefaultStubConfig())); err != nil {
+		t.Fatalf("failed to set processor: %v", err)
+	}
+	server := NewStatusServer(tracker, "127.0.0.1:0")
+
+	// Idle: not alive, not ready, stats still served
+	if code := statusCode(t, server, "/healthz"); code != http.StatusServiceUnavailable {
+		t.Errorf("idle /healthz: expected 503, got %d", code)
+	}
+	if code := statusCode(t, server, "/readyz"); code != http.StatusServiceUnavailable {
+		t.Errorf("idle /readyz: expected 503, got %d", code)
+	}
+	if code := statusCode(t, server, "/stats"); code != http.StatusOK {
+		t.Errorf("idle /stats: expected 200, got %d", code)
+	}
+
+	if err := tracker.Start(); err != nil {
+		t.Fatalf("failed to start: %v", err)
+	}
+
+	// Running without a frame yet: alive but not ready
+	if code := statusCode(t, server, "/healthz"); code != http.StatusOK {
+		t.Errorf("running /healthz: expected 200, got %d", code)
+	}
+	if code := statusCode(t, server, "/readyz"); code != http.StatusServiceUnavailable {
+		t.Errorf("running /readyz before a frame: expected 503, got %d", code)
+	}
+
+	interval := time.Second / time.Duration(config.Default().Camera.FPS)
+	clock.Advance(interval)
+	if code := statusCode(t, server, "/readyz"); code != http.StatusOK {
+		t.Errorf("running /readyz after a frame: expected 200, got %d", code)
+	}
+
+	rec := httptest.NewRecorder()
+	server.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/stats", nil))
+	var stats TrackerStats
+	if err := json.NewDecoder(rec.Body).Decode(&stats); err != nil {
+		t.Fatalf("failed to decode stats: %v", err)
+	}
+	if stats.FrameNumber != 1 {
+		t.Errorf("expected frame_number 1, got %d", stats.FrameNumber)
+	}
+
+	if err := tracker.Stop(); err != nil {
+		t.Fatalf("failed to stop: %v", err)
+	}
+	if code := statusCode(t, server, "/healthz"); code != http.StatusServiceUnavailable {
+		t.Errorf("stopped /healthz: expected 503, got %d", code)
+	}
+}
+
+func TestStatusServerStaleFrame(t *testing.T) {
+	tracker, err := NewTracker(nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer tracker.Close()
+
+	clock := NewFakeClock(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC))
+	tracker.SetClock(clock)
+	tracker.SetCameraSource(&MockCameraSource{})
+	tracker.SetProcessor(NewStubProcessor(DefaultStubConfig()))
+	if err := tracker.Start(); err != nil {
+		t.Fatalf("failed to start: %v", err)
+	}
+
+	server := NewStatusServer(tracker, "127.0.0.1:0")
+	server.SetReadyMaxAge(time.Second)
+	clock.Advance(time.Second / time.Duration(config.Default().Camera.FPS))
+	if code := statusCode(t, server, "/readyz"); code != http.StatusOK {
+		t.Fatalf("expected 200 after a frame, got %d", code)
+	}
+
+	// Without a processor no more frames are output
+	tracker.mu.Lock()
+	tracker.processor = nil
+	tracker.mu.Unlock()
+	clock.Advance(2 * time.Second)
+	if code := statusCode(t, server, "/readyz"); code != http.StatusServiceUnavailable {
+		t.Errorf("expected 503 with a stale frame, got %d", code)
+	}
+	if code := statusCode(t, server, "/healthz"); code != http.StatusOK {
+		t.Errorf("expected /healthz to stay 200, got %d", code)
+	}
+}
+
+func TestStatusServerListen(t *testing.T) {
+	tracker, err := NewTracker(nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer tracker.Close()
+
+	server := NewStatusServer(tracker, "127.0.0.1:0")
+	if err := server.Start(); err != nil {
+		t.Fatalf("failed to start: %v", err)
+	}
+	defer server.Close()
+
+	resp, err := http.Get("http://" + server.Addr() + "/stats")
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Errorf("expected 200, got %d", resp.StatusCode)
+	}
+}
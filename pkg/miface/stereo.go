@@ -0,0 +1,355 @@
+package miface
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/BurntSushi/toml"
+)
+
+// CameraIntrinsics is the pinhole model of one camera, in pixels at the
+// resolution it was calibrated at.
+type CameraIntrinsics struct {
+	Width  int `toml:"width"`
+	Height int `toml:"height"`
+	// FX and FY are the focal lengths.
+	FX float64 `toml:"fx"`
+	FY float64 `toml:"fy"`
+	// CX and CY are the principal point.
+	CX float64 `toml:"cx"`
+	CY float64 `toml:"cy"`
+}
+
+// StereoCalibration describes a rectified stereo camera pair: both cameras
+// face the same way with parallel optical axes and matching image rows,
+// and the right camera is Baseline meters to the right of the left one.
+// Pairs that aren't physically aligned must be rectified first, e.g. with
+// OpenCV's stereoRectify, and the rectified intrinsics used here.
+//
+// Cameras that flip their frames horizontally swap the sign of the
+// disparity, so no landmark would triangulate; set Mirrored for such a
+// pair and the flip is undone before triangulating.
+//
+// The file format read by LoadStereoCalibration is TOML:
+//
+//	baseline = 0.12
+//
+//	[left]
+//	width = 1280
+//	height = 720
+//	fx = 910.0
+//	fy = 910.0
+//	cx = 640.0
+//	cy = 360.0
+//
+//	[right]
+//	width = 1280
+//	height = 720
+//	fx = 910.0
+//	fy = 910.0
+//	cx = 640.0
+//	cy = 360.0
+type StereoCalibration struct {
+	// Baseline is the distance between the optical centers, in meters.
+	Baseline float64 `toml:"baseline"`
+	// Mirrored is set if both cameras deliver horizontally flipped frames.
+	Mirrored bool             `toml:"mirrored"`
+	Left     CameraIntrinsics `toml:"left"`
+	Right    CameraIntrinsics `toml:"right"`
+}
+
+// LoadStereoCalibration reads a stereo calibration file and validates it.
+func LoadStereoCalibration(path string) (StereoCalibration, error) {
+	var c StereoCalibration
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return c, fmt.Errorf("reading stereo calibration file: %w", err)
+	}
+	if _, err := toml.Decode(string(data), &c); err != nil {
+		return c, fmt.Errorf("parsing stereo calibration file: %w", err)
+	}
+	if err := c.Validate(); err != nil {
+		return c, fmt.Errorf("invalid stereo calibration: %w", err)
+	}
+	return c, nil
+}
+
+// Validate checks that the calibration can triangulate.
+func (c StereoCalibration) Validate() error {
+	if c.Baseline <= 0 {
+		return fmt.Errorf("baseline must be positive, got %f", c.Baseline)
+	}
+	for _, cam := range []struct {
+		name string
+		in   CameraIntrinsics
+	}{{"left", c.Left}, {"right", c.Right}} {
+		if cam.in.Width <= 0 || cam.in.Height <= 0 {
+			return fmt.Errorf("%s camera size must be positive, got %dx%d", cam.name, cam.in.Width, cam.in.Height)
+		}
+		if cam.in.FX <= 0 || cam.in.FY <= 0 {
+			return fmt.Errorf("%s camera focal length must be positive, got %f, %f", cam.name, cam.in.FX, cam.in.FY)
+		}
+	}
+	return nil
+}
+
+// Triangulate returns the point seen at the normalized image positions
+// left and right in the two views, in meters in the left camera's frame:
+// X right, Y down and Z forward from its optical center. It returns false
+// if the views don't converge in front of the cameras, which happens for
+// mismatched landmarks and for flipped frames unless Mirrored is set.
+func (c StereoCalibration) Triangulate(left, right Point3D) (Point3D, bool) {
+	if c.Mirrored {
+		left.X, right.X = 1-left.X, 1-right.X
+	}
+	xl := (left.X*float64(c.Left.Width) - c.Left.CX) / c.Left.FX
+	yl := (left.Y*float64(c.Left.Height) - c.Left.CY) / c.Left.FY
+	xr := (right.X*float64(c.Right.Width) - c.Right.CX) / c.Right.FX
+
+	disparity := xl - xr
+	if disparity <= 0 {
+		return Point3D{}, false
+	}
+	z := c.Baseline / disparity
+	return Point3D{X: xl * z, Y: yl * z, Z: z}, true
+}
+
+// RefineDepth refines the depths of the landmarks in left with the same
+// landmarks triangulated in right, for every modality detected in both
+// views. Landmarks that don't triangulate keep their Z.
+//
+// Landmark Z stays in MediaPipe's units, image widths relative to a
+// reference depth, so downstream code is unaffected: the face is relative
+// to its mean depth, each hand to its wrist and the pose to the hip
+// center. The absolute depths are kept in meters: the face's mean depth
+// becomes the Z of HeadPosition and each wrist's depth the hand's Depth.
+func (c StereoCalibration) RefineDepth(left, right *TrackingData) {
+	if left == nil || right == nil {
+		return
+	}
+	if left.Face != nil && right.Face != nil {
+		if depth, ok := c.refineLandmarks(left.Face.Landmarks, right.Face.Landmarks, nil); ok {
+			left.Face.HeadPosition.Z = depth
+		}
+	}
+	for _, hands := range [][2]*HandData{{left.LeftHand, right.LeftHand}, {left.RightHand, right.RightHand}} {
+		if hands[0] == nil || hands[1] == nil {
+			continue
+		}
+		if depth, ok := c.refineLandmarks(hands[0].Landmarks, hands[1].Landmarks, []int{HandWrist}); ok {
+			hands[0].Depth = depth
+		}
+	}
+	if left.Pose != nil && right.Pose != nil {
+		c.refineLandmarks(left.Pose.Landmarks, right.Pose.Landmarks, []int{PoseLeftHip, PoseRightHip})
+	}
+}
+
+// refineLandmarks triangulates matching landmarks and sets the Z of left
+// relative to the mean depth of the ref landmarks, or of all of them if
+// ref is nil. It returns that mean depth in meters, or false if none of
+// the ref landmarks triangulated.
+func (c StereoCalibration) refineLandmarks(left, right []Landmark, ref []int) (float64, bool) {
+	if len(left) != len(right) {
+		return 0, false
+	}
+
+	depths := make([]float64, len(left))
+	ok := make([]bool, len(left))
+	for i := range left {
+		if p, valid := c.Triangulate(left[i].Point, right[i].Point); valid {
+			depths[i], ok[i] = p.Z, true
+		}
+	}
+
+	var sum float64
+	var n int
+	accumulate := func(i int) {
+		if i < len(depths) && ok[i] {
+			sum += depths[i]
+			n++
+		}
+	}
+	if ref == nil {
+		for i := range depths {
+			accumulate(i)
+		}
+	} else {
+		for _, i := range ref {
+			accumulate(i)
+		}
+	}
+	if n == 0 {
+		return 0, false
+	}
+	refDepth := sum / float64(n)
+
+	// At refDepth one image width spans Width/FX*refDepth meters
+	scale := c.Left.FX / (float64(c.Left.Width) * refDepth)
+	for i := range left {
+		if ok[i] {
+			left[i].Point.Z = (depths[i] - refDepth) * scale
+		}
+	}
+	return refDepth, true
+}
+
+// StereoCameraSource is a CameraSource over two cameras of a stereo pair.
+// Each Read returns the left and right frames side by side in one frame
+// twice as wide, which StereoProcessor splits again.
+//
+// The two frames are read one after the other, so cameras without
+// hardware sync may be a few milliseconds apart; fast motion then
+// triangulates slightly off.
+type StereoCameraSource struct {
+	left, right   CameraSource
+	rightDeviceID int
+
+	frame []byte
+}
+
+// NewStereoCameraSource creates a stereo source. Open opens left with the
+// device ID it is given and right with rightDeviceID.
+func NewStereoCameraSource(left, right CameraSource, rightDeviceID int) *StereoCameraSource {
+	return &StereoCameraSource{
+		left:          left,
+		right:         right,
+		rightDeviceID: rightDeviceID,
+	}
+}
+
+// Open opens both cameras with the same size and frame rate.
+func (s *StereoCameraSource) Open(deviceID, width, height, fps int) error {
+	if err := s.left.Open(deviceID, width, height, fps); err != nil {
+		return fmt.Errorf("opening left camera: %w", err)
+	}
+	if err := s.right.Open(s.rightDeviceID, width, height, fps); err != nil {
+		_ = s.left.Close()
+		return fmt.Errorf("opening right camera: %w", err)
+	}
+	return nil
+}
+
+// Read captures a frame from each camera and returns them side by side as
+// one RGB24 frame. The frames must be the same size.
+func (s *StereoCameraSource) Read() ([]byte, int, int, error) {
+	left, width, height, err := s.left.Read()
+	if err != nil {
+		return nil, 0, 0, fmt.Errorf("reading left camera: %w", err)
+	}
+	right, rightWidth, rightHeight, err := s.right.Read()
+	if err != nil {
+		return nil, 0, 0, fmt.Errorf("reading right camera: %w", err)
+	}
+	if rightWidth != width || rightHeight != height {
+		return nil, 0, 0, fmt.Errorf("stereo frames differ in size: %dx%d and %dx%d", width, height, rightWidth, rightHeight)
+	}
+	row := width * 3
+	if len(left) < row*height || len(right) < row*height {
+		return nil, 0, 0, fmt.Errorf("stereo frames are too short for %dx%d RGB24", width, height)
+	}
+
+	size := 2 * row * height
+	if cap(s.frame) < size {
+		s.frame = make([]byte, size)
+	}
+	s.frame = s.frame[:size]
+	for y := 0; y < height; y++ {
+		copy(s.frame[2*row*y:], left[row*y:row*(y+1)])
+		copy(s.frame[2*row*y+row:], right[row*y:row*(y+1)])
+	}
+	return s.frame, 2 * width, height, nil
+}
+
+// Close releases both cameras.
+func (s *StereoCameraSource) Close() error {
+	return errors.Join(s.left.Close(), s.right.Close())
+}
+
+// StereoProcessor is a Processor for the side-by-side frames of a
+// StereoCameraSource. It runs one processor on each view, concurrently,
+// and returns the left view's tracking data with depths refined by
+// StereoCalibration.RefineDepth.
+//
+// Two processors are needed because detectors such as MediaPipe track
+// landmarks from frame to frame, which breaks if the views alternate.
+type StereoProcessor struct {
+	left, right Processor
+	calibration StereoCalibration
+
+	// leftView and rightView are reused by every Process call.
+	leftView, rightView []byte
+}
+
+// NewStereoProcessor creates a stereo processor running left and right on
+// the two views.
+func NewStereoProcessor(left, right Processor, calibration StereoCalibration) *StereoProcessor {
+	return &StereoProcessor{
+		left:        left,
+		right:       right,
+		calibration: calibration,
+	}
+}
+
+// Process splits a side-by-side RGB24 frame into its views, processes
+// both and triangulates the result. The views are split into buffers
+// reused on the next call, so processors must not retain the frame they
+// are given after Process returns.
+func (p *StereoProcessor) Process(ctx context.Context, frame []byte, width, height int) (*TrackingData, error) {
+	if width%2 != 0 || len(frame) < width*height*3 {
+		return nil, fmt.Errorf("frame is not a side-by-side stereo pair: %d bytes for %dx%d", len(frame), width, height)
+	}
+	p.leftView, p.rightView = splitStereoFrame(p.leftView, p.rightView, frame, width, height)
+	left, right := p.leftView, p.rightView
+	half := width / 2
+
+	type result struct {
+		data *TrackingData
+		err  error
+	}
+	rightDone := make(chan result, 1)
+	go func() {
+		data, err := p.right.Process(ctx, right, half, height)
+		rightDone <- result{data, err}
+	}()
+
+	leftData, leftErr := p.left.Process(ctx, left, half, height)
+	r := <-rightDone
+	if leftErr != nil {
+		return nil, fmt.Errorf("processing left view: %w", leftErr)
+	}
+	if r.err != nil {
+		return nil, fmt.Errorf("processing right view: %w", r.err)
+	}
+
+	p.calibration.RefineDepth(leftData, r.data)
+	return leftData, nil
+}
+
+// Close releases both processors.
+func (p *StereoProcessor) Close() error {
+	return errors.Join(p.left.Close(), p.right.Close())
+}
+
+// splitStereoFrame copies the left and right halves of a side-by-side
+// RGB24 frame into left and right, growing them if needed, and returns
+// them.
+func splitStereoFrame(left, right, frame []byte, width, height int) ([]byte, []byte) {
+	row := width * 3
+	half := row / 2
+	size := half * height
+	if cap(left) < size {
+		left = make([]byte, size)
+	}
+	if cap(right) < size {
+		right = make([]byte, size)
+	}
+	left, right = left[:size], right[:size]
+	for y := 0; y < height; y++ {
+		copy(left[half*y:], frame[row*y:row*y+half])
+		copy(right[half*y:], frame[row*y+half:row*(y+1)])
+	}
+	return left, right
+}
@@ -0,0 +1,240 @@
+package miface
+
+import (
+	"context"
+	"math"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// testStereoCalibration is a pair of identical 640x480 cameras 10cm apart.
+func testStereoCalibration() StereoCalibration {
+	cam := CameraIntrinsics{Width: 640, Height: 480, FX: 500, FY: 500, CX: 320, CY: 240}
+	return StereoCalibration{Baseline: 0.1, Left: cam, Right: cam}
+}
+
+// project returns the normalized image positions of a point in the left
+// camera's frame in both views.
+func project(c StereoCalibration, p Point3D) (Point3D, Point3D) {
+	view := func(in CameraIntrinsics, x float64) Point3D {
+		return Point3D{
+			X: (in.FX*x/p.Z + in.CX) / float64(in.Width),
+			Y: (in.FY*p.Y/p.Z + in.CY) / float64(in.Height),
+		}
+	}
+	return view(c.Left, p.X), view(c.Right, p.X-c.Baseline)
+}
+
+func TestStereoTriangulate(t *testing.T) {
+	c := testStereoCalibration()
+
+	for _, want := range []Point3D{
+		{X: 0, Y: 0, Z: 0.6},
+		{X: 0.15, Y: -0.08, Z: 0.45},
+		{X: -0.3, Y: 0.2, Z: 1.8},
+	} {
+		left, right := project(c, want)
+		got, ok := c.Triangulate(left, right)
+		if !ok {
+			t.Errorf("expected %+v to triangulate", want)
+			continue
+		}
+		if !pointsClose(got, want) {
+			t.Errorf("expected %+v, got %+v", want, got)
+		}
+	}
+
+	// Crossed rays would put the point behind the cameras
+	if _, ok := c.Triangulate(Point3D{X: 0.4, Y: 0.5}, Point3D{X: 0.6, Y: 0.5}); ok {
+		t.Error("expected negative disparity to fail")
+	}
+}
+
+func TestStereoRefineDepth(t *testing.T) {
+	c := testStereoCalibration()
+
+	// A hand 60cm away with the index fingertip 5cm closer than the wrist
+	points := make([]Point3D, HandLandmarkCount)
+	for i := range points {
+		points[i] = Point3D{X: 0.1, Y: 0.05, Z: 0.6}
+	}
+	points[HandIndexTip] = Point3D{X: 0.12, Y: 0.0, Z: 0.55}
+
+	left, right := testHand(true, 1), testHand(true, 1)
+	for i, p := range points {
+		left.Landmarks[i].Point, right.Landmarks[i].Point = project(c, p)
+		left.Landmarks[i].Point.Z = 0.3 // A poor monocular estimate
+	}
+	data := &TrackingData{LeftHand: left}
+	c.RefineDepth(data, &TrackingData{LeftHand: right})
+
+	// 5cm at 60cm is 0.05 * 500 / (640 * 0.6) image widths
+	want := -0.05 * 500 / (640 * 0.6)
+	if got := data.LeftHand.Landmarks[HandIndexTip].Point.Z; math.Abs(got-want) > 1e-9 {
+		t.Errorf("expected fingertip Z %f, got %f", want, got)
+	}
+	if got := data.LeftHand.Landmarks[HandWrist].Point.Z; math.Abs(got) > 1e-9 {
+		t.Errorf("expected the wrist at Z 0, got %f", got)
+	}
+	if got := data.LeftHand.Depth; math.Abs(got-0.6) > 1e-9 {
+		t.Errorf("expected the hand at 0.6m, got %f", got)
+	}
+
+	// A mirrored pair triangulates once the calibration says so
+	mirrored := testHand(true, 1)
+	mirroredRight := testHand(true, 1)
+	for i, p := range points {
+		l, r := project(c, p)
+		l.X, r.X = 1-l.X, 1-r.X
+		mirrored.Landmarks[i].Point, mirroredRight.Landmarks[i].Point = l, r
+	}
+	c.RefineDepth(&TrackingData{LeftHand: mirrored}, &TrackingData{LeftHand: mirroredRight})
+	if mirrored.Depth != 0 {
+		t.Errorf("expected flipped views not to triangulate, got %f", mirrored.Depth)
+	}
+	mc := c
+	mc.Mirrored = true
+	mc.RefineDepth(&TrackingData{LeftHand: mirrored}, &TrackingData{LeftHand: mirroredRight})
+	if math.Abs(mirrored.Depth-0.6) > 1e-9 {
+		t.Errorf("expected the mirrored hand at 0.6m, got %f", mirrored.Depth)
+	}
+
+	// A modality missing from one view is left alone
+	face := &FaceData{Landmarks: []Landmark{{Point: Point3D{X: 0.5, Y: 0.5, Z: 0.2}}}}
+	c.RefineDepth(&TrackingData{Face: face}, &TrackingData{})
+	if face.Landmarks[0].Point.Z != 0.2 {
+		t.Errorf("expected an unmatched face untouched, got Z %f", face.Landmarks[0].Point.Z)
+	}
+}
+
+// colorCamera is a CameraSource producing 2x1 frames of one color.
+type colorCamera struct {
+	color  byte
+	opened int
+}
+
+func (c *colorCamera) Open(deviceID, width, height, fps int) error {
+	c.opened = deviceID
+	return nil
+}
+
+func (c *colorCamera) Read() ([]byte, int, int, error) {
+	return []byte{c.color, c.color, c.color, c.color, c.color, c.color}, 2, 1, nil
+}
+
+func (c *colorCamera) Close() error { return nil }
+
+// viewProcessor reports a single face landmark where point appears in the
+// left view, or in the right view for frames starting with 2.
+type viewProcessor struct {
+	c     StereoCalibration
+	point Point3D
+}
+
+func (p viewProcessor) Process(ctx context.Context, frame []byte, width, height int) (*TrackingData, error) {
+	left, right := project(p.c, p.point)
+	if frame[0] == 2 {
+		left = right
+	}
+	left.Z = 0.3 // A poor monocular estimate
+	return &TrackingData{Face: &FaceData{Landmarks: []Landmark{{Point: left}}}}, nil
+}
+
+func (viewProcessor) Close() error { return nil }
+
+func TestStereoCameraAndProcessor(t *testing.T) {
+	leftCam, rightCam := &colorCamera{color: 1}, &colorCamera{color: 2}
+	camera := NewStereoCameraSource(leftCam, rightCam, 3)
+	if err := camera.Open(1, 640, 480, 30); err != nil {
+		t.Fatalf("failed to open: %v", err)
+	}
+	if leftCam.opened != 1 || rightCam.opened != 3 {
+		t.Errorf("expected devices 1 and 3, got %d and %d", leftCam.opened, rightCam.opened)
+	}
+
+	frame, width, height, err := camera.Read()
+	if err != nil {
+		t.Fatalf("failed to read: %v", err)
+	}
+	if width != 4 || height != 1 || frame[0] != 1 || frame[6] != 2 {
+		t.Fatalf("expected a 4x1 side-by-side frame, got %dx%d %v", width, height, frame)
+	}
+
+	c := testStereoCalibration()
+	proc := viewProcessor{c: c, point: Point3D{X: 0.05, Y: 0.1, Z: 0.7}}
+	data, err := NewStereoProcessor(proc, proc, c).Process(context.Background(), frame, width, height)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	// The views only triangulate if the frame was split correctly, and
+	// then the only landmark is its own reference depth
+	lm := data.Face.Landmarks[0].Point
+	wantLeft, _ := project(c, proc.point)
+	if lm.Z != 0 {
+		t.Errorf("expected the refined Z 0, got %f", lm.Z)
+	}
+	if got := data.Face.HeadPosition.Z; math.Abs(got-0.7) > 1e-9 {
+		t.Errorf("expected the head at 0.7m, got %f", got)
+	}
+	if math.Abs(lm.X-wantLeft.X) > 1e-9 {
+		t.Errorf("expected the left view's landmarks, got %+v", lm)
+	}
+
+	// The views are split into the same buffers every frame
+	stereo := NewStereoProcessor(proc, proc, c)
+	if _, err := stereo.Process(context.Background(), frame, width, height); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	view := &stereo.leftView[0]
+	if _, err := stereo.Process(context.Background(), frame, width, height); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if &stereo.leftView[0] != view {
+		t.Error("expected the left view buffer to be reused")
+	}
+
+	if _, err := NewStereoProcessor(proc, proc, c).Process(context.Background(), frame, 3, 1); err == nil {
+		t.Error("expected error for an odd frame width")
+	}
+}
+
+func TestLoadStereoCalibration(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "stereo.toml")
+	data := `baseline = 0.12
+
+[left]
+width = 1280
+height = 720
+fx = 910.0
+fy = 910.0
+cx = 640.0
+cy = 360.0
+
+[right]
+width = 1280
+height = 720
+fx = 905.0
+fy = 905.0
+cx = 642.0
+cy = 358.0
+`
+	if err := os.WriteFile(path, []byte(data), 0o644); err != nil {
+		t.Fatalf("failed to write: %v", err)
+	}
+
+	c, err := LoadStereoCalibration(path)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if c.Baseline != 0.12 || c.Left.FX != 910 || c.Right.CX != 642 || c.Right.Height != 720 {
+		t.Errorf("unexpected calibration %+v", c)
+	}
+
+	if err := os.WriteFile(path, []byte("baseline = 0.12\n"), 0o644); err != nil {
+		t.Fatalf("failed to write: %v", err)
+	}
+	if _, err := LoadStereoCalibration(path); err == nil {
+		t.Error("expected error for missing intrinsics")
+	}
+}
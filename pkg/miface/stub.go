@@ -0,0 +1,227 @@
+package miface
+
+import (
+	"context"
+	"math"
+	"sync"
+	"time"
+)
+
+// numFaceLandmarks is the size of the unrefined face mesh, without the
+// iris landmarks.
+const numFaceLandmarks = 468
+
+// StubConfig controls the synthetic data generated by StubProcessor.
+type StubConfig struct {
+	// EnableFace generates 468 face landmarks, head pose and blend shapes.
+	EnableFace bool
+	// EnableHands generates 21 landmarks for each hand.
+	EnableHands bool
+	// EnablePose generates 33 body pose landmarks.
+	EnablePose bool
+	// HeadYawAmplitude is the peak head yaw in radians.
+	HeadYawAmplitude float64
+	// Period is the duration of one full head oscillation.
+	Period time.Duration
+}
+
+// DefaultStubConfig returns a stub configuration with all modalities enabled
+// and a gentle head sway of ±20° every four seconds.
+func DefaultStubConfig() StubConfig {
+	return StubConfig{
+		EnableFace:       true,
+		EnableHands:      true,
+		EnablePose:       true,
+		HeadYawAmplitude: 20 * math.Pi / 180,
+		Period:           4 * time.Second,
+	}
+}
+
+// StubProcessor implements Processor by generating synthetic landmarks.
+// It ignores the input frame, so it can run without a camera or MediaPipe
+// to exercise senders, subscribers and overlays.
+type StubProcessor struct {
+	mu    sync.Mutex
+	cfg   StubConfig
+	clock Clock
+	start time.Time
+}
+
+// NewStubProcessor creates a stub processor with the given configuration.
+func NewStubProcessor(cfg StubConfig) *StubProcessor {
+	if cfg.Period <= 0 {
+		cfg.Period = DefaultStubConfig().Period
+	}
+	return &StubProcessor{
+		cfg:   cfg,
+		clock: realClock{},
+		start: time.Now(),
+	}
+}
+
+// SetClock sets the clock the head oscillation and timestamps follow, and
+// restarts the oscillation at the clock's current time. It defaults to
+// the system clock; pass the tracker's clock, e.g. a FakeClock in tests.
+func (s *StubProcessor) SetClock(clock Clock) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.clock = clock
+	s.start = clock.Now()
+}
+
+// Process returns synthetic tracking data. The frame arguments are ignored.
+func (s *StubProcessor) Process(ctx context.Context, frame []byte, width, height int) (*TrackingData, error) {
+	s.mu.Lock()
+	cfg := s.cfg
+	now := s.clock.Now()
+	elapsed := now.Sub(s.start)
+	s.mu.Unlock()
+
+	phase := 2 * math.Pi * elapsed.Seconds() / cfg.Period.Seconds()
+	yaw := cfg.HeadYawAmplitude * math.Sin(phase)
+
+	data := &TrackingData{
+		Timestamp: now,
+	}
+	if cfg.EnableFace {
+		data.Face = stubFace(yaw, phase)
+	}
+	if cfg.EnableHands {
+		data.LeftHand = stubHand(true, phase)
+		data.RightHand = stubHand(false, phase)
+	}
+	if cfg.EnablePose {
+		data.Pose = stubPose(yaw)
+	}
+	return data, nil
+}
+
+// Close is a no-op for the stub processor.
+func (s *StubProcessor) Close() error {
+	return nil
+}
+
+// stubFace lays out face landmarks on an ellipsoid rotated by yaw.
+func stubFace(yaw, phase float64) *FaceData {
+	landmarks := make([]Landmark, numFaceLandmarks)
+	sinYaw, cosYaw := math.Sin(yaw), math.Cos(yaw)
+
+	// Fibonacci lattice gives an even spread of points over the front hemisphere
+	golden := math.Pi * (3 - math.Sqrt(5))
+	for i := range landmarks {
+		y := 1 - float64(i)/float64(numFaceLandmarks-1)*2
+		r := math.Sqrt(1 - y*y)
+		theta := golden * float64(i)
+		x := math.Cos(theta) * r
+		z := -math.Abs(math.Sin(theta) * r)
+
+		// Rotate around the vertical axis
+		rx := x*cosYaw + z*sinYaw
+		rz := -x*sinYaw + z*cosYaw
+
+		landmarks[i] = Landmark{
+			Point: Point3D{
+				X: 0.5 + 0.12*rx,
+				Y: 0.4 + 0.16*y,
+				Z: 0.12 * rz,
+			},
+			Visibility: 1,
+			Presence:   1,
+		}
+	}
+
+	return &FaceData{
+		Landmarks: landmarks,
+		BlendShapes: map[string]float64{
+			"jawOpen": 0.25 * (1 + math.Sin(phase*2)),
+		},
+		HeadRotation: Quaternion{Y: math.Sin(yaw / 2), W: math.Cos(yaw / 2)},
+		HeadPosition: Point3D{X: 0.5, Y: 0.4},
+	}
+}
+
+// stubHand fans out a wrist and five four-joint fingers that slowly curl.
+func stubHand(isLeft bool, phase float64) *HandData {
+	landmarks := make([]Landmark, HandLandmarkCount)
+
+	wristX, dir := 0.7, 1.0
+	if isLeft {
+		wristX, dir = 0.3, -1.0
+	}
+	wrist := Point3D{X: wristX, Y: 0.75}
+	landmarks[0] = Landmark{Point: wrist, Visibility: 1, Presence: 1}
+
+	curl := 0.5 * (1 + math.Sin(phase))
+	for finger := 0; finger < 5; finger++ {
+		angle := -math.Pi/2 + dir*(float64(finger)-2)*0.3
+		for joint := 0; joint < 4; joint++ {
+			reach := 0.03 * float64(joint+1) * (1 - 0.4*curl)
+			landmarks[1+finger*4+joint] = Landmark{
+				Point: Point3D{
+					X: wrist.X + reach*math.Cos(angle),
+					Y: wrist.Y + reach*math.Sin(angle),
+					Z: -0.01 * float64(joint) * curl,
+				},
+				Visibility: 1,
+				Presence:   1,
+			}
+		}
+	}
+
+	return &HandData{
+		IsLeft:     isLeft,
+		Landmarks:  landmarks,
+		Confidence: HandConfidence(landmarks),
+	}
+}
+
+// stubPose returns a standing upper body with the head turned by yaw.
+func stubPose(yaw float64) *PoseData {
+	landmarks := make([]Landmark, PoseLandmarkCount)
+	for i, p := range stubPoseRest {
+		landmarks[i] = Landmark{Point: p, Visibility: 1, Presence: 1}
+	}
+	// Turn the head points (0-10) with the face
+	for i := 0; i <= 10; i++ {
+		landmarks[i].Point.X += 0.05 * math.Sin(yaw)
+	}
+	return &PoseData{Landmarks: landmarks}
+}
+
+// stubPoseRest is an approximate rest pose in normalized image coordinates,
+// indexed by MediaPipe pose landmark.
+var stubPoseRest = [PoseLandmarkCount]Point3D{
+	{X: 0.50, Y: 0.40},           // 0 nose
+	{X: 0.48, Y: 0.37},           // 1 left eye inner
+	{X: 0.47, Y: 0.37},           // 2 left eye
+	{X: 0.46, Y: 0.37},           // 3 left eye outer
+	{X: 0.52, Y: 0.37},           // 4 right eye inner
+	{X: 0.53, Y: 0.37},           // 5 right eye
+	{X: 0.54, Y: 0.37},           // 6 right eye outer
+	{X: 0.44, Y: 0.38},           // 7 left ear
+	{X: 0.56, Y: 0.38},           // 8 right ear
+	{X: 0.49, Y: 0.44},           // 9 mouth left
+	{X: 0.51, Y: 0.44},           // 10 mouth right
+	{X: 0.38, Y: 0.58},           // 11 left shoulder
+	{X: 0.62, Y: 0.58},           // 12 right shoulder
+	{X: 0.34, Y: 0.75},           // 13 left elbow
+	{X: 0.66, Y: 0.75},           // 14 right elbow
+	{X: 0.32, Y: 0.90},           // 15 left wrist
+	{X: 0.68, Y: 0.90},           // 16 right wrist
+	{X: 0.31, Y: 0.93},           // 17 left pinky
+	{X: 0.69, Y: 0.93},           // 18 right pinky
+	{X: 0.32, Y: 0.94},           // 19 left index
+	{X: 0.68, Y: 0.94},           // 20 right index
+	{X: 0.33, Y: 0.92},           // 21 left thumb
+	{X: 0.67, Y: 0.92},           // 22 right thumb
+	{X: 0.42, Y: 1.00},           // 23 left hip
+	{X: 0.58, Y: 1.00},           // 24 right hip
+	{X: 0.42, Y: 1.30},           // 25 left knee
+	{X: 0.58, Y: 1.30},           // 26 right knee
+	{X: 0.42, Y: 1.60},           // 27 left ankle
+	{X: 0.58, Y: 1.60},           // 28 right ankle
+	{X: 0.41, Y: 1.63},           // 29 left heel
+	{X: 0.59, Y: 1.63},           // 30 right heel
+	{X: 0.43, Y: 1.65, Z: -0.05}, // 31 left foot index
+	{X: 0.57, Y: 1.65, Z: -0.05}, // 32 right foot index
+}
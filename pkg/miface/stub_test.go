@@ -0,0 +1,78 @@
+package miface
+
+import (
+	"context"
+	"math"
+	"testing"
+	"time"
+)
+
+func TestStubProcessor(t *testing.T) {
+	stub := NewStubProcessor(DefaultStubConfig())
+	defer stub.Close()
+
+	data, err := stub.Process(context.Background(), nil, 0, 0)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if data.Face == nil {
+		t.Fatal("expected non-nil face data")
+	}
+	if len(data.Face.Landmarks) != 468 {
+		t.Errorf("expected 468 face landmarks, got %d", len(data.Face.Landmarks))
+	}
+	if data.LeftHand == nil || !data.LeftHand.IsLeft || len(data.LeftHand.Landmarks) != 21 {
+		t.Errorf("expected left hand with 21 landmarks, got %+v", data.LeftHand)
+	}
+	if data.RightHand == nil || data.RightHand.IsLeft || len(data.RightHand.Landmarks) != 21 {
+		t.Errorf("expected right hand with 21 landmarks, got %+v", data.RightHand)
+	}
+	if data.Pose == nil || len(data.Pose.Landmarks) != 33 {
+		t.Errorf("expected pose with 33 landmarks, got %+v", data.Pose)
+	}
+
+	q := data.Face.HeadRotation
+	norm := math.Sqrt(q.X*q.X + q.Y*q.Y + q.Z*q.Z + q.W*q.W)
+	if math.Abs(norm-1) > 1e-9 {
+		t.Errorf("expected unit head rotation, got norm %f", norm)
+	}
+}
+
+func TestStubProcessorModalities(t *testing.T) {
+	stub := NewStubProcessor(StubConfig{EnableFace: true})
+
+	data, err := stub.Process(context.Background(), nil, 0, 0)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if data.Face == nil {
+		t.Error("expected face data")
+	}
+	if data.LeftHand != nil || data.RightHand != nil || data.Pose != nil {
+		t.Error("expected only face data")
+	}
+}
+
+func TestStubProcessorOscillates(t *testing.T) {
+	cfg := DefaultStubConfig()
+	stub := NewStubProcessor(cfg)
+	clock := NewFakeClock(time.Unix(0, 0))
+	stub.SetClock(clock)
+
+	first, _ := stub.Process(context.Background(), nil, 0, 0)
+	if first.Face.HeadRotation != (Quaternion{W: 1}) {
+		t.Errorf("expected the head to start centered, got %+v", first.Face.HeadRotation)
+	}
+
+	// A quarter period later the head is at its peak yaw
+	clock.Advance(cfg.Period / 4)
+	second, _ := stub.Process(context.Background(), nil, 0, 0)
+	if !second.Timestamp.Equal(clock.Now()) {
+		t.Errorf("expected the clock's time as timestamp, got %v", second.Timestamp)
+	}
+	want := Quaternion{Y: math.Sin(cfg.HeadYawAmplitude / 2), W: math.Cos(cfg.HeadYawAmplitude / 2)}
+	if got := second.Face.HeadRotation; math.Abs(got.Y-want.Y) > 1e-9 || math.Abs(got.W-want.W) > 1e-9 {
+		t.Errorf("expected peak yaw rotation %+v, got %+v", want, got)
+	}
+}
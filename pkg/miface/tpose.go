@@ -0,0 +1,186 @@
+package miface
+
+import (
+	"math"
+	"sync"
+	"time"
+)
+
+// TPoseConfig controls T-pose detection.
+type TPoseConfig struct {
+	// Hold is how long the T-pose must be held before it fires.
+	Hold time.Duration
+	// Tolerance is the largest angle in radians each arm segment may make
+	// with the shoulder line and still count as horizontal.
+	Tolerance float64
+	// MinVisibility is the visibility every shoulder, elbow and wrist
+	// landmark needs for the pose to be judged at all.
+	MinVisibility float64
+}
+
+// DefaultTPoseConfig returns a T-pose configuration that fires after the
+// arms are held out for a second, within 15° of horizontal.
+func DefaultTPoseConfig() TPoseConfig {
+	return TPoseConfig{
+		Hold:          time.Second,
+		Tolerance:     15 * math.Pi / 180,
+		MinVisibility: 0.5,
+	}
+}
+
+// BodyCalibration holds the user's body proportions measured in a T-pose,
+// in the axes of ImageToNormalized.
+type BodyCalibration struct {
+	// ArmSpan is the distance between the wrists.
+	ArmSpan float64 `toml:"arm_span"`
+	// ShoulderWidth is the distance between the shoulders.
+	ShoulderWidth float64 `toml:"shoulder_width"`
+}
+
+// TPoseDetector watches the pose for a T-pose, both arms held out level
+// with the shoulders, and calls a callback with the body proportions
+// averaged over the hold once it has been held for TPoseConfig.Hold. It
+// fires once per T-pose: the arms must drop before it can fire again.
+//
+// TPoseDetector implements TransformStage for use in a ChainProcessor; the
+// data passes through unchanged.
+type TPoseDetector struct {
+	mu      sync.Mutex
+	cfg     TPoseConfig
+	onTPose func(BodyCalibration)
+
+	since   time.Time // Start of the current T-pose; zero when not in one
+	fired   bool
+	sum     BodyCalibration
+	samples int
+
+	clock Clock
+}
+
+// NewTPoseDetector creates a T-pose detector that calls onTPose each time a
+// T-pose has been held long enough. To keep the measurement, onTPose can
+// pass it to Tracker.SaveBodyCalibration.
+func NewTPoseDetector(cfg TPoseConfig, onTPose func(BodyCalibration)) *TPoseDetector {
+	return &TPoseDetector{
+		cfg:     cfg,
+		onTPose: onTPose,
+		clock:   realClock{},
+	}
+}
+
+// SetClock sets the clock the hold is timed with. It defaults to the
+// system clock; pass the tracker's clock so the hold follows its time,
+// e.g. a FakeClock in tests.
+func (d *TPoseDetector) SetClock(clock Clock) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.clock = clock
+}
+
+// Transform checks the frame's pose for a T-pose.
+func (d *TPoseDetector) Transform(data *TrackingData) (*TrackingData, error) {
+	if data == nil {
+		return data, nil
+	}
+
+	d.mu.Lock()
+	body, fire := d.update(data.Pose, d.clock.Now())
+	d.mu.Unlock()
+
+	if fire && d.onTPose != nil {
+		d.onTPose(body)
+	}
+	return data, nil
+}
+
+// Reset forgets the T-pose in progress, so a held T-pose can fire again.
+func (d *TPoseDetector) Reset() {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.reset()
+}
+
+// update advances the detector by one frame and reports whether the T-pose
+// fires, with the averaged measurements. Must be called with d.mu held.
+func (d *TPoseDetector) update(pose *PoseData, now time.Time) (BodyCalibration, bool) {
+	body, ok := d.measure(pose)
+	if !ok {
+		d.reset()
+		return BodyCalibration{}, false
+	}
+
+	if d.since.IsZero() {
+		d.since = now
+	}
+	d.sum.ArmSpan += body.ArmSpan
+	d.sum.ShoulderWidth += body.ShoulderWidth
+	d.samples++
+
+	if d.fired || now.Sub(d.since) < d.cfg.Hold {
+		return BodyCalibration{}, false
+	}
+	d.fired = true
+	n := float64(d.samples)
+	return BodyCalibration{ArmSpan: d.sum.ArmSpan / n, ShoulderWidth: d.sum.ShoulderWidth / n}, true
+}
+
+// reset clears the T-pose in progress.
+func (d *TPoseDetector) reset() {
+	d.since = time.Time{}
+	d.fired = false
+	d.sum = BodyCalibration{}
+	d.samples = 0
+}
+
+// measure returns the body proportions if pose is a T-pose.
+func (d *TPoseDetector) measure(pose *PoseData) (BodyCalibration, bool) {
+	if pose == nil || len(pose.Landmarks) <= PoseRightWrist {
+		return BodyCalibration{}, false
+	}
+	lms := pose.Landmarks
+	for _, idx := range []int{
+		PoseLeftShoulder, PoseRightShoulder, PoseLeftElbow,
+		PoseRightElbow, PoseLeftWrist, PoseRightWrist,
+	} {
+		if lms[idx].Visibility < d.cfg.MinVisibility {
+			return BodyCalibration{}, false
+		}
+	}
+
+	// Depth is too noisy to judge, so the arms are checked in the image plane
+	point := func(idx int) Point3D {
+		p := ImageToNormalized(lms[idx].Point)
+		p.Z = 0
+		return p
+	}
+	leftShoulder, rightShoulder := point(PoseLeftShoulder), point(PoseRightShoulder)
+	if !d.armLevel(rightShoulder, leftShoulder, point(PoseLeftElbow), point(PoseLeftWrist)) ||
+		!d.armLevel(leftShoulder, rightShoulder, point(PoseRightElbow), point(PoseRightWrist)) {
+		return BodyCalibration{}, false
+	}
+
+	return BodyCalibration{
+		ArmSpan:       Distance(point(PoseLeftWrist), point(PoseRightWrist)),
+		ShoulderWidth: Distance(leftShoulder, rightShoulder),
+	}, true
+}
+
+// armLevel reports whether both segments of an arm point outward along the
+// shoulder line, within the tolerance. Like the Retargeter's T-pose, the
+// outward direction runs from the other shoulder through this one.
+func (d *TPoseDetector) armLevel(otherShoulder, shoulder, elbow, wrist Point3D) bool {
+	outward := shoulder.Sub(otherShoulder).Normalize()
+	if outward == (Point3D{}) {
+		return false
+	}
+	for _, seg := range []Point3D{elbow.Sub(shoulder), wrist.Sub(elbow)} {
+		length := seg.Length()
+		if length == 0 {
+			return false
+		}
+		if math.Acos(clampUnit(seg.Dot(outward)/length)) > d.cfg.Tolerance {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,132 @@
+package miface
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+// tPose returns a pose with the shoulders 0.2 apart and both arms held out
+// at droop radians below horizontal, with each segment 0.12 long.
+func tPose(droop float64) *PoseData {
+	lms := make([]Landmark, PoseLandmarkCount)
+	for i := range lms {
+		lms[i].Visibility = 1
+	}
+	set := func(idx int, x, y float64) {
+		lms[idx].Point = Point3D{X: x, Y: y}
+	}
+
+	dx, dy := 0.12*math.Cos(droop), 0.12*math.Sin(droop)
+	set(PoseLeftShoulder, 0.6, 0.4)
+	set(PoseLeftElbow, 0.6+dx, 0.4+dy)
+	set(PoseLeftWrist, 0.6+2*dx, 0.4+2*dy)
+	set(PoseRightShoulder, 0.4, 0.4)
+	set(PoseRightElbow, 0.4-dx, 0.4+dy)
+	set(PoseRightWrist, 0.4-2*dx, 0.4+2*dy)
+	return &PoseData{Landmarks: lms}
+}
+
+// newTestTPoseDetector returns a detector with a 500ms hold on a clock the
+// step function advances by 100ms per frame, and the measurements it fired.
+func newTestTPoseDetector(t *testing.T) (step func(*PoseData), fired *[]BodyCalibration) {
+	fired = new([]BodyCalibration)
+	cfg := DefaultTPoseConfig()
+	cfg.Hold = 500 * time.Millisecond
+	d := NewTPoseDetector(cfg, func(body BodyCalibration) {
+		*fired = append(*fired, body)
+	})
+	clock := NewFakeClock(time.Unix(0, 0))
+	d.SetClock(clock)
+
+	return func(pose *PoseData) {
+		t.Helper()
+		if _, err := d.Transform(&TrackingData{Pose: pose}); err != nil {
+			t.Fatalf("transform failed: %v", err)
+		}
+		clock.Advance(100 * time.Millisecond)
+	}, fired
+}
+
+func TestTPoseDetectorFiresOnceAfterHold(t *testing.T) {
+	step, fired := newTestTPoseDetector(t)
+
+	for i := 0; i < 5; i++ {
+		step(tPose(0))
+	}
+	if len(*fired) != 0 {
+		t.Fatalf("expected no T-pose before the hold, got %d", len(*fired))
+	}
+
+	// Held for 500ms on the sixth frame, then kept without firing again
+	for i := 0; i < 10; i++ {
+		step(tPose(0))
+	}
+	if len(*fired) != 1 {
+		t.Fatalf("expected the T-pose to fire once, got %d", len(*fired))
+	}
+
+	body := (*fired)[0]
+	if math.Abs(body.ArmSpan-0.68) > 1e-9 {
+		t.Errorf("expected arm span 0.68, got %f", body.ArmSpan)
+	}
+	if math.Abs(body.ShoulderWidth-0.2) > 1e-9 {
+		t.Errorf("expected shoulder width 0.2, got %f", body.ShoulderWidth)
+	}
+
+	// Dropping the arms and striking the pose again fires again
+	step(tPose(math.Pi / 3))
+	for i := 0; i < 6; i++ {
+		step(tPose(0))
+	}
+	if len(*fired) != 2 {
+		t.Errorf("expected a second T-pose to fire, got %d", len(*fired))
+	}
+}
+
+func TestTPoseDetectorTolerance(t *testing.T) {
+	step, fired := newTestTPoseDetector(t)
+
+	// Slightly drooping arms are close enough
+	for i := 0; i < 6; i++ {
+		step(tPose(10 * math.Pi / 180))
+	}
+	if len(*fired) != 1 {
+		t.Errorf("expected arms 10° below horizontal to count, got %d", len(*fired))
+	}
+
+	step, fired = newTestTPoseDetector(t)
+	for i := 0; i < 10; i++ {
+		step(tPose(30 * math.Pi / 180))
+	}
+	if len(*fired) != 0 {
+		t.Errorf("expected arms 30° below horizontal not to count, got %d", len(*fired))
+	}
+}
+
+func TestTPoseDetectorBrokenHold(t *testing.T) {
+	step, fired := newTestTPoseDetector(t)
+
+	// A missing pose restarts the hold
+	for i := 0; i < 4; i++ {
+		step(tPose(0))
+	}
+	step(nil)
+	for i := 0; i < 4; i++ {
+		step(tPose(0))
+	}
+	if len(*fired) != 0 {
+		t.Errorf("expected an interrupted T-pose not to fire, got %d", len(*fired))
+	}
+
+	// As does a hidden wrist
+	pose := tPose(0)
+	pose.Landmarks[PoseLeftWrist].Visibility = 0.1
+	step(pose)
+	for i := 0; i < 4; i++ {
+		step(tPose(0))
+	}
+	if len(*fired) != 0 {
+		t.Errorf("expected a T-pose with a hidden wrist not to count, got %d", len(*fired))
+	}
+}
@@ -40,6 +40,8 @@
 //   - Tracker: Main coordinator managing capture, tracking, and output
 //   - CameraSource: Webcam capture abstraction (pluggable)
 //   - MediaPipeProcessor: MediaPipe Holistic integration interface
+//   - ChainProcessor: Composes a processor with post-processing stages
+//   - AsyncProcessor: Runs a slow processor off the tracking loop
 //   - KalmanFilter: Smoothing filter for landmark stabilization
 //   - VMCSender/OSCSender: Protocol senders for VTuber applications
 //
@@ -50,19 +52,26 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"image"
+	"log/slog"
+	"math"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/MiFaceDEV/miface/internal/config"
+	"gocv.io/x/gocv"
 )
 
 // Common errors returned by MiFace.
 var (
-	ErrTrackerClosed  = errors.New("tracker is closed")
-	ErrTrackerRunning = errors.New("tracker is already running")
-	ErrTrackerStopped = errors.New("tracker is not running")
-	ErrCameraNotFound = errors.New("camera device not found")
-	ErrMediaPipeInit  = errors.New("failed to initialize MediaPipe")
+	ErrTrackerClosed            = errors.New("tracker is closed")
+	ErrTrackerRunning           = errors.New("tracker is already running")
+	ErrTrackerStopped           = errors.New("tracker is not running")
+	ErrCameraNotFound           = errors.New("camera device not found")
+	ErrCameraBackendUnsupported = errors.New("camera backend not supported on this system")
+	ErrUnsupportedFrameType     = errors.New("unsupported camera frame type")
+	ErrMediaPipeInit            = errors.New("failed to initialize MediaPipe")
 )
 
 // Point3D represents a 3D coordinate.
@@ -70,10 +79,11 @@ type Point3D struct {
 	X, Y, Z float64
 }
 
-// Landmark represents a tracked landmark point with visibility confidence.
+// Landmark represents a tracked landmark point with visibility and presence confidence.
 type Landmark struct {
 	Point      Point3D
 	Visibility float64 // 0.0 to 1.0 confidence score
+	Presence   float64 // 0.0 to 1.0 likelihood the landmark is present (not occluded)
 }
 
 // Quaternion represents a rotation in 3D space.
@@ -101,6 +111,9 @@ type HandData struct {
 	Landmarks []Landmark
 	// Confidence is the hand detection confidence (0.0 to 1.0).
 	Confidence float64
+	// Depth is the wrist's distance from the camera in meters, or 0 if
+	// unknown. Only stereo tracking measures it.
+	Depth float64
 }
 
 // PoseData contains body pose tracking results.
@@ -154,6 +167,85 @@ func (s TrackerState) String() string {
 	}
 }
 
+// Modality is a kind of tracking data that can be enabled or disabled.
+// Modalities are bit flags, so several can be combined in a mask.
+type Modality int
+
+const (
+	// ModalityFace is the face mesh, head pose and blend shapes.
+	ModalityFace Modality = 1 << iota
+	// ModalityHands is both hands.
+	ModalityHands
+	// ModalityPose is the body pose.
+	ModalityPose
+)
+
+func (m Modality) String() string {
+	switch m {
+	case ModalityFace:
+		return "face"
+	case ModalityHands:
+		return "hands"
+	case ModalityPose:
+		return "pose"
+	default:
+		return "unknown"
+	}
+}
+
+// TrackerStats is a snapshot of the tracker's runtime statistics.
+type TrackerStats struct {
+	// FrameNumber is the number of the last frame sent to outputs.
+	FrameNumber uint64 `json:"frame_number"`
+	// FPS is the achieved output frame rate, smoothed over recent frames.
+	FPS float64 `json:"fps"`
+	// Latency is how long the last frame took from capture to output.
+	Latency time.Duration `json:"latency_ns"`
+	// LastFrame is when the last frame was sent to outputs.
+	LastFrame time.Time `json:"last_frame"`
+	// SendErrors counts frames the VMC sender or an added sender failed to send.
+	SendErrors uint64 `json:"send_errors"`
+	// Senders holds the statistics of each registered sender, in the order
+	// they receive frames.
+	Senders []SenderStats `json:"senders"`
+	// DroppedFrames counts frames not delivered to slow subscribers.
+	DroppedFrames uint64 `json:"dropped_frames"`
+
+	// Whether each modality was detected in the last frame.
+	FaceDetected      bool `json:"face_detected"`
+	LeftHandDetected  bool `json:"left_hand_detected"`
+	RightHandDetected bool `json:"right_hand_detected"`
+	PoseDetected      bool `json:"pose_detected"`
+}
+
+// SenderStats is a snapshot of one registered sender's statistics.
+type SenderStats struct {
+	// Name identifies the sender: "vmc" for the VMC sender, and "senderN"
+	// for the Nth sender added with AddSender.
+	Name string `json:"name"`
+	// SendErrors counts frames the sender failed to send.
+	SendErrors uint64 `json:"send_errors"`
+}
+
+// registeredSender is a sender registered with the tracker, with its
+// statistics and decimation.
+type registeredSender struct {
+	Sender
+	name   string
+	errors atomic.Uint64
+	everyN atomic.Int64 // Set by SetSendEveryN; 0 or 1 sends every frame
+}
+
+// skips reports whether the sender skips frame under the decimation set by
+// SetSendEveryN.
+func (s *registeredSender) skips(frame uint64) bool {
+	n := s.everyN.Load()
+	return n > 1 && frame%uint64(n) != 0
+}
+
+// fpsSmoothing is the weight of the newest frame interval in TrackerStats.FPS.
+const fpsSmoothing = 0.1
+
 // CameraSource is the interface for camera capture backends.
 type CameraSource interface {
 	// Open initializes the camera with the given configuration.
@@ -164,6 +256,29 @@ type CameraSource interface {
 	Close() error
 }
 
+// MirrorSource is implemented by camera sources that can flip their frames
+// horizontally, such as OpenCVCamera. The tracker uses it to correct the
+// head yaw of mirrored frames; see MirrorHeadYaw.
+type MirrorSource interface {
+	// IsMirror reports whether frames are flipped horizontally.
+	IsMirror() bool
+}
+
+// TimestampedSource is implemented by camera sources that know when each
+// frame was captured. The tracker uses it to skip frames that sat in the
+// camera's queue for too long; see TrackingConfig.MaxFrameAgeMS.
+type TimestampedSource interface {
+	// ReadTimestamped captures a frame like Read and also returns when it
+	// was captured.
+	ReadTimestamped() ([]byte, int, int, time.Time, error)
+}
+
+// isMirrored reports whether camera flips its frames horizontally.
+func isMirrored(camera CameraSource) bool {
+	m, ok := camera.(MirrorSource)
+	return ok && m.IsMirror()
+}
+
 // Processor is the interface for landmark detection processors.
 type Processor interface {
 	// Process analyzes a frame and returns tracking data.
@@ -172,9 +287,23 @@ type Processor interface {
 	Close() error
 }
 
+// FramePreprocessor transforms each captured RGB24 frame before landmark
+// detection, for example to equalize, denoise or crop it. It returns the new
+// frame and its dimensions, and may modify frame in place. An error skips
+// the frame.
+type FramePreprocessor func(frame []byte, width, height int) ([]byte, int, int, error)
+
+// DataTransform post-processes each frame's tracking data before it is sent
+// and broadcast, for example to add a blend shape or clamp a rotation. It
+// may modify data in place or return different data; returning nil drops
+// the frame.
+type DataTransform func(data *TrackingData) *TrackingData
+
 // Sender is the interface for protocol output senders.
 type Sender interface {
-	// Send transmits tracking data.
+	// Send transmits tracking data. The data is shared by all senders and
+	// only valid during the call: Send must not modify it, and must Clone
+	// it to keep it.
 	Send(data *TrackingData) error
 	// Close releases sender resources.
 	Close() error
@@ -184,21 +313,65 @@ type Sender interface {
 type Tracker struct {
 	cfg *config.Config
 
+	// frameMu is held for each processed frame, so a camera swap can
+	// pause capture between frames.
+	frameMu sync.Mutex
+	// frameBuf holds RGB bytes converted from a MatSource frame; guarded by frameMu.
+	frameBuf []byte
+	// scaledBuf holds the downscaled RGB frame; guarded by frameMu.
+	scaledBuf []byte
+
 	mu          sync.RWMutex
 	state       TrackerState
 	camera      CameraSource
 	processor   Processor
-	vmcSender   Sender
+	preprocess  FramePreprocessor
+	transform   DataTransform
+	senders     []*registeredSender // Every registered sender, replaced rather than modified
+	vmcSender   Sender              // The sender in senders managed by the VMC config, if any
+	retargeter  *Retargeter         // Applied to every VMC sender; nil if unset
+	addedCount  int                 // Senders added with AddSender so far, for their names
 	preview     *PreviewWindow
 	subscribers []chan *TrackingData
+	smoothers   *trackerSmoothers
+	shaper      *BlendShapeShaper  // nil without blend shape curves
+	neutral     map[string]float64 // Neutral blend shape baseline; nil until calibrated
+	calibration *neutralCapture    // In-progress CalibrateNeutral, if any
+
+	// calibrationFileMu serializes updates of the calibration file.
+	calibrationFileMu sync.Mutex
 
 	ctx    context.Context
 	cancel context.CancelFunc
+	drain  chan struct{} // Closed by Drain to end the loop without cancelling ctx
 	wg     sync.WaitGroup
 
+	drained       chan struct{} // Closed when the last Drain's goroutine ends; nil if none
+	drainTimedOut bool          // The last Drain timed out, so its final frame is skipped
+
 	frameCount uint64
+	latest     *TrackingData // Private copy of the last processed frame
+	recent     *frameRing    // Private copies of recent frames, for RecentFrames
+	stats      TrackerStats  // Snapshot returned by Stats
+	lastSeen   time.Time     // When real tracking data was last produced
+	nextOutput time.Time     // Earliest output time under MaxOutputFPS
+	lastSmooth time.Time     // When the last smoothed frame was captured
+
+	restPose        *TrackingData
+	restPoseTimeout time.Duration
+	restModalities  Modality // Disabled modalities whose rest pose is yet to be sent
+
+	logger         *slog.Logger
+	statsObserver  func(TrackerStats)   // Called after each output frame; nil if unset
+	statsObservers []func(TrackerStats) // Added with AddStatsObserver, replaced rather than modified
+	errCh          chan error
+	clock          Clock
 }
 
+// errorBufferSize is how many unread sender errors Errors() buffers before
+// newer ones are dropped.
+const errorBufferSize = 16
+
 // NewTracker creates a new tracker with the given configuration.
 // If cfg is nil, default configuration is used.
 func NewTracker(cfg *config.Config) (*Tracker, error) {
@@ -210,12 +383,124 @@ func NewTracker(cfg *config.Config) (*Tracker, error) {
 		return nil, fmt.Errorf("invalid configuration: %w", err)
 	}
 
+	neutral, err := loadNeutral(cfg.Tracking.CalibrationFile)
+	if err != nil {
+		return nil, err
+	}
+
 	return &Tracker{
-		cfg:   cfg,
-		state: StateIdle,
+		cfg:       cfg,
+		state:     StateIdle,
+		smoothers: newTrackerSmoothers(cfg.Tracking),
+		shaper:    newBlendShapeShaper(cfg.BlendShapeCurves),
+		neutral:   neutral,
+		recent:    newFrameRing(0),
+		logger:    slog.New(slog.DiscardHandler),
+		errCh:     make(chan error, errorBufferSize),
+		clock:     realClock{},
 	}, nil
 }
 
+// trackerSmoothers holds one landmark smoother per tracked modality.
+type trackerSmoothers struct {
+	face        *LandmarkSmoother
+	leftHand    *LandmarkSmoother
+	rightHand   *LandmarkSmoother
+	pose        *LandmarkSmoother
+	blendShapes *BlendShapeSmoother
+}
+
+// newTrackerSmoothers creates smoothers for all modalities with the
+// configured smoothing algorithm and visibility floors.
+func newTrackerSmoothers(tracking config.TrackingConfig) *trackerSmoothers {
+	var s *trackerSmoothers
+	switch tracking.SmoothingAlgorithm {
+	case "", config.SmoothingKalman:
+		s = &trackerSmoothers{
+			face:        NewLandmarkSmoother(tracking.SmoothingFactor),
+			leftHand:    NewLandmarkSmoother(tracking.SmoothingFactor),
+			rightHand:   NewLandmarkSmoother(tracking.SmoothingFactor),
+			pose:        NewLandmarkSmoother(tracking.SmoothingFactor),
+			blendShapes: NewBlendShapeSmoother(tracking.SmoothingFactor),
+		}
+	default:
+		newFilter := smoothingFilterFactory(tracking)
+		s = &trackerSmoothers{
+			face:        NewLandmarkSmootherWithFilter(newFilter),
+			leftHand:    NewLandmarkSmootherWithFilter(newFilter),
+			rightHand:   NewLandmarkSmootherWithFilter(newFilter),
+			pose:        NewLandmarkSmootherWithFilter(newFilter),
+			blendShapes: NewBlendShapeSmootherWithFilter(newFilter),
+		}
+	}
+	s.setVisibilityFloor(tracking.VisibilityFloor)
+	return s
+}
+
+// setVisibilityFloor sets the visibility floor of each landmark smoother.
+func (s *trackerSmoothers) setVisibilityFloor(floor config.VisibilityFloorConfig) {
+	s.face.SetVisibilityFloor(floor.Face)
+	s.leftHand.SetVisibilityFloor(floor.Hands)
+	s.rightHand.SetVisibilityFloor(floor.Hands)
+	s.pose.SetVisibilityFloor(floor.Pose)
+}
+
+// setFilter switches every smoother to the configured smoothing algorithm
+// and parameters without dropping its state.
+func (s *trackerSmoothers) setFilter(tracking config.TrackingConfig) {
+	landmarks := []*LandmarkSmoother{s.face, s.leftHand, s.rightHand, s.pose}
+	newFilter := smoothingFilterFactory(tracking)
+	switch tracking.SmoothingAlgorithm {
+	case "", config.SmoothingKalman:
+		for _, ls := range landmarks {
+			ls.SetSmoothingFactor(tracking.SmoothingFactor)
+		}
+	default:
+		for _, ls := range landmarks {
+			ls.SetFilter(newFilter)
+		}
+	}
+	s.blendShapes.SetFilter(newFilter)
+}
+
+// smoothingFilterFactory returns the filter factory for the configured
+// smoothing algorithm. Unknown algorithms are rejected by validation and
+// fall back to Kalman.
+func smoothingFilterFactory(tracking config.TrackingConfig) FilterFactory {
+	switch tracking.SmoothingAlgorithm {
+	case config.SmoothingOneEuro:
+		p := tracking.OneEuro
+		return OneEuroFilterFactory(p.MinCutoff, p.Beta, p.DCutoff)
+	case config.SmoothingDoubleExp:
+		p := tracking.DoubleExp
+		return DoubleExponentialFilterFactory(p.Alpha, p.Beta, p.Lookahead)
+	}
+	return KalmanFilterFactory(tracking.SmoothingFactor)
+}
+
+// resetModality clears the state of the smoothers of one modality.
+func (s *trackerSmoothers) resetModality(modality Modality) {
+	switch modality {
+	case ModalityFace:
+		s.face.Reset()
+		s.blendShapes.Reset()
+	case ModalityHands:
+		s.leftHand.Reset()
+		s.rightHand.Reset()
+	case ModalityPose:
+		s.pose.Reset()
+	}
+}
+
+// reset clears the state of all smoothers.
+func (s *trackerSmoothers) reset() {
+	s.face.Reset()
+	s.leftHand.Reset()
+	s.rightHand.Reset()
+	s.pose.Reset()
+	s.blendShapes.Reset()
+}
+
 // Config returns the current configuration.
 func (t *Tracker) Config() *config.Config {
 	t.mu.RLock()
@@ -223,6 +508,370 @@ func (t *Tracker) Config() *config.Config {
 	return t.cfg
 }
 
+// ApplyConfig updates the tracker configuration, including while running.
+//
+// Tracking settings (enabled modalities, smoothing) and the VMC target
+// (enabled, address, port) take effect on the next frame. Camera settings are
+// fixed once capture starts; changing them while running returns an error.
+func (t *Tracker) ApplyConfig(cfg *config.Config) error {
+	if cfg == nil {
+		return errors.New("config is nil")
+	}
+	if err := cfg.Validate(); err != nil {
+		return fmt.Errorf("invalid configuration: %w", err)
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.state == StateClosed {
+		return ErrTrackerClosed
+	}
+	if t.state == StateRunning && cfg.Camera != t.cfg.Camera {
+		return errors.New("cannot change camera settings while tracker is running")
+	}
+
+	neutral, err := t.reloadNeutral(cfg.Tracking.CalibrationFile)
+	if err != nil {
+		return err
+	}
+	if err := t.applyVMCConfig(cfg.VMC); err != nil {
+		return err
+	}
+	t.neutral = neutral
+	t.applyModalityToggles(cfg.Tracking)
+	t.configureSmoothers(cfg.Tracking)
+	t.applySenderThresholds(cfg.Tracking)
+	t.shaper = newBlendShapeShaper(cfg.BlendShapeCurves)
+
+	newCfg := *cfg
+	t.cfg = &newCfg
+	return nil
+}
+
+// SetTrackingConfig updates the tracking settings, including while running.
+//
+// Every tracking setting is safe to change at runtime: enabled modalities
+// and the hand confidence threshold apply to the next frame (a disabled
+// modality goes back to rest, as with SetModalityEnabled), and a new
+// smoothing algorithm or factor retunes the smoothers in place, so the
+// output carries on from where it was without interrupting capture.
+// Camera and VMC settings are not affected; use ApplyConfig to change the
+// VMC target.
+func (t *Tracker) SetTrackingConfig(tracking config.TrackingConfig) error {
+	if err := tracking.Validate(); err != nil {
+		return fmt.Errorf("invalid tracking configuration: %w", err)
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.state == StateClosed {
+		return ErrTrackerClosed
+	}
+
+	neutral, err := t.reloadNeutral(tracking.CalibrationFile)
+	if err != nil {
+		return err
+	}
+	t.neutral = neutral
+	t.applyModalityToggles(tracking)
+	t.configureSmoothers(tracking)
+	t.applySenderThresholds(tracking)
+
+	newCfg := *t.cfg
+	newCfg.Tracking = tracking
+	t.cfg = &newCfg
+	return nil
+}
+
+// SetModalityEnabled enables or disables tracking of a modality, the same
+// as the EnableFace, EnableHands and EnablePose tracking settings. It can be
+// called while running, e.g. from a hotkey to drop the hands while typing.
+//
+// Disabling a modality resets its smoother, so it doesn't glide in from
+// where it was when enabled again, and the next frame carries that
+// modality from the rest pose set by SetRestPose, or DefaultRestPose if
+// none is set, so receivers put it back at rest instead of freezing it.
+func (t *Tracker) SetModalityEnabled(modality Modality, enabled bool) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.state == StateClosed {
+		return ErrTrackerClosed
+	}
+
+	newCfg := *t.cfg
+	tracking := &newCfg.Tracking
+	switch modality {
+	case ModalityFace:
+		tracking.EnableFace = enabled
+	case ModalityHands:
+		tracking.EnableHands = enabled
+	case ModalityPose:
+		tracking.EnablePose = enabled
+	default:
+		return fmt.Errorf("unknown modality %d", modality)
+	}
+	t.applyModalityToggles(*tracking)
+	t.cfg = &newCfg
+	return nil
+}
+
+// applyModalityToggles handles the modalities that tracking enables or
+// disables compared to the current settings, as SetModalityEnabled
+// describes: a disabled modality has its smoother reset and its rest pose
+// queued, and an enabled one has any queued rest pose dropped.
+// Must be called with t.mu held, before t.cfg is replaced.
+func (t *Tracker) applyModalityToggles(tracking config.TrackingConfig) {
+	current := t.cfg.Tracking
+	for _, m := range []struct {
+		modality     Modality
+		was, enabled bool
+	}{
+		{ModalityFace, current.EnableFace, tracking.EnableFace},
+		{ModalityHands, current.EnableHands, tracking.EnableHands},
+		{ModalityPose, current.EnablePose, tracking.EnablePose},
+	} {
+		switch {
+		case m.was && !m.enabled:
+			t.smoothers.resetModality(m.modality)
+			t.restModalities |= m.modality
+		case m.enabled:
+			t.restModalities &^= m.modality
+		}
+	}
+}
+
+// applySenderThresholds updates the tracking-dependent settings of the VMC sender.
+// Must be called with t.mu held.
+func (t *Tracker) applySenderThresholds(tracking config.TrackingConfig) {
+	if vmc, ok := t.vmcSender.(*VMCSender); ok {
+		configureVMCSender(vmc, tracking, t.retargeter, t.restPose)
+	}
+}
+
+// configureVMCSender applies the tracking settings that the VMC sender
+// enforces, and retargeter and restPose if they are not nil.
+func configureVMCSender(vmc *VMCSender, tracking config.TrackingConfig, retargeter *Retargeter, restPose *TrackingData) {
+	vmc.SetMinHandConfidence(tracking.MinHandConfidence)
+	vmc.SetLowerBodyLock(tracking.LockLowerBody)
+	vmc.SetVisibilityFloor(tracking.VisibilityFloor.Hands, tracking.VisibilityFloor.Pose)
+	if retargeter != nil {
+		vmc.SetRetargeter(retargeter)
+	}
+	if restPose != nil {
+		vmc.SetRestPose(restPose)
+	}
+}
+
+// SetRetargeter sets the retargeter that solves the arm bone rotations of
+// the VMC sender, e.g. NewRetargeter with the avatar's VRM proportions. It
+// is applied to the current *VMCSender and to every one set later or
+// created by a VMC config reload. Passing nil removes it from the current
+// sender and stops applying it.
+// Can be called while running.
+func (t *Tracker) SetRetargeter(retargeter *Retargeter) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.retargeter = retargeter
+	if vmc, ok := t.vmcSender.(*VMCSender); ok {
+		vmc.SetRetargeter(retargeter)
+	}
+}
+
+// newBlendShapeShaper returns a shaper for curves, or nil if there are none.
+func newBlendShapeShaper(curves map[string]config.BlendShapeCurve) *BlendShapeShaper {
+	if len(curves) == 0 {
+		return nil
+	}
+	return NewBlendShapeShaper(curves)
+}
+
+// loadNeutral reads the neutral blend shape baseline from a calibration
+// file, or returns nil if path is empty.
+func loadNeutral(path string) (map[string]float64, error) {
+	if path == "" {
+		return nil, nil
+	}
+	c, err := LoadCalibration(path)
+	if err != nil {
+		return nil, fmt.Errorf("loading calibration: %w", err)
+	}
+	return c.NeutralBlendShapes, nil
+}
+
+// reloadNeutral returns the neutral baseline for a calibration file path,
+// keeping the current baseline if the path did not change.
+// Must be called with t.mu held.
+func (t *Tracker) reloadNeutral(path string) (map[string]float64, error) {
+	if path == t.cfg.Tracking.CalibrationFile {
+		return t.neutral, nil
+	}
+	return loadNeutral(path)
+}
+
+// configureSmoothers retunes the landmark smoothers if the smoothing
+// algorithm or its parameters changed, keeping their state so the output
+// doesn't snap, and updates their visibility floors.
+// Must be called with t.mu held.
+func (t *Tracker) configureSmoothers(tracking config.TrackingConfig) {
+	current := t.cfg.Tracking
+	if tracking.SmoothingAlgorithm != current.SmoothingAlgorithm ||
+		tracking.SmoothingFactor != current.SmoothingFactor ||
+		tracking.OneEuro != current.OneEuro ||
+		tracking.DoubleExp != current.DoubleExp {
+		t.smoothers.setFilter(tracking)
+	}
+	t.smoothers.setVisibilityFloor(tracking.VisibilityFloor)
+}
+
+// applyVMCConfig retargets, enables or disables the VMC sender.
+// Custom senders set via SetVMCSender are left untouched.
+// Must be called with t.mu held.
+func (t *Tracker) applyVMCConfig(vmc config.VMCConfig) error {
+	if vmc == t.cfg.VMC {
+		return nil
+	}
+
+	sender, isVMC := t.vmcSender.(*VMCSender)
+	if t.vmcSender != nil && !isVMC {
+		return nil
+	}
+
+	switch {
+	case !vmc.Enabled:
+		if sender != nil {
+			sender.SetEnabled(false)
+		}
+	case sender == nil:
+		newSender, err := NewVMCSenderFromConfig(vmc)
+		if err != nil {
+			return err
+		}
+		configureVMCSender(newSender, t.cfg.Tracking, t.retargeter, t.restPose)
+		t.replaceVMCSender(newSender)
+	default:
+		if vmc.Network != t.cfg.VMC.Network {
+			if err := sender.SetNetwork(vmcNetwork(vmc.Network)); err != nil {
+				return fmt.Errorf("setting VMC network: %w", err)
+			}
+		}
+		if vmc.LocalAddress != t.cfg.VMC.LocalAddress {
+			if err := sender.SetLocalAddr(vmc.LocalAddress); err != nil {
+				return fmt.Errorf("binding VMC sender: %w", err)
+			}
+		}
+		if err := sender.SetTarget(vmc.Address, vmc.Port); err != nil {
+			return fmt.Errorf("updating VMC target: %w", err)
+		}
+		if err := sender.SetDeltaMode(vmc.DeltaEpsilon, vmc.KeyframeInterval); err != nil {
+			return fmt.Errorf("setting VMC delta mode: %w", err)
+		}
+		sender.SetAxisConvention(axisConventionFor(vmc.Axes))
+		sender.SetEnabled(true)
+	}
+	return nil
+}
+
+// vmcNetwork returns the VMCSender network for a VMCConfig.Network.
+func vmcNetwork(network string) string {
+	if network == "" {
+		return "udp"
+	}
+	return network
+}
+
+// NewVMCSenderFromConfig creates a VMC sender for the target, network,
+// local address, delta mode and axes of a VMC config section, as the
+// tracker does when a config reload enables VMC output. The tracking
+// settings are applied by Tracker.SetVMCSender.
+func NewVMCSenderFromConfig(vmc config.VMCConfig) (*VMCSender, error) {
+	sender, err := NewVMCSender(vmc.Address, vmc.Port)
+	if err != nil {
+		return nil, fmt.Errorf("creating VMC sender: %w", err)
+	}
+	if vmc.Network != "" {
+		if err := sender.SetNetwork(vmcNetwork(vmc.Network)); err != nil {
+			sender.Close()
+			return nil, fmt.Errorf("setting VMC network: %w", err)
+		}
+	}
+	if vmc.LocalAddress != "" {
+		if err := sender.SetLocalAddr(vmc.LocalAddress); err != nil {
+			sender.Close()
+			return nil, fmt.Errorf("binding VMC sender: %w", err)
+		}
+	}
+	if err := sender.SetDeltaMode(vmc.DeltaEpsilon, vmc.KeyframeInterval); err != nil {
+		sender.Close()
+		return nil, fmt.Errorf("setting VMC delta mode: %w", err)
+	}
+	sender.SetAxisConvention(axisConventionFor(vmc.Axes))
+	return sender, nil
+}
+
+// SetRestPose sets the pose the tracker emits once no tracking data has been
+// produced for longer than timeout, so the avatar returns to a sane idle
+// instead of freezing in its last pose. DefaultRestPose provides a neutral
+// pose, and LoadRestPose reads one from a file. Passing nil disables the
+// fallback, which is the default.
+// The VMC sender anchors the locked or untracked hips at the pose's hip
+// center, or DefaultRestPose's if pose is nil.
+// Can be called while running.
+func (t *Tracker) SetRestPose(pose *TrackingData, timeout time.Duration) {
+	pose = pose.Clone()
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.restPose = pose
+	t.restPoseTimeout = timeout
+	if vmc, ok := t.vmcSender.(*VMCSender); ok {
+		vmc.SetRestPose(pose)
+	}
+}
+
+// SetLogger sets the structured logger for tracker events such as processed
+// and dropped frames and send errors. Passing nil silences logging again,
+// which is the default. Can be called while running.
+func (t *Tracker) SetLogger(logger *slog.Logger) {
+	if logger == nil {
+		logger = slog.New(slog.DiscardHandler)
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.logger = logger
+}
+
+// SetStatsObserver sets a function called with the updated statistics after
+// every frame sent to outputs, for consumers such as metrics exporters that
+// need each frame's latency rather than periodic Stats snapshots. It runs on
+// the tracking loop, so it must be fast. Passing nil removes the observer.
+// Observers added with AddStatsObserver are kept.
+// Can be called while running.
+func (t *Tracker) SetStatsObserver(observer func(TrackerStats)) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.statsObserver = observer
+}
+
+// AddStatsObserver adds a function called with the updated statistics after
+// every frame sent to outputs, like SetStatsObserver, but alongside the
+// observer set there and any added before, so several consumers (e.g. the
+// metrics exporter and a log) can observe the same tracker. Observers run
+// in the order they were added, after the one set with SetStatsObserver.
+// Can be called while running.
+func (t *Tracker) AddStatsObserver(observer func(TrackerStats)) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	// Copy so a frame in flight keeps iterating its own snapshot
+	observers := make([]func(TrackerStats), len(t.statsObservers), len(t.statsObservers)+1)
+	copy(observers, t.statsObservers)
+	t.statsObservers = append(observers, observer)
+}
+
 // State returns the current tracker state.
 func (t *Tracker) State() TrackerState {
 	t.mu.RLock()
@@ -230,6 +879,20 @@ func (t *Tracker) State() TrackerState {
 	return t.state
 }
 
+// hasCamera reports whether a camera source is set.
+func (t *Tracker) hasCamera() bool {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	return t.camera != nil
+}
+
+// now returns the current time on the tracker's clock.
+func (t *Tracker) now() time.Time {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	return t.clock.Now()
+}
+
 // SetCameraSource sets a custom camera source.
 // Must be called before Start().
 func (t *Tracker) SetCameraSource(camera CameraSource) error {
@@ -243,7 +906,39 @@ func (t *Tracker) SetCameraSource(camera CameraSource) error {
 	return nil
 }
 
+// SwapCameraSource replaces the camera source, including while running.
+// Capture pauses between frames for the swap, the old source is closed,
+// and the landmark smoothers are reset because the new camera's coordinate
+// frame differs. Senders and subscribers are not affected.
+// The new source must already be open.
+func (t *Tracker) SwapCameraSource(camera CameraSource) error {
+	t.frameMu.Lock()
+	defer t.frameMu.Unlock()
+
+	t.mu.Lock()
+	if t.state == StateClosed {
+		t.mu.Unlock()
+		return ErrTrackerClosed
+	}
+	old := t.camera
+	t.camera = camera
+	t.smoothers.reset()
+	logger := t.logger
+	t.mu.Unlock()
+
+	logger.Info("camera source swapped")
+
+	if old != nil && old != camera {
+		if err := old.Close(); err != nil {
+			return fmt.Errorf("closing previous camera: %w", err)
+		}
+	}
+	return nil
+}
+
 // SetProcessor sets a custom landmark processor.
+// Without a processor the tracker produces no tracking data; use
+// StubProcessor to generate synthetic data for testing.
 // Must be called before Start().
 func (t *Tracker) SetProcessor(processor Processor) error {
 	t.mu.Lock()
@@ -256,7 +951,48 @@ func (t *Tracker) SetProcessor(processor Processor) error {
 	return nil
 }
 
-// SetVMCSender sets the VMC protocol sender.
+// SetFramePreprocessor sets a function applied to every captured frame
+// before it is downscaled and processed. The preview still shows the
+// original frame. Passing nil removes it, which is the default.
+// Can be called while running.
+func (t *Tracker) SetFramePreprocessor(fn FramePreprocessor) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.preprocess = fn
+}
+
+// SetDataTransform sets a function applied to every frame's tracking data
+// after smoothing and blend shape calibration, before it reaches senders
+// and subscribers. Frames it drops count as untracked, so the rest pose
+// still takes over. Passing nil removes it, which is the default.
+// Can be called while running.
+func (t *Tracker) SetDataTransform(fn DataTransform) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.transform = fn
+}
+
+// SetClock sets the clock used for frame pacing and timestamps. It
+// defaults to the system clock; tests can pass a FakeClock to step the
+// tracker frame by frame.
+// Must be called before Start().
+func (t *Tracker) SetClock(clock Clock) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.state != StateIdle {
+		return fmt.Errorf("cannot set clock: tracker is %s", t.state)
+	}
+	t.clock = clock
+	return nil
+}
+
+// SetVMCSender sets the VMC protocol sender. It is registered like
+// AddSender, replacing the previous VMC sender, and is the sender the VMC
+// config section and Drain apply to.
+// A *VMCSender is configured with the tracking MinHandConfidence and
+// LockLowerBody, the VMC Axes, the retargeter set with SetRetargeter and
+// the rest pose set with SetRestPose.
 // Must be called before Start().
 func (t *Tracker) SetVMCSender(sender Sender) error {
 	t.mu.Lock()
@@ -265,10 +1001,108 @@ func (t *Tracker) SetVMCSender(sender Sender) error {
 	if t.state != StateIdle {
 		return fmt.Errorf("cannot set VMC sender: tracker is %s", t.state)
 	}
+	if vmc, ok := sender.(*VMCSender); ok {
+		configureVMCSender(vmc, t.cfg.Tracking, t.retargeter, t.restPose)
+		vmc.SetAxisConvention(axisConventionFor(t.cfg.VMC.Axes))
+	}
+	t.replaceVMCSender(sender)
+	return nil
+}
+
+// replaceVMCSender swaps the VMC sender in the registered senders, or
+// unregisters it if sender is nil. The old sender is not closed.
+// Must be called with t.mu held.
+func (t *Tracker) replaceVMCSender(sender Sender) {
+	senders := make([]*registeredSender, 0, len(t.senders)+1)
+	replaced := false
+	for _, s := range t.senders {
+		switch {
+		case t.vmcSender == nil || s.Sender != t.vmcSender:
+			senders = append(senders, s)
+		case sender != nil:
+			// Carry the statistics and decimation over, so a config
+			// reload that recreates the VMC sender keeps them
+			r := &registeredSender{Sender: sender, name: s.name}
+			r.errors.Store(s.errors.Load())
+			r.everyN.Store(s.everyN.Load())
+			senders = append(senders, r)
+			replaced = true
+		}
+	}
+	if sender != nil && !replaced {
+		senders = append(senders, &registeredSender{Sender: sender, name: "vmc"})
+	}
+	t.senders = senders
 	t.vmcSender = sender
+}
+
+// AddSender registers a sender that receives every frame, such as a
+// JSONSender or another VMCSender for a second target. Senders receive
+// frames in the order they were added. Send errors are reported on Errors,
+// and registered senders are closed by Close.
+// Can be called while running.
+func (t *Tracker) AddSender(sender Sender) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.state == StateClosed {
+		return ErrTrackerClosed
+	}
+	// Copy so a frame in flight keeps iterating its own snapshot
+	senders := make([]*registeredSender, len(t.senders), len(t.senders)+1)
+	copy(senders, t.senders)
+	t.addedCount++
+	name := fmt.Sprintf("sender%d", t.addedCount)
+	t.senders = append(senders, &registeredSender{Sender: sender, name: name})
 	return nil
 }
 
+// RemoveSender unregisters a sender added with AddSender or SetVMCSender,
+// compared with ==. The sender is not closed; that is up to the caller.
+// Can be called while running.
+func (t *Tracker) RemoveSender(sender Sender) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	for i, s := range t.senders {
+		if s.Sender != sender {
+			continue
+		}
+		senders := make([]*registeredSender, 0, len(t.senders)-1)
+		senders = append(senders, t.senders[:i]...)
+		t.senders = append(senders, t.senders[i+1:]...)
+		if sender == t.vmcSender {
+			t.vmcSender = nil
+		}
+		return nil
+	}
+	return errors.New("removing sender: sender is not registered")
+}
+
+// SetSendEveryN makes a registered sender receive only every nth output
+// frame, those whose FrameNumber is a multiple of n, e.g. for a receiver on
+// a bandwidth-limited link. Other senders and subscribers still receive
+// every frame. Unlike MaxOutputFPS, which limits all output by time, this
+// counts frames. n = 1 sends every frame again. The setting of the VMC
+// sender carries over when SetVMCSender or a config reload replaces it.
+// Can be called while running.
+func (t *Tracker) SetSendEveryN(sender Sender, n int) error {
+	if n < 1 {
+		return fmt.Errorf("send every n must be at least 1, got %d", n)
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	for _, s := range t.senders {
+		if s.Sender == sender {
+			s.everyN.Store(int64(n))
+			return nil
+		}
+	}
+	return errors.New("setting send every n: sender is not registered")
+}
+
 // SetPreviewWindow sets the preview window for debug visualization.
 // Must be called before Start().
 func (t *Tracker) SetPreviewWindow(preview *PreviewWindow) error {
@@ -282,7 +1116,8 @@ func (t *Tracker) SetPreviewWindow(preview *PreviewWindow) error {
 	return nil
 }
 
-// Subscribe returns a channel that receives tracking data.
+// Subscribe returns a channel that receives tracking data. Each subscriber
+// receives its own copy of every frame, which it may modify.
 // The caller must drain the channel or risk blocking the tracker.
 // Close the tracker to close all subscriber channels.
 func (t *Tracker) Subscribe() <-chan *TrackingData {
@@ -294,12 +1129,21 @@ func (t *Tracker) Subscribe() <-chan *TrackingData {
 	return ch
 }
 
+// Errors returns a channel of per-frame sender errors. A failed send does
+// not stop the tracking loop; the error is reported here (and logged) instead.
+// Errors are dropped while the channel is full, so consumers that care should
+// drain it continuously. The channel is closed by Close.
+func (t *Tracker) Errors() <-chan error {
+	return t.errCh
+}
+
 // Start begins the tracking loop.
 // Returns immediately; tracking runs in background goroutines.
 func (t *Tracker) Start() error {
 	t.mu.Lock()
 	defer t.mu.Unlock()
 
+	t.waitDrain()
 	switch t.state {
 	case StateRunning:
 		return ErrTrackerRunning
@@ -308,12 +1152,25 @@ func (t *Tracker) Start() error {
 	}
 
 	t.ctx, t.cancel = context.WithCancel(context.Background())
+	t.drain = make(chan struct{})
 	t.state = StateRunning
 	t.frameCount = 0
+	t.latest = nil
+	t.recent = newFrameRing(len(t.recent.frames))
+	t.stats = TrackerStats{}
+	for _, sender := range t.senders {
+		sender.errors.Store(0)
+	}
+	t.lastSeen = time.Time{}
+	t.nextOutput = time.Time{}
+	t.lastSmooth = time.Time{}
 
+	interval := time.Second / time.Duration(t.cfg.Camera.FPS)
+	ticker := t.clock.NewTicker(interval)
 	t.wg.Add(1)
-	go t.trackingLoop()
+	go t.trackingLoop(ticker)
 
+	t.logger.Info("tracker started", "interval", interval)
 	return nil
 }
 
@@ -328,12 +1185,113 @@ func (t *Tracker) Stop() error {
 
 	t.cancel()
 	t.state = StateStopped
+	logger := t.logger
 	t.mu.Unlock()
 
 	t.wg.Wait()
+	logger.Info("tracker stopped")
 	return nil
 }
 
+// Drain stops the tracking loop gracefully: the frame in flight, if any,
+// is processed and sent rather than cancelled, and a final rest pose frame
+// is then sent to every registered sender, including those decimated with
+// SetSendEveryN, so each receiver is left in a clean state.
+// The rest pose is the one set with SetRestPose, or DefaultRestPose.
+//
+// If draining takes longer than timeout, for example because the sender
+// is stuck, the in-flight frame is cancelled, the final frame is skipped
+// and an error is returned. Either way the tracker ends up stopped; call
+// Close afterwards to release resources. Start and Close wait for a
+// timed-out drain to finish a send it already began.
+func (t *Tracker) Drain(timeout time.Duration) error {
+	t.mu.Lock()
+	if t.state != StateRunning {
+		t.mu.Unlock()
+		return ErrTrackerStopped
+	}
+
+	close(t.drain)
+	t.state = StateStopped
+	cancel := t.cancel
+	logger := t.logger
+	done := make(chan struct{})
+	t.drained = done
+	t.drainTimedOut = false
+	t.mu.Unlock()
+	defer cancel()
+
+	go func() {
+		defer close(done)
+		t.wg.Wait()
+		t.sendFinalFrame()
+	}()
+
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+	select {
+	case <-done:
+		logger.Info("tracker drained")
+		return nil
+	case <-timer.C:
+		t.mu.Lock()
+		t.drainTimedOut = true
+		t.mu.Unlock()
+		logger.Warn("drain timed out", "timeout", timeout)
+		return fmt.Errorf("draining tracker: timed out after %v", timeout)
+	}
+}
+
+// waitDrain waits for the goroutine of a Drain that timed out, which may
+// still be in the middle of the final frame. Must be called with t.mu held;
+// it is released while waiting.
+func (t *Tracker) waitDrain() {
+	for t.drained != nil {
+		drained := t.drained
+		t.mu.Unlock()
+		<-drained
+		t.mu.Lock()
+		if t.drained == drained {
+			t.drained = nil
+		}
+	}
+}
+
+// sendFinalFrame sends the rest pose to every registered sender as the
+// last frame, unless the drain has already timed out.
+func (t *Tracker) sendFinalFrame() {
+	t.frameMu.Lock()
+	defer t.frameMu.Unlock()
+
+	t.mu.RLock()
+	senders := t.senders
+	restPose := t.restPose
+	logger := t.logger
+	clock := t.clock
+	timedOut := t.drainTimedOut
+	t.mu.RUnlock()
+
+	if len(senders) == 0 || timedOut {
+		return
+	}
+
+	data := restPose.Clone()
+	if data == nil {
+		data = DefaultRestPose()
+	}
+	t.frameCount++
+	data.FrameNumber = t.frameCount
+	data.Timestamp = clock.Now()
+
+	// Only logged: the tracker is already stopped, so nothing may be
+	// reading the error channel
+	for _, sender := range senders {
+		if err := sender.Send(data); err != nil {
+			logger.Warn("sending final frame failed", "sender", sender.name, "error", err)
+		}
+	}
+}
+
 // Close stops tracking and releases all resources.
 func (t *Tracker) Close() error {
 	t.mu.Lock()
@@ -353,6 +1311,7 @@ func (t *Tracker) Close() error {
 	var errs []error
 
 	t.mu.Lock()
+	t.waitDrain()
 	if t.camera != nil {
 		if err := t.camera.Close(); err != nil {
 			errs = append(errs, fmt.Errorf("closing camera: %w", err))
@@ -363,9 +1322,13 @@ func (t *Tracker) Close() error {
 			errs = append(errs, fmt.Errorf("closing processor: %w", err))
 		}
 	}
-	if t.vmcSender != nil {
-		if err := t.vmcSender.Close(); err != nil {
-			errs = append(errs, fmt.Errorf("closing VMC sender: %w", err))
+	for _, sender := range t.senders {
+		if err := sender.Close(); err != nil {
+			if sender.Sender == t.vmcSender {
+				errs = append(errs, fmt.Errorf("closing VMC sender: %w", err))
+			} else {
+				errs = append(errs, fmt.Errorf("closing sender: %w", err))
+			}
 		}
 	}
 	if t.preview != nil {
@@ -379,26 +1342,28 @@ func (t *Tracker) Close() error {
 		close(ch)
 	}
 	t.subscribers = nil
+	close(t.errCh)
 	t.mu.Unlock()
 
 	if len(errs) > 0 {
-		return fmt.Errorf("closing tracker: %v", errs)
+		return fmt.Errorf("closing tracker: %w", errors.Join(errs...))
 	}
 	return nil
 }
 
-// trackingLoop is the main capture and processing loop.
-func (t *Tracker) trackingLoop() {
+// trackingLoop is the main capture and processing loop. It processes a
+// frame on each tick and stops the ticker when it returns.
+func (t *Tracker) trackingLoop(ticker Ticker) {
 	defer t.wg.Done()
-
-	ticker := time.NewTicker(time.Second / time.Duration(t.cfg.Camera.FPS))
 	defer ticker.Stop()
 
 	for {
 		select {
 		case <-t.ctx.Done():
 			return
-		case <-ticker.C:
+		case <-t.drain:
+			return
+		case <-ticker.C():
 			t.processFrame()
 		}
 	}
@@ -406,77 +1371,802 @@ func (t *Tracker) trackingLoop() {
 
 // processFrame captures and processes a single frame.
 func (t *Tracker) processFrame() {
+	t.frameMu.Lock()
+	defer t.frameMu.Unlock()
+
 	t.mu.RLock()
 	camera := t.camera
 	processor := t.processor
-	vmcSender := t.vmcSender
+	preprocess := t.preprocess
+	transform := t.transform
+	senders := t.senders
 	preview := t.preview
 	subscribers := t.subscribers
+	tracking := t.cfg.Tracking
+	smoothers := t.smoothers
+	shaper := t.shaper
+	neutral := t.neutral
+	calibration := t.calibration
+	logger := t.logger
+	statsObserver := t.statsObserver
+	statsObservers := t.statsObservers
+	restPose := t.restPose
+	restPoseTimeout := t.restPoseTimeout
+	clock := t.clock
 	t.mu.RUnlock()
 
-	// Generate mock data if no camera/processor configured
-	var data *TrackingData
-	if camera != nil && processor != nil {
-		frame, width, height, err := camera.Read()
+	start := clock.Now()
+	if t.lastSeen.IsZero() {
+		t.lastSeen = start
+	}
+
+	// Capture one frame, shared by the processor and the preview
+	var frame []byte
+	var width, height int
+	mat := gocv.NewMat()
+	defer mat.Close()
+	if camera != nil && (processor != nil || preview != nil) {
+		var err error
+		maxAge := time.Duration(tracking.MaxFrameAgeMS) * time.Millisecond
+		frame, width, height, err = t.captureFrame(camera, preview != nil, &mat, maxAge, clock, logger)
 		if err != nil {
-			// Silent return - errors are expected during shutdown
+			// Debug only - errors are expected during shutdown
+			logger.Debug("camera read failed", "error", err)
 			return
 		}
+	}
 
-		data, err = processor.Process(t.ctx, frame, width, height)
+	// Without a processor there is no tracking data; only the preview runs.
+	// Use StubProcessor to generate synthetic data without MediaPipe.
+	var data *TrackingData
+	if processor != nil {
+		var err error
+		if preprocess != nil {
+			frame, width, height, err = preprocess(frame, width, height)
+			if err != nil {
+				logger.Warn("frame preprocessing failed", "error", err)
+				return
+			}
+		}
+		if tracking.Downscale {
+			frame, width, height, err = t.downscaleFrame(frame, width, height, tracking.DownscaleMaxDimension)
+			if err != nil {
+				logger.Warn("downscaling failed", "error", err)
+				return
+			}
+		}
+		ctx := t.ctx
+		if ctx == nil { // Not started
+			ctx = context.Background()
+		}
+		if tracking.ProcessTimeoutMS > 0 {
+			var cancel context.CancelFunc
+			ctx, cancel = context.WithTimeout(ctx, time.Duration(tracking.ProcessTimeoutMS)*time.Millisecond)
+			defer cancel()
+		}
+		data, err = processor.Process(ctx, frame, width, height)
 		if err != nil {
+			logger.Warn("processing failed", "error", err)
 			return
 		}
-	} else if camera != nil {
-		// Camera only mode (for preview without processor)
-		// Just read for preview, generate stub data
-		data = &TrackingData{
-			Timestamp:   time.Now(),
-			FrameNumber: t.frameCount,
+	}
+
+	// Show preview with the detected landmarks and the stats up to the
+	// previous frame if enabled; the overlay is drawn on a clone, before
+	// mirroring and smoothing change the data
+	if preview != nil && !mat.Empty() {
+		preview.ShowWithHUD(mat, data, t.Stats())
+	}
+
+	if data != nil {
+		// Work on a copy from here on: processors may keep the data they
+		// return, and mirroring and smoothing modify it in place
+		data = data.Clone()
+		// Smooth over the real time since the last frame, which drifts
+		// from the nominal interval when frames are slow or dropped
+		var dt float64
+		if !t.lastSmooth.IsZero() {
+			dt = start.Sub(t.lastSmooth).Seconds()
+		}
+		t.lastSmooth = start
+		applyTracking(data, tracking, smoothers, dt)
+		// The head yaw follows the image; when the image the landmarks
+		// describe is mirrored, turn it back so the avatar mirrors the user
+		if isMirrored(camera) != tracking.MirrorLandmarks {
+			MirrorHeadYaw(data.Face)
 		}
-	} else {
-		// Generate stub tracking data for testing
-		data = &TrackingData{
-			Timestamp:   time.Now(),
-			FrameNumber: t.frameCount,
+		t.applyModalityRest(data, restPose)
+	}
+	if calibration != nil {
+		neutral = t.updateCalibration(calibration, data, start, neutral)
+	}
+	if data != nil && data.Face != nil {
+		if neutral != nil {
+			SubtractNeutral(data.Face.BlendShapes, neutral)
 		}
+		if shaper != nil {
+			shaper.Apply(data.Face.BlendShapes)
+		}
+	}
+	if data != nil && transform != nil {
+		data = transform(data)
 	}
 
-	// Show preview if enabled (do this before processing to reduce latency)
-	if preview != nil && camera != nil {
-		t.showPreview(camera, preview)
+	var resting bool
+	switch {
+	case hasTracking(data):
+		t.lastSeen = start
+	case restPose != nil && start.Sub(t.lastSeen) >= restPoseTimeout:
+		logger.Debug("no tracking data, sending rest pose")
+		data = restPose.Clone()
+		resting = true
+	case data == nil:
+		return
+	}
+
+	if !t.allowOutput(start, tracking.MaxOutputFPS) {
+		logger.Debug("frame throttled", "max_output_fps", tracking.MaxOutputFPS)
+		return
 	}
 
 	t.frameCount++
 	data.FrameNumber = t.frameCount
-	data.Timestamp = time.Now()
+	data.Timestamp = clock.Now()
+
+	latest := data.Clone()
+	t.mu.Lock()
+	t.latest = latest
+	t.recent.push(latest)
+	t.mu.Unlock()
 
-	// Send to VMC sender
-	if vmcSender != nil {
-		_ = vmcSender.Send(data)
+	// Send to every registered sender
+	var sendFailed bool
+	for _, sender := range senders {
+		if sender.skips(data.FrameNumber) {
+			continue
+		}
+		if err := sender.Send(data); err != nil {
+			sendFailed = true
+			sender.errors.Add(1)
+			logger.Warn("send failed", "sender", sender.name, "frame", data.FrameNumber, "error", err)
+			select {
+			case t.errCh <- fmt.Errorf("sending frame %d to %s: %w", data.FrameNumber, sender.name, err):
+			default:
+			}
+		}
 	}
 
 	// Broadcast to subscribers (already captured above)
-	for _, ch := range subscribers {
+	var dropped uint64
+	for i, ch := range subscribers {
+		// Each subscriber gets its own copy, so none can change the
+		// data seen by the senders or the others
 		select {
-		case ch <- data:
+		case ch <- data.Clone():
 		default:
 			// Drop frame if subscriber is slow
+			dropped++
+			logger.Debug("frame dropped", "frame", data.FrameNumber, "subscriber", i)
+		}
+	}
+
+	end := clock.Now()
+	stats := t.updateStats(data, resting, start, end, sendFailed, dropped)
+	if statsObserver != nil {
+		statsObserver(stats)
+	}
+	for _, observer := range statsObservers {
+		observer(stats)
+	}
+	logger.Debug("frame processed", "frame", data.FrameNumber, "latency", end.Sub(start))
+}
+
+// applyModalityRest fills in the modalities disabled since the last frame
+// from the rest pose, or DefaultRestPose if rest is nil, once.
+func (t *Tracker) applyModalityRest(data *TrackingData, rest *TrackingData) {
+	t.mu.Lock()
+	modalities := t.restModalities
+	t.restModalities = 0
+	t.mu.Unlock()
+
+	if modalities == 0 {
+		return
+	}
+	if rest == nil {
+		rest = DefaultRestPose()
+	}
+	rest = rest.Clone()
+	if modalities&ModalityFace != 0 {
+		data.Face = rest.Face
+	}
+	if modalities&ModalityHands != 0 {
+		data.LeftHand, data.RightHand = rest.LeftHand, rest.RightHand
+	}
+	if modalities&ModalityPose != 0 {
+		data.Pose = rest.Pose
+	}
+}
+
+// updateCalibration adds a frame to an in-progress neutral calibration, or
+// ends the calibration once it is due and installs the captured baseline.
+// It returns the baseline to apply to the frame.
+// Must be called with t.frameMu held.
+func (t *Tracker) updateCalibration(capture *neutralCapture, data *TrackingData, now time.Time, neutral map[string]float64) map[string]float64 {
+	if now.Before(capture.until) {
+		if data != nil && data.Face != nil {
+			capture.add(data.Face.BlendShapes)
+		}
+		return neutral
+	}
+
+	baseline := capture.baseline()
+	t.mu.Lock()
+	if baseline != nil {
+		t.neutral = baseline
+		neutral = baseline
+	}
+	if t.calibration == capture {
+		t.calibration = nil
+	}
+	t.mu.Unlock()
+
+	close(capture.done)
+	return neutral
+}
+
+// outputSlackDivisor sets how early, as a fraction of the output interval,
+// a frame may arrive and still be output under MaxOutputFPS. Without slack,
+// frames arriving a hair before the deadline would be dropped and the
+// achieved rate would fall well below the cap.
+const outputSlackDivisor = 10
+
+// allowOutput reports whether a frame captured at now may be output under a
+// cap of maxFPS frames per second (0 = unlimited). Output deadlines advance
+// by one interval per allowed frame, so the long-run rate never exceeds the
+// cap. Must be called with t.frameMu held.
+func (t *Tracker) allowOutput(now time.Time, maxFPS int) bool {
+	if maxFPS <= 0 {
+		t.nextOutput = time.Time{}
+		return true
+	}
+
+	interval := time.Second / time.Duration(maxFPS)
+	if !t.nextOutput.IsZero() && now.Before(t.nextOutput.Add(-interval/outputSlackDivisor)) {
+		return false
+	}
+	// Resynchronize after the first frame or a gap, instead of letting a
+	// backlog of missed deadlines through in a burst
+	if t.nextOutput.IsZero() || now.Sub(t.nextOutput) > interval {
+		t.nextOutput = now
+	}
+	t.nextOutput = t.nextOutput.Add(interval)
+	return true
+}
+
+// updateStats records a frame sent to outputs in the tracker statistics.
+// A resting frame is the rest pose, so no modality counts as detected.
+// It returns the updated snapshot.
+func (t *Tracker) updateStats(data *TrackingData, resting bool, start, end time.Time, sendFailed bool, dropped uint64) TrackerStats {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	stats := &t.stats
+	if !stats.LastFrame.IsZero() {
+		if interval := end.Sub(stats.LastFrame).Seconds(); interval > 0 {
+			if stats.FPS == 0 {
+				stats.FPS = 1 / interval
+			} else {
+				stats.FPS += fpsSmoothing * (1/interval - stats.FPS)
+			}
+		}
+	}
+	stats.FrameNumber = data.FrameNumber
+	stats.Latency = end.Sub(start)
+	stats.LastFrame = end
+	if sendFailed {
+		stats.SendErrors++
+	}
+	stats.DroppedFrames += dropped
+	stats.FaceDetected = !resting && data.Face != nil
+	stats.LeftHandDetected = !resting && data.LeftHand != nil
+	stats.RightHandDetected = !resting && data.RightHand != nil
+	stats.PoseDetected = !resting && data.Pose != nil
+	return t.statsSnapshot()
+}
+
+// statsSnapshot returns a copy of the statistics with the current
+// per-sender statistics.
+// Must be called with t.mu held.
+func (t *Tracker) statsSnapshot() TrackerStats {
+	stats := t.stats
+	stats.Senders = make([]SenderStats, len(t.senders))
+	for i, s := range t.senders {
+		stats.Senders[i] = SenderStats{Name: s.name, SendErrors: s.errors.Load()}
+	}
+	return stats
+}
+
+// CalibrateNeutral captures the user's neutral face. It averages the face
+// blend shapes over duration while the user holds a relaxed expression, then
+// subtracts that baseline from every following frame, so a resting face no
+// longer sends small non-zero weights. The baseline is saved to the
+// tracking CalibrationFile, if one is set.
+//
+// CalibrateNeutral blocks until the capture ends. The tracker must be
+// running, and frames keep being sent with the previous baseline meanwhile.
+func (t *Tracker) CalibrateNeutral(duration time.Duration) error {
+	if duration <= 0 {
+		return fmt.Errorf("calibration duration must be positive, got %v", duration)
+	}
+
+	t.mu.Lock()
+	if t.state != StateRunning {
+		t.mu.Unlock()
+		return ErrTrackerStopped
+	}
+	if t.calibration != nil {
+		t.mu.Unlock()
+		return errors.New("neutral calibration already in progress")
+	}
+	capture := newNeutralCapture(t.clock.Now().Add(duration))
+	t.calibration = capture
+	ctx := t.ctx
+	t.mu.Unlock()
+
+	select {
+	case <-capture.done:
+	case <-ctx.Done():
+		t.mu.Lock()
+		if t.calibration == capture {
+			t.calibration = nil
+		}
+		t.mu.Unlock()
+		return ErrTrackerStopped
+	}
+
+	neutral := capture.baseline()
+	if neutral == nil {
+		return errors.New("no face detected during neutral calibration")
+	}
+
+	t.mu.RLock()
+	path := t.cfg.Tracking.CalibrationFile
+	logger := t.logger
+	t.mu.RUnlock()
+
+	logger.Info("neutral calibration captured", "blend_shapes", len(neutral))
+	return t.updateCalibrationFile(path, func(c *Calibration) {
+		c.NeutralBlendShapes = neutral
+	})
+}
+
+// SaveBodyCalibration saves body, e.g. as measured by a TPoseDetector, to
+// the tracking CalibrationFile, keeping the rest of the file. It does
+// nothing if no CalibrationFile is set.
+func (t *Tracker) SaveBodyCalibration(body BodyCalibration) error {
+	t.mu.RLock()
+	path := t.cfg.Tracking.CalibrationFile
+	t.mu.RUnlock()
+
+	return t.updateCalibrationFile(path, func(c *Calibration) {
+		c.Body = &body
+	})
+}
+
+// updateCalibrationFile applies update to the calibration file at path,
+// if path is set.
+func (t *Tracker) updateCalibrationFile(path string, update func(c *Calibration)) error {
+	if path == "" {
+		return nil
+	}
+
+	t.calibrationFileMu.Lock()
+	defer t.calibrationFileMu.Unlock()
+	if err := updateCalibrationFile(path, update); err != nil {
+		return fmt.Errorf("saving calibration: %w", err)
+	}
+	return nil
+}
+
+// NeutralBaseline returns a copy of the neutral blend shape baseline, or
+// nil if the tracker has not been calibrated.
+func (t *Tracker) NeutralBaseline() map[string]float64 {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+
+	if t.neutral == nil {
+		return nil
+	}
+	neutral := make(map[string]float64, len(t.neutral))
+	for name, value := range t.neutral {
+		neutral[name] = value
+	}
+	return neutral
+}
+
+// Stats returns a snapshot of the tracker's runtime statistics.
+// Counters are reset by Start.
+func (t *Tracker) Stats() TrackerStats {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	return t.statsSnapshot()
+}
+
+// LatestData returns a copy of the most recently processed tracking data,
+// or nil if no frame has been processed since Start. It is meant for
+// pull-based consumers, such as a render loop, that don't want a channel.
+func (t *Tracker) LatestData() *TrackingData {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	return t.latest.Clone()
+}
+
+// SetRecentFramesCapacity sets how many of the most recent frames the
+// tracker retains for RecentFrames, for instant replay and debugging.
+// Memory is bounded by the capacity; 0, the default, retains none.
+// Frames already retained are kept up to the new capacity.
+// Can be called while running.
+func (t *Tracker) SetRecentFramesCapacity(capacity int) error {
+	if capacity < 0 {
+		return fmt.Errorf("recent frames capacity must not be negative, got %d", capacity)
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.recent = t.recent.resize(capacity)
+	return nil
+}
+
+// RecentFrames returns up to the last SetRecentFramesCapacity frames sent
+// to outputs since Start, oldest first. Each call returns new copies, like
+// LatestData, so callers may modify them.
+func (t *Tracker) RecentFrames() []*TrackingData {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+
+	frames := t.recent.snapshot()
+	for i, data := range frames {
+		frames[i] = data.Clone()
+	}
+	return frames
+}
+
+// Clone returns a deep copy of the tracking data, which shares no landmark
+// slices or blend shape maps with it, so either can be changed without
+// affecting the other. Cloning nil returns nil.
+func (d *TrackingData) Clone() *TrackingData {
+	if d == nil {
+		return nil
+	}
+
+	c := *d
+	if d.Face != nil {
+		face := *d.Face
+		face.Landmarks = copyLandmarks(d.Face.Landmarks)
+		if d.Face.BlendShapes != nil {
+			face.BlendShapes = make(map[string]float64, len(d.Face.BlendShapes))
+			for name, value := range d.Face.BlendShapes {
+				face.BlendShapes[name] = value
+			}
+		}
+		c.Face = &face
+	}
+	if d.LeftHand != nil {
+		hand := *d.LeftHand
+		hand.Landmarks = copyLandmarks(d.LeftHand.Landmarks)
+		c.LeftHand = &hand
+	}
+	if d.RightHand != nil {
+		hand := *d.RightHand
+		hand.Landmarks = copyLandmarks(d.RightHand.Landmarks)
+		c.RightHand = &hand
+	}
+	if d.Pose != nil {
+		pose := *d.Pose
+		pose.Landmarks = copyLandmarks(d.Pose.Landmarks)
+		c.Pose = &pose
+	}
+	return &c
+}
+
+// EqualTolerance is the largest difference between two floating point
+// values that TrackingData.Equal treats as equal.
+const EqualTolerance = 1e-6
+
+// Equal reports whether d and other hold the same frame: the same
+// timestamp, frame number and detected modalities, with every landmark,
+// score, head transform and blend shape within EqualTolerance. Two nil
+// frames are equal; a nil frame equals no other.
+func (d *TrackingData) Equal(other *TrackingData) bool {
+	if d == nil || other == nil {
+		return d == other
+	}
+	if !d.Timestamp.Equal(other.Timestamp) || d.FrameNumber != other.FrameNumber {
+		return false
+	}
+	return d.Face.equal(other.Face) &&
+		d.LeftHand.equal(other.LeftHand) &&
+		d.RightHand.equal(other.RightHand) &&
+		d.Pose.equal(other.Pose)
+}
+
+// equal compares face data for TrackingData.Equal.
+func (f *FaceData) equal(other *FaceData) bool {
+	if f == nil || other == nil {
+		return f == other
+	}
+	if !landmarksEqual(f.Landmarks, other.Landmarks) ||
+		!pointsEqual(f.HeadPosition, other.HeadPosition) ||
+		!quaternionsEqual(f.HeadRotation, other.HeadRotation) ||
+		len(f.BlendShapes) != len(other.BlendShapes) {
+		return false
+	}
+	for name, value := range f.BlendShapes {
+		otherValue, ok := other.BlendShapes[name]
+		if !ok || !floatsEqual(value, otherValue) {
+			return false
+		}
+	}
+	return true
+}
+
+// equal compares hand data for TrackingData.Equal.
+func (h *HandData) equal(other *HandData) bool {
+	if h == nil || other == nil {
+		return h == other
+	}
+	return h.IsLeft == other.IsLeft &&
+		floatsEqual(h.Confidence, other.Confidence) &&
+		floatsEqual(h.Depth, other.Depth) &&
+		landmarksEqual(h.Landmarks, other.Landmarks)
+}
+
+// equal compares pose data for TrackingData.Equal.
+func (p *PoseData) equal(other *PoseData) bool {
+	if p == nil || other == nil {
+		return p == other
+	}
+	return landmarksEqual(p.Landmarks, other.Landmarks)
+}
+
+// landmarksEqual reports whether two landmark slices match within
+// EqualTolerance. A nil slice equals an empty one.
+func landmarksEqual(a, b []Landmark) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if !pointsEqual(a[i].Point, b[i].Point) ||
+			!floatsEqual(a[i].Visibility, b[i].Visibility) ||
+			!floatsEqual(a[i].Presence, b[i].Presence) {
+			return false
 		}
 	}
+	return true
 }
 
-// showPreview displays the current frame in the preview window.
-// This method is only compiled when CGO is enabled (same as PreviewWindow).
-func (t *Tracker) showPreview(camera CameraSource, preview *PreviewWindow) {
-	// Type assert to OpenCVCamera to access ReadMat
-	if ocvCam, ok := camera.(*OpenCVCamera); ok {
-		mat, err := ocvCam.ReadMat()
+// pointsEqual reports whether two points match within EqualTolerance.
+func pointsEqual(a, b Point3D) bool {
+	return floatsEqual(a.X, b.X) && floatsEqual(a.Y, b.Y) && floatsEqual(a.Z, b.Z)
+}
+
+// quaternionsEqual reports whether two quaternions match component-wise
+// within EqualTolerance.
+func quaternionsEqual(a, b Quaternion) bool {
+	return floatsEqual(a.X, b.X) && floatsEqual(a.Y, b.Y) && floatsEqual(a.Z, b.Z) && floatsEqual(a.W, b.W)
+}
+
+// floatsEqual reports whether a and b differ by at most EqualTolerance.
+func floatsEqual(a, b float64) bool {
+	return math.Abs(a-b) <= EqualTolerance
+}
+
+// copyLandmarks returns a copy of landmarks, preserving nil.
+func copyLandmarks(landmarks []Landmark) []Landmark {
+	if landmarks == nil {
+		return nil
+	}
+	return append([]Landmark(nil), landmarks...)
+}
+
+// applyTracking corrects the hand labels and mirrors the data if enabled,
+// drops disabled modalities and smooths the remaining landmarks, all in
+// place. dt is the time in seconds since the previous frame, or 0 if
+// unknown; see LandmarkSmoother.SmoothIntoDt.
+func applyTracking(data *TrackingData, tracking config.TrackingConfig, smoothers *trackerSmoothers, dt float64) {
+	if tracking.CorrectHandedness {
+		CorrectHandedness(data)
+	}
+	if tracking.MirrorLandmarks {
+		MirrorTrackingData(data)
+	}
+
+	if !tracking.EnableFace {
+		data.Face = nil
+	}
+	if !tracking.EnableHands {
+		data.LeftHand = nil
+		data.RightHand = nil
+	}
+	if !tracking.EnablePose {
+		data.Pose = nil
+	}
+
+	if smoothers == nil {
+		return
+	}
+	if data.Face != nil {
+		data.Face.Landmarks = smoothers.face.SmoothIntoDt(data.Face.Landmarks, data.Face.Landmarks, dt)
+		data.Face.BlendShapes = smoothers.blendShapes.SmoothDt(data.Face.BlendShapes, dt)
+	}
+	if data.LeftHand != nil {
+		data.LeftHand.Landmarks = smoothers.leftHand.SmoothIntoDt(data.LeftHand.Landmarks, data.LeftHand.Landmarks, dt)
+	}
+	if data.RightHand != nil {
+		data.RightHand.Landmarks = smoothers.rightHand.SmoothIntoDt(data.RightHand.Landmarks, data.RightHand.Landmarks, dt)
+	}
+	if data.Pose != nil {
+		data.Pose.Landmarks = smoothers.pose.SmoothIntoDt(data.Pose.Landmarks, data.Pose.Landmarks, dt)
+	}
+}
+
+// captureFrame reads a frame from camera as RGB24 bytes. If withMat is set
+// it also stores the frame as a BGR Mat in mat for the preview. Sources that
+// implement MatSource are read once with ReadMat and the bytes converted from
+// the Mat; other sources are read with readFresh and the Mat converted from
+// the bytes. Must be called with t.frameMu held.
+func (t *Tracker) captureFrame(camera CameraSource, withMat bool, mat *gocv.Mat, maxAge time.Duration, clock Clock, logger *slog.Logger) ([]byte, int, int, error) {
+	if matSource, ok := camera.(MatSource); ok && withMat {
+		m, err := readFreshMat(matSource, maxAge, clock, logger)
 		if err != nil {
-			return
+			return nil, 0, 0, err
 		}
-		// Don't defer close - Show() clones the mat
-		preview.Show(mat)
 		mat.Close()
+		*mat = m
+
+		rgb := gocv.NewMat()
+		defer rgb.Close()
+		if err := gocv.CvtColor(m, &rgb, gocv.ColorBGRToRGB); err != nil {
+			return nil, 0, 0, fmt.Errorf("converting frame to RGB: %w", err)
+		}
+		pixels, err := rgb.DataPtrUint8()
+		if err != nil {
+			return nil, 0, 0, fmt.Errorf("accessing frame data: %w", err)
+		}
+		if cap(t.frameBuf) < len(pixels) {
+			t.frameBuf = make([]byte, len(pixels))
+		}
+		t.frameBuf = t.frameBuf[:len(pixels)]
+		copy(t.frameBuf, pixels)
+		return t.frameBuf, m.Cols(), m.Rows(), nil
+	}
+
+	frame, width, height, err := readFresh(camera, maxAge, clock, logger)
+	if err != nil {
+		return nil, 0, 0, err
+	}
+	if withMat && len(frame) == width*height*3 {
+		rgb, err := gocv.NewMatFromBytes(height, width, gocv.MatTypeCV8UC3, frame)
+		if err != nil {
+			return nil, 0, 0, fmt.Errorf("wrapping frame: %w", err)
+		}
+		defer rgb.Close()
+		if err := gocv.CvtColor(rgb, mat, gocv.ColorRGBToBGR); err != nil {
+			return nil, 0, 0, fmt.Errorf("converting frame to BGR: %w", err)
+		}
+	}
+	return frame, width, height, nil
+}
+
+// maxStaleReads bounds how many stale frames skipStale discards per frame,
+// so a source whose timestamps are always old can't stall the loop.
+const maxStaleReads = 10
+
+// readFresh reads a frame from camera. If camera is a TimestampedSource and
+// maxAge is positive, stale frames are skipped; see skipStale.
+func readFresh(camera CameraSource, maxAge time.Duration, clock Clock, logger *slog.Logger) ([]byte, int, int, error) {
+	timestamped, ok := camera.(TimestampedSource)
+	if !ok || maxAge <= 0 {
+		return camera.Read()
+	}
+
+	var frame []byte
+	var width, height int
+	err := skipStale(maxAge, clock, logger, func() (time.Time, error) {
+		var capturedAt time.Time
+		var err error
+		frame, width, height, capturedAt, err = timestamped.ReadTimestamped()
+		return capturedAt, err
+	})
+	if err != nil {
+		return nil, 0, 0, err
+	}
+	return frame, width, height, nil
+}
+
+// readFreshMat is readFresh for the Mat path: if source is a
+// TimestampedMatSource and maxAge is positive, stale frames are skipped
+// and closed. The caller must close the returned Mat.
+func readFreshMat(source MatSource, maxAge time.Duration, clock Clock, logger *slog.Logger) (gocv.Mat, error) {
+	timestamped, ok := source.(TimestampedMatSource)
+	if !ok || maxAge <= 0 {
+		return source.ReadMat()
+	}
+
+	var mat gocv.Mat
+	read := false
+	err := skipStale(maxAge, clock, logger, func() (time.Time, error) {
+		if read {
+			mat.Close()
+		}
+		m, capturedAt, err := timestamped.ReadMatTimestamped()
+		mat, read = m, true
+		return capturedAt, err
+	})
+	return mat, err
+}
+
+// skipStale calls read until it returns a frame captured at most maxAge
+// ago, discarding up to maxStaleReads older frames, and returns the first
+// error from read.
+func skipStale(maxAge time.Duration, clock Clock, logger *slog.Logger, read func() (time.Time, error)) error {
+	for skipped := 0; ; skipped++ {
+		capturedAt, err := read()
+		if err != nil {
+			return err
+		}
+		age := clock.Now().Sub(capturedAt)
+		if age <= maxAge || skipped == maxStaleReads {
+			if skipped > 0 {
+				logger.Debug("stale frames skipped", "count", skipped, "age", age)
+			}
+			return nil
+		}
+	}
+}
+
+// downscaleSize returns the size of a width×height frame shrunk so its long
+// edge is at most maxDim, keeping the aspect ratio. Frames already small
+// enough keep their size.
+func downscaleSize(width, height, maxDim int) (int, int) {
+	long := max(width, height)
+	if maxDim <= 0 || long <= maxDim {
+		return width, height
+	}
+	scale := float64(maxDim) / float64(long)
+	return max(int(math.Round(float64(width)*scale)), 1), max(int(math.Round(float64(height)*scale)), 1)
+}
+
+// downscaleFrame shrinks an RGB24 frame with downscaleSize before landmark
+// detection. Landmarks come back normalized, so they apply to the
+// full-size frame unchanged. The returned slice is reused by the next call.
+// Must be called with t.frameMu held.
+func (t *Tracker) downscaleFrame(frame []byte, width, height, maxDim int) ([]byte, int, int, error) {
+	w, h := downscaleSize(width, height, maxDim)
+	if w == width && h == height {
+		return frame, width, height, nil
+	}
+
+	src, err := gocv.NewMatFromBytes(height, width, gocv.MatTypeCV8UC3, frame)
+	if err != nil {
+		return nil, 0, 0, fmt.Errorf("wrapping frame: %w", err)
+	}
+	defer src.Close()
+	dst := gocv.NewMat()
+	defer dst.Close()
+	// Area interpolation averages the source pixels, avoiding aliasing
+	if err := gocv.Resize(src, &dst, image.Pt(w, h), 0, 0, gocv.InterpolationArea); err != nil {
+		return nil, 0, 0, fmt.Errorf("resizing frame: %w", err)
+	}
+
+	pixels, err := dst.DataPtrUint8()
+	if err != nil {
+		return nil, 0, 0, fmt.Errorf("accessing frame data: %w", err)
+	}
+	if cap(t.scaledBuf) < len(pixels) {
+		t.scaledBuf = make([]byte, len(pixels))
 	}
+	t.scaledBuf = t.scaledBuf[:len(pixels)]
+	copy(t.scaledBuf, pixels)
+	return t.scaledBuf, w, h, nil
 }
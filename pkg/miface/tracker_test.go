@@ -1,8 +1,20 @@
 package miface
 
 import (
+	"context"
+	"errors"
+	"log/slog"
+	"math"
+	"path/filepath"
+	"reflect"
+	"runtime"
+	"strings"
+	"sync"
 	"testing"
 	"time"
+
+	"github.com/MiFaceDEV/miface/internal/config"
+	"gocv.io/x/gocv"
 )
 
 func TestNewTracker(t *testing.T) {
@@ -90,6 +102,10 @@ func TestTrackerSubscribe(t *testing.T) {
 	}
 	defer tracker.Close()
 
+	if err := tracker.SetProcessor(NewStubProcessor(DefaultStubConfig())); err != nil {
+		t.Fatalf("failed to set processor: %v", err)
+	}
+
 	// Subscribe before start
 	ch := tracker.Subscribe()
 	if ch == nil {
@@ -203,3 +219,2381 @@ func TestTrackerWithMockComponents(t *testing.T) {
 		t.Error("expected camera to be closed")
 	}
 }
+
+func TestTrackerApplyConfig(t *testing.T) {
+	tracker, err := NewTracker(nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer tracker.Close()
+
+	if err := tracker.Start(); err != nil {
+		t.Fatalf("failed to start: %v", err)
+	}
+
+	tracker.mu.RLock()
+	oldSmoothers := tracker.smoothers
+	tracker.mu.RUnlock()
+
+	cfg := *tracker.Config()
+	cfg.VMC.Enabled = false
+	cfg.Tracking.SmoothingFactor = 0.9
+	if err := tracker.ApplyConfig(&cfg); err != nil {
+		t.Fatalf("ApplyConfig failed: %v", err)
+	}
+
+	tracker.mu.RLock()
+	newSmoothers := tracker.smoothers
+	tracker.mu.RUnlock()
+
+	if newSmoothers != oldSmoothers {
+		t.Fatal("expected smoothers to be retuned in place")
+	}
+	if newSmoothers.face.factor != 0.9 {
+		t.Errorf("expected face smoother factor 0.9, got %f", newSmoothers.face.factor)
+	}
+	if got := tracker.Config().Tracking.SmoothingFactor; got != 0.9 {
+		t.Errorf("expected config smoothing factor 0.9, got %f", got)
+	}
+	if tracker.State() != StateRunning {
+		t.Errorf("expected tracker to keep running, got %s", tracker.State())
+	}
+}
+
+func TestTrackerRetargeter(t *testing.T) {
+	tracker, err := NewTracker(nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer tracker.Close()
+
+	retargeter := NewRetargeter(nil)
+	tracker.SetRetargeter(retargeter)
+	vmcRetargeter := func() *Retargeter {
+		t.Helper()
+		tracker.mu.RLock()
+		vmc, ok := tracker.vmcSender.(*VMCSender)
+		tracker.mu.RUnlock()
+		if !ok {
+			t.Fatal("expected a VMC sender")
+		}
+		vmc.mu.Lock()
+		defer vmc.mu.Unlock()
+		return vmc.retargeter
+	}
+
+	// A VMC sender created by a config reload gets the retargeter
+	cfg := *tracker.Config()
+	cfg.VMC.Address = "127.0.0.1"
+	cfg.VMC.Port = 39541
+	if err := tracker.ApplyConfig(&cfg); err != nil {
+		t.Fatalf("ApplyConfig failed: %v", err)
+	}
+	if vmcRetargeter() != retargeter {
+		t.Error("expected the reloaded VMC sender to use the retargeter")
+	}
+
+	// Clearing it removes it from the current sender
+	tracker.SetRetargeter(nil)
+	if vmcRetargeter() != nil {
+		t.Error("expected the retargeter to be removed")
+	}
+
+	// A VMC sender set later gets it too
+	tracker.SetRetargeter(retargeter)
+	tracker.mu.RLock()
+	reloaded := tracker.vmcSender
+	tracker.mu.RUnlock()
+	defer reloaded.Close()
+	sender, err := NewVMCSender("127.0.0.1", 39542)
+	if err != nil {
+		t.Fatalf("failed to create sender: %v", err)
+	}
+	if err := tracker.SetVMCSender(sender); err != nil {
+		t.Fatalf("failed to set sender: %v", err)
+	}
+	if vmcRetargeter() != retargeter {
+		t.Error("expected the new VMC sender to use the retargeter")
+	}
+}
+
+func TestNewVMCSenderFromConfig(t *testing.T) {
+	vmc := config.Default().VMC
+	vmc.Address = "127.0.0.1"
+	vmc.Network = "udp4"
+	vmc.DeltaEpsilon = 0.01
+	vmc.KeyframeInterval = 10
+	vmc.Axes = config.AxesMediaPipeUnity
+	sender, err := NewVMCSenderFromConfig(vmc)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer sender.Close()
+
+	sender.mu.Lock()
+	network, epsilon, interval, axes := sender.network, sender.deltaEpsilon, sender.keyframeInterval, sender.axes
+	sender.mu.Unlock()
+	if network != "udp4" || epsilon != 0.01 || interval != 10 || axes != AxesMediaPipeToUnity {
+		t.Errorf("expected the config applied, got network %q, delta %f/%d, axes %+v", network, epsilon, interval, axes)
+	}
+
+	vmc.DeltaEpsilon, vmc.KeyframeInterval = 0.01, 0
+	if _, err := NewVMCSenderFromConfig(vmc); err == nil {
+		t.Error("expected error for an invalid delta mode")
+	}
+}
+
+func TestTrackerApplyConfigRejectsCameraChange(t *testing.T) {
+	tracker, err := NewTracker(nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer tracker.Close()
+
+	if err := tracker.Start(); err != nil {
+		t.Fatalf("failed to start: %v", err)
+	}
+
+	cfg := *tracker.Config()
+	cfg.Camera.Width = 640
+	if err := tracker.ApplyConfig(&cfg); err == nil {
+		t.Error("expected error changing camera resolution while running")
+	}
+	if got := tracker.Config().Camera.Width; got != 1280 {
+		t.Errorf("expected camera width to remain 1280, got %d", got)
+	}
+}
+
+// stepProcessor returns a single face landmark at a configurable X position.
+type stepProcessor struct {
+	x float64
+}
+
+func (p *stepProcessor) Process(ctx context.Context, frame []byte, width, height int) (*TrackingData, error) {
+	return &TrackingData{
+		Face: &FaceData{
+			Landmarks: []Landmark{{Point: Point3D{X: p.x}, Visibility: 1}},
+		},
+	}, nil
+}
+
+func (p *stepProcessor) Close() error {
+	return nil
+}
+
+// stepResponse feeds a 0→1 step through a tracker and returns the face
+// landmark X after the first post-step frame. If raiseTo is non-negative, the
+// smoothing factor is changed to it right before the step.
+func stepResponse(t *testing.T, raiseTo float64) float64 {
+	t.Helper()
+	if raiseTo < 0 {
+		return stepResponseWith(t, nil, nil)
+	}
+	return stepResponseWith(t, nil, func(tracking *config.TrackingConfig) {
+		tracking.SmoothingFactor = raiseTo
+	})
+}
+
+// stepResponseWith is like stepResponse, but applies setup (if not nil) to
+// the tracking settings the tracker is created with, and change (if not nil)
+// to the tracking settings right before the step.
+func stepResponseWith(t *testing.T, setup, change func(*config.TrackingConfig)) float64 {
+	t.Helper()
+	return stepResponseAt(t, config.Default().Camera.FPS, setup, change)
+}
+
+// stepResponseAt is like stepResponseWith with frames arriving at fps.
+func stepResponseAt(t *testing.T, fps int, setup, change func(*config.TrackingConfig)) float64 {
+	t.Helper()
+
+	cfg := config.Default()
+	cfg.Camera.FPS = fps
+	cfg.Tracking.SmoothingFactor = 0.1
+	if setup != nil {
+		setup(&cfg.Tracking)
+	}
+	tracker, err := NewTracker(cfg)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer tracker.Close()
+
+	clock := NewFakeClock(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC))
+	if err := tracker.SetClock(clock); err != nil {
+		t.Fatalf("failed to set clock: %v", err)
+	}
+	proc := &stepProcessor{}
+	if err := tracker.SetCameraSource(&MockCameraSource{}); err != nil {
+		t.Fatalf("failed to set camera: %v", err)
+	}
+	if err := tracker.SetProcessor(proc); err != nil {
+		t.Fatalf("failed to set processor: %v", err)
+	}
+	ch := tracker.Subscribe()
+
+	interval := time.Second / time.Duration(fps)
+	for i := 0; i < 5; i++ {
+		tracker.processFrame()
+		<-ch
+		clock.Advance(interval)
+	}
+
+	if change != nil {
+		tracking := tracker.Config().Tracking
+		change(&tracking)
+		if err := tracker.SetTrackingConfig(tracking); err != nil {
+			t.Fatalf("SetTrackingConfig failed: %v", err)
+		}
+	}
+
+	proc.x = 1
+	tracker.processFrame()
+	data := <-ch
+	return data.Face.Landmarks[0].Point.X
+}
+
+func TestTrackerSmoothingFrameInterval(t *testing.T) {
+	// Each slow frame stands for more time, so the step gets further
+	fast := stepResponseAt(t, 30, nil, nil)
+	slow := stepResponseAt(t, 10, nil, nil)
+	if fast <= 0 || slow <= fast || slow >= 1 {
+		t.Errorf("expected the step to get further at 10 fps than at 30 fps (%f), got %f", fast, slow)
+	}
+
+	// Double-exponential smoothing is scaled the same way
+	doubleExp := func(tracking *config.TrackingConfig) {
+		tracking.SmoothingAlgorithm = config.SmoothingDoubleExp
+	}
+	fast = stepResponseAt(t, 30, doubleExp, nil)
+	slow = stepResponseAt(t, 10, doubleExp, nil)
+	if fast <= 0 || slow <= fast || slow >= 1 {
+		t.Errorf("expected double_exp to get further at 10 fps than at 30 fps (%f), got %f", fast, slow)
+	}
+}
+
+func TestTrackerSmoothingAlgorithm(t *testing.T) {
+	kalman := stepResponse(t, -1)
+
+	// Double-exponential with alpha 1 follows the step at once
+	doubleExp := stepResponseWith(t, func(tracking *config.TrackingConfig) {
+		tracking.SmoothingAlgorithm = config.SmoothingDoubleExp
+		tracking.DoubleExp = config.DoubleExpConfig{Alpha: 1}
+	}, nil)
+	if doubleExp != 1 {
+		t.Errorf("expected double_exp with alpha 1 to follow the step, got %f", doubleExp)
+	}
+
+	// Switching at runtime continues from the last output instead of
+	// snapping to the next measurement
+	switched := stepResponseWith(t, nil, func(tracking *config.TrackingConfig) {
+		tracking.SmoothingAlgorithm = config.SmoothingOneEuro
+	})
+	if switched <= 0 || switched >= 1 {
+		t.Errorf("expected the step to be smoothed after switching algorithm, got %f", switched)
+	}
+
+	// One Euro without speed adaptation is a plain low-pass filter
+	oneEuro := stepResponseWith(t, func(tracking *config.TrackingConfig) {
+		tracking.SmoothingAlgorithm = config.SmoothingOneEuro
+		tracking.OneEuro.Beta = 0
+	}, nil)
+	if oneEuro <= 0 || oneEuro >= 1 || oneEuro == kalman {
+		t.Errorf("expected oneeuro to smooth the step differently from kalman (%f), got %f", kalman, oneEuro)
+	}
+
+	tracker, err := NewTracker(nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer tracker.Close()
+	tracking := tracker.Config().Tracking
+	tracking.SmoothingAlgorithm = "median"
+	if err := tracker.SetTrackingConfig(tracking); err == nil {
+		t.Error("expected error for unknown smoothing algorithm")
+	}
+}
+
+func TestTrackerSetTrackingConfig(t *testing.T) {
+	baseline := stepResponse(t, -1)
+	raised := stepResponse(t, 1.0)
+
+	if raised <= baseline {
+		t.Errorf("expected raised smoothing factor to respond faster: baseline=%f, raised=%f", baseline, raised)
+	}
+	if baseline >= 1 {
+		t.Errorf("expected baseline output to be smoothed, got %f", baseline)
+	}
+	// A reset filter would pass the step straight through
+	if raised >= 1 {
+		t.Errorf("expected the smoothers to keep their state across the change, got %f", raised)
+	}
+}
+
+func TestTrackerSetTrackingConfigInvalid(t *testing.T) {
+	tracker, err := NewTracker(nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer tracker.Close()
+
+	tracking := tracker.Config().Tracking
+	tracking.SmoothingFactor = 2
+	if err := tracker.SetTrackingConfig(tracking); err == nil {
+		t.Error("expected error for smoothing factor > 1")
+	}
+}
+
+func TestApplyTrackingMirror(t *testing.T) {
+	tracking := config.Default().Tracking
+	tracking.MirrorLandmarks = true
+
+	data := &TrackingData{
+		LeftHand: &HandData{Landmarks: []Landmark{{Point: Point3D{X: 0.25}}}, IsLeft: true},
+	}
+	applyTracking(data, tracking, nil, 0)
+
+	if data.LeftHand != nil || data.RightHand == nil {
+		t.Fatal("expected hands to be swapped")
+	}
+	if got := data.RightHand.Landmarks[0].Point.X; got != 0.75 {
+		t.Errorf("expected mirrored X 0.75, got %f", got)
+	}
+}
+
+func TestApplyTrackingCorrectHandedness(t *testing.T) {
+	tracking := config.Default().Tracking
+	tracking.CorrectHandedness = true
+
+	data := &TrackingData{Pose: handednessPose(0.7, 0.3), RightHand: handAt(false, 0.7)}
+	applyTracking(data, tracking, nil, 0)
+
+	if data.LeftHand == nil || data.RightHand != nil {
+		t.Error("expected the hand at the left wrist to become the left hand")
+	}
+}
+
+func TestApplyTrackingLockLowerBody(t *testing.T) {
+	tracking := config.Default().Tracking
+	tracking.LockLowerBody = true
+
+	data := &TrackingData{Pose: &PoseData{Landmarks: make([]Landmark, PoseLandmarkCount)}}
+	applyTracking(data, tracking, nil, 0)
+
+	// The lock is enforced by the VMC sender; the landmarks stay intact
+	if got := len(data.Pose.Landmarks); got != PoseLandmarkCount {
+		t.Errorf("expected all %d pose landmarks, got %d", PoseLandmarkCount, got)
+	}
+}
+
+// cloneTestData returns a frame with every modality and blend shapes.
+func cloneTestData() *TrackingData {
+	return &TrackingData{
+		Timestamp:   time.Unix(100, 0),
+		FrameNumber: 7,
+		Face: &FaceData{
+			Landmarks:    []Landmark{{Point: Point3D{X: 0.5, Y: 0.4}, Visibility: 1}},
+			BlendShapes:  map[string]float64{"jawOpen": 0.3},
+			HeadRotation: Quaternion{W: 1},
+		},
+		LeftHand:  testHand(true, 1),
+		RightHand: testHand(false, 1),
+		Pose:      testPose(),
+	}
+}
+
+func TestTrackingDataClone(t *testing.T) {
+	data := cloneTestData()
+	clone := data.Clone()
+	if !clone.Equal(data) {
+		t.Fatal("expected the clone to equal the original")
+	}
+
+	clone.Face.Landmarks[0].Point.X = 0.9
+	clone.Face.BlendShapes["jawOpen"] = 1
+	clone.LeftHand.Landmarks[HandWrist].Point.Y = 0.9
+	clone.RightHand.IsLeft = true
+	clone.Pose.Landmarks[PoseNose].Visibility = 0
+	if data.Face.Landmarks[0].Point.X != 0.5 || data.Face.BlendShapes["jawOpen"] != 0.3 ||
+		data.LeftHand.Landmarks[HandWrist].Point.Y != 0 || data.RightHand.IsLeft ||
+		data.Pose.Landmarks[PoseNose].Visibility != 1 {
+		t.Error("expected changes to the clone not to affect the original")
+	}
+
+	if (*TrackingData)(nil).Clone() != nil {
+		t.Error("expected cloning nil to return nil")
+	}
+	if partial := (&TrackingData{Pose: testPose()}).Clone(); partial.Face != nil || partial.LeftHand != nil {
+		t.Error("expected missing modalities to stay nil")
+	}
+}
+
+func TestTrackingDataEqual(t *testing.T) {
+	tests := []struct {
+		name   string
+		modify func(*TrackingData)
+		want   bool
+	}{
+		{"identical", func(*TrackingData) {}, true},
+		{"within tolerance", func(d *TrackingData) { d.Pose.Landmarks[0].Point.X += EqualTolerance / 2 }, true},
+		{"landmark moved", func(d *TrackingData) { d.Pose.Landmarks[0].Point.X += 0.01 }, false},
+		{"blend shape changed", func(d *TrackingData) { d.Face.BlendShapes["jawOpen"] = 0.5 }, false},
+		{"blend shape added", func(d *TrackingData) { d.Face.BlendShapes["eyeBlinkLeft"] = 0 }, false},
+		{"head rotation changed", func(d *TrackingData) { d.Face.HeadRotation.Y = 0.1 }, false},
+		{"hand missing", func(d *TrackingData) { d.LeftHand = nil }, false},
+		{"face missing", func(d *TrackingData) { d.Face = nil }, false},
+		{"handedness changed", func(d *TrackingData) { d.RightHand.IsLeft = true }, false},
+		{"frame number changed", func(d *TrackingData) { d.FrameNumber++ }, false},
+		{"timestamp changed", func(d *TrackingData) { d.Timestamp = d.Timestamp.Add(time.Millisecond) }, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a, b := cloneTestData(), cloneTestData()
+			tt.modify(b)
+			if got := a.Equal(b); got != tt.want {
+				t.Errorf("expected Equal %v, got %v", tt.want, got)
+			}
+			if got := b.Equal(a); got != tt.want {
+				t.Errorf("expected Equal to be symmetric, got %v", got)
+			}
+		})
+	}
+
+	var nilData *TrackingData
+	if !nilData.Equal(nil) {
+		t.Error("expected two nil frames to be equal")
+	}
+	if nilData.Equal(&TrackingData{}) || (&TrackingData{}).Equal(nil) {
+		t.Error("expected a nil frame not to equal a non-nil one")
+	}
+	if !(&TrackingData{}).Equal(&TrackingData{}) {
+		t.Error("expected empty frames with nil modalities to be equal")
+	}
+}
+
+func TestTrackerLatestData(t *testing.T) {
+	tracker, err := NewTracker(nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer tracker.Close()
+
+	if data := tracker.LatestData(); data != nil {
+		t.Fatalf("expected nil before the first frame, got frame %d", data.FrameNumber)
+	}
+
+	if err := tracker.SetProcessor(NewStubProcessor(DefaultStubConfig())); err != nil {
+		t.Fatalf("failed to set processor: %v", err)
+	}
+	if err := tracker.Start(); err != nil {
+		t.Fatalf("failed to start: %v", err)
+	}
+
+	deadline := time.Now().Add(500 * time.Millisecond)
+	var data *TrackingData
+	for time.Now().Before(deadline) {
+		if data = tracker.LatestData(); data != nil {
+			break
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	if data == nil {
+		t.Fatal("timeout waiting for latest data")
+	}
+	if data.FrameNumber == 0 {
+		t.Error("expected non-zero frame number")
+	}
+	if data.Face == nil || len(data.Face.Landmarks) != numFaceLandmarks {
+		t.Fatal("expected face landmarks from the stub processor")
+	}
+
+	// Mutating the snapshot must not affect later snapshots
+	data.Face.Landmarks[0].Point.X = -100
+	data.Face.BlendShapes["jawOpen"] = -1
+	again := tracker.LatestData()
+	if again.Face.Landmarks[0].Point.X == -100 || again.Face.BlendShapes["jawOpen"] == -1 {
+		t.Error("expected LatestData to return independent copies")
+	}
+	if again.FrameNumber < data.FrameNumber {
+		t.Errorf("expected frame number to be non-decreasing, got %d after %d", again.FrameNumber, data.FrameNumber)
+	}
+}
+
+// recordHandler is a slog.Handler that keeps every record it handles.
+type recordHandler struct {
+	mu      sync.Mutex
+	records []slog.Record
+}
+
+func (h *recordHandler) Enabled(context.Context, slog.Level) bool { return true }
+
+func (h *recordHandler) Handle(_ context.Context, r slog.Record) error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.records = append(h.records, r.Clone())
+	return nil
+}
+
+func (h *recordHandler) WithAttrs([]slog.Attr) slog.Handler { return h }
+func (h *recordHandler) WithGroup(string) slog.Handler      { return h }
+
+// failingSender is a Sender whose Send always fails.
+type failingSender struct{}
+
+func (failingSender) Send(*TrackingData) error { return errors.New("network unreachable") }
+func (failingSender) Close() error             { return nil }
+
+func TestTrackerLogsSendError(t *testing.T) {
+	tracker, err := NewTracker(nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer tracker.Close()
+
+	handler := &recordHandler{}
+	tracker.SetLogger(slog.New(handler))
+	if err := tracker.SetProcessor(NewStubProcessor(DefaultStubConfig())); err != nil {
+		t.Fatalf("failed to set processor: %v", err)
+	}
+	if err := tracker.SetVMCSender(failingSender{}); err != nil {
+		t.Fatalf("failed to set sender: %v", err)
+	}
+
+	tracker.processFrame()
+
+	handler.mu.Lock()
+	defer handler.mu.Unlock()
+
+	var found bool
+	for _, r := range handler.records {
+		if r.Message != "send failed" {
+			continue
+		}
+		found = true
+		if r.Level != slog.LevelWarn {
+			t.Errorf("expected warn level, got %s", r.Level)
+		}
+		attrs := map[string]slog.Value{}
+		r.Attrs(func(a slog.Attr) bool {
+			attrs[a.Key] = a.Value
+			return true
+		})
+		if attrs["frame"].Uint64() != 1 {
+			t.Errorf("expected frame 1, got %v", attrs["frame"])
+		}
+		if attrs["sender"].String() != "vmc" {
+			t.Errorf("expected sender vmc, got %v", attrs["sender"])
+		}
+		if !strings.Contains(attrs["error"].String(), "network unreachable") {
+			t.Errorf("expected error attribute, got %v", attrs["error"])
+		}
+	}
+	if !found {
+		t.Errorf("expected a send failed record, got %d records", len(handler.records))
+	}
+}
+
+func TestTrackerErrors(t *testing.T) {
+	tracker, err := NewTracker(nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if err := tracker.SetProcessor(NewStubProcessor(DefaultStubConfig())); err != nil {
+		t.Fatalf("failed to set processor: %v", err)
+	}
+	if err := tracker.SetVMCSender(failingSender{}); err != nil {
+		t.Fatalf("failed to set sender: %v", err)
+	}
+	if err := tracker.Start(); err != nil {
+		t.Fatalf("failed to start: %v", err)
+	}
+
+	// The loop keeps running and reports each failure
+	for i := 0; i < 3; i++ {
+		select {
+		case err := <-tracker.Errors():
+			if !strings.Contains(err.Error(), "network unreachable") {
+				t.Errorf("expected sender error, got %v", err)
+			}
+			if !strings.Contains(err.Error(), "to vmc") {
+				t.Errorf("expected the sender name in the error, got %v", err)
+			}
+		case <-time.After(time.Second):
+			t.Fatalf("timeout waiting for error %d", i+1)
+		}
+	}
+	if tracker.State() != StateRunning {
+		t.Errorf("expected tracker to keep running, got %s", tracker.State())
+	}
+
+	if err := tracker.Close(); err != nil {
+		t.Fatalf("failed to close: %v", err)
+	}
+	for range tracker.Errors() {
+		// Drain buffered errors until the channel is closed
+	}
+}
+
+// sizedCamera is a CameraSource producing frames of a fixed size.
+type sizedCamera struct {
+	width  int
+	mu     sync.Mutex
+	closed bool
+}
+
+func (c *sizedCamera) Open(deviceID, width, height, fps int) error { return nil }
+
+func (c *sizedCamera) Read() ([]byte, int, int, error) {
+	return make([]byte, c.width*3), c.width, 1, nil
+}
+
+func (c *sizedCamera) Close() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.closed = true
+	return nil
+}
+
+// widthProcessor reports the frame width as the X of a single face landmark.
+type widthProcessor struct{}
+
+func (widthProcessor) Process(ctx context.Context, frame []byte, width, height int) (*TrackingData, error) {
+	return &TrackingData{
+		Face: &FaceData{Landmarks: []Landmark{{Point: Point3D{X: float64(width)}}}},
+	}, nil
+}
+
+func (widthProcessor) Close() error { return nil }
+
+func TestTrackerSwapCameraSource(t *testing.T) {
+	cfg := config.Default()
+	cfg.Tracking.SmoothingFactor = 1
+	tracker, err := NewTracker(cfg)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer tracker.Close()
+
+	oldCam := &sizedCamera{width: 100}
+	newCam := &sizedCamera{width: 200}
+	if err := tracker.SetCameraSource(oldCam); err != nil {
+		t.Fatalf("failed to set camera: %v", err)
+	}
+	if err := tracker.SetProcessor(widthProcessor{}); err != nil {
+		t.Fatalf("failed to set processor: %v", err)
+	}
+	ch := tracker.Subscribe()
+	if err := tracker.Start(); err != nil {
+		t.Fatalf("failed to start: %v", err)
+	}
+
+	waitForWidth := func(width float64) {
+		t.Helper()
+		timeout := time.After(time.Second)
+		for {
+			select {
+			case data := <-ch:
+				if data.Face.Landmarks[0].Point.X == width {
+					return
+				}
+			case <-timeout:
+				t.Fatalf("timeout waiting for frame of width %.0f", width)
+			}
+		}
+	}
+
+	waitForWidth(100)
+	if err := tracker.SwapCameraSource(newCam); err != nil {
+		t.Fatalf("SwapCameraSource failed: %v", err)
+	}
+	waitForWidth(200)
+
+	oldCam.mu.Lock()
+	closed := oldCam.closed
+	oldCam.mu.Unlock()
+	if !closed {
+		t.Error("expected old camera to be closed")
+	}
+	if tracker.State() != StateRunning {
+		t.Errorf("expected tracker to keep running, got %s", tracker.State())
+	}
+}
+
+// toggleProcessor returns stub data while enabled and nothing otherwise.
+type toggleProcessor struct {
+	stub    *StubProcessor
+	enabled bool
+}
+
+func (p *toggleProcessor) Process(ctx context.Context, frame []byte, width, height int) (*TrackingData, error) {
+	if !p.enabled {
+		return nil, nil
+	}
+	return p.stub.Process(ctx, frame, width, height)
+}
+
+func (p *toggleProcessor) Close() error { return nil }
+
+// recordingSender keeps every frame it is asked to send.
+type recordingSender struct {
+	sent   []*TrackingData
+	closed bool
+}
+
+func (s *recordingSender) Send(data *TrackingData) error {
+	s.sent = append(s.sent, data)
+	return nil
+}
+
+func (s *recordingSender) Close() error {
+	s.closed = true
+	return nil
+}
+
+func TestTrackerRestPose(t *testing.T) {
+	tracker, err := NewTracker(nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer tracker.Close()
+
+	proc := &toggleProcessor{stub: NewStubProcessor(DefaultStubConfig()), enabled: true}
+	sender := &recordingSender{}
+	if err := tracker.SetProcessor(proc); err != nil {
+		t.Fatalf("failed to set processor: %v", err)
+	}
+	if err := tracker.SetVMCSender(sender); err != nil {
+		t.Fatalf("failed to set sender: %v", err)
+	}
+
+	const timeout = 30 * time.Millisecond
+	tracker.SetRestPose(DefaultRestPose(), timeout)
+
+	tracker.processFrame()
+	if len(sender.sent) != 1 {
+		t.Fatalf("expected 1 tracked frame, got %d", len(sender.sent))
+	}
+
+	// Tracking stops; nothing is sent until the timeout passes
+	proc.enabled = false
+	tracker.processFrame()
+	if len(sender.sent) != 1 {
+		t.Fatalf("expected no frame before the timeout, got %d", len(sender.sent))
+	}
+
+	time.Sleep(timeout)
+	tracker.processFrame()
+	if len(sender.sent) != 2 {
+		t.Fatalf("expected rest pose to be sent, got %d frames", len(sender.sent))
+	}
+	rest := sender.sent[1]
+	if rest.Face == nil {
+		t.Fatal("expected rest pose face")
+	}
+	for _, name := range []string{"eyeBlinkLeft", "jawOpen"} {
+		value, ok := rest.Face.BlendShapes[name]
+		if !ok || value != 0 {
+			t.Errorf("expected neutral %s blend shape, got %v (present=%v)", name, value, ok)
+		}
+	}
+	if rest.Face.HeadRotation != (Quaternion{W: 1}) {
+		t.Errorf("expected centered head, got %+v", rest.Face.HeadRotation)
+	}
+
+	// Tracking resumes: real data again
+	proc.enabled = true
+	tracker.processFrame()
+	got := sender.sent[len(sender.sent)-1]
+	if _, isRest := got.Face.BlendShapes["eyeBlinkLeft"]; isRest {
+		t.Error("expected tracked data after resuming, got the rest pose")
+	}
+}
+
+// matCamera is a CameraSource and MatSource that counts each kind of read.
+type matCamera struct {
+	reads    int
+	matReads int
+}
+
+func (c *matCamera) Open(deviceID, width, height, fps int) error { return nil }
+
+func (c *matCamera) Read() ([]byte, int, int, error) {
+	c.reads++
+	return []byte{3, 2, 1, 6, 5, 4}, 2, 1, nil
+}
+
+func (c *matCamera) ReadMat() (gocv.Mat, error) {
+	c.matReads++
+	return gocv.NewMatFromBytes(1, 2, gocv.MatTypeCV8UC3, []byte{1, 2, 3, 4, 5, 6})
+}
+
+func (c *matCamera) Close() error { return nil }
+
+// byteCamera is a CameraSource without ReadMat that counts reads.
+type byteCamera struct {
+	reads int
+}
+
+func (c *byteCamera) Open(deviceID, width, height, fps int) error { return nil }
+
+func (c *byteCamera) Read() ([]byte, int, int, error) {
+	c.reads++
+	return []byte{3, 2, 1, 6, 5, 4}, 2, 1, nil
+}
+
+func (c *byteCamera) Close() error { return nil }
+
+// frameProcessor records the last frame it was given.
+type frameProcessor struct {
+	frame         []byte
+	width, height int
+}
+
+func (p *frameProcessor) Process(ctx context.Context, frame []byte, width, height int) (*TrackingData, error) {
+	p.frame = append([]byte(nil), frame...)
+	p.width, p.height = width, height
+	return &TrackingData{}, nil
+}
+
+func (p *frameProcessor) Close() error { return nil }
+
+func newPreviewTracker(t *testing.T, camera CameraSource, proc Processor) *Tracker {
+	t.Helper()
+	if runtime.GOOS == "darwin" {
+		t.Skip("Skipping GUI test on macOS: NSWindow requires main thread")
+	}
+	tracker, err := NewTracker(nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	t.Cleanup(func() { tracker.Close() })
+
+	if err := tracker.SetCameraSource(camera); err != nil {
+		t.Fatalf("failed to set camera: %v", err)
+	}
+	if err := tracker.SetProcessor(proc); err != nil {
+		t.Fatalf("failed to set processor: %v", err)
+	}
+	if err := tracker.SetPreviewWindow(NewPreviewWindow("Test Window")); err != nil {
+		t.Fatalf("failed to set preview window: %v", err)
+	}
+	return tracker
+}
+
+func TestTrackerPreviewUsesMatSource(t *testing.T) {
+	var _ MatSource = (*OpenCVCamera)(nil)
+	var _ MatSource = (*matCamera)(nil)
+	if _, ok := CameraSource(&byteCamera{}).(MatSource); ok {
+		t.Fatal("expected byteCamera not to implement MatSource")
+	}
+
+	camera := &matCamera{}
+	proc := &frameProcessor{}
+	tracker := newPreviewTracker(t, camera, proc)
+
+	tracker.processFrame()
+
+	if camera.matReads != 1 || camera.reads != 0 {
+		t.Errorf("expected a single ReadMat, got %d ReadMat and %d Read calls", camera.matReads, camera.reads)
+	}
+	want := []byte{3, 2, 1, 6, 5, 4}
+	if string(proc.frame) != string(want) {
+		t.Errorf("expected RGB frame %v, got %v", want, proc.frame)
+	}
+	if proc.width != 2 || proc.height != 1 {
+		t.Errorf("expected 2x1 frame, got %dx%d", proc.width, proc.height)
+	}
+}
+
+func TestTrackerPreviewFallsBackToRead(t *testing.T) {
+	camera := &byteCamera{}
+	proc := &frameProcessor{}
+	tracker := newPreviewTracker(t, camera, proc)
+
+	tracker.processFrame()
+
+	if camera.reads != 1 {
+		t.Errorf("expected a single Read, got %d", camera.reads)
+	}
+	want := []byte{3, 2, 1, 6, 5, 4}
+	if string(proc.frame) != string(want) {
+		t.Errorf("expected RGB frame %v, got %v", want, proc.frame)
+	}
+}
+
+func TestTrackerWithoutPreviewUsesRead(t *testing.T) {
+	tracker, err := NewTracker(nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer tracker.Close()
+
+	camera := &matCamera{}
+	if err := tracker.SetCameraSource(camera); err != nil {
+		t.Fatalf("failed to set camera: %v", err)
+	}
+	if err := tracker.SetProcessor(&frameProcessor{}); err != nil {
+		t.Fatalf("failed to set processor: %v", err)
+	}
+
+	tracker.processFrame()
+
+	if camera.reads != 1 || camera.matReads != 0 {
+		t.Errorf("expected a single Read, got %d Read and %d ReadMat calls", camera.reads, camera.matReads)
+	}
+}
+
+// blankCamera is a CameraSource that returns blank frames of a fixed size.
+type blankCamera struct {
+	width, height int
+}
+
+func (c *blankCamera) Open(deviceID, width, height, fps int) error { return nil }
+
+func (c *blankCamera) Read() ([]byte, int, int, error) {
+	return make([]byte, c.width*c.height*3), c.width, c.height, nil
+}
+
+func (c *blankCamera) Close() error { return nil }
+
+// hungProcessor blocks until its context ends.
+type hungProcessor struct{}
+
+func (hungProcessor) Process(ctx context.Context, frame []byte, width, height int) (*TrackingData, error) {
+	<-ctx.Done()
+	return nil, ctx.Err()
+}
+
+func (hungProcessor) Close() error { return nil }
+
+func TestTrackerProcessTimeout(t *testing.T) {
+	cfg := config.Default()
+	cfg.Tracking.ProcessTimeoutMS = 20
+	tracker, err := NewTracker(cfg)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer tracker.Close()
+
+	if err := tracker.SetCameraSource(&blankCamera{width: 64, height: 48}); err != nil {
+		t.Fatalf("failed to set camera: %v", err)
+	}
+	if err := tracker.SetProcessor(hungProcessor{}); err != nil {
+		t.Fatalf("failed to set processor: %v", err)
+	}
+
+	done := make(chan struct{})
+	go func() {
+		tracker.processFrame()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("expected the frame to time out")
+	}
+	if tracker.LatestData() != nil {
+		t.Error("expected no data from a timed-out frame")
+	}
+}
+
+// ctxProcessor takes delay to process a frame and fails if its context
+// ends first.
+type ctxProcessor struct {
+	delay time.Duration
+}
+
+func (p ctxProcessor) Process(ctx context.Context, frame []byte, width, height int) (*TrackingData, error) {
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case <-time.After(p.delay):
+	}
+	return &TrackingData{Face: &FaceData{HeadRotation: Quaternion{W: 1}}}, nil
+}
+
+func (ctxProcessor) Close() error { return nil }
+
+func TestTrackerProcessTimeoutAsync(t *testing.T) {
+	cfg := config.Default()
+	cfg.Tracking.ProcessTimeoutMS = 500
+	tracker, err := NewTracker(cfg)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer tracker.Close()
+
+	if err := tracker.SetCameraSource(&blankCamera{width: 64, height: 48}); err != nil {
+		t.Fatalf("failed to set camera: %v", err)
+	}
+	if err := tracker.SetProcessor(NewAsyncProcessor(ctxProcessor{delay: 5 * time.Millisecond})); err != nil {
+		t.Fatalf("failed to set processor: %v", err)
+	}
+
+	// The worker outlives each processFrame call and its timeout context,
+	// but still has the rest of the timeout to finish the frame
+	for i := 0; i < 100 && tracker.LatestData() == nil; i++ {
+		tracker.processFrame()
+		time.Sleep(time.Millisecond)
+	}
+	if tracker.LatestData() == nil {
+		t.Fatal("expected the async processor to produce data within the timeout")
+	}
+
+	// A hung frame is still cut off by the timeout on the worker
+	async := NewAsyncProcessor(hungProcessor{})
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	if _, err := async.Process(ctx, []byte{0}, 1, 1); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	cancel()
+	for taken := false; !taken; time.Sleep(time.Millisecond) {
+		async.mu.Lock()
+		taken = async.pending == nil
+		async.mu.Unlock()
+	}
+	closed := make(chan struct{})
+	go func() {
+		async.Close()
+		close(closed)
+	}()
+	select {
+	case <-closed:
+	case <-time.After(time.Second):
+		t.Fatal("expected the hung frame to time out on the worker")
+	}
+}
+
+// mirrorCamera is a blankCamera that reports whether its frames are flipped.
+type mirrorCamera struct {
+	blankCamera
+	mirror bool
+}
+
+func (c *mirrorCamera) IsMirror() bool { return c.mirror }
+
+func TestTrackerMirrorHeadYaw(t *testing.T) {
+	raw := &TrackingData{Face: &FaceData{
+		HeadRotation: QuaternionFromEuler(0, 0.3, 0),
+		BlendShapes:  map[string]float64{"eyeLookOutLeft": 0.4, "eyeBlinkLeft": 0.7},
+	}}
+
+	yaw := func(mirror, mirrorLandmarks bool) (float64, *FaceData) {
+		t.Helper()
+		cfg := config.Default()
+		cfg.Tracking.SmoothingFactor = 1
+		cfg.Tracking.MirrorLandmarks = mirrorLandmarks
+		tracker, err := NewTracker(cfg)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		defer tracker.Close()
+
+		camera := &mirrorCamera{blankCamera: blankCamera{width: 64, height: 48}, mirror: mirror}
+		if err := tracker.SetCameraSource(camera); err != nil {
+			t.Fatalf("failed to set camera: %v", err)
+		}
+		if err := tracker.SetProcessor(&fixedProcessor{data: raw}); err != nil {
+			t.Fatalf("failed to set processor: %v", err)
+		}
+		tracker.processFrame()
+
+		face := tracker.LatestData().Face
+		_, y, _ := face.HeadRotation.ToEuler()
+		return y, face
+	}
+
+	plain, face := yaw(false, false)
+	if math.Abs(plain-0.3) > 1e-9 {
+		t.Errorf("expected unmirrored yaw 0.3, got %f", plain)
+	}
+	if face.BlendShapes["eyeLookOutLeft"] != 0.4 {
+		t.Errorf("expected unmirrored eyeLookOutLeft 0.4, got %v", face.BlendShapes)
+	}
+
+	mirrored, face := yaw(true, false)
+	if math.Abs(mirrored+0.3) > 1e-9 {
+		t.Errorf("expected mirrored yaw -0.3, got %f", mirrored)
+	}
+	if face.BlendShapes["eyeLookOutRight"] != 0.4 || face.BlendShapes["eyeBlinkRight"] != 0.7 {
+		t.Errorf("expected mirrored eyeLookOutRight 0.4 and eyeBlinkRight 0.7, got %v", face.BlendShapes)
+	}
+
+	// Mirroring the landmarks as well undoes the camera mirror, so only
+	// MirrorTrackingData reflects the head, and both modes agree
+	both, face := yaw(true, true)
+	if math.Abs(both-mirrored) > 1e-9 {
+		t.Errorf("expected doubly mirrored yaw %f, got %f", mirrored, both)
+	}
+	if face.BlendShapes["eyeLookOutRight"] != 0.4 || face.BlendShapes["eyeBlinkRight"] != 0.7 {
+		t.Errorf("expected doubly mirrored eyeLookOutRight 0.4 and eyeBlinkRight 0.7, got %v", face.BlendShapes)
+	}
+
+	// Mirroring only the landmarks is undone for the head as a whole
+	if _, face := yaw(false, true); face.BlendShapes["eyeLookOutLeft"] != 0.4 || face.BlendShapes["eyeBlinkLeft"] != 0.7 {
+		t.Errorf("expected eyeLookOutLeft 0.4 and eyeBlinkLeft 0.7, got %v", face.BlendShapes)
+	}
+}
+
+func TestTrackerMirrorBlendShapes(t *testing.T) {
+	cfg := config.Default()
+	cfg.Tracking.SmoothingFactor = 1
+	tracker, err := NewTracker(cfg)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer tracker.Close()
+
+	// A mirrored camera swaps every pair, not just the eyeLook* ones
+	camera := &mirrorCamera{blankCamera: blankCamera{width: 64, height: 48}, mirror: true}
+	if err := tracker.SetCameraSource(camera); err != nil {
+		t.Fatalf("failed to set camera: %v", err)
+	}
+
+	proc := &fixedProcessor{data: &TrackingData{Face: &FaceData{
+		HeadRotation: Quaternion{W: 1},
+		BlendShapes:  map[string]float64{"eyeBlinkLeft": 0.9, "eyeBlinkRight": 0.2},
+	}}}
+	if err := tracker.SetProcessor(proc); err != nil {
+		t.Fatalf("failed to set processor: %v", err)
+	}
+	tracker.processFrame()
+
+	shapes := tracker.LatestData().Face.BlendShapes
+	if shapes["eyeBlinkLeft"] != 0.2 || shapes["eyeBlinkRight"] != 0.9 {
+		t.Errorf("expected the blinks swapped, got %v", shapes)
+	}
+}
+
+func TestTrackerDownscale(t *testing.T) {
+	cfg := config.Default()
+	cfg.Tracking.Downscale = true
+	cfg.Tracking.DownscaleMaxDimension = 640
+	tracker, err := NewTracker(cfg)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer tracker.Close()
+
+	proc := &frameProcessor{}
+	if err := tracker.SetCameraSource(&blankCamera{width: 1280, height: 720}); err != nil {
+		t.Fatalf("failed to set camera: %v", err)
+	}
+	if err := tracker.SetProcessor(proc); err != nil {
+		t.Fatalf("failed to set processor: %v", err)
+	}
+
+	tracker.processFrame()
+
+	if proc.width != 640 || proc.height != 360 {
+		t.Errorf("expected a 640x360 frame, got %dx%d", proc.width, proc.height)
+	}
+	if len(proc.frame) != 640*360*3 {
+		t.Errorf("expected %d bytes, got %d", 640*360*3, len(proc.frame))
+	}
+
+	// Turned off, the full frame is processed
+	cfg.Tracking.Downscale = false
+	if err := tracker.SetTrackingConfig(cfg.Tracking); err != nil {
+		t.Fatalf("failed to set tracking config: %v", err)
+	}
+	tracker.processFrame()
+	if proc.width != 1280 || proc.height != 720 {
+		t.Errorf("expected a 1280x720 frame, got %dx%d", proc.width, proc.height)
+	}
+}
+
+func TestTrackerFramePreprocessor(t *testing.T) {
+	tracker, err := NewTracker(nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer tracker.Close()
+
+	proc := &frameProcessor{}
+	if err := tracker.SetCameraSource(&blankCamera{width: 64, height: 48}); err != nil {
+		t.Fatalf("failed to set camera: %v", err)
+	}
+	if err := tracker.SetProcessor(proc); err != nil {
+		t.Fatalf("failed to set processor: %v", err)
+	}
+
+	// Keep the top-left quarter of the frame
+	tracker.SetFramePreprocessor(func(frame []byte, width, height int) ([]byte, int, int, error) {
+		w, h := width/2, height/2
+		out := make([]byte, 0, w*h*3)
+		for y := 0; y < h; y++ {
+			out = append(out, frame[y*width*3:(y*width+w)*3]...)
+		}
+		return out, w, h, nil
+	})
+	tracker.processFrame()
+	if proc.width != 32 || proc.height != 24 || len(proc.frame) != 32*24*3 {
+		t.Errorf("expected a 32x24 frame, got %dx%d with %d bytes", proc.width, proc.height, len(proc.frame))
+	}
+
+	// A failing preprocessor skips the frame
+	proc.width, proc.height = 0, 0
+	tracker.SetFramePreprocessor(func([]byte, int, int) ([]byte, int, int, error) {
+		return nil, 0, 0, errors.New("denoise failed")
+	})
+	tracker.processFrame()
+	if proc.width != 0 {
+		t.Errorf("expected the frame to be skipped, got a %dx%d frame", proc.width, proc.height)
+	}
+
+	tracker.SetFramePreprocessor(nil)
+	tracker.processFrame()
+	if proc.width != 64 || proc.height != 48 {
+		t.Errorf("expected the original 64x48 frame, got %dx%d", proc.width, proc.height)
+	}
+}
+
+func TestTrackerDataTransform(t *testing.T) {
+	cfg := config.Default()
+	cfg.Tracking.SmoothingFactor = 1
+	tracker, err := NewTracker(cfg)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer tracker.Close()
+
+	proc := &fixedProcessor{data: &TrackingData{Face: &FaceData{BlendShapes: map[string]float64{"jawOpen": 0.3}}}}
+	if err := tracker.SetProcessor(proc); err != nil {
+		t.Fatalf("failed to set processor: %v", err)
+	}
+	ch := tracker.Subscribe()
+
+	tracker.SetDataTransform(func(data *TrackingData) *TrackingData {
+		data.Face.BlendShapes["cheekPuff"] = 0.8
+		return data
+	})
+	tracker.processFrame()
+	select {
+	case data := <-ch:
+		shapes := data.Face.BlendShapes
+		if shapes["cheekPuff"] != 0.8 || shapes["jawOpen"] != 0.3 {
+			t.Errorf("expected the injected cheekPuff next to jawOpen, got %v", shapes)
+		}
+	default:
+		t.Fatal("expected a frame")
+	}
+
+	// Returning nil drops the frame
+	tracker.SetDataTransform(func(*TrackingData) *TrackingData { return nil })
+	tracker.processFrame()
+	select {
+	case data := <-ch:
+		t.Errorf("expected the frame to be dropped, got %+v", data)
+	default:
+	}
+}
+
+func TestTrackerSetModalityEnabled(t *testing.T) {
+	cfg := config.Default()
+	cfg.Tracking.SmoothingFactor = 1
+	tracker, err := NewTracker(cfg)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer tracker.Close()
+
+	proc := &fixedProcessor{data: &TrackingData{
+		Face:     stubFace(0, 0),
+		LeftHand: testHand(true, 1),
+	}}
+	if err := tracker.SetProcessor(proc); err != nil {
+		t.Fatalf("failed to set processor: %v", err)
+	}
+	ch := tracker.Subscribe()
+	next := func() *TrackingData {
+		t.Helper()
+		tracker.processFrame()
+		select {
+		case data := <-ch:
+			return data
+		default:
+			t.Fatal("expected a frame")
+			return nil
+		}
+	}
+
+	if data := next(); data.LeftHand == nil {
+		t.Fatal("expected hand data while enabled")
+	}
+
+	if err := tracker.SetModalityEnabled(ModalityHands, false); err != nil {
+		t.Fatalf("failed to disable hands: %v", err)
+	}
+	// One frame puts the hands at rest, then they stop
+	rest := DefaultRestPose().LeftHand
+	if data := next(); data.LeftHand == nil || data.LeftHand.Landmarks[HandIndexTip] != rest.Landmarks[HandIndexTip] {
+		t.Errorf("expected the rest hand after disabling, got %+v", data.LeftHand)
+	}
+	for i := 0; i < 3; i++ {
+		data := next()
+		if data.LeftHand != nil || data.RightHand != nil {
+			t.Errorf("frame %d: expected no hand data while disabled", i)
+		}
+		if data.Face == nil {
+			t.Errorf("frame %d: expected the face to keep tracking", i)
+		}
+	}
+	if tracker.Config().Tracking.EnableHands {
+		t.Error("expected the config to report hands disabled")
+	}
+
+	if err := tracker.SetModalityEnabled(ModalityHands, true); err != nil {
+		t.Fatalf("failed to enable hands: %v", err)
+	}
+	if data := next(); data.LeftHand == nil || data.LeftHand.Landmarks[HandIndexTip] != proc.data.LeftHand.Landmarks[HandIndexTip] {
+		t.Errorf("expected tracked hand data after re-enabling, got %+v", data.LeftHand)
+	}
+
+	if err := tracker.SetModalityEnabled(Modality(0), false); err == nil {
+		t.Error("expected error for an unknown modality")
+	}
+}
+
+func TestTrackerDisableModalityByConfig(t *testing.T) {
+	tests := []struct {
+		name    string
+		disable func(tracker *Tracker) error
+	}{
+		{"ApplyConfig", func(tracker *Tracker) error {
+			cfg := *tracker.Config()
+			cfg.Tracking.EnableHands = false
+			return tracker.ApplyConfig(&cfg)
+		}},
+		{"SetTrackingConfig", func(tracker *Tracker) error {
+			tracking := tracker.Config().Tracking
+			tracking.EnableHands = false
+			return tracker.SetTrackingConfig(tracking)
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := config.Default()
+			cfg.Tracking.SmoothingFactor = 1
+			tracker, err := NewTracker(cfg)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			defer tracker.Close()
+
+			proc := &fixedProcessor{data: &TrackingData{LeftHand: testHand(true, 1)}}
+			if err := tracker.SetProcessor(proc); err != nil {
+				t.Fatalf("failed to set processor: %v", err)
+			}
+			ch := tracker.Subscribe()
+			tracker.processFrame()
+			if data := <-ch; data.LeftHand == nil {
+				t.Fatal("expected hand data while enabled")
+			}
+
+			// Disabling hands through the config puts them at rest, like
+			// SetModalityEnabled, instead of freezing them
+			if err := tt.disable(tracker); err != nil {
+				t.Fatalf("failed to disable hands: %v", err)
+			}
+			rest := DefaultRestPose().LeftHand
+			tracker.processFrame()
+			if data := <-ch; data.LeftHand == nil || data.LeftHand.Landmarks[HandIndexTip] != rest.Landmarks[HandIndexTip] {
+				t.Errorf("expected the rest hand after disabling, got %+v", data.LeftHand)
+			}
+			tracker.processFrame()
+			if data := <-ch; data.LeftHand != nil {
+				t.Errorf("expected no hand data while disabled, got %+v", data.LeftHand)
+			}
+		})
+	}
+}
+
+// queueCamera is a TimestampedSource replaying queued 1x1 frames whose
+// first byte is their position in the queue, captured age ago.
+type queueCamera struct {
+	clock *FakeClock
+	ages  []time.Duration
+	reads int
+}
+
+func (c *queueCamera) Open(deviceID, width, height, fps int) error { return nil }
+
+func (c *queueCamera) Read() ([]byte, int, int, error) {
+	frame, width, height, _, err := c.ReadTimestamped()
+	return frame, width, height, err
+}
+
+func (c *queueCamera) ReadTimestamped() ([]byte, int, int, time.Time, error) {
+	i := c.reads
+	if i >= len(c.ages) {
+		i = len(c.ages) - 1
+	}
+	c.reads++
+	return []byte{byte(i), 0, 0}, 1, 1, c.clock.Now().Add(-c.ages[i]), nil
+}
+
+func (c *queueCamera) Close() error { return nil }
+
+func TestTrackerSkipsStaleFrames(t *testing.T) {
+	cfg := config.Default()
+	cfg.Tracking.MaxFrameAgeMS = 100
+	tracker, err := NewTracker(cfg)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer tracker.Close()
+
+	clock := NewFakeClock(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC))
+	tracker.SetClock(clock)
+	proc := &frameProcessor{}
+	if err := tracker.SetProcessor(proc); err != nil {
+		t.Fatalf("failed to set processor: %v", err)
+	}
+
+	// Two queued frames are too old; the third is fresh
+	camera := &queueCamera{clock: clock, ages: []time.Duration{
+		500 * time.Millisecond, 300 * time.Millisecond, 10 * time.Millisecond,
+	}}
+	if err := tracker.SetCameraSource(camera); err != nil {
+		t.Fatalf("failed to set camera: %v", err)
+	}
+	tracker.processFrame()
+	if camera.reads != 3 || len(proc.frame) == 0 || proc.frame[0] != 2 {
+		t.Errorf("expected the fresh third frame after 3 reads, got frame %v after %d reads", proc.frame, camera.reads)
+	}
+
+	// A source whose frames are always old still yields a frame
+	camera = &queueCamera{clock: clock, ages: []time.Duration{time.Second}}
+	if err := tracker.SetCameraSource(camera); err != nil {
+		t.Fatalf("failed to set camera: %v", err)
+	}
+	tracker.processFrame()
+	if camera.reads != maxStaleReads+1 {
+		t.Errorf("expected %d reads for a stale source, got %d", maxStaleReads+1, camera.reads)
+	}
+
+	// Without a limit the first frame is used
+	tracking := cfg.Tracking
+	tracking.MaxFrameAgeMS = 0
+	if err := tracker.SetTrackingConfig(tracking); err != nil {
+		t.Fatalf("failed to update config: %v", err)
+	}
+	camera = &queueCamera{clock: clock, ages: []time.Duration{time.Second, 0}}
+	if err := tracker.SetCameraSource(camera); err != nil {
+		t.Fatalf("failed to set camera: %v", err)
+	}
+	tracker.processFrame()
+	if camera.reads != 1 || proc.frame[0] != 0 {
+		t.Errorf("expected the first frame without a limit, got frame %v after %d reads", proc.frame, camera.reads)
+	}
+}
+
+func TestDownscaleSize(t *testing.T) {
+	tests := []struct {
+		width, height, maxDim int
+		wantW, wantH          int
+	}{
+		{1920, 1080, 640, 640, 360},
+		{720, 1280, 640, 360, 640}, // Portrait: the height is the long edge
+		{640, 480, 640, 640, 480},  // Already small enough
+		{320, 240, 640, 320, 240},  // Never upscaled
+		{1280, 720, 0, 1280, 720},  // No limit
+		{4000, 10, 640, 640, 2},    // Rounded
+		{10000, 1, 640, 640, 1},    // At least a pixel
+	}
+	for _, tt := range tests {
+		w, h := downscaleSize(tt.width, tt.height, tt.maxDim)
+		if w != tt.wantW || h != tt.wantH {
+			t.Errorf("downscaleSize(%d, %d, %d) = %dx%d, want %dx%d",
+				tt.width, tt.height, tt.maxDim, w, h, tt.wantW, tt.wantH)
+		}
+	}
+}
+
+func TestDownscaleKeepsNormalizedLandmarks(t *testing.T) {
+	// A landmark found in the downscaled frame lands on the same spot of
+	// the full frame, since both are normalized by their own size
+	lm := Landmark{Point: Point3D{X: 0.25, Y: 0.75, Z: -0.1}}
+	w, h := downscaleSize(1920, 1080, 640)
+	scale := 1920.0 / float64(w)
+
+	small := lm.ToPixel(w, h)
+	full := lm.ToPixel(1920, 1080)
+	if !pointsClose(small.Scale(scale), full) {
+		t.Errorf("expected %+v scaled by %f to match %+v", small, scale, full)
+	}
+}
+
+func BenchmarkTrackerDownscaleFrame(b *testing.B) {
+	tracker, err := NewTracker(nil)
+	if err != nil {
+		b.Fatalf("unexpected error: %v", err)
+	}
+	defer tracker.Close()
+	frame := make([]byte, 1920*1080*3)
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, _, _, err := tracker.downscaleFrame(frame, 1920, 1080, 640); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func TestTrackerStats(t *testing.T) {
+	tracker, err := NewTracker(nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer tracker.Close()
+
+	cfg := DefaultStubConfig()
+	cfg.EnableHands = false
+	proc := &toggleProcessor{stub: NewStubProcessor(cfg), enabled: true}
+	if err := tracker.SetProcessor(proc); err != nil {
+		t.Fatalf("failed to set processor: %v", err)
+	}
+	if err := tracker.SetVMCSender(failingSender{}); err != nil {
+		t.Fatalf("failed to set sender: %v", err)
+	}
+	if err := tracker.AddSender(&recordingSender{}); err != nil {
+		t.Fatalf("failed to add sender: %v", err)
+	}
+
+	if stats := tracker.Stats(); stats.FrameNumber != 0 || !stats.LastFrame.IsZero() {
+		t.Errorf("expected empty stats before the first frame, got %+v", stats)
+	}
+
+	for i := 0; i < 3; i++ {
+		tracker.processFrame()
+		time.Sleep(5 * time.Millisecond)
+	}
+
+	stats := tracker.Stats()
+	if stats.FrameNumber != 3 {
+		t.Errorf("expected frame number 3, got %d", stats.FrameNumber)
+	}
+	if stats.SendErrors != 3 {
+		t.Errorf("expected 3 send errors, got %d", stats.SendErrors)
+	}
+	wantSenders := []SenderStats{{Name: "vmc", SendErrors: 3}, {Name: "sender1"}}
+	if !reflect.DeepEqual(stats.Senders, wantSenders) {
+		t.Errorf("expected sender stats %+v, got %+v", wantSenders, stats.Senders)
+	}
+	if stats.FPS <= 0 {
+		t.Errorf("expected positive FPS, got %f", stats.FPS)
+	}
+	if !stats.FaceDetected || !stats.PoseDetected {
+		t.Error("expected face and pose to be detected")
+	}
+	if stats.LeftHandDetected || stats.RightHandDetected {
+		t.Error("expected hands not to be detected")
+	}
+
+	// The rest pose doesn't count as a detection
+	proc.enabled = false
+	tracker.SetRestPose(DefaultRestPose(), 0)
+	tracker.processFrame()
+
+	stats = tracker.Stats()
+	if stats.FrameNumber != 4 {
+		t.Errorf("expected frame number 4, got %d", stats.FrameNumber)
+	}
+	if stats.FaceDetected || stats.PoseDetected {
+		t.Error("expected no modality to be detected while resting")
+	}
+}
+
+func TestTrackerFakeClock(t *testing.T) {
+	tracker, err := NewTracker(nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer tracker.Close()
+
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	clock := NewFakeClock(start)
+	if err := tracker.SetClock(clock); err != nil {
+		t.Fatalf("failed to set clock: %v", err)
+	}
+	if err := tracker.SetProcessor(NewStubProcessor(DefaultStubConfig())); err != nil {
+		t.Fatalf("failed to set processor: %v", err)
+	}
+
+	ch := tracker.Subscribe()
+	if err := tracker.Start(); err != nil {
+		t.Fatalf("failed to start: %v", err)
+	}
+
+	interval := time.Second / time.Duration(config.Default().Camera.FPS)
+	var last *TrackingData
+	for i := 0; i < 5; i++ {
+		clock.Advance(interval)
+		select {
+		case last = <-ch:
+		case <-time.After(time.Second):
+			t.Fatalf("timeout waiting for frame %d", i+1)
+		}
+	}
+
+	if last.FrameNumber != 5 {
+		t.Errorf("expected FrameNumber 5, got %d", last.FrameNumber)
+	}
+	if want := start.Add(5 * interval); !last.Timestamp.Equal(want) {
+		t.Errorf("expected timestamp %v, got %v", want, last.Timestamp)
+	}
+	select {
+	case data := <-ch:
+		t.Errorf("expected no frame without a tick, got frame %d", data.FrameNumber)
+	default:
+	}
+
+	if err := tracker.SetClock(realClock{}); err == nil {
+		t.Error("expected error setting clock while running")
+	}
+}
+
+func TestTrackerMaxOutputFPS(t *testing.T) {
+	tests := []struct {
+		name     string
+		maxFPS   int
+		inputFPS int
+		min, max int
+	}{
+		// One second of input; the first frame is always output
+		{"capped", 30, 1000, 30, 31},
+		{"input slower than cap", 30, 20, 20, 20},
+		{"unlimited", 0, 200, 200, 200},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := config.Default()
+			cfg.Tracking.MaxOutputFPS = tt.maxFPS
+			tracker, err := NewTracker(cfg)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			defer tracker.Close()
+
+			clock := NewFakeClock(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC))
+			sender := &recordingSender{}
+			if err := tracker.SetClock(clock); err != nil {
+				t.Fatalf("failed to set clock: %v", err)
+			}
+			if err := tracker.SetProcessor(NewStubProcessor(DefaultStubConfig())); err != nil {
+				t.Fatalf("failed to set processor: %v", err)
+			}
+			if err := tracker.SetVMCSender(sender); err != nil {
+				t.Fatalf("failed to set sender: %v", err)
+			}
+			ch := tracker.Subscribe()
+
+			var received int
+			step := time.Second / time.Duration(tt.inputFPS)
+			for i := 0; i < tt.inputFPS; i++ {
+				tracker.processFrame()
+				clock.Advance(step)
+				select {
+				case <-ch:
+					received++
+				default:
+				}
+			}
+
+			if received < tt.min || received > tt.max {
+				t.Errorf("expected %d-%d frames to subscribers, got %d", tt.min, tt.max, received)
+			}
+			if len(sender.sent) != received {
+				t.Errorf("expected sender to get the same %d frames, got %d", received, len(sender.sent))
+			}
+		})
+	}
+}
+
+// fixedProcessor returns a copy of the same tracking data for every frame.
+type fixedProcessor struct {
+	data *TrackingData
+}
+
+func (p *fixedProcessor) Process(ctx context.Context, frame []byte, width, height int) (*TrackingData, error) {
+	return p.data.Clone(), nil
+}
+
+func (p *fixedProcessor) Close() error { return nil }
+
+// sharedProcessor returns the same tracking data for every frame, like a
+// processor that reuses its result.
+type sharedProcessor struct {
+	data *TrackingData
+}
+
+func (p *sharedProcessor) Process(ctx context.Context, frame []byte, width, height int) (*TrackingData, error) {
+	return p.data, nil
+}
+
+func (p *sharedProcessor) Close() error { return nil }
+
+// TestTrackerConcurrentSubscribers checks that subscribers can modify the
+// frames they receive while the tracker runs. Run it with go test -race.
+func TestTrackerConcurrentSubscribers(t *testing.T) {
+	cfg := config.Default()
+	cfg.Tracking.MirrorLandmarks = true
+	tracker, err := NewTracker(cfg)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer tracker.Close()
+
+	stub, _ := NewStubProcessor(DefaultStubConfig()).Process(context.Background(), nil, 0, 0)
+	want := stub.Clone()
+	if err := tracker.SetProcessor(&sharedProcessor{data: stub}); err != nil {
+		t.Fatalf("failed to set processor: %v", err)
+	}
+
+	const subscribers, frames = 3, 5
+	var wg sync.WaitGroup
+	for i := 0; i < subscribers; i++ {
+		ch := tracker.Subscribe()
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for n := 0; n < frames; n++ {
+				select {
+				case data := <-ch:
+					// Read and scribble over everything
+					for _, lm := range data.Pose.Landmarks {
+						_ = lm.Point.X
+					}
+					data.Face.Landmarks[0].Point.X = -1
+					data.Face.BlendShapes["jawOpen"] = 1
+					data.LeftHand.Landmarks = nil
+				case <-time.After(time.Second):
+					t.Error("timeout waiting for tracking data")
+					return
+				}
+			}
+		}()
+	}
+
+	if err := tracker.Start(); err != nil {
+		t.Fatalf("failed to start: %v", err)
+	}
+	wg.Wait()
+	if err := tracker.Stop(); err != nil {
+		t.Fatalf("failed to stop: %v", err)
+	}
+
+	if !stub.Equal(want) {
+		t.Error("expected the processor's data not to be modified")
+	}
+	if latest := tracker.LatestData(); latest == nil || latest.Face.Landmarks[0].Point.X < 0 || latest.LeftHand.Landmarks == nil {
+		t.Error("expected subscriber changes not to reach the tracker's data")
+	}
+}
+
+func TestTrackerBlendShapeCurves(t *testing.T) {
+	cfg := config.Default()
+	cfg.Tracking.SmoothingFactor = 1
+	cfg.BlendShapeCurves = map[string]config.BlendShapeCurve{"jawOpen": {Gain: 2}}
+	tracker, err := NewTracker(cfg)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer tracker.Close()
+
+	proc := &fixedProcessor{data: &TrackingData{Face: &FaceData{BlendShapes: map[string]float64{"jawOpen": 0.3}}}}
+	if err := tracker.SetProcessor(proc); err != nil {
+		t.Fatalf("failed to set processor: %v", err)
+	}
+
+	tracker.processFrame()
+	if got := tracker.LatestData().Face.BlendShapes["jawOpen"]; got < 0.6-1e-9 || got > 0.6+1e-9 {
+		t.Errorf("expected shaped jawOpen 0.6, got %f", got)
+	}
+
+	// Curves can be removed at runtime
+	cfg2 := config.Default()
+	cfg2.Tracking.SmoothingFactor = 1
+	if err := tracker.ApplyConfig(cfg2); err != nil {
+		t.Fatalf("failed to apply config: %v", err)
+	}
+	tracker.processFrame()
+	if got := tracker.LatestData().Face.BlendShapes["jawOpen"]; got != 0.3 {
+		t.Errorf("expected unshaped jawOpen 0.3, got %f", got)
+	}
+}
+
+func TestTrackerCalibrateNeutral(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "calibration.toml")
+	cfg := config.Default()
+	cfg.Tracking.SmoothingFactor = 1
+	cfg.Tracking.CalibrationFile = path
+	tracker, err := NewTracker(cfg)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer tracker.Close()
+
+	if err := tracker.CalibrateNeutral(time.Second); !errors.Is(err, ErrTrackerStopped) {
+		t.Errorf("expected ErrTrackerStopped before Start, got %v", err)
+	}
+	body := BodyCalibration{ArmSpan: 1.6, ShoulderWidth: 0.4}
+	if err := tracker.SaveBodyCalibration(body); err != nil {
+		t.Fatalf("failed to save body calibration: %v", err)
+	}
+
+	clock := NewFakeClock(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC))
+	if err := tracker.SetClock(clock); err != nil {
+		t.Fatalf("failed to set clock: %v", err)
+	}
+	// A resting face with a constant small offset
+	proc := &fixedProcessor{data: &TrackingData{Face: &FaceData{BlendShapes: map[string]float64{"jawOpen": 0.1, "mouthClose": 0.05}}}}
+	if err := tracker.SetProcessor(proc); err != nil {
+		t.Fatalf("failed to set processor: %v", err)
+	}
+
+	ch := tracker.Subscribe()
+	if err := tracker.Start(); err != nil {
+		t.Fatalf("failed to start: %v", err)
+	}
+
+	interval := time.Second / time.Duration(cfg.Camera.FPS)
+	next := func() *TrackingData {
+		t.Helper()
+		clock.Advance(interval)
+		select {
+		case data := <-ch:
+			return data
+		case <-time.After(time.Second):
+			t.Fatal("timeout waiting for frame")
+			return nil
+		}
+	}
+
+	done := make(chan error, 1)
+	go func() { done <- tracker.CalibrateNeutral(200 * time.Millisecond) }()
+
+	// Only step frames once the capture has started, or they may all pass
+	// before it does
+	for calibrating := false; !calibrating; {
+		tracker.mu.RLock()
+		calibrating = tracker.calibration != nil
+		tracker.mu.RUnlock()
+		time.Sleep(time.Millisecond)
+	}
+	for i := 0; i < 30 && tracker.NeutralBaseline() == nil; i++ {
+		next()
+	}
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Fatalf("calibration failed: %v", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("timeout waiting for calibration")
+	}
+
+	data := next()
+	for name, value := range data.Face.BlendShapes {
+		if value > 1e-9 {
+			t.Errorf("%s: expected offset to be removed, got %f", name, value)
+		}
+	}
+
+	// Expressions above the baseline still come through
+	proc.data = &TrackingData{Face: &FaceData{BlendShapes: map[string]float64{"jawOpen": 0.6, "mouthClose": 0.05}}}
+	for i := 0; i < 30; i++ {
+		data = next()
+	}
+	if got := data.Face.BlendShapes["jawOpen"]; got < 0.5-1e-3 || got > 0.5+1e-3 {
+		t.Errorf("expected jawOpen to settle at 0.5, got %f", got)
+	}
+
+	// The baseline is persisted and loaded by new trackers
+	reloaded, err := NewTracker(cfg)
+	if err != nil {
+		t.Fatalf("failed to create tracker: %v", err)
+	}
+	defer reloaded.Close()
+	if got := reloaded.NeutralBaseline()["jawOpen"]; got < 0.1-1e-9 || got > 0.1+1e-9 {
+		t.Errorf("expected persisted jawOpen baseline 0.1, got %f", got)
+	}
+
+	// The body calibration saved before is kept
+	saved, err := LoadCalibration(path)
+	if err != nil {
+		t.Fatalf("failed to load calibration: %v", err)
+	}
+	if saved.Body == nil || *saved.Body != body {
+		t.Errorf("expected body calibration %+v to be kept, got %+v", body, saved.Body)
+	}
+}
+
+func TestTrackerSaveBodyCalibration(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "calibration.toml")
+	neutral := map[string]float64{"jawOpen": 0.1}
+	if err := (&Calibration{NeutralBlendShapes: neutral}).Save(path); err != nil {
+		t.Fatalf("failed to save calibration: %v", err)
+	}
+
+	cfg := config.Default()
+	cfg.Tracking.CalibrationFile = path
+	tracker, err := NewTracker(cfg)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer tracker.Close()
+
+	body := BodyCalibration{ArmSpan: 1.7, ShoulderWidth: 0.42}
+	if err := tracker.SaveBodyCalibration(body); err != nil {
+		t.Fatalf("failed to save body calibration: %v", err)
+	}
+	saved, err := LoadCalibration(path)
+	if err != nil {
+		t.Fatalf("failed to load calibration: %v", err)
+	}
+	if saved.Body == nil || *saved.Body != body {
+		t.Errorf("expected body calibration %+v, got %+v", body, saved.Body)
+	}
+	if !reflect.DeepEqual(saved.NeutralBlendShapes, neutral) {
+		t.Errorf("expected the neutral baseline to be kept, got %v", saved.NeutralBlendShapes)
+	}
+
+	// Without a calibration file there is nothing to save
+	tracking := tracker.Config().Tracking
+	tracking.CalibrationFile = ""
+	if err := tracker.SetTrackingConfig(tracking); err != nil {
+		t.Fatalf("failed to update config: %v", err)
+	}
+	if err := tracker.SaveBodyCalibration(body); err != nil {
+		t.Errorf("expected no error without a calibration file, got %v", err)
+	}
+}
+
+func TestTrackerDrain(t *testing.T) {
+	tracker, err := NewTracker(nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer tracker.Close()
+
+	clock := NewFakeClock(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC))
+	sender := &recordingSender{}
+	if err := tracker.SetClock(clock); err != nil {
+		t.Fatalf("failed to set clock: %v", err)
+	}
+	if err := tracker.SetProcessor(NewStubProcessor(DefaultStubConfig())); err != nil {
+		t.Fatalf("failed to set processor: %v", err)
+	}
+	if err := tracker.SetVMCSender(sender); err != nil {
+		t.Fatalf("failed to set sender: %v", err)
+	}
+	added := &recordingSender{}
+	if err := tracker.AddSender(added); err != nil {
+		t.Fatalf("failed to add sender: %v", err)
+	}
+	if err := tracker.Start(); err != nil {
+		t.Fatalf("failed to start: %v", err)
+	}
+
+	interval := time.Second / time.Duration(config.Default().Camera.FPS)
+	for i := 0; i < 3; i++ {
+		clock.Advance(interval)
+	}
+
+	if err := tracker.Drain(time.Second); err != nil {
+		t.Fatalf("drain failed: %v", err)
+	}
+	if tracker.State() != StateStopped {
+		t.Errorf("expected stopped state, got %s", tracker.State())
+	}
+
+	if len(sender.sent) != 4 {
+		t.Fatalf("expected 3 frames and a final frame, got %d", len(sender.sent))
+	}
+	final := sender.sent[3]
+	if final.FrameNumber != 4 {
+		t.Errorf("expected final FrameNumber 4, got %d", final.FrameNumber)
+	}
+	if final.Face == nil || final.Face.HeadRotation != (Quaternion{W: 1}) {
+		t.Errorf("expected final frame in the rest pose, got %+v", final.Face)
+	}
+	if len(added.sent) != 4 || added.sent[3].FrameNumber != 4 {
+		t.Errorf("expected the added sender to get the final frame too, got %d frames", len(added.sent))
+	}
+
+	if err := tracker.Drain(time.Second); err != ErrTrackerStopped {
+		t.Errorf("expected ErrTrackerStopped draining a stopped tracker, got %v", err)
+	}
+}
+
+// blockingSender blocks every Send until release is closed.
+type blockingSender struct {
+	release chan struct{}
+}
+
+func (s *blockingSender) Send(data *TrackingData) error {
+	<-s.release
+	return nil
+}
+
+func (s *blockingSender) Close() error { return nil }
+
+func TestTrackerDrainWithoutVMCSender(t *testing.T) {
+	tracker, err := NewTracker(nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer tracker.Close()
+
+	sender := &recordingSender{}
+	if err := tracker.AddSender(sender); err != nil {
+		t.Fatalf("failed to add sender: %v", err)
+	}
+	if err := tracker.Start(); err != nil {
+		t.Fatalf("failed to start: %v", err)
+	}
+
+	if err := tracker.Drain(time.Second); err != nil {
+		t.Fatalf("drain failed: %v", err)
+	}
+	if len(sender.sent) != 1 || sender.sent[0].Face == nil {
+		t.Errorf("expected the added sender to get the final rest pose, got %d frames", len(sender.sent))
+	}
+}
+
+func TestTrackerDrainTimeout(t *testing.T) {
+	tracker, err := NewTracker(nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer tracker.Close()
+
+	sender := &blockingSender{release: make(chan struct{})}
+	defer close(sender.release)
+	if err := tracker.SetVMCSender(sender); err != nil {
+		t.Fatalf("failed to set sender: %v", err)
+	}
+	if err := tracker.Start(); err != nil {
+		t.Fatalf("failed to start: %v", err)
+	}
+
+	start := time.Now()
+	if err := tracker.Drain(50 * time.Millisecond); err == nil {
+		t.Error("expected error when the final send hangs")
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("expected drain to give up after the timeout, took %v", elapsed)
+	}
+	if tracker.State() != StateStopped {
+		t.Errorf("expected stopped state, got %s", tracker.State())
+	}
+}
+
+// gateProcessor signals entered when it starts a frame and returns face
+// data once release is closed, ignoring its context.
+type gateProcessor struct {
+	entered chan struct{}
+	release chan struct{}
+}
+
+func (p *gateProcessor) Process(ctx context.Context, frame []byte, width, height int) (*TrackingData, error) {
+	select {
+	case p.entered <- struct{}{}:
+	default:
+	}
+	<-p.release
+	return &TrackingData{Face: stubFace(0, 0)}, nil
+}
+
+func (p *gateProcessor) Close() error { return nil }
+
+func TestTrackerDrainTimeoutSkipsFinalFrame(t *testing.T) {
+	tracker, err := NewTracker(nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	clock := NewFakeClock(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC))
+	proc := &gateProcessor{entered: make(chan struct{}, 1), release: make(chan struct{})}
+	sender := &recordingSender{}
+	if err := tracker.SetClock(clock); err != nil {
+		t.Fatalf("failed to set clock: %v", err)
+	}
+	if err := tracker.SetProcessor(proc); err != nil {
+		t.Fatalf("failed to set processor: %v", err)
+	}
+	if err := tracker.SetVMCSender(sender); err != nil {
+		t.Fatalf("failed to set sender: %v", err)
+	}
+	if err := tracker.Start(); err != nil {
+		t.Fatalf("failed to start: %v", err)
+	}
+
+	// The in-flight frame outlasts the drain timeout
+	clock.Advance(time.Second / time.Duration(config.Default().Camera.FPS))
+	<-proc.entered
+	if err := tracker.Drain(20 * time.Millisecond); err == nil {
+		t.Fatal("expected the drain to time out")
+	}
+	close(proc.release)
+
+	// Start waits for the drain to finish, which then skips the final frame
+	if err := tracker.Start(); err != nil {
+		t.Fatalf("failed to restart: %v", err)
+	}
+	if err := tracker.Close(); err != nil {
+		t.Fatalf("failed to close: %v", err)
+	}
+	for i, data := range sender.sent {
+		if data.LeftHand != nil {
+			t.Errorf("frame %d: expected no final rest pose frame after a timed-out drain", i)
+		}
+	}
+}
+
+func TestTrackerSenders(t *testing.T) {
+	tracker, err := NewTracker(nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if err := tracker.SetProcessor(NewStubProcessor(DefaultStubConfig())); err != nil {
+		t.Fatalf("failed to set processor: %v", err)
+	}
+	senders := []*recordingSender{{}, {}, {}}
+	if err := tracker.SetVMCSender(senders[0]); err != nil {
+		t.Fatalf("failed to set VMC sender: %v", err)
+	}
+	for _, s := range senders[1:] {
+		if err := tracker.AddSender(s); err != nil {
+			t.Fatalf("failed to add sender: %v", err)
+		}
+	}
+
+	for i := 0; i < 3; i++ {
+		tracker.processFrame()
+	}
+	for i, s := range senders {
+		if len(s.sent) != 3 {
+			t.Errorf("sender %d: expected 3 frames, got %d", i, len(s.sent))
+		}
+	}
+
+	// A replaced VMC sender stops receiving frames; others are unaffected
+	replacement := &recordingSender{}
+	if err := tracker.SetVMCSender(replacement); err != nil {
+		t.Fatalf("failed to replace VMC sender: %v", err)
+	}
+	if err := tracker.RemoveSender(senders[1]); err != nil {
+		t.Fatalf("failed to remove sender: %v", err)
+	}
+	if err := tracker.RemoveSender(senders[1]); err == nil {
+		t.Error("expected error removing an unregistered sender")
+	}
+	tracker.processFrame()
+	if len(senders[0].sent) != 3 || len(senders[1].sent) != 3 {
+		t.Errorf("expected removed senders to get no more frames, got %d and %d",
+			len(senders[0].sent), len(senders[1].sent))
+	}
+	if len(senders[2].sent) != 4 || len(replacement.sent) != 1 {
+		t.Errorf("expected registered senders to get the frame, got %d and %d",
+			len(senders[2].sent), len(replacement.sent))
+	}
+
+	if err := tracker.Close(); err != nil {
+		t.Fatalf("failed to close: %v", err)
+	}
+	if !senders[2].closed || !replacement.closed {
+		t.Error("expected registered senders to be closed")
+	}
+	if senders[1].closed {
+		t.Error("expected removed sender to be left open")
+	}
+}
+
+func TestTrackerSendEveryN(t *testing.T) {
+	tracker, err := NewTracker(nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer tracker.Close()
+
+	if err := tracker.SetProcessor(NewStubProcessor(DefaultStubConfig())); err != nil {
+		t.Fatalf("failed to set processor: %v", err)
+	}
+	every, decimated := &recordingSender{}, &recordingSender{}
+	for _, s := range []*recordingSender{every, decimated} {
+		if err := tracker.AddSender(s); err != nil {
+			t.Fatalf("failed to add sender: %v", err)
+		}
+	}
+	if err := tracker.SetSendEveryN(decimated, 3); err != nil {
+		t.Fatalf("failed to set send every n: %v", err)
+	}
+	ch := tracker.Subscribe()
+
+	for i := 0; i < 9; i++ {
+		tracker.processFrame()
+		<-ch
+	}
+	if len(every.sent) != 9 {
+		t.Errorf("expected the other sender to get all 9 frames, got %d", len(every.sent))
+	}
+	if len(decimated.sent) != 3 {
+		t.Fatalf("expected 3 of 9 frames, got %d", len(decimated.sent))
+	}
+	for i, data := range decimated.sent {
+		if want := uint64(3 * (i + 1)); data.FrameNumber != want {
+			t.Errorf("expected frame %d, got %d", want, data.FrameNumber)
+		}
+	}
+
+	// Back to every frame
+	if err := tracker.SetSendEveryN(decimated, 1); err != nil {
+		t.Fatalf("failed to reset send every n: %v", err)
+	}
+	tracker.processFrame()
+	if len(decimated.sent) != 4 {
+		t.Errorf("expected frame 10 to be sent with n = 1, got %d frames", len(decimated.sent))
+	}
+
+	if err := tracker.SetSendEveryN(decimated, 0); err == nil {
+		t.Error("expected error for n < 1")
+	}
+	if err := tracker.SetSendEveryN(&recordingSender{}, 2); err == nil {
+		t.Error("expected error for an unregistered sender")
+	}
+
+	// A sender of a non-comparable type works alongside decimated ones
+	var funcSent int
+	if err := tracker.AddSender(funcSender{send: func(*TrackingData) error { funcSent++; return nil }}); err != nil {
+		t.Fatalf("failed to add sender: %v", err)
+	}
+	if err := tracker.SetSendEveryN(decimated, 2); err != nil {
+		t.Fatalf("failed to set send every n: %v", err)
+	}
+	tracker.processFrame()
+	if funcSent != 1 {
+		t.Errorf("expected the func sender to get the frame, got %d", funcSent)
+	}
+
+	// The VMC sender's setting survives its replacement
+	first, second := &recordingSender{}, &recordingSender{}
+	if err := tracker.SetVMCSender(first); err != nil {
+		t.Fatalf("failed to set VMC sender: %v", err)
+	}
+	if err := tracker.SetSendEveryN(first, 1000); err != nil {
+		t.Fatalf("failed to set send every n: %v", err)
+	}
+	if err := tracker.SetVMCSender(second); err != nil {
+		t.Fatalf("failed to set VMC sender: %v", err)
+	}
+	tracker.processFrame()
+	if len(second.sent) != 0 {
+		t.Errorf("expected the replacement VMC sender to stay decimated, got %d frames", len(second.sent))
+	}
+}
+
+// funcSender is a Sender of a non-comparable type.
+type funcSender struct {
+	send func(*TrackingData) error
+}
+
+func (s funcSender) Send(data *TrackingData) error { return s.send(data) }
+func (funcSender) Close() error                    { return nil }
+
+// failingCloseSender fails to close.
+type failingCloseSender struct {
+	recordingSender
+}
+
+func (s *failingCloseSender) Close() error { return errors.New("close failed") }
+
+func TestTrackerCloseAggregatesSenderErrors(t *testing.T) {
+	tracker, err := NewTracker(nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	ok := &recordingSender{}
+	for _, s := range []Sender{&failingCloseSender{}, ok, &failingCloseSender{}} {
+		if err := tracker.AddSender(s); err != nil {
+			t.Fatalf("failed to add sender: %v", err)
+		}
+	}
+
+	err = tracker.Close()
+	if err == nil {
+		t.Fatal("expected close error")
+	}
+	if n := strings.Count(err.Error(), "close failed"); n != 2 {
+		t.Errorf("expected both sender errors, got %q", err)
+	}
+	if !ok.closed {
+		t.Error("expected the other sender to be closed")
+	}
+}
+
+func TestTrackerRecentFrames(t *testing.T) {
+	tracker, err := NewTracker(nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer tracker.Close()
+
+	if err := tracker.SetProcessor(NewStubProcessor(DefaultStubConfig())); err != nil {
+		t.Fatalf("failed to set processor: %v", err)
+	}
+	if frames := tracker.RecentFrames(); len(frames) != 0 {
+		t.Errorf("expected no frames by default, got %d", len(frames))
+	}
+	if err := tracker.SetRecentFramesCapacity(-1); err == nil {
+		t.Error("expected error for negative capacity")
+	}
+	if err := tracker.SetRecentFramesCapacity(5); err != nil {
+		t.Fatalf("failed to set capacity: %v", err)
+	}
+
+	for i := 0; i < 3; i++ {
+		tracker.processFrame()
+	}
+	if frames := tracker.RecentFrames(); len(frames) != 3 || frames[0].FrameNumber != 1 {
+		t.Fatalf("expected frames 1-3 before the ring fills, got %d frames", len(frames))
+	}
+
+	for i := 0; i < 9; i++ {
+		tracker.processFrame()
+	}
+	frames := tracker.RecentFrames()
+	if len(frames) != 5 {
+		t.Fatalf("expected 5 frames, got %d", len(frames))
+	}
+	for i, data := range frames {
+		if want := uint64(8 + i); data.FrameNumber != want {
+			t.Errorf("frame %d: expected FrameNumber %d, got %d", i, want, data.FrameNumber)
+		}
+	}
+
+	// Callers get their own copies
+	frames[4].FrameNumber = 0
+	if latest := tracker.LatestData(); latest.FrameNumber != 12 {
+		t.Errorf("expected LatestData unaffected, got FrameNumber %d", latest.FrameNumber)
+	}
+	if again := tracker.RecentFrames(); again[4].FrameNumber != 12 {
+		t.Errorf("expected RecentFrames unaffected, got FrameNumber %d", again[4].FrameNumber)
+	}
+
+	// Shrinking keeps the newest frames
+	if err := tracker.SetRecentFramesCapacity(2); err != nil {
+		t.Fatalf("failed to set capacity: %v", err)
+	}
+	frames = tracker.RecentFrames()
+	if len(frames) != 2 || frames[0].FrameNumber != 11 || frames[1].FrameNumber != 12 {
+		t.Errorf("expected frames 11 and 12 after shrinking, got %d frames", len(frames))
+	}
+}
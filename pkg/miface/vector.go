@@ -0,0 +1,52 @@
+package miface
+
+import "math"
+
+// Add returns p + q.
+func (p Point3D) Add(q Point3D) Point3D {
+	return Point3D{X: p.X + q.X, Y: p.Y + q.Y, Z: p.Z + q.Z}
+}
+
+// Sub returns p - q.
+func (p Point3D) Sub(q Point3D) Point3D {
+	return Point3D{X: p.X - q.X, Y: p.Y - q.Y, Z: p.Z - q.Z}
+}
+
+// Scale returns p * s.
+func (p Point3D) Scale(s float64) Point3D {
+	return Point3D{X: p.X * s, Y: p.Y * s, Z: p.Z * s}
+}
+
+// Dot returns the dot product of p and q.
+func (p Point3D) Dot(q Point3D) float64 {
+	return p.X*q.X + p.Y*q.Y + p.Z*q.Z
+}
+
+// Cross returns the cross product p × q.
+func (p Point3D) Cross(q Point3D) Point3D {
+	return Point3D{
+		X: p.Y*q.Z - p.Z*q.Y,
+		Y: p.Z*q.X - p.X*q.Z,
+		Z: p.X*q.Y - p.Y*q.X,
+	}
+}
+
+// Length returns the Euclidean length of p.
+func (p Point3D) Length() float64 {
+	return math.Sqrt(p.Dot(p))
+}
+
+// Normalize returns p scaled to unit length, or the zero vector if p is
+// (nearly) zero.
+func (p Point3D) Normalize() Point3D {
+	n := p.Length()
+	if n < 1e-12 {
+		return Point3D{}
+	}
+	return p.Scale(1 / n)
+}
+
+// Distance returns the Euclidean distance between a and b.
+func Distance(a, b Point3D) float64 {
+	return a.Sub(b).Length()
+}
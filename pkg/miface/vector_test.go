@@ -0,0 +1,78 @@
+package miface
+
+import (
+	"math"
+	"testing"
+)
+
+func TestPoint3DCrossOrthogonal(t *testing.T) {
+	pairs := [][2]Point3D{
+		{{X: 1}, {Y: 1}},
+		{{X: 1, Y: 2, Z: 3}, {X: -4, Y: 0.5, Z: 2}},
+		{{X: 0.1, Y: -0.7, Z: 0.2}, {X: 0.3, Y: 0.3, Z: -0.9}},
+	}
+	for _, p := range pairs {
+		c := p[0].Cross(p[1])
+		if d := c.Dot(p[0]); math.Abs(d) > 1e-12 {
+			t.Errorf("expected %v × %v orthogonal to the first, dot %v", p[0], p[1], d)
+		}
+		if d := c.Dot(p[1]); math.Abs(d) > 1e-12 {
+			t.Errorf("expected %v × %v orthogonal to the second, dot %v", p[0], p[1], d)
+		}
+	}
+
+	if c := (Point3D{X: 1}).Cross(Point3D{Y: 1}); c != (Point3D{Z: 1}) {
+		t.Errorf("expected X × Y = Z, got %v", c)
+	}
+}
+
+func TestPoint3DNormalize(t *testing.T) {
+	for _, p := range []Point3D{{X: 3, Y: 4}, {X: -0.01, Y: 0.02, Z: 0.5}, {Z: 1e6}} {
+		if l := p.Normalize().Length(); math.Abs(l-1) > 1e-12 {
+			t.Errorf("expected %v to normalize to unit length, got %v", p, l)
+		}
+	}
+	if n := (Point3D{}).Normalize(); n != (Point3D{}) {
+		t.Errorf("expected zero vector to stay zero, got %v", n)
+	}
+}
+
+func TestPoint3DArithmetic(t *testing.T) {
+	a := Point3D{X: 1, Y: 2, Z: 3}
+	b := Point3D{X: 4, Y: -1, Z: 0.5}
+
+	if got := a.Add(b); got != (Point3D{X: 5, Y: 1, Z: 3.5}) {
+		t.Errorf("expected Add to give (5, 1, 3.5), got %v", got)
+	}
+	if got := a.Sub(b); got != (Point3D{X: -3, Y: 3, Z: 2.5}) {
+		t.Errorf("expected Sub to give (-3, 3, 2.5), got %v", got)
+	}
+	if got := a.Scale(2); got != (Point3D{X: 2, Y: 4, Z: 6}) {
+		t.Errorf("expected Scale to give (2, 4, 6), got %v", got)
+	}
+	if got := a.Dot(b); got != 3.5 {
+		t.Errorf("expected Dot 3.5, got %v", got)
+	}
+	if got := (Point3D{X: 3, Y: 4}).Length(); got != 5 {
+		t.Errorf("expected Length 5, got %v", got)
+	}
+}
+
+func TestDistanceMatchesDistance(t *testing.T) {
+	// Pairs at avatar scale, where the Newton sqrt distance() used converges
+	pairs := [][2]Point3D{
+		{{}, {X: 3, Y: 4}},
+		{{X: 0, Y: 1.4, Z: 0}, {X: 0.2, Y: 1.45, Z: 0.02}},
+		{{X: -0.8, Y: 1.3, Z: 0.1}, {X: 0.8, Y: 1.3, Z: 0.1}},
+	}
+	for _, p := range pairs {
+		d := p[0].Sub(p[1])
+		legacy := sqrt(d.X*d.X + d.Y*d.Y + d.Z*d.Z)
+		if got := Distance(p[0], p[1]); math.Abs(got-legacy) > 1e-9 {
+			t.Errorf("expected Distance %v, got %v", legacy, got)
+		}
+		if got := distance(p[0], p[1]); got != Distance(p[0], p[1]) {
+			t.Errorf("expected distance to match Distance, got %v", got)
+		}
+	}
+}
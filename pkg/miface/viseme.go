@@ -0,0 +1,104 @@
+package miface
+
+import "math"
+
+// Mouth shape calibration for EstimateVisemes, relative to the distance
+// between the outer eye corners.
+const (
+	// visemeFullOpen is the inner lip gap over the mouth width of a fully
+	// open "A".
+	visemeFullOpen = 0.6
+	// visemeNeutralWidth is the mouth width at rest.
+	visemeNeutralWidth = 0.55
+	// visemeSpreadRange is the change in mouth width from rest to a full
+	// spread ("I") or full pucker ("U").
+	visemeSpreadRange = 0.15
+	// visemeActiveFrom is how far (in open or spread units) the mouth must
+	// move from rest for the visemes to reach full strength.
+	visemeActiveFrom = 0.3
+	// visemeSpread is the width of each vowel's region in shape space.
+	visemeSpread = 0.35
+)
+
+// visemeShapes are the mouth shapes of the five vowels: how open the mouth
+// is (0 to 1) and how spread (1) or rounded (-1) the lips are.
+var visemeShapes = []struct {
+	name         string
+	open, spread float64
+}{
+	{"A", 1.0, 0},
+	{"I", 0.15, 1.0},
+	{"U", 0.2, -1.0},
+	{"E", 0.5, 0.6},
+	{"O", 0.7, -0.7},
+}
+
+// EstimateVisemes estimates the Japanese vowel mouth shapes A, I, U, E and O
+// from the mouth landmarks, for lip-sync without audio. The weights map to
+// the VRM blend shapes of the same names; each is in [0, 1] and together
+// they sum to how far the mouth is from rest, reaching 1 once it is clearly
+// shaped. A closed, relaxed mouth gives all zeros.
+//
+// Openness is the inner lip gap relative to the mouth width, and spread
+// versus roundedness is the mouth width relative to the eye corners, so the
+// estimate doesn't depend on the face's size in the image. It returns nil if
+// the face has too few landmarks.
+func EstimateVisemes(face *FaceData) map[string]float64 {
+	if face == nil || len(face.Landmarks) <= FaceMouthLeft {
+		return nil
+	}
+
+	lm := face.Landmarks
+	eyes := Distance(lm[FaceLeftEyeOuter].Point, lm[FaceRightEyeOuter].Point)
+	width := Distance(lm[FaceMouthLeft].Point, lm[FaceMouthRight].Point)
+	gap := Distance(lm[FaceUpperLip].Point, lm[FaceLowerLip].Point)
+
+	visemes := make(map[string]float64, len(visemeShapes))
+	for _, v := range visemeShapes {
+		visemes[v.name] = 0
+	}
+	if eyes == 0 || width == 0 {
+		return visemes
+	}
+
+	open := min(gap/width/visemeFullOpen, 1)
+	spread := clampUnit((width/eyes - visemeNeutralWidth) / visemeSpreadRange)
+	strength := min(max(open, math.Abs(spread))/visemeActiveFrom, 1)
+	if strength == 0 {
+		return visemes
+	}
+
+	// Weight each vowel by how close the mouth is to its shape
+	var total float64
+	for _, v := range visemeShapes {
+		do, ds := open-v.open, spread-v.spread
+		w := math.Exp(-(do*do + ds*ds) / (2 * visemeSpread * visemeSpread))
+		visemes[v.name] = w
+		total += w
+	}
+	for name, w := range visemes {
+		visemes[name] = w / total * strength
+	}
+	return visemes
+}
+
+// NewVisemeStage returns a TransformStage that estimates visemes and stores
+// them in FaceData.BlendShapes as "A", "I", "U", "E" and "O".
+func NewVisemeStage() TransformStage {
+	return TransformFunc(func(data *TrackingData) (*TrackingData, error) {
+		if data == nil || data.Face == nil {
+			return data, nil
+		}
+		visemes := EstimateVisemes(data.Face)
+		if visemes == nil {
+			return data, nil
+		}
+		if data.Face.BlendShapes == nil {
+			data.Face.BlendShapes = make(map[string]float64)
+		}
+		for name, value := range visemes {
+			data.Face.BlendShapes[name] = value
+		}
+		return data, nil
+	})
+}
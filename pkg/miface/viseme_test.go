@@ -0,0 +1,120 @@
+package miface
+
+import (
+	"math"
+	"testing"
+)
+
+// mouthFace builds a face mesh with the outer eye corners 0.2 apart, a
+// mouth of the given width (relative to the eye corners) and an inner lip
+// gap of open times the mouth width.
+func mouthFace(width, open float64) *FaceData {
+	lm := make([]Landmark, FaceLandmarkCount)
+	set := func(idx int, x, y float64) {
+		lm[idx] = Landmark{Point: Point3D{X: x, Y: y}, Visibility: 1}
+	}
+
+	set(FaceRightEyeOuter, 0.40, 0.40)
+	set(FaceLeftEyeOuter, 0.60, 0.40)
+
+	w := 0.2 * width
+	set(FaceMouthRight, 0.5-w/2, 0.60)
+	set(FaceMouthLeft, 0.5+w/2, 0.60)
+	set(FaceUpperLip, 0.5, 0.60-open*w/2)
+	set(FaceLowerLip, 0.5, 0.60+open*w/2)
+
+	return &FaceData{Landmarks: lm}
+}
+
+// dominantViseme returns the name of the strongest viseme.
+func dominantViseme(visemes map[string]float64) string {
+	best := ""
+	for name, w := range visemes {
+		if best == "" || w > visemes[best] {
+			best = name
+		}
+	}
+	return best
+}
+
+func TestEstimateVisemesA(t *testing.T) {
+	visemes := EstimateVisemes(mouthFace(visemeNeutralWidth, visemeFullOpen))
+
+	if got := dominantViseme(visemes); got != "A" {
+		t.Errorf("expected A to dominate, got %s (%v)", got, visemes)
+	}
+	var total float64
+	for name, w := range visemes {
+		if w < 0 || w > 1 {
+			t.Errorf("expected %s in [0, 1], got %f", name, w)
+		}
+		total += w
+	}
+	if math.Abs(total-1) > 1e-9 {
+		t.Errorf("expected weights to sum to 1, got %f", total)
+	}
+}
+
+func TestEstimateVisemesShapes(t *testing.T) {
+	tests := []struct {
+		name        string
+		width, open float64
+	}{
+		{"I", visemeNeutralWidth + visemeSpreadRange, 0.1},
+		{"U", visemeNeutralWidth - visemeSpreadRange, 0.1},
+		{"E", visemeNeutralWidth + 0.6*visemeSpreadRange, 0.3},
+		{"O", visemeNeutralWidth - 0.7*visemeSpreadRange, 0.42},
+	}
+	for _, tt := range tests {
+		visemes := EstimateVisemes(mouthFace(tt.width, tt.open))
+		if got := dominantViseme(visemes); got != tt.name {
+			t.Errorf("expected %s to dominate, got %s (%v)", tt.name, got, visemes)
+		}
+	}
+}
+
+func TestEstimateVisemesClosed(t *testing.T) {
+	visemes := EstimateVisemes(mouthFace(visemeNeutralWidth, 0))
+	if len(visemes) != 5 {
+		t.Fatalf("expected 5 visemes, got %d", len(visemes))
+	}
+	for name, w := range visemes {
+		if w > 1e-9 {
+			t.Errorf("expected %s to be 0 for a relaxed mouth, got %f", name, w)
+		}
+	}
+
+	// Slightly parted lips give weak visemes
+	var total float64
+	for _, w := range EstimateVisemes(mouthFace(visemeNeutralWidth, 0.05)) {
+		total += w
+	}
+	if total <= 0 || total >= 0.5 {
+		t.Errorf("expected weak visemes for slightly parted lips, got total %f", total)
+	}
+}
+
+func TestEstimateVisemesMissingFace(t *testing.T) {
+	if v := EstimateVisemes(nil); v != nil {
+		t.Errorf("expected nil for no face, got %v", v)
+	}
+	if v := EstimateVisemes(&FaceData{Landmarks: make([]Landmark, 10)}); v != nil {
+		t.Errorf("expected nil for too few landmarks, got %v", v)
+	}
+}
+
+func TestVisemeStage(t *testing.T) {
+	face := mouthFace(visemeNeutralWidth, visemeFullOpen)
+	face.BlendShapes = map[string]float64{"jawOpen": 0.8}
+
+	data, err := NewVisemeStage().Transform(&TrackingData{Face: face})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if data.Face.BlendShapes["A"] <= 0.5 {
+		t.Errorf("expected A blend shape above 0.5, got %f", data.Face.BlendShapes["A"])
+	}
+	if data.Face.BlendShapes["jawOpen"] != 0.8 {
+		t.Errorf("expected other blend shapes kept, got %v", data.Face.BlendShapes)
+	}
+}
@@ -24,6 +24,13 @@ type VRMBone struct {
 	ParentIndex int
 	// Children contains indices of child bones.
 	Children []int
+	// ParentJoint is the node index of the nearest ancestor that is a joint
+	// of the skeleton's skin, skipping intermediate transform nodes (-1 if
+	// none). Without a skin it equals ParentIndex.
+	ParentJoint int
+	// RestPosition is the bone's rest position in model space, composed
+	// from the scene root through all ancestors.
+	RestPosition Point3D
 }
 
 // VRMSkeleton represents the bone hierarchy extracted from a VRM file.
@@ -38,6 +45,15 @@ type VRMSkeleton struct {
 	Height float64
 	// HeadSize is the estimated head size (distance from chin to top).
 	HeadSize float64
+	// SkeletonRoot is the node index of the skin's skeleton root, or -1 if
+	// the file has no skin.
+	SkeletonRoot int
+	// Joints lists the node indices of the skin's joints, nil without a skin.
+	// With several skins, the one containing the most humanoid bones is used.
+	Joints []int
+
+	// vrm1 is set for VRM 1.0 (VRMC_vrm) files
+	vrm1 bool
 }
 
 // BoneProportions contains calculated bone proportions for tracking calibration.
@@ -71,42 +87,62 @@ func LoadVRMSkeleton(path string) (*VRMSkeleton, error) {
 }
 
 // ParseVRMSkeleton parses bone data from a VRM file reader.
+// The whole file is read; see ParseVRMSkeletonBytes.
 func ParseVRMSkeleton(r io.Reader) (*VRMSkeleton, error) {
-	// Read glTF binary header
-	header := make([]byte, 12)
-	if _, err := io.ReadFull(r, header); err != nil {
-		return nil, fmt.Errorf("reading glTF header: %w", err)
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, fmt.Errorf("reading VRM data: %w", err)
+	}
+	return ParseVRMSkeletonBytes(data)
+}
+
+// ParseVRMSkeletonBytes parses bone data from a VRM file in memory, such as
+// an avatar embedded with go:embed or a downloaded HTTP body. Chunks are
+// located by type rather than position: the glTF spec puts JSON first, but
+// some exporters don't, so BIN and unknown chunks before the JSON chunk are
+// skipped. Data after the length declared in the header is ignored.
+func ParseVRMSkeletonBytes(data []byte) (*VRMSkeleton, error) {
+	if len(data) < 12 {
+		return nil, fmt.Errorf("reading glTF header: %w", io.ErrUnexpectedEOF)
 	}
 
 	// Verify magic number (glTF)
-	magic := binary.LittleEndian.Uint32(header[0:4])
-	if magic != 0x46546C67 { // "glTF" in little-endian
+	magic := binary.LittleEndian.Uint32(data[0:4])
+	if magic != glbMagic {
 		return nil, fmt.Errorf("invalid glTF magic number: %x", magic)
 	}
 
 	// Verify version
-	version := binary.LittleEndian.Uint32(header[4:8])
-	if version != 2 {
+	version := binary.LittleEndian.Uint32(data[4:8])
+	if version != glbVersion {
 		return nil, fmt.Errorf("unsupported glTF version: %d", version)
 	}
 
-	// Read JSON chunk header
-	chunkHeader := make([]byte, 8)
-	if _, err := io.ReadFull(r, chunkHeader); err != nil {
-		return nil, fmt.Errorf("reading chunk header: %w", err)
+	// Ignore trailing data after the declared length
+	length := binary.LittleEndian.Uint32(data[8:12])
+	if uint64(length) > uint64(len(data)) {
+		return nil, fmt.Errorf("glTF data truncated: header declares %d bytes, got %d", length, len(data))
 	}
-
-	chunkLength := binary.LittleEndian.Uint32(chunkHeader[0:4])
-	chunkType := binary.LittleEndian.Uint32(chunkHeader[4:8])
-
-	if chunkType != 0x4E4F534A { // "JSON" in little-endian
-		return nil, fmt.Errorf("expected JSON chunk, got %x", chunkType)
+	if length >= 12 {
+		data = data[:length]
 	}
 
-	// Read JSON data
-	jsonData := make([]byte, chunkLength)
-	if _, err := io.ReadFull(r, jsonData); err != nil {
-		return nil, fmt.Errorf("reading JSON chunk: %w", err)
+	// Find the JSON chunk
+	var jsonData []byte
+	for offset := 12; jsonData == nil; {
+		if len(data)-offset < 8 {
+			return nil, fmt.Errorf("no JSON chunk in glTF data")
+		}
+		chunkLength := binary.LittleEndian.Uint32(data[offset : offset+4])
+		chunkType := binary.LittleEndian.Uint32(data[offset+4 : offset+8])
+		offset += 8
+		if uint64(chunkLength) > uint64(len(data)-offset) {
+			return nil, fmt.Errorf("reading chunk %x: %w", chunkType, io.ErrUnexpectedEOF)
+		}
+		if chunkType == glbChunkJSON {
+			jsonData = data[offset : offset+int(chunkLength)]
+		}
+		offset += int(chunkLength)
 	}
 
 	// Parse glTF JSON
@@ -121,6 +157,7 @@ func ParseVRMSkeleton(r io.Reader) (*VRMSkeleton, error) {
 // gltfDocument represents the minimal glTF JSON structure needed for skeleton extraction.
 type gltfDocument struct {
 	Nodes      []gltfNode      `json:"nodes"`
+	Skins      []gltfSkin      `json:"skins"`
 	Extensions gltfExtensions  `json:"extensions"`
 }
 
@@ -132,6 +169,12 @@ type gltfNode struct {
 	Scale       []float64 `json:"scale"`
 }
 
+type gltfSkin struct {
+	Name     string `json:"name"`
+	Joints   []int  `json:"joints"`
+	Skeleton *int   `json:"skeleton"`
+}
+
 type gltfExtensions struct {
 	VRM  *vrmExtension  `json:"VRM"`
 	VRMC *vrmcExtension `json:"VRMC_vrm"`
@@ -167,8 +210,9 @@ type vrmcHumanBone struct {
 // extractSkeleton extracts skeleton data from parsed glTF.
 func extractSkeleton(gltf *gltfDocument) (*VRMSkeleton, error) {
 	skeleton := &VRMSkeleton{
-		Bones:      make(map[string]*VRMBone),
-		HumanBones: make(map[string]int),
+		Bones:        make(map[string]*VRMBone),
+		HumanBones:   make(map[string]int),
+		SkeletonRoot: -1,
 	}
 
 	// Build parent-child relationships
@@ -185,6 +229,7 @@ func extractSkeleton(gltf *gltfDocument) (*VRMSkeleton, error) {
 			Name:        node.Name,
 			NodeIndex:   i,
 			ParentIndex: -1,
+			ParentJoint: -1,
 			Children:    node.Children,
 		}
 
@@ -233,109 +278,110 @@ func extractSkeleton(gltf *gltfDocument) (*VRMSkeleton, error) {
 		}
 	} else if gltf.Extensions.VRMC != nil && gltf.Extensions.VRMC.Humanoid != nil {
 		// VRM 1.0 format
+		skeleton.vrm1 = true
 		for name, hb := range gltf.Extensions.VRMC.Humanoid.HumanBones {
 			skeleton.HumanBones[name] = hb.Node
 		}
 	}
 
+	// Resolve the skin's joints and rest positions
+	skeleton.applySkin(gltf, parentMap)
+
 	// Calculate model proportions
-	skeleton.calculateProportions(gltf.Nodes)
+	skeleton.calculateProportions()
 
 	return skeleton, nil
 }
 
-// calculateProportions calculates body proportions from bone positions.
-func (s *VRMSkeleton) calculateProportions(nodes []gltfNode) {
-	// Get key bone positions
-	getWorldPos := func(boneName string) (Point3D, bool) {
-		nodeIdx, ok := s.HumanBones[boneName]
-		if !ok || nodeIdx >= len(nodes) {
-			return Point3D{}, false
-		}
-		
-		// For simplicity, use local position (proper implementation would compute world transforms)
-		node := nodes[nodeIdx]
-		if len(node.Translation) >= 3 {
-			return Point3D{
-				X: node.Translation[0],
-				Y: node.Translation[1],
-				Z: node.Translation[2],
-			}, true
-		}
-		return Point3D{}, false
-	}
-
+// calculateProportions calculates body proportions from the bones' rest
+// positions in model space.
+func (s *VRMSkeleton) calculateProportions() {
 	// Calculate arm span
-	if leftHand, ok := getWorldPos("leftHand"); ok {
-		if rightHand, ok := getWorldPos("rightHand"); ok {
-			s.ArmSpan = distance(leftHand, rightHand)
+	if leftHand := s.humanBone("leftHand"); leftHand != nil {
+		if rightHand := s.humanBone("rightHand"); rightHand != nil {
+			s.ArmSpan = distance(leftHand.RestPosition, rightHand.RestPosition)
 		}
 	}
 
 	// Estimate height from hips to head
-	if hips, ok := getWorldPos("hips"); ok {
-		if head, ok := getWorldPos("head"); ok {
-			s.Height = head.Y - hips.Y
+	if hips := s.humanBone("hips"); hips != nil {
+		if head := s.humanBone("head"); head != nil {
+			s.Height = head.RestPosition.Y - hips.RestPosition.Y
 			// Add estimated leg length (roughly equal to upper body)
 			s.Height *= 2
 		}
 	}
 
-	// Estimate head size from head to neck
-	if head, ok := getWorldPos("head"); ok {
-		if neck, ok := getWorldPos("neck"); ok {
-			s.HeadSize = distance(head, neck) * 1.5 // Approximate full head size
+	// Estimate head size from the head joint's distance to its parent (the neck)
+	if length, ok := s.boneLength("head"); ok {
+		s.HeadSize = length * 1.5 // Approximate full head size
+	}
+}
+
+// humanBone returns the bone of a VRM humanoid bone name, or nil if the
+// skeleton doesn't map it.
+func (s *VRMSkeleton) humanBone(name string) *VRMBone {
+	if nodeIdx, ok := s.HumanBones[name]; ok {
+		for _, bone := range s.Bones {
+			if bone.NodeIndex == nodeIdx {
+				return bone
+			}
 		}
 	}
+	return nil
 }
 
-// GetProportions calculates detailed bone proportions for tracking calibration.
+// boneLength returns the model-space distance from a humanoid bone's joint
+// to its parent joint, skipping intermediate transform nodes. It reports
+// false if the bone is missing or has no parent joint.
+func (s *VRMSkeleton) boneLength(name string) (float64, bool) {
+	bone := s.humanBone(name)
+	if bone == nil || bone.ParentJoint < 0 {
+		return 0, false
+	}
+	for _, parent := range s.Bones {
+		if parent.NodeIndex == bone.ParentJoint {
+			return distance(bone.RestPosition, parent.RestPosition), true
+		}
+	}
+	return 0, false
+}
+
+// GetProportions calculates detailed bone proportions for tracking
+// calibration, from the bones' rest positions in model space.
 func (s *VRMSkeleton) GetProportions() *BoneProportions {
 	props := &BoneProportions{
 		HeadSize: s.HeadSize,
 	}
 
-	// Helper to get bone by VRM name
-	getBone := func(name string) *VRMBone {
-		if nodeIdx, ok := s.HumanBones[name]; ok {
-			for _, bone := range s.Bones {
-				if bone.NodeIndex == nodeIdx {
-					return bone
-				}
-			}
-		}
-		return nil
-	}
-
-	// Calculate arm proportions (using left arm as reference)
-	if shoulder := getBone("leftUpperArm"); shoulder != nil {
-		if elbow := getBone("leftLowerArm"); elbow != nil {
-			props.UpperArmLength = distance(shoulder.Position, elbow.Position)
-			if wrist := getBone("leftHand"); wrist != nil {
-				props.LowerArmLength = distance(elbow.Position, wrist.Position)
-				props.ArmLength = props.UpperArmLength + props.LowerArmLength
-			}
+	// Calculate arm proportions (using left arm as reference): each
+	// segment ends at the joint below it
+	if upper, ok := s.boneLength("leftLowerArm"); ok {
+		props.UpperArmLength = upper
+		if lower, ok := s.boneLength("leftHand"); ok {
+			props.LowerArmLength = lower
+			props.ArmLength = props.UpperArmLength + props.LowerArmLength
 		}
 	}
 
 	// Calculate spine length
-	if hips := getBone("hips"); hips != nil {
-		if chest := getBone("chest"); chest != nil {
-			props.SpineLength = distance(hips.Position, chest.Position)
+	if hips := s.humanBone("hips"); hips != nil {
+		if chest := s.humanBone("chest"); chest != nil {
+			props.SpineLength = distance(hips.RestPosition, chest.RestPosition)
 		}
 	}
 
 	// Calculate neck length
-	if chest := getBone("chest"); chest != nil {
-		if head := getBone("head"); head != nil {
-			props.NeckLength = distance(chest.Position, head.Position)
+	if chest := s.humanBone("chest"); chest != nil {
+		if head := s.humanBone("head"); head != nil {
+			props.NeckLength = distance(chest.RestPosition, head.RestPosition)
 		}
 	}
 
 	// Calculate shoulder width
-	if leftShoulder := getBone("leftUpperArm"); leftShoulder != nil {
-		if rightShoulder := getBone("rightUpperArm"); rightShoulder != nil {
-			props.ShoulderWidth = distance(leftShoulder.Position, rightShoulder.Position)
+	if leftShoulder := s.humanBone("leftUpperArm"); leftShoulder != nil {
+		if rightShoulder := s.humanBone("rightUpperArm"); rightShoulder != nil {
+			props.ShoulderWidth = distance(leftShoulder.RestPosition, rightShoulder.RestPosition)
 		}
 	}
 
@@ -358,6 +404,37 @@ func (s *VRMSkeleton) GetBonePosition(boneName string) (Point3D, bool) {
 	return Point3D{}, false
 }
 
+// requiredHumanBones are the humanoid bones VRM 1.0 requires.
+var requiredHumanBones = []string{
+	"hips", "spine", "head",
+	"leftUpperLeg", "leftLowerLeg", "leftFoot",
+	"rightUpperLeg", "rightLowerLeg", "rightFoot",
+	"leftUpperArm", "leftLowerArm", "leftHand",
+	"rightUpperArm", "rightLowerArm", "rightHand",
+}
+
+// requiredHumanBonesVRM0 are the humanoid bones VRM 0.x requires: those of
+// VRM 1.0 plus the chest and neck, which 1.0 made optional.
+var requiredHumanBonesVRM0 = append([]string{"chest", "neck"}, requiredHumanBones...)
+
+// ValidateHumanoid returns the bones the VRM specification requires that
+// are missing from HumanBones, or nil if the humanoid is complete. VRM 1.0
+// files are checked against the 1.0 required set, others against 0.x.
+func (s *VRMSkeleton) ValidateHumanoid() []string {
+	required := requiredHumanBonesVRM0
+	if s.vrm1 {
+		required = requiredHumanBones
+	}
+
+	var missing []string
+	for _, name := range required {
+		if _, ok := s.HumanBones[name]; !ok {
+			missing = append(missing, name)
+		}
+	}
+	return missing
+}
+
 // ListHumanBones returns a list of all available humanoid bone names.
 func (s *VRMSkeleton) ListHumanBones() []string {
 	names := make([]string, 0, len(s.HumanBones))
@@ -369,10 +446,7 @@ func (s *VRMSkeleton) ListHumanBones() []string {
 
 // distance calculates the Euclidean distance between two 3D points.
 func distance(a, b Point3D) float64 {
-	dx := a.X - b.X
-	dy := a.Y - b.Y
-	dz := a.Z - b.Z
-	return sqrt(dx*dx + dy*dy + dz*dz)
+	return Distance(a, b)
 }
 
 // sqrt is a simple square root approximation using Newton's method.
@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"encoding/binary"
 	"encoding/json"
+	"math"
 	"testing"
 )
 
@@ -281,3 +282,290 @@ func TestParseVRM1Skeleton(t *testing.T) {
 		t.Error("expected 'hips' in VRM 1.0 humanoid mapping")
 	}
 }
+
+// createSkinnedTestVRM creates a VRM 1.0 binary whose humanoid bones are
+// joints of a skin, with an intermediate transform node between the hips
+// and the spine and a scaled armature. A second, unrelated skin comes first.
+// skeleton is the skin's skeleton root, or -1 to omit it.
+func createSkinnedTestVRM(t *testing.T, skeleton int) []byte {
+	t.Helper()
+
+	skin := map[string]interface{}{"joints": []int{2, 4, 5}}
+	if skeleton >= 0 {
+		skin["skeleton"] = skeleton
+	}
+	gltf := map[string]interface{}{
+		"asset": map[string]interface{}{
+			"version": "2.0",
+		},
+		"nodes": []map[string]interface{}{
+			{"name": "Scene", "children": []int{1, 6}},
+			{"name": "Armature", "children": []int{2}, "scale": []float64{0.01, 0.01, 0.01}},
+			{"name": "J_Hips", "children": []int{3}, "translation": []float64{0, 100, 0}},
+			{"name": "Offset", "children": []int{4}, "translation": []float64{0, 5, 0}},
+			{"name": "J_Spine", "children": []int{5}, "translation": []float64{0, 10, 0}},
+			{"name": "J_Head", "translation": []float64{0, 50, 0}},
+			{"name": "Hair"},
+		},
+		"skins": []map[string]interface{}{
+			{"joints": []int{6}},
+			skin,
+		},
+		"extensions": map[string]interface{}{
+			"VRMC_vrm": map[string]interface{}{
+				"humanoid": map[string]interface{}{
+					"humanBones": map[string]interface{}{
+						"hips":  map[string]interface{}{"node": 2},
+						"spine": map[string]interface{}{"node": 4},
+						"head":  map[string]interface{}{"node": 5},
+					},
+				},
+			},
+		},
+	}
+
+	jsonData, err := json.Marshal(gltf)
+	if err != nil {
+		t.Fatalf("failed to marshal test glTF: %v", err)
+	}
+
+	padding := (4 - len(jsonData)%4) % 4
+	for i := 0; i < padding; i++ {
+		jsonData = append(jsonData, ' ')
+	}
+
+	var buf bytes.Buffer
+	buf.Write([]byte("glTF"))
+	_ = binary.Write(&buf, binary.LittleEndian, uint32(2))
+	_ = binary.Write(&buf, binary.LittleEndian, uint32(12+8+len(jsonData)))
+	_ = binary.Write(&buf, binary.LittleEndian, uint32(len(jsonData)))
+	buf.Write([]byte("JSON"))
+	buf.Write(jsonData)
+
+	return buf.Bytes()
+}
+
+func TestParseVRMSkeletonSkin(t *testing.T) {
+	skeleton, err := ParseVRMSkeleton(bytes.NewReader(createSkinnedTestVRM(t, 1)))
+	if err != nil {
+		t.Fatalf("failed to parse: %v", err)
+	}
+
+	if skeleton.SkeletonRoot != 1 {
+		t.Errorf("expected skeleton root 1 (Armature), got %d", skeleton.SkeletonRoot)
+	}
+	if len(skeleton.Joints) != 3 || skeleton.Joints[0] != 2 {
+		t.Errorf("expected the humanoid skin's joints [2 4 5], got %v", skeleton.Joints)
+	}
+
+	spine := skeleton.Bones["J_Spine"]
+	if spine.ParentIndex != 3 {
+		t.Errorf("expected spine parent node 3, got %d", spine.ParentIndex)
+	}
+	if spine.ParentJoint != 2 {
+		t.Errorf("expected spine parent joint 2 (hips), got %d", spine.ParentJoint)
+	}
+	if hips := skeleton.Bones["J_Hips"]; hips.ParentJoint != -1 {
+		t.Errorf("expected no parent joint for the hips, got %d", hips.ParentJoint)
+	}
+
+	head := skeleton.Bones["J_Head"].RestPosition
+	if math.Abs(head.Y-1.65) > 1e-9 || head.X != 0 || head.Z != 0 {
+		t.Errorf("expected head rest position (0, 1.65, 0), got %+v", head)
+	}
+
+	// Proportions are measured in model space between joints: the head
+	// joint sits 0.5 above the spine, its parent joint, past the offset node
+	if math.Abs(skeleton.HeadSize-0.75) > 1e-9 {
+		t.Errorf("expected head size 0.75, got %v", skeleton.HeadSize)
+	}
+	if math.Abs(skeleton.Height-1.3) > 1e-9 {
+		t.Errorf("expected height 1.3, got %v", skeleton.Height)
+	}
+}
+
+func TestParseVRMSkeletonSkinWithoutRoot(t *testing.T) {
+	skeleton, err := ParseVRMSkeleton(bytes.NewReader(createSkinnedTestVRM(t, -1)))
+	if err != nil {
+		t.Fatalf("failed to parse: %v", err)
+	}
+
+	// The common ancestor of the joints
+	if skeleton.SkeletonRoot != 2 {
+		t.Errorf("expected skeleton root 2 (J_Hips), got %d", skeleton.SkeletonRoot)
+	}
+}
+
+func TestParseVRMSkeletonNoSkin(t *testing.T) {
+	skeleton, err := ParseVRMSkeleton(bytes.NewReader(createTestVRM(t)))
+	if err != nil {
+		t.Fatalf("failed to parse: %v", err)
+	}
+
+	if skeleton.SkeletonRoot != -1 || skeleton.Joints != nil {
+		t.Errorf("expected no skin, got root %d and joints %v", skeleton.SkeletonRoot, skeleton.Joints)
+	}
+	for name, bone := range skeleton.Bones {
+		if bone.ParentJoint != bone.ParentIndex {
+			t.Errorf("%s: expected parent joint %d, got %d", name, bone.ParentIndex, bone.ParentJoint)
+		}
+	}
+	if spine := skeleton.Bones["Spine"].RestPosition; math.Abs(spine.Y-2.2) > 1e-9 {
+		t.Errorf("expected spine rest Y 2.2, got %v", spine.Y)
+	}
+}
+
+func TestRotatePoint(t *testing.T) {
+	// A quarter turn about Z takes X to Y
+	q := Quaternion{Z: math.Sin(math.Pi / 4), W: math.Cos(math.Pi / 4)}
+	p := rotatePoint(q, Point3D{X: 1})
+	if math.Abs(p.X) > 1e-9 || math.Abs(p.Y-1) > 1e-9 || math.Abs(p.Z) > 1e-9 {
+		t.Errorf("expected (0, 1, 0), got %+v", p)
+	}
+}
+
+func TestVRMSkeletonValidateHumanoid(t *testing.T) {
+	skeleton, err := ParseVRMSkeleton(bytes.NewReader(createTestVRM(t)))
+	if err != nil {
+		t.Fatalf("failed to parse: %v", err)
+	}
+
+	missing := skeleton.ValidateHumanoid()
+	got := make(map[string]bool)
+	for _, name := range missing {
+		got[name] = true
+	}
+	for _, name := range []string{"leftUpperArm", "rightHand", "leftFoot", "chest", "neck"} {
+		if !got[name] {
+			t.Errorf("expected %s reported missing, got %v", name, missing)
+		}
+	}
+	for _, name := range []string{"hips", "head", "spine"} {
+		if got[name] {
+			t.Errorf("expected %s not reported missing", name)
+		}
+	}
+	if len(missing) != 14 {
+		t.Errorf("expected 14 missing VRM 0.x bones, got %d: %v", len(missing), missing)
+	}
+}
+
+func TestVRMSkeletonValidateHumanoidVRM1(t *testing.T) {
+	skeleton, err := ParseVRMSkeleton(bytes.NewReader(createSkinnedTestVRM(t, 1)))
+	if err != nil {
+		t.Fatalf("failed to parse: %v", err)
+	}
+
+	// Chest and neck are optional in VRM 1.0
+	for _, name := range skeleton.ValidateHumanoid() {
+		if name == "chest" || name == "neck" {
+			t.Errorf("expected %s to be optional for VRM 1.0", name)
+		}
+	}
+	if n := len(skeleton.ValidateHumanoid()); n != 12 {
+		t.Errorf("expected 12 missing VRM 1.0 bones, got %d", n)
+	}
+
+	complete := &VRMSkeleton{HumanBones: make(map[string]int), vrm1: true}
+	for i, name := range requiredHumanBones {
+		complete.HumanBones[name] = i
+	}
+	if missing := complete.ValidateHumanoid(); missing != nil {
+		t.Errorf("expected a complete humanoid, got missing %v", missing)
+	}
+}
+
+func TestParseVRMSkeletonBytes(t *testing.T) {
+	// As if embedded with go:embed, followed by unrelated bytes
+	data := append(createTestVRM(t), []byte("trailing garbage")...)
+
+	skeleton, err := ParseVRMSkeletonBytes(data)
+	if err != nil {
+		t.Fatalf("failed to parse with trailing data: %v", err)
+	}
+	if len(skeleton.HumanBones) != 3 {
+		t.Errorf("expected 3 human bones, got %d", len(skeleton.HumanBones))
+	}
+
+	// The same through the reader
+	if _, err := ParseVRMSkeleton(bytes.NewReader(data)); err != nil {
+		t.Errorf("failed to parse from reader: %v", err)
+	}
+}
+
+func TestParseVRMSkeletonBytesSkipsChunks(t *testing.T) {
+	vrm := createTestVRM(t)
+
+	// Insert an unknown chunk before the JSON chunk
+	var buf bytes.Buffer
+	buf.Write(vrm[:8])
+	_ = binary.Write(&buf, binary.LittleEndian, uint32(len(vrm)+12))
+	_ = binary.Write(&buf, binary.LittleEndian, uint32(4))
+	buf.Write([]byte("XTRA"))
+	buf.Write([]byte{1, 2, 3, 4})
+	buf.Write(vrm[12:])
+
+	if _, err := ParseVRMSkeletonBytes(buf.Bytes()); err != nil {
+		t.Errorf("failed to parse with a leading unknown chunk: %v", err)
+	}
+}
+
+func TestParseVRMSkeletonBytesTruncated(t *testing.T) {
+	vrm := createTestVRM(t)
+	if _, err := ParseVRMSkeletonBytes(vrm[:len(vrm)-10]); err == nil {
+		t.Error("expected error for truncated data")
+	}
+	if _, err := ParseVRMSkeletonBytes(vrm[:5]); err == nil {
+		t.Error("expected error for a truncated header")
+	}
+}
+
+// glbWithChunks wraps chunks, in order, in a GLB container.
+func glbWithChunks(chunks ...[]byte) []byte {
+	total := 12
+	for _, c := range chunks {
+		total += len(c)
+	}
+	var buf bytes.Buffer
+	_ = binary.Write(&buf, binary.LittleEndian, uint32(glbMagic))
+	_ = binary.Write(&buf, binary.LittleEndian, uint32(glbVersion))
+	_ = binary.Write(&buf, binary.LittleEndian, uint32(total))
+	for _, c := range chunks {
+		buf.Write(c)
+	}
+	return buf.Bytes()
+}
+
+// glbChunk encodes a chunk header and its data.
+func glbChunk(chunkType uint32, data []byte) []byte {
+	var buf bytes.Buffer
+	_ = binary.Write(&buf, binary.LittleEndian, uint32(len(data)))
+	_ = binary.Write(&buf, binary.LittleEndian, chunkType)
+	buf.Write(data)
+	return buf.Bytes()
+}
+
+func TestParseVRMSkeletonBINChunkFirst(t *testing.T) {
+	vrm := createTestVRM(t)
+	jsonLength := binary.LittleEndian.Uint32(vrm[12:16])
+	jsonData := vrm[20 : 20+jsonLength]
+
+	data := glbWithChunks(
+		glbChunk(glbChunkBIN, []byte{0, 1, 2, 3, 4, 5, 6, 7}),
+		glbChunk(glbChunkJSON, jsonData),
+	)
+	skeleton, err := ParseVRMSkeleton(bytes.NewReader(data))
+	if err != nil {
+		t.Fatalf("failed to parse with BIN chunk first: %v", err)
+	}
+	if len(skeleton.HumanBones) != 3 {
+		t.Errorf("expected 3 human bones, got %d", len(skeleton.HumanBones))
+	}
+}
+
+func TestParseVRMSkeletonNoJSONChunk(t *testing.T) {
+	data := glbWithChunks(glbChunk(glbChunkBIN, []byte{0, 1, 2, 3}))
+	if _, err := ParseVRMSkeleton(bytes.NewReader(data)); err == nil {
+		t.Error("expected error for data without a JSON chunk")
+	}
+}
@@ -0,0 +1,349 @@
+package miface
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+)
+
+// glTF binary container constants.
+const (
+	glbMagic     = 0x46546C67 // "glTF" in little-endian
+	glbChunkJSON = 0x4E4F534A // "JSON" in little-endian
+	glbChunkBIN  = 0x004E4942 // "BIN\0" in little-endian
+	glbVersion   = 2
+
+	gltfFloat = 5126 // FLOAT component type
+)
+
+// vrmaFingers describes the finger bones exported to VRMA, from the base of
+// each finger to its tip.
+var vrmaFingers = []struct {
+	name      string     // VRM finger name, e.g. "Index"
+	segments  []string   // VRM segment names, from the base
+	landmarks [4]int     // Hand landmarks along the finger, from the base
+	base      [3]float64 // Rest offset of the first segment from the hand (left side)
+}{
+	{"Thumb", []string{"Metacarpal", "Proximal", "Distal"}, [4]int{1, 2, 3, 4}, [3]float64{0.02, -0.01, 0.02}},
+	{"Index", []string{"Proximal", "Intermediate", "Distal"}, [4]int{5, 6, 7, 8}, [3]float64{0.08, 0, 0.025}},
+	{"Middle", []string{"Proximal", "Intermediate", "Distal"}, [4]int{9, 10, 11, 12}, [3]float64{0.085, 0, 0.005}},
+	{"Ring", []string{"Proximal", "Intermediate", "Distal"}, [4]int{13, 14, 15, 16}, [3]float64{0.08, 0, -0.015}},
+	{"Little", []string{"Proximal", "Intermediate", "Distal"}, [4]int{17, 18, 19, 20}, [3]float64{0.07, 0, -0.035}},
+}
+
+// vrmaBone is a humanoid bone in the exported rest skeleton.
+type vrmaBone struct {
+	name        string     // VRM 1.0 humanoid bone name
+	parent      int        // Index of the parent bone, -1 for the root
+	translation [3]float64 // Rest offset from the parent in meters
+}
+
+// vrmaSkeleton returns the rest skeleton written to VRMA files: the hips,
+// the head, and both arms down to the finger tips. Bones between them are
+// left out, so each rotation is relative to the nearest exported parent.
+func vrmaSkeleton() []vrmaBone {
+	bones := []vrmaBone{
+		{"hips", -1, [3]float64{0, 1, 0}},
+		{"head", 0, [3]float64{0, 0.6, 0}},
+	}
+	for _, side := range []struct {
+		prefix string
+		x      float64
+	}{{"left", 1}, {"right", -1}} {
+		upper := len(bones)
+		bones = append(bones,
+			vrmaBone{side.prefix + "UpperArm", 0, [3]float64{0.18 * side.x, 0.45, 0}},
+			vrmaBone{side.prefix + "LowerArm", upper, [3]float64{0.28 * side.x, 0, 0}},
+			vrmaBone{side.prefix + "Hand", upper + 1, [3]float64{0.25 * side.x, 0, 0}},
+		)
+		hand := upper + 2
+		for _, finger := range vrmaFingers {
+			parent := hand
+			for i, segment := range finger.segments {
+				offset := [3]float64{0.03 * side.x, 0, 0}
+				if i == 0 {
+					offset = [3]float64{finger.base[0] * side.x, finger.base[1], finger.base[2]}
+				}
+				bones = append(bones, vrmaBone{side.prefix + finger.name + segment, parent, offset})
+				parent = len(bones) - 1
+			}
+		}
+	}
+	return bones
+}
+
+// ExportVRMA writes frames as a VRM Animation (.vrma): a binary glTF file
+// with the VRMC_vrm_animation extension and one rotation track per humanoid
+// bone, keyed by the frame timestamps relative to the first frame.
+//
+// The head rotation comes from the face, the arm and hand rotations from a
+// Retargeter without avatar proportions, and the finger rotations from the
+// hand landmarks. A bone missing from a frame holds its previous rotation.
+// Frames whose timestamp does not advance are skipped.
+func ExportVRMA(frames []*TrackingData, w io.Writer) error {
+	var keyed []*TrackingData
+	for _, data := range frames {
+		if data == nil {
+			continue
+		}
+		if len(keyed) > 0 && !data.Timestamp.After(keyed[len(keyed)-1].Timestamp) {
+			continue
+		}
+		keyed = append(keyed, data)
+	}
+	if len(keyed) == 0 {
+		return errors.New("no frames to export")
+	}
+
+	bones := vrmaSkeleton()
+	retargeter := NewRetargeter(nil)
+
+	// Sample every bone's rotation in every frame
+	times := make([]float64, len(keyed))
+	tracks := make([][]Quaternion, len(bones))
+	current := make(map[string]Quaternion, len(bones))
+	for _, bone := range bones {
+		current[bone.name] = Quaternion{W: 1}
+	}
+	for i, data := range keyed {
+		times[i] = data.Timestamp.Sub(keyed[0].Timestamp).Seconds()
+		frameRotations(data, retargeter, current)
+		for b, bone := range bones {
+			tracks[b] = append(tracks[b], current[bone.name])
+		}
+	}
+
+	doc, bin := buildVRMA(bones, times, tracks)
+	return writeGLB(w, doc, bin)
+}
+
+// frameRotations updates rotations with the bone rotations tracked in data.
+func frameRotations(data *TrackingData, retargeter *Retargeter, rotations map[string]Quaternion) {
+	if data.Face != nil {
+		rotations["head"] = data.Face.HeadRotation.Normalize()
+	}
+	if data.Pose != nil {
+		left, right := retargeter.Solve(data.Pose)
+		rotations["leftUpperArm"] = left.UpperArm
+		rotations["leftLowerArm"] = left.LowerArm
+		rotations["leftHand"] = left.Hand
+		rotations["rightUpperArm"] = right.UpperArm
+		rotations["rightLowerArm"] = right.LowerArm
+		rotations["rightHand"] = right.Hand
+	}
+	if data.LeftHand != nil {
+		fingerRotations("left", data.LeftHand, rotations)
+	}
+	if data.RightHand != nil {
+		fingerRotations("right", data.RightHand, rotations)
+	}
+}
+
+// fingerRotations stores the local rotation of each finger bone of hand,
+// relative to a straight finger along the hand, under its VRM bone name.
+func fingerRotations(prefix string, hand *HandData, rotations map[string]Quaternion) {
+	lms := hand.Landmarks
+	if len(lms) < 21 {
+		return
+	}
+	point := func(i int) Point3D { return ImageToNormalized(lms[i].Point) }
+
+	// The hand points from the wrist to the middle finger base
+	handDir := point(9).Sub(point(0)).Normalize()
+	if handDir == (Point3D{}) {
+		return
+	}
+
+	for _, finger := range vrmaFingers {
+		prevDir := handDir
+		parent := Quaternion{W: 1}
+		for i, segment := range finger.segments {
+			dir := point(finger.landmarks[i+1]).Sub(point(finger.landmarks[i])).Normalize()
+			if dir == (Point3D{}) {
+				dir = prevDir
+			}
+			global := quatFromTo(prevDir, dir).Mul(parent)
+			rotations[prefix+finger.name+segment] = parent.Conjugate().Mul(global)
+			parent, prevDir = global, dir
+		}
+	}
+}
+
+// vrmaDocument is the glTF JSON written by ExportVRMA.
+type vrmaDocument struct {
+	Asset          vrmaAsset        `json:"asset"`
+	ExtensionsUsed []string         `json:"extensionsUsed"`
+	Extensions     vrmaExtensions   `json:"extensions"`
+	Scene          int              `json:"scene"`
+	Scenes         []vrmaScene      `json:"scenes"`
+	Nodes          []vrmaNode       `json:"nodes"`
+	Animations     []vrmaAnimation  `json:"animations"`
+	Accessors      []vrmaAccessor   `json:"accessors"`
+	BufferViews    []vrmaBufferView `json:"bufferViews"`
+	Buffers        []vrmaBuffer     `json:"buffers"`
+}
+
+type vrmaAsset struct {
+	Version   string `json:"version"`
+	Generator string `json:"generator"`
+}
+
+type vrmaExtensions struct {
+	Animation vrmaAnimationExtension `json:"VRMC_vrm_animation"`
+}
+
+type vrmaAnimationExtension struct {
+	SpecVersion string       `json:"specVersion"`
+	Humanoid    vrmcHumanoid `json:"humanoid"`
+}
+
+type vrmaScene struct {
+	Nodes []int `json:"nodes"`
+}
+
+type vrmaNode struct {
+	Name        string     `json:"name"`
+	Children    []int      `json:"children,omitempty"`
+	Translation [3]float64 `json:"translation"`
+}
+
+type vrmaAnimation struct {
+	Name     string        `json:"name"`
+	Channels []vrmaChannel `json:"channels"`
+	Samplers []vrmaSampler `json:"samplers"`
+}
+
+type vrmaChannel struct {
+	Sampler int               `json:"sampler"`
+	Target  vrmaChannelTarget `json:"target"`
+}
+
+type vrmaChannelTarget struct {
+	Node int    `json:"node"`
+	Path string `json:"path"`
+}
+
+type vrmaSampler struct {
+	Input         int    `json:"input"`
+	Output        int    `json:"output"`
+	Interpolation string `json:"interpolation"`
+}
+
+type vrmaAccessor struct {
+	BufferView    int       `json:"bufferView"`
+	ByteOffset    int       `json:"byteOffset"`
+	ComponentType int       `json:"componentType"`
+	Count         int       `json:"count"`
+	Type          string    `json:"type"`
+	Min           []float64 `json:"min,omitempty"`
+	Max           []float64 `json:"max,omitempty"`
+}
+
+type vrmaBufferView struct {
+	Buffer     int `json:"buffer"`
+	ByteLength int `json:"byteLength"`
+}
+
+type vrmaBuffer struct {
+	ByteLength int `json:"byteLength"`
+}
+
+// buildVRMA lays out the glTF document and its binary buffer: the key
+// times first, then one rotation track per bone.
+func buildVRMA(bones []vrmaBone, times []float64, tracks [][]Quaternion) (*vrmaDocument, []byte) {
+	doc := &vrmaDocument{
+		Asset:          vrmaAsset{Version: "2.0", Generator: "MiFace"},
+		ExtensionsUsed: []string{"VRMC_vrm_animation"},
+		Extensions: vrmaExtensions{Animation: vrmaAnimationExtension{
+			SpecVersion: "1.0",
+			Humanoid:    vrmcHumanoid{HumanBones: make(map[string]vrmcHumanBone, len(bones))},
+		}},
+		Scenes: []vrmaScene{{Nodes: []int{0}}},
+	}
+
+	for i, bone := range bones {
+		doc.Nodes = append(doc.Nodes, vrmaNode{Name: bone.name, Translation: bone.translation})
+		if bone.parent >= 0 {
+			doc.Nodes[bone.parent].Children = append(doc.Nodes[bone.parent].Children, i)
+		}
+		doc.Extensions.Animation.Humanoid.HumanBones[bone.name] = vrmcHumanBone{Node: i}
+	}
+
+	var bin []byte
+	for _, t := range times {
+		bin = binary.LittleEndian.AppendUint32(bin, math.Float32bits(float32(t)))
+	}
+	doc.Accessors = append(doc.Accessors, vrmaAccessor{
+		ComponentType: gltfFloat,
+		Count:         len(times),
+		Type:          "SCALAR",
+		Min:           []float64{float64(float32(times[0]))},
+		Max:           []float64{float64(float32(times[len(times)-1]))},
+	})
+
+	animation := vrmaAnimation{Name: "MiFace"}
+	for b, track := range tracks {
+		accessor := vrmaAccessor{
+			ByteOffset:    len(bin),
+			ComponentType: gltfFloat,
+			Count:         len(track),
+			Type:          "VEC4",
+		}
+		for _, q := range track {
+			for _, v := range []float64{q.X, q.Y, q.Z, q.W} {
+				bin = binary.LittleEndian.AppendUint32(bin, math.Float32bits(float32(v)))
+			}
+		}
+		doc.Accessors = append(doc.Accessors, accessor)
+
+		animation.Samplers = append(animation.Samplers, vrmaSampler{
+			Input:         0,
+			Output:        len(doc.Accessors) - 1,
+			Interpolation: "LINEAR",
+		})
+		animation.Channels = append(animation.Channels, vrmaChannel{
+			Sampler: len(animation.Samplers) - 1,
+			Target:  vrmaChannelTarget{Node: b, Path: "rotation"},
+		})
+	}
+	doc.Animations = []vrmaAnimation{animation}
+	doc.BufferViews = []vrmaBufferView{{Buffer: 0, ByteLength: len(bin)}}
+	doc.Buffers = []vrmaBuffer{{ByteLength: len(bin)}}
+
+	return doc, bin
+}
+
+// writeGLB writes doc and bin as a binary glTF container, padding the JSON
+// chunk with spaces and the binary chunk with zeros to 4-byte boundaries.
+func writeGLB(w io.Writer, doc *vrmaDocument, bin []byte) error {
+	jsonData, err := json.Marshal(doc)
+	if err != nil {
+		return fmt.Errorf("encoding glTF JSON: %w", err)
+	}
+	for len(jsonData)%4 != 0 {
+		jsonData = append(jsonData, ' ')
+	}
+	for len(bin)%4 != 0 {
+		bin = append(bin, 0)
+	}
+
+	var buf bytes.Buffer
+	total := 12 + 8 + len(jsonData) + 8 + len(bin)
+	buf.Grow(total)
+	for _, v := range []uint32{glbMagic, glbVersion, uint32(total), uint32(len(jsonData)), glbChunkJSON} {
+		_ = binary.Write(&buf, binary.LittleEndian, v)
+	}
+	buf.Write(jsonData)
+	_ = binary.Write(&buf, binary.LittleEndian, uint32(len(bin)))
+	_ = binary.Write(&buf, binary.LittleEndian, uint32(glbChunkBIN))
+	buf.Write(bin)
+
+	if _, err := w.Write(buf.Bytes()); err != nil {
+		return fmt.Errorf("writing VRMA: %w", err)
+	}
+	return nil
+}
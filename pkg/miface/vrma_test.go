@@ -0,0 +1,133 @@
+package miface
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"testing"
+	"time"
+)
+
+func TestExportVRMA(t *testing.T) {
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	var frames []*TrackingData
+	for i := 0; i < 5; i++ {
+		frames = append(frames, &TrackingData{
+			Timestamp: start.Add(time.Duration(i) * 33 * time.Millisecond),
+			Face:      &FaceData{HeadRotation: QuaternionFromEuler(0, 0.1*float64(i), 0)},
+			LeftHand:  testHand(true, 1),
+			Pose:      uprightPose(),
+		})
+	}
+	// A frame at the same time is skipped
+	frames = append(frames, &TrackingData{Timestamp: frames[4].Timestamp})
+
+	var buf bytes.Buffer
+	if err := ExportVRMA(frames, &buf); err != nil {
+		t.Fatalf("export failed: %v", err)
+	}
+	data := buf.Bytes()
+
+	// Header
+	if len(data) < 20 {
+		t.Fatalf("expected a glTF header, got %d bytes", len(data))
+	}
+	if magic := binary.LittleEndian.Uint32(data[0:4]); magic != glbMagic {
+		t.Errorf("expected glTF magic, got %x", magic)
+	}
+	if version := binary.LittleEndian.Uint32(data[4:8]); version != 2 {
+		t.Errorf("expected version 2, got %d", version)
+	}
+	if length := binary.LittleEndian.Uint32(data[8:12]); int(length) != len(data) {
+		t.Errorf("expected length %d, got %d", len(data), length)
+	}
+
+	// JSON chunk
+	jsonLength := int(binary.LittleEndian.Uint32(data[12:16]))
+	if chunkType := binary.LittleEndian.Uint32(data[16:20]); chunkType != glbChunkJSON {
+		t.Fatalf("expected JSON chunk, got %x", chunkType)
+	}
+	if jsonLength%4 != 0 {
+		t.Errorf("expected JSON chunk padded to 4 bytes, got %d", jsonLength)
+	}
+	var doc vrmaDocument
+	if err := json.Unmarshal(data[20:20+jsonLength], &doc); err != nil {
+		t.Fatalf("parsing glTF JSON: %v", err)
+	}
+
+	if doc.Asset.Version != "2.0" {
+		t.Errorf("expected glTF 2.0, got %q", doc.Asset.Version)
+	}
+	if len(doc.ExtensionsUsed) != 1 || doc.ExtensionsUsed[0] != "VRMC_vrm_animation" {
+		t.Errorf("expected VRMC_vrm_animation extension, got %v", doc.ExtensionsUsed)
+	}
+	humanBones := doc.Extensions.Animation.Humanoid.HumanBones
+	for _, name := range []string{"hips", "head", "leftHand", "rightHand", "leftIndexProximal", "rightThumbDistal"} {
+		bone, ok := humanBones[name]
+		if !ok {
+			t.Errorf("expected human bone %q", name)
+			continue
+		}
+		if bone.Node >= len(doc.Nodes) || doc.Nodes[bone.Node].Name != name {
+			t.Errorf("expected %q to map to its node, got %d", name, bone.Node)
+		}
+	}
+
+	if len(doc.Animations) != 1 {
+		t.Fatalf("expected 1 animation, got %d", len(doc.Animations))
+	}
+	anim := doc.Animations[0]
+	if len(anim.Channels) != len(doc.Nodes) {
+		t.Errorf("expected a channel per node (%d), got %d", len(doc.Nodes), len(anim.Channels))
+	}
+	for _, sampler := range anim.Samplers {
+		input, output := doc.Accessors[sampler.Input], doc.Accessors[sampler.Output]
+		if input.Count != 5 || output.Count != 5 {
+			t.Fatalf("expected 5 keyframes, got input %d, output %d", input.Count, output.Count)
+		}
+		if output.Type != "VEC4" {
+			t.Errorf("expected VEC4 rotations, got %s", output.Type)
+		}
+	}
+	if input := doc.Accessors[0]; len(input.Max) != 1 || input.Max[0] < 0.13 || input.Max[0] > 0.133 {
+		t.Errorf("expected last key time 0.132, got %v", input.Max)
+	}
+
+	// Binary chunk
+	binStart := 20 + jsonLength
+	binLength := int(binary.LittleEndian.Uint32(data[binStart : binStart+4]))
+	if chunkType := binary.LittleEndian.Uint32(data[binStart+4 : binStart+8]); chunkType != glbChunkBIN {
+		t.Errorf("expected BIN chunk, got %x", chunkType)
+	}
+	if binLength < doc.Buffers[0].ByteLength {
+		t.Errorf("expected BIN chunk of at least %d bytes, got %d", doc.Buffers[0].ByteLength, binLength)
+	}
+}
+
+func TestExportVRMAEmpty(t *testing.T) {
+	var buf bytes.Buffer
+	if err := ExportVRMA(nil, &buf); err == nil {
+		t.Error("expected error with no frames")
+	}
+}
+
+func TestFingerRotationsStraightHand(t *testing.T) {
+	// All landmarks along one line: every finger is straight
+	hand := &HandData{Landmarks: make([]Landmark, 21)}
+	hand.Landmarks[0].Point = Point3D{X: 0.5, Y: 0.5}
+	for i := 1; i < len(hand.Landmarks); i++ {
+		joint := (i - 1) % 4 // 0 at the finger base, 3 at the tip
+		hand.Landmarks[i].Point = Point3D{X: 0.5, Y: 0.45 - 0.02*float64(joint)}
+	}
+
+	rotations := make(map[string]Quaternion)
+	fingerRotations("left", hand, rotations)
+	if len(rotations) != 15 {
+		t.Fatalf("expected 15 finger bones, got %d", len(rotations))
+	}
+	for name, q := range rotations {
+		if quatAngle(q) > 1e-6 {
+			t.Errorf("%s: expected identity for a straight finger, got %+v", name, q)
+		}
+	}
+}